@@ -19,6 +19,7 @@ import (
 
 	"github.com/GuanceCloud/cliutils"
 	"github.com/GuanceCloud/cliutils/logger"
+	"github.com/GuanceCloud/cliutils/point"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/checkutil"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/cmds"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/confd"
@@ -35,6 +36,7 @@ import (
 	plRemote "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/pipeline/remote"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
 	_ "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs/all"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/recorder"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/resourcelimit"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/service"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/usagetrace"
@@ -227,6 +229,7 @@ func startIO() {
 		dkio.WithDataway(config.Cfg.Dataway),
 		dkio.WithCompactAt(c.MaxCacheCount),
 		dkio.WithFilters(c.Filters),
+		dkio.WithSinks(c.Sinks),
 		dkio.WithCompactWorkers(c.CompactWorkers),
 		dkio.WithRecorder(config.Cfg.Recorder),
 		dkio.WithRemoteJob(config.Cfg.RemoteJob, config.Cfg.Dataway),
@@ -237,6 +240,17 @@ func startIO() {
 	dkio.Start(opts...)
 }
 
+// startReplay feed recorded data through IO if replay enabled.
+func startReplay() {
+	feeder := dkio.DefaultFeeder()
+	if err := recorder.StartReplay(config.Cfg.Recorder,
+		func(cat point.Category, pts []*point.Point, input string) error {
+			return feeder.Feed(cat, pts, dkio.WithSource(input))
+		}); err != nil {
+		l.Errorf("recorder.StartReplay: %s, ignored", err)
+	}
+}
+
 func startDatawayWorkers() {
 	dw := config.Cfg.Dataway
 
@@ -299,6 +313,7 @@ func doRun() error {
 
 	startDatawayWorkers()
 	startIO()
+	startReplay()
 
 	// start NTP syncer on dataway.
 	if n := config.Cfg.Dataway.NTP; n != nil && n.Enable {
@@ -323,7 +338,26 @@ func doRun() error {
 			election.WithNamespace(config.Cfg.Election.Namespace),
 		}
 
-		if err := config.Cfg.Operator.Ping(); err == nil {
+		var (
+			backend    election.Backend
+			backendErr error
+		)
+		if config.Cfg.Election.Enable {
+			backend, backendErr = config.Cfg.Election.NewBackend()
+		}
+
+		if backendErr != nil {
+			// do not elect on other backend, or multiple leaders may run at the same time
+			l.Errorf("invalid election backend %q, election inputs keep paused: %s",
+				config.Cfg.Election.Backend, backendErr)
+			electionsOpts = append(electionsOpts, election.WithBackendError(backendErr))
+		} else if backend != nil {
+			electionsOpts = append(electionsOpts,
+				election.WithBackend(backend),
+				election.WithLeaseDuration(config.Cfg.Election.LeaseDuration),
+				election.WithRenewInterval(config.Cfg.Election.RenewInterval),
+			)
+		} else if err := config.Cfg.Operator.Ping(); err == nil {
 			l.Infof("datakit-operator connection successed.")
 			electionsOpts = append(electionsOpts, election.WithOperatorPuller(config.Cfg.Operator))
 		} else {
@@ -21,6 +21,9 @@ func LoadAllManifests() error {
 	if globalManifests.K8sManifest, err = loadManifest("manifests/k8s.toml"); err != nil {
 		return err
 	}
+	if globalManifests.HostManifest, err = loadManifest("manifests/host.toml"); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -33,7 +36,11 @@ func MustLoadAllManifest() *Manifests {
 		m.K8sManifest = x
 	}
 
-	// TODO: add more
+	if x, err := loadManifest("manifests/host.toml"); err != nil {
+		panic(fmt.Sprintf("loadManifest.host: %s", err.Error()))
+	} else {
+		m.HostManifest = x
+	}
 
 	return m
 }
@@ -56,8 +63,18 @@ func RenderK8sTemplate(language Language, changeID ChangeID, data interface{}) (
 	if globalManifests.K8sManifest == nil {
 		return "", "", fmt.Errorf("k8s manifest not initialized")
 	}
+	return renderManifest(globalManifests.K8sManifest, language, changeID, data)
+}
+
+func RenderHostTemplate(language Language, changeID ChangeID, data interface{}) (title string, message string, err error) {
+	if globalManifests.HostManifest == nil {
+		return "", "", fmt.Errorf("host manifest not initialized")
+	}
+	return renderManifest(globalManifests.HostManifest, language, changeID, data)
+}
 
-	for _, change := range globalManifests.K8sManifest.Changes {
+func renderManifest(manifest *Manifest, language Language, changeID ChangeID, data interface{}) (title string, message string, err error) {
+	for _, change := range manifest.Changes {
 		if change.ID != string(changeID) {
 			continue
 		}
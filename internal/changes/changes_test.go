@@ -14,6 +14,9 @@ import (
 func TestLoadManifest(t *testing.T) {
 	_, err := loadManifest("manifests/k8s.toml")
 	assert.NoError(t, err)
+
+	_, err = loadManifest("manifests/host.toml")
+	assert.NoError(t, err)
 }
 
 func TestRenderK8sTemplate(t *testing.T) {
@@ -54,3 +57,38 @@ New Value: {{.NewValue}}`},
 	assert.Equal(t, "Deployment Changed", title)
 	t.Logf(message)
 }
+
+func TestRenderHostTemplate(t *testing.T) {
+	assert.NoError(t, LoadAllManifests())
+
+	data := struct {
+		Host               string
+		Source             string
+		OldValue, NewValue string
+		ChangeValueList    string
+	}{
+		Host:            "host-01",
+		Source:          "dpkg",
+		ChangeValueList: "- Add: nginx = 1.18.0",
+	}
+
+	title, message, err := RenderHostTemplate(LangEn, HostPackageInstall, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Host host-01 Package Installed", title)
+	assert.Equal(t, "New packages have been installed on host host-01 (dpkg)\nChange details:\n- Add: nginx = 1.18.0", message)
+
+	// all host change IDs should be defined in manifest
+	for _, id := range []ChangeID{
+		HostPackageInstall, HostPackageRemove, HostPackageUpgrade,
+		HostPortOpen, HostPortClose,
+		HostUserAdd, HostUserDelete, HostUserModify, HostGroupAdd, HostGroupDelete, HostGroupModify,
+		HostCrontab,
+		HostKernelRelease, HostKernelCmdline, HostSysctl,
+	} {
+		_, _, err := RenderHostTemplate(LangZh, id, data)
+		assert.NoError(t, err, "change ID %s", id)
+	}
+
+	_, _, err = RenderHostTemplate(LangEn, PodTemplateImage, data)
+	assert.Error(t, err)
+}
@@ -42,4 +42,59 @@ var (
 	StatefulSetLabels      ChangeID = "k8s_change_04_03"
 	StatefulSetAnnotations ChangeID = "k8s_change_04_04"
 	StatefulSetReplicas    ChangeID = "k8s_change_04_05"
+
+	IngressCreate      ChangeID = "k8s_change_05_01"
+	IngressDelete      ChangeID = "k8s_change_05_02"
+	IngressLabels      ChangeID = "k8s_change_05_03"
+	IngressAnnotations ChangeID = "k8s_change_05_04"
+	IngressRules       ChangeID = "k8s_change_05_05"
+
+	HPACreate      ChangeID = "k8s_change_06_01"
+	HPADelete      ChangeID = "k8s_change_06_02"
+	HPALabels      ChangeID = "k8s_change_06_03"
+	HPAAnnotations ChangeID = "k8s_change_06_04"
+	HPAReplicas    ChangeID = "k8s_change_06_05"
+	HPAScaleTarget ChangeID = "k8s_change_06_06"
+
+	NamespaceCreate      ChangeID = "k8s_change_07_01"
+	NamespaceDelete      ChangeID = "k8s_change_07_02"
+	NamespaceLabels      ChangeID = "k8s_change_07_03"
+	NamespaceAnnotations ChangeID = "k8s_change_07_04"
+
+	ResourceQuotaCreate ChangeID = "k8s_change_08_01"
+	ResourceQuotaDelete ChangeID = "k8s_change_08_02"
+	ResourceQuotaHard   ChangeID = "k8s_change_08_03"
+
+	LimitRangeCreate ChangeID = "k8s_change_09_01"
+	LimitRangeDelete ChangeID = "k8s_change_09_02"
+	LimitRangeLimits ChangeID = "k8s_change_09_03"
+
+	PDBCreate ChangeID = "k8s_change_10_01"
+	PDBDelete ChangeID = "k8s_change_10_02"
+	PDBBudget ChangeID = "k8s_change_10_03"
+)
+
+var (
+	//
+	// DO NOT MODIFY - Strongly linked to host change manifest.
+	//
+	HostPackageInstall ChangeID = "host_change_01_01"
+	HostPackageRemove  ChangeID = "host_change_01_02"
+	HostPackageUpgrade ChangeID = "host_change_01_03"
+
+	HostPortOpen  ChangeID = "host_change_02_01"
+	HostPortClose ChangeID = "host_change_02_02"
+
+	HostUserAdd     ChangeID = "host_change_03_01"
+	HostUserDelete  ChangeID = "host_change_03_02"
+	HostUserModify  ChangeID = "host_change_03_03"
+	HostGroupAdd    ChangeID = "host_change_03_04"
+	HostGroupDelete ChangeID = "host_change_03_05"
+	HostGroupModify ChangeID = "host_change_03_06"
+
+	HostCrontab ChangeID = "host_change_04_01"
+
+	HostKernelRelease ChangeID = "host_change_05_01"
+	HostKernelCmdline ChangeID = "host_change_05_02"
+	HostSysctl        ChangeID = "host_change_05_03"
 )
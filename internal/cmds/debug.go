@@ -69,6 +69,14 @@ func runDebugFlags() error {
 		}
 		return nil
 
+	case *flagDebugConfd:
+		tryLoadMainCfg()
+		if err := confdDryRun(); err != nil {
+			cp.Errorf("[E] %s\n", err)
+			return err
+		}
+		return nil
+
 	case *flagDebugLoadLog:
 		tryLoadMainCfg()
 		cp.Infof("Upload log start...\n")
@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package cmds
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	cp "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/colorprint"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/confd"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/config"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+)
+
+// confdDryRun shows the merged result of all confd backends configured in
+// datakit.conf, nothing applied to the running DataKit.
+func confdDryRun() error {
+	if !config.ConfdEnabled() {
+		return fmt.Errorf("no confd backend enabled in datakit.conf")
+	}
+
+	res := confd.DryRun(config.Cfg.Confds)
+
+	for _, err := range res.Errors {
+		cp.Warnf("[W] %s\n", err)
+	}
+
+	if len(res.Backends) == 0 {
+		return fmt.Errorf("no confd backend available")
+	}
+
+	cp.Printf("============= backends(by precedence) ============\n")
+	for _, b := range res.Backends {
+		cp.Printf("%s\n", b)
+	}
+
+	cp.Printf("\n============= inputs ============\n")
+	for _, v := range res.Inputs {
+		showMergedValue(v)
+
+		if ipts, err := config.LoadSingleConf(v.Value, inputs.AllInputs); err != nil {
+			cp.Errorf("  invalid input conf: %s\n", err)
+		} else {
+			var arr []string
+			for k, x := range ipts {
+				arr = append(arr, fmt.Sprintf("%s(%d)", k, len(x)))
+			}
+			sort.Strings(arr)
+			cp.Printf("  inputs: %s\n", strings.Join(arr, ", "))
+		}
+
+		cp.Printf("%s\n\n", indentText(v.Value))
+	}
+
+	cp.Printf("\n============= pipelines ============\n")
+	for _, v := range res.Pipelines {
+		showMergedValue(v)
+		cp.Printf("%s\n\n", indentText(v.Value))
+	}
+
+	cp.Printf("\n============= summary ============\n")
+	cp.Printf("Total inputs configures: %d\n", len(res.Inputs))
+	cp.Printf("Total pipeline scripts: %d\n", len(res.Pipelines))
+
+	return nil
+}
+
+func showMergedValue(v *confd.MergedValue) {
+	cp.Infof("%s", v.Key)
+	cp.Printf(" <- %s(priority: %d)\n", v.Backend, v.Priority)
+
+	if len(v.Shadowed) > 0 {
+		cp.Warnf("  shadowed: %s\n", strings.Join(v.Shadowed, ", "))
+	}
+}
+
+func indentText(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i := range lines {
+		lines[i] = "  | " + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
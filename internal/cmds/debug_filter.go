@@ -59,24 +59,32 @@ func debugFilter(filterConf, data []byte) error {
 
 	start := time.Now()
 	for k, v := range f.Filters {
-		conds, err := filter.GetConds(v)
-		if err != nil {
-			return fmt.Errorf("invalid filter rule: %w", err)
-		}
+		cat := point.CatString(k)
 
-		var tfdatas []*filter.KVs
 		for _, pt := range pts {
-			kvs := &filter.KVs{}
-			kvs.Setup(point.CatString(k), pt)
-			tfdatas = append(tfdatas, kvs)
-		}
+			for j, x := range v {
+				action, matched, err := filter.MatchRule(x, cat, pt)
+				if err != nil {
+					return fmt.Errorf("invalid filter rule: %w", err)
+				}
+
+				if !matched {
+					continue
+				}
+
+				if action == "drop" {
+					cp.Infof("Dropped\n\n")
+				} else {
+					cp.Infof("Matched(%s)\n\n", action)
+				}
+
+				cp.Printf("\t%s\n\n", pt.LineProto())
+				cp.Infof("By %dth rule(cost %s) from category %q:\n\n", j+1, time.Since(start), cat)
+				cp.Printf("\t%+s\n", x)
 
-		for i, tfdata := range tfdatas {
-			if j := conds.Eval(tfdata); j >= 0 {
-				cp.Infof("Dropped\n\n")
-				cp.Printf("\t%s\n\n", pts[i].LineProto())
-				cp.Infof("By %dth rule(cost %s) from category %q:\n\n", j+1, time.Since(start), point.CatString(k))
-				cp.Printf("\t%+s\n", v[j])
+				if action == "drop" {
+					break
+				}
 			}
 		}
 	}
@@ -37,11 +37,11 @@ func (u *uploaderImpl) upload(pts []*point.Point, cat point.Category) error {
 	return u.dw.Write(dataway.WithPoints(pts),
 		dataway.WithCategory(cat),
 		dataway.WithNoWAL(true), // upload to dataway directly
-		// gzip the body during building body.
+		// compress the body during building body.
 		//
-		// dataway's default GZip is true, so we must gzip the body within body-building.
-		// or the endpoint HTTP POST will set gzip header.
-		dataway.WithGzipDuringBuildBody(true),
+		// we post the body to dataway directly(without WAL), so we must compress
+		// the body within body-building.
+		dataway.WithCompressDuringBuildBody(true),
 	)
 }
 
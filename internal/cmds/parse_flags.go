@@ -204,6 +204,7 @@ var (
 	flagDebugFilter     = fsDebug.String("filter", "", "filter configure file(JSON)")
 	flagDebugData       = fsDebug.String("data", "", "data used during debugging")
 	flagDebugKVFile     = fsDebug.String("kv-file", "", "kv file path")
+	flagDebugConfd      = fsDebug.Bool("confd-dry-run", false, "show the merged result of all confd backends without applying it")
 
 	fsDebugUsage = func() {
 		cp.Printf("usage: datakit debug [options]\n\n")
@@ -242,6 +243,17 @@ var (
 	flagToolChangeDockerContainersRuntime = fsTool.String("change-docker-containers-runtime", "",
 		"change the runtime of the created container, the value is runc or dk-runc")
 
+	flagToolWAL            = fsTool.Bool("wal", false, "inspect Dataway WAL and fail-cache, list queue sizes if no other WAL options set")
+	flagToolWALPath        = fsTool.String("wal-path", "", "WAL path, default to the WAL path within datakit.conf")
+	flagToolWALCategory    = fsTool.StringSlice("wal-category", nil, "WAL queues to inspect, such as metric,logging,fc")
+	flagToolWALMeasurement = fsTool.StringSlice("wal-measurement", nil, "only select points of these measurements")
+	flagToolWALStart       = fsTool.String("wal-start", "", "only select points after the time(RFC3339, such as 2024-01-02T15:04:05+08:00)")
+	flagToolWALEnd         = fsTool.String("wal-end", "", "only select points before the time(RFC3339)")
+	flagToolWALShow        = fsTool.Bool("wal-show", false, "show selected points")
+	flagToolWALExport      = fsTool.String("wal-export", "", "export selected points to file(*.lp in line-protocol, or in protobuf-json)")
+	flagToolWALReplay      = fsTool.Bool("wal-replay", false, "POST selected entries to Dataway within datakit.conf")
+	flagToolWALPurge       = fsTool.Bool("wal-purge", false, "purge selected entries(DataKit should be stopped), with --wal-replay, only purge replayed entries")
+
 	fsToolUsage = func() {
 		cp.Printf("usage: datakit tool [options]\n\n")
 		cp.Printf("Various tools for DataKit\n\n")
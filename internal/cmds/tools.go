@@ -40,6 +40,13 @@ func runToolFlags() error {
 		setupCompleterScripts()
 		os.Exit(0)
 
+	case *flagToolWAL:
+		if err := runWALTool(); err != nil {
+			cp.Errorf("[E] %s\n", err.Error())
+			os.Exit(-1)
+		}
+		os.Exit(0)
+
 	case *flagToolCompleterScripts:
 		showCompletionScripts()
 		os.Exit(0)
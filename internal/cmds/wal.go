@@ -0,0 +1,273 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package cmds
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/dustin/go-humanize"
+
+	cp "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/colorprint"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/config"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/dataway"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/recorder"
+)
+
+// walSelector select WAL entries on measurement and point time.
+type walSelector struct {
+	measurements map[string]bool
+	start, end   time.Time
+}
+
+func newWALSelector(measurements []string, start, end string) (*walSelector, error) {
+	s := &walSelector{}
+
+	for _, m := range measurements {
+		if s.measurements == nil {
+			s.measurements = map[string]bool{}
+		}
+		s.measurements[m] = true
+	}
+
+	var err error
+	if start != "" {
+		if s.start, err = time.Parse(time.RFC3339, start); err != nil {
+			return nil, fmt.Errorf("invalid start time: %w", err)
+		}
+	}
+
+	if end != "" {
+		if s.end, err = time.Parse(time.RFC3339, end); err != nil {
+			return nil, fmt.Errorf("invalid end time: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// all check if all entries selected, we need not to decode the entry.
+func (s *walSelector) all() bool {
+	return len(s.measurements) == 0 && s.start.IsZero() && s.end.IsZero()
+}
+
+func (s *walSelector) match(pt *point.Point) bool {
+	if len(s.measurements) > 0 && !s.measurements[pt.Name()] {
+		return false
+	}
+
+	if !s.start.IsZero() && pt.Time().Before(s.start) {
+		return false
+	}
+
+	if !s.end.IsZero() && pt.Time().After(s.end) {
+		return false
+	}
+
+	return true
+}
+
+// selectEntry get selected points within the entry. The entry selected if
+// any of it's point selected.
+func (s *walSelector) selectEntry(e *dataway.WALEntry) ([]*point.Point, error) {
+	pts, err := e.Points()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*point.Point
+	for _, pt := range pts {
+		if s.match(pt) {
+			res = append(res, pt)
+		}
+	}
+
+	return res, nil
+}
+
+func walToolPath() string {
+	if *flagToolWALPath != "" {
+		return *flagToolWALPath
+	}
+
+	if dw := config.Cfg.Dataway; dw != nil && dw.WAL != nil && dw.WAL.Path != "" {
+		return dw.WAL.Path
+	}
+
+	return filepath.Join(datakit.CacheDir, "dw-wal")
+}
+
+// walQueues list WAL queues(such as metric/logging/fc) under WAL path.
+func walQueues(walPath string, names []string) ([]string, error) {
+	if len(names) > 0 {
+		return names, nil
+	}
+
+	des, err := os.ReadDir(walPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var arr []string
+	for _, de := range des {
+		if de.IsDir() && !strings.HasSuffix(de.Name(), ".purging") {
+			arr = append(arr, de.Name())
+		}
+	}
+
+	sort.Strings(arr)
+	return arr, nil
+}
+
+func runWALTool() error {
+	tryLoadMainCfg()
+
+	walPath := walToolPath()
+
+	queues, err := walQueues(walPath, *flagToolWALCategory)
+	if err != nil {
+		return err
+	}
+
+	if !*flagToolWALShow && *flagToolWALExport == "" && !*flagToolWALReplay && !*flagToolWALPurge {
+		return showWALStats(walPath, queues)
+	}
+
+	sel, err := newWALSelector(*flagToolWALMeasurement, *flagToolWALStart, *flagToolWALEnd)
+	if err != nil {
+		return err
+	}
+
+	var dw *dataway.Dataway
+	if *flagToolWALReplay {
+		dw = config.Cfg.Dataway
+		if err := dw.Init(); err != nil {
+			return err
+		}
+	}
+
+	var exported []*point.Point
+
+	for _, q := range queues {
+		dir := filepath.Join(walPath, q)
+		selected, replayed := 0, 0
+
+		// apply on each entry, returns true if the entry selected(and replayed ok).
+		fn := func(e *dataway.WALEntry) bool {
+			var pts []*point.Point
+
+			if !sel.all() || *flagToolWALShow || *flagToolWALExport != "" {
+				x, err := sel.selectEntry(e)
+				if err != nil {
+					cp.Warnf("[W] decode %s: %s, ignored\n", e, err)
+					return false
+				}
+
+				if len(x) == 0 {
+					return false
+				}
+
+				pts = x
+			}
+
+			selected++
+
+			if *flagToolWALShow {
+				cp.Infof("%s\n", e)
+				for _, pt := range pts {
+					cp.Printf("%s\n", pt.LineProto())
+				}
+			}
+
+			exported = append(exported, pts...)
+
+			if dw != nil {
+				if err := dw.ReplayWALEntry(e); err != nil {
+					cp.Warnf("[W] replay %s: %s\n", e, err)
+					return false
+				}
+				replayed++
+			}
+
+			return true
+		}
+
+		if *flagToolWALPurge {
+			n, err := dataway.PurgeWAL(dir, fn)
+			if err != nil {
+				return fmt.Errorf("purge %q: %w", dir, err)
+			}
+
+			cp.Infof("%s: %d entries purged\n", q, n)
+		} else {
+			if err := dataway.ScanWAL(dir, func(e *dataway.WALEntry) error {
+				fn(e)
+				return nil
+			}); err != nil {
+				return fmt.Errorf("scan %q: %w", dir, err)
+			}
+		}
+
+		cp.Infof("%s: %d entries selected", q, selected)
+		if dw != nil {
+			cp.Infof(", %d replayed", replayed)
+		}
+		cp.Printf("\n")
+	}
+
+	if *flagToolWALExport != "" {
+		if err := exportWALPoints(*flagToolWALExport, exported); err != nil {
+			return err
+		}
+
+		cp.Infof("%d points exported to %q\n", len(exported), *flagToolWALExport)
+	}
+
+	return nil
+}
+
+func showWALStats(walPath string, queues []string) error {
+	cp.Printf("WAL: %s\n", walPath)
+	cp.Printf("%-36s %8s %12s %10s %12s\n", "Queue", "Files", "Size", "Entries", "Points")
+
+	for _, q := range queues {
+		st, err := dataway.StatWAL(filepath.Join(walPath, q))
+		if err != nil {
+			cp.Warnf("[W] %s: %s\n", q, err)
+			continue
+		}
+
+		cp.Printf("%-36s %8d %12s %10d %12d\n", q, st.Files, humanize.IBytes(uint64(st.Bytes)), st.Entries, st.Points)
+	}
+
+	return nil
+}
+
+func exportWALPoints(file string, pts []*point.Point) error {
+	var data []byte
+
+	if filepath.Ext(file) == recorder.ExtLineProtocol {
+		var arr []string
+		for _, pt := range pts {
+			arr = append(arr, pt.LineProto())
+		}
+		data = []byte(strings.Join(arr, "\n"))
+	} else {
+		x, err := recorder.Pts2PBJson(pts)
+		if err != nil {
+			return err
+		}
+		data = x
+	}
+
+	return os.WriteFile(file, data, datakit.ConfPerm)
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package cmds
+
+import (
+	"os"
+	"path/filepath"
+	T "testing"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/recorder"
+)
+
+func TestWALSelector(t *T.T) {
+	t.Run(`all`, func(t *T.T) {
+		s, err := newWALSelector(nil, "", "")
+		require.NoError(t, err)
+		assert.True(t, s.all())
+		assert.True(t, s.match(point.NewPoint("cpu", nil)))
+	})
+
+	t.Run(`measurement-and-time`, func(t *T.T) {
+		s, err := newWALSelector([]string{"cpu", "mem"}, "2024-01-01T00:00:00Z", "2024-01-02T00:00:00Z")
+		require.NoError(t, err)
+		assert.False(t, s.all())
+
+		in := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		out := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+		assert.True(t, s.match(point.NewPoint("cpu", nil, point.WithTime(in))))
+		assert.False(t, s.match(point.NewPoint("cpu", nil, point.WithTime(out))))
+		assert.False(t, s.match(point.NewPoint("disk", nil, point.WithTime(in))))
+	})
+
+	t.Run(`invalid-time`, func(t *T.T) {
+		_, err := newWALSelector(nil, "2024-01-01", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestExportWALPoints(t *T.T) {
+	pts := []*point.Point{
+		point.NewPoint("cpu", point.NewKVs(map[string]any{"f1": 1}), point.WithTime(time.Unix(0, 123))),
+		point.NewPoint("mem", point.NewKVs(map[string]any{"f1": 2}), point.WithTime(time.Unix(0, 456))),
+	}
+
+	dir := t.TempDir()
+
+	t.Run(`lp`, func(t *T.T) {
+		f := filepath.Join(dir, "wal"+recorder.ExtLineProtocol)
+		require.NoError(t, exportWALPoints(f, pts))
+
+		x, err := os.ReadFile(f)
+		require.NoError(t, err)
+		assert.Equal(t, "cpu f1=1i 123\nmem f1=2i 456", string(x))
+	})
+
+	t.Run(`pbjson`, func(t *T.T) {
+		f := filepath.Join(dir, "wal"+recorder.ExtPBJson)
+		require.NoError(t, exportWALPoints(f, pts))
+
+		x, err := os.ReadFile(f)
+		require.NoError(t, err)
+
+		got, err := recorder.PBJson2pts(x)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, "mem", got[1].Name())
+	})
+}
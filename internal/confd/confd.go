@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -33,7 +34,6 @@ const (
 	Lazy            = 2                                         // Delay execution time (seconds).
 	Timeout         = 20                                        // Confd Execute in case of blocking, Timeout seconds.
 	NameSpace       = "confd"                                   // Name space for pipeline.
-	OnlyOneBackend  = true                                      // Only one backend configuration takes effect.
 	AllowedBackends = "nacos consul zookeeper etcdv3 redis aws" // Only some backend name allowed.
 )
 
@@ -42,17 +42,29 @@ type clientStruct struct {
 	client     backends.StoreClient
 	backend    string // Backend type.
 	prefixKind string // Like "confd" or "pipeline".
+	name       string // Backend name, default to <backend>-<index>.
+	priority   int    // Backend priority, the higher one takes effect on the same key.
+	index      int    // Index of the backend in datakit.conf.
 }
 
 var (
 	clientConfds []clientStruct                 // Confd backends list.
 	gotConfdCh   chan string                    // WatchPrefix find confd or pipeline data.
 	confdInputs  map[string][]*inputs.ConfdInfo // Total confd data list got from all backends.
-	prefix       map[string]string              // Like prefix["confd"]="/datakit/confd", prefix["pipeline"]="/datakit/pipeline".
 	doOnce       sync.Once
 	isFirst      = true
 	l            = logger.DefaultSLogger("confd")
 
+	// Key prefix of confd and pipeline data.
+	prefix = map[string]string{
+		"confd":    "/datakit/confd",
+		"pipeline": "/datakit/pipeline",
+	}
+
+	// Merged data of last round, used to log the changes.
+	lastConfdValues    map[string]*MergedValue
+	lastPipelineValues map[string]*MergedValue
+
 	confds []*config.ConfdCfg
 )
 
@@ -74,10 +86,6 @@ func confdMain() error {
 	// Signal, if confd watchPrefix find New data. Like "confd" or "pipeline"
 	gotConfdCh = make(chan string, 10)
 
-	prefix = make(map[string]string)
-	prefix["confd"] = "/datakit/confd"
-	prefix["pipeline"] = "/datakit/pipeline"
-
 	// Init all confd backend clients.
 	clientConfds = make([]clientStruct, 0)
 	if err := creatClients(); err != nil {
@@ -116,83 +124,105 @@ func confdMain() error {
 }
 
 func creatClients() error {
-	length := len(confds)
-	if OnlyOneBackend && len(confds) > 1 {
-		l.Errorf("too many confd backend configuration. Only one backend configuration takes effect")
-		length = 1
+	clientConfds = buildClients(confds, creatClient)
+
+	if len(clientConfds) == 0 {
+		l.Errorf("used confd, but no backends")
+		return errors.New("used confd, but no backends")
 	}
+	return nil
+}
+
+// buildClients creates clients for all enabled backends, all of them take effect
+// at the same time.
+func buildClients(arr []*config.ConfdCfg, create func(backends.Config) backends.StoreClient) []clientStruct {
+	clients := make([]clientStruct, 0)
 
-	for i := 0; i < length; i++ {
-		if !strings.Contains(AllowedBackends, confds[i].Backend) {
-			l.Errorf("confd backend name not be allowed : %s", confds[i].Backend)
+	for i, c := range arr {
+		if !strings.Contains(AllowedBackends, c.Backend) {
+			l.Errorf("confd backend name not be allowed : %s", c.Backend)
 			continue
 		}
-		if !confds[i].Enable {
+		if !c.Enable {
 			continue
 		}
 
-		// Backends config
-		cfg := backends.Config{
-			AuthToken:      confds[i].AuthToken,
-			AuthType:       confds[i].AuthType,
-			Backend:        confds[i].Backend,
-			BasicAuth:      confds[i].BasicAuth,
-			ClientCaKeys:   confds[i].ClientCaKeys,
-			ClientCert:     confds[i].ClientCert,
-			ClientKey:      confds[i].ClientKey,
-			ClientInsecure: confds[i].ClientInsecure,
-			BackendNodes:   confds[i].BackendNodes,
-			Password:       confds[i].Password,
-			Scheme:         confds[i].Scheme,
-			Table:          confds[i].Table,
-			Separator:      confds[i].Separator,
-			Username:       confds[i].Username,
-			AppID:          confds[i].AppID,
-			UserID:         confds[i].UserID,
-			RoleID:         confds[i].RoleID,
-			SecretID:       confds[i].SecretID,
-			YAMLFile:       confds[i].YAMLFile,
-			Filter:         confds[i].Filter,
-			Path:           confds[i].Path,
-			Role:           confds[i].Role,
-			AccessKey:      confds[i].AccessKey,
-			SecretKey:      confds[i].SecretKey,
-			CircleInterval: confds[i].CircleInterval,
-			Region:         confds[i].Region,
+		cfg := backendConfig(c)
+		cs := clientStruct{
+			backend:  c.Backend,
+			name:     backendName(c, i),
+			priority: c.Priority,
+			index:    i,
 		}
 
 		// Creat 1 backend client.
-		if confds[i].Backend == "nacos" {
+		if c.Backend == "nacos" {
 			// Nacos backend.
-			if confds[i].ConfdNamespace != "" {
-				cfg.Namespace = confds[i].ConfdNamespace
-				client := creatClient(cfg)
-				if client != nil {
-					clientConfds = append(clientConfds, clientStruct{client, confds[i].Backend, "confd"})
+			if c.ConfdNamespace != "" {
+				cfg.Namespace = c.ConfdNamespace
+				if client := create(cfg); client != nil {
+					cs.client, cs.prefixKind = client, "confd"
+					clients = append(clients, cs)
 				}
 			}
-			if confds[i].ConfdNamespace != "" {
-				cfg.Namespace = confds[i].PipelineNamespace
-				client := creatClient(cfg)
-				if client != nil {
-					clientConfds = append(clientConfds, clientStruct{client, confds[i].Backend, "pipeline"})
+			if c.ConfdNamespace != "" {
+				cfg.Namespace = c.PipelineNamespace
+				if client := create(cfg); client != nil {
+					cs.client, cs.prefixKind = client, "pipeline"
+					clients = append(clients, cs)
 				}
 			}
 		} else {
 			// Others backends.
-			client := creatClient(cfg)
-			if client != nil {
-				clientConfds = append(clientConfds, clientStruct{client, confds[i].Backend, "confd"})
-				clientConfds = append(clientConfds, clientStruct{client, confds[i].Backend, "pipeline"})
+			if client := create(cfg); client != nil {
+				cs.client = client
+				cs.prefixKind = "confd"
+				clients = append(clients, cs)
+				cs.prefixKind = "pipeline"
+				clients = append(clients, cs)
 			}
 		}
 	}
 
-	if len(clientConfds) == 0 {
-		l.Errorf("used confd, but no backends")
-		return errors.New("used confd, but no backends")
+	return clients
+}
+
+func backendName(c *config.ConfdCfg, index int) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fmt.Sprintf("%s-%d", c.Backend, index)
+}
+
+func backendConfig(c *config.ConfdCfg) backends.Config {
+	return backends.Config{
+		AuthToken:      c.AuthToken,
+		AuthType:       c.AuthType,
+		Backend:        c.Backend,
+		BasicAuth:      c.BasicAuth,
+		ClientCaKeys:   c.ClientCaKeys,
+		ClientCert:     c.ClientCert,
+		ClientKey:      c.ClientKey,
+		ClientInsecure: c.ClientInsecure,
+		BackendNodes:   c.BackendNodes,
+		Password:       c.Password,
+		Scheme:         c.Scheme,
+		Table:          c.Table,
+		Separator:      c.Separator,
+		Username:       c.Username,
+		AppID:          c.AppID,
+		UserID:         c.UserID,
+		RoleID:         c.RoleID,
+		SecretID:       c.SecretID,
+		YAMLFile:       c.YAMLFile,
+		Filter:         c.Filter,
+		Path:           c.Path,
+		Role:           c.Role,
+		AccessKey:      c.AccessKey,
+		SecretKey:      c.SecretKey,
+		CircleInterval: c.CircleInterval,
+		Region:         c.Region,
 	}
-	return nil
 }
 
 func creatClient(cfg backends.Config) backends.StoreClient {
@@ -304,9 +334,19 @@ func watchConfd(c *clientStruct, stopCh chan bool) {
 }
 
 func doConfdData() {
+	data, err := getConfdData()
+	if err != nil {
+		// Keep current inputs, or the inputs from the failed backend will be deleted.
+		l.Warnf("skip applying confd data: %v", err)
+		return
+	}
+
+	merged := mergeValues(data)
+	logChanges("confd", lastConfdValues, merged)
+	lastConfdValues = merged
+
 	confdInputs = make(map[string][]*inputs.ConfdInfo)
-	data := getConfdData()
-	handleConfdData(data)
+	handleConfdData(mergedToData(merged))
 	// Execute collector comparison, addition, deletion and modification.
 	l.Debug("before run CompareInputs from confd ")
 	inputs.CompareInputs(confdInputs, config.Cfg.DefaultEnabledInputs)
@@ -333,29 +373,46 @@ func doConfdData() {
 }
 
 // getConfdData get all confd data form backends.
-func getConfdData() []map[string]string {
-	// Traverse and reads all data sources to get the latest configuration set.
-	prefixKind := "confd"
+func getConfdData() ([]*backendValues, error) {
+	return getValues(clientConfds, "confd")
+}
 
-	data := make([]map[string]string, 0)
+// getValues get all data of the prefix kind form backends.
+func getValues(clients []clientStruct, prefixKind string) ([]*backendValues, error) {
+	data := make([]*backendValues, 0)
 
 	// Traverse all backends.
-	for _, clientStru := range clientConfds {
+	for _, clientStru := range clients {
 		if clientStru.prefixKind != prefixKind {
 			continue
 		}
 
-		l.Debugf("before get values from: %v %v", prefixKind, clientStru.backend)
-		values, err := clientStru.client.GetValues([]string{prefix[prefixKind]})
+		bv, err := getBackendValues(&clientStru)
 		if err != nil {
-			l.Errorf("get values from: %v %v %v", prefixKind, clientStru.backend, err)
+			l.Errorf("%v", err)
 			time.Sleep(time.Second * 1)
 			// Any error, stop get all this loop.
-			return data
+			return nil, err
 		}
-		data = append(data, values)
+
+		data = append(data, bv)
+	}
+	return data, nil
+}
+
+func getBackendValues(c *clientStruct) (*backendValues, error) {
+	l.Debugf("before get values from: %v %v", c.prefixKind, c.name)
+	values, err := c.client.GetValues([]string{prefix[c.prefixKind]})
+	if err != nil {
+		return nil, fmt.Errorf("get %s values from %s: %w", c.prefixKind, c.name, err)
 	}
-	return data
+
+	return &backendValues{
+		backend:  c.name,
+		priority: c.priority,
+		index:    c.index,
+		values:   values,
+	}, nil
 }
 
 func handleConfdData(data []map[string]string) {
@@ -456,8 +513,19 @@ func getPipelineData() {
 		dirCategory[dirName] = category
 	}
 
+	// Get all data before rebuilding the folder, keep current scripts on any error.
+	data, err := getValues(clientConfds, "pipeline")
+	if err != nil {
+		l.Warnf("skip applying confd pipeline: %v", err)
+		return
+	}
+
+	merged := mergeValues(data)
+	logChanges("pipeline", lastPipelineValues, merged)
+	lastPipelineValues = merged
+
 	// Build pipeline top folder. If exists, remove and rebuild.
-	err := datakit.RebuildFolder(datakit.ConfdPipelineDir, datakit.ConfPerm)
+	err = datakit.RebuildFolder(datakit.ConfdPipelineDir, datakit.ConfPerm)
 	if err != nil {
 		l.Errorf("%v", err)
 		return
@@ -473,29 +541,12 @@ func getPipelineData() {
 		}
 	}
 
-	prefixKind := "pipeline"
-	// Traverse all backends.
-	for _, clientStru := range clientConfds {
-		if clientStru.prefixKind != prefixKind {
-			continue
-		}
-
-		l.Debugf("before get values from: %v %v", prefixKind, clientStru.backend)
-		values, err := clientStru.client.GetValues([]string{prefix[prefixKind]})
+	// Traverse all merged data.
+	for keyPath, v := range merged {
+		err := storeDataToDisk(keyPath, v.Value, dirCategory)
 		if err != nil {
-			l.Errorf("get values from: %v %v %v", prefixKind, clientStru.backend, err)
-			time.Sleep(time.Second * 1)
-			// Any error, stop get all this loop.
 			return
 		}
-
-		// Traverse all data in one backends.
-		for keyPath, data := range values {
-			err := storeDataToDisk(keyPath, data, dirCategory)
-			if err != nil {
-				return
-			}
-		}
 	}
 
 	// Update pipeline script.
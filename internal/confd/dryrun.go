@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package confd
+
+import (
+	"fmt"
+
+	"github.com/GuanceCloud/confd/backends"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/config"
+)
+
+// DryRunResult is the merged result of all confd backends.
+type DryRunResult struct {
+	Backends  []string       // Names of available backends, ordered by precedence.
+	Inputs    []*MergedValue // Merged input configures, ordered by key.
+	Pipelines []*MergedValue // Merged pipeline scripts, ordered by key.
+	Errors    []error        // Errors on unavailable backends.
+}
+
+// DryRun gets data from all enabled backends once and merges them, the result
+// is not applied to the running DataKit.
+func DryRun(arr []*config.ConfdCfg) *DryRunResult {
+	res := &DryRunResult{}
+
+	var created []backends.StoreClient
+	defer func() {
+		for _, c := range created {
+			c.Close()
+		}
+	}()
+
+	clients := buildClients(arr, func(cfg backends.Config) backends.StoreClient {
+		client, err := backends.New(cfg)
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Errorf("create %s client: %w", cfg.Backend, err))
+			return nil
+		}
+
+		created = append(created, client)
+		return client
+	})
+
+	var inputData, pipelineData []*backendValues
+	for i := range clients {
+		bv, err := getBackendValues(&clients[i])
+		if err != nil {
+			res.Errors = append(res.Errors, err)
+			continue
+		}
+
+		if clients[i].prefixKind == "confd" {
+			inputData = append(inputData, bv)
+		} else {
+			pipelineData = append(pipelineData, bv)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, bv := range sortedBackends(append(inputData, pipelineData...)) {
+		if !seen[bv.backend] {
+			seen[bv.backend] = true
+			res.Backends = append(res.Backends, bv.backend)
+		}
+	}
+
+	res.Inputs = sortedValues(mergeValues(inputData))
+	res.Pipelines = sortedValues(mergeValues(pipelineData))
+
+	return res
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package confd
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MergedValue is a key-value merged from all confd backends.
+type MergedValue struct {
+	Key      string
+	Value    string
+	Backend  string // Name of the backend the value comes from.
+	Priority int
+
+	// Backends that also have the key, but with lower precedence.
+	Shadowed []string
+}
+
+// backendValues is the key-values got from one backend.
+type backendValues struct {
+	backend  string
+	priority int
+	index    int // Index of the backend in datakit.conf.
+	values   map[string]string
+}
+
+// mergeValues merges key-values from all backends. If the same key exists in
+// multiple backends, the value from the backend with the highest priority takes
+// effect, and for the same priority, the backend configured first takes effect.
+func mergeValues(data []*backendValues) map[string]*MergedValue {
+	res := map[string]*MergedValue{}
+	for _, bv := range sortedBackends(data) {
+		for k, v := range bv.values {
+			if mv, ok := res[k]; ok {
+				mv.Shadowed = append(mv.Shadowed, bv.backend)
+				continue
+			}
+
+			res[k] = &MergedValue{
+				Key:      k,
+				Value:    v,
+				Backend:  bv.backend,
+				Priority: bv.priority,
+			}
+		}
+	}
+
+	return res
+}
+
+// sortedBackends returns backends ordered by precedence.
+func sortedBackends(data []*backendValues) []*backendValues {
+	sorted := make([]*backendValues, len(data))
+	copy(sorted, data)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].priority != sorted[j].priority {
+			return sorted[i].priority > sorted[j].priority
+		}
+		return sorted[i].index < sorted[j].index
+	})
+
+	return sorted
+}
+
+// sortedValues returns merged values ordered by key.
+func sortedValues(m map[string]*MergedValue) []*MergedValue {
+	arr := make([]*MergedValue, 0, len(m))
+	for _, v := range m {
+		arr = append(arr, v)
+	}
+
+	sort.Slice(arr, func(i, j int) bool {
+		return arr[i].Key < arr[j].Key
+	})
+
+	return arr
+}
+
+// diffValues compares merged values between 2 rounds, and returns the changes
+// in human readable text.
+func diffValues(last, cur map[string]*MergedValue) []string {
+	var changes []string
+
+	for _, v := range sortedValues(cur) {
+		old, ok := last[v.Key]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("add %s from %s", v.Key, v.Backend))
+		case old.Backend != v.Backend:
+			changes = append(changes, fmt.Sprintf("override %s: %s -> %s", v.Key, old.Backend, v.Backend))
+		case old.Value != v.Value:
+			changes = append(changes, fmt.Sprintf("update %s from %s", v.Key, v.Backend))
+		}
+	}
+
+	for _, v := range sortedValues(last) {
+		if _, ok := cur[v.Key]; !ok {
+			changes = append(changes, fmt.Sprintf("remove %s from %s", v.Key, v.Backend))
+		}
+	}
+
+	return changes
+}
+
+func logChanges(kind string, last, cur map[string]*MergedValue) {
+	for _, c := range diffValues(last, cur) {
+		l.Infof("confd %s changed: %s", kind, c)
+	}
+
+	for _, v := range sortedValues(cur) {
+		if len(v.Shadowed) > 0 {
+			l.Debugf("confd %s %s from %s shadows the same key in %v", kind, v.Key, v.Backend, v.Shadowed)
+		}
+	}
+}
+
+// mergedToData converts merged values to the data format used by handleConfdData.
+func mergedToData(m map[string]*MergedValue) []map[string]string {
+	values := make(map[string]string, len(m))
+	for k, v := range m {
+		values[k] = v.Value
+	}
+	return []map[string]string{values}
+}
@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package confd
+
+import (
+	"testing"
+
+	"github.com/GuanceCloud/confd/backends"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/config"
+)
+
+func TestMergeValues(t *testing.T) {
+	data := []*backendValues{
+		{
+			backend: "etcdv3-0",
+			index:   0,
+			values: map[string]string{
+				"/datakit/confd/host/cpu.conf": "cpu-from-etcd",
+				"/datakit/confd/host/mem.conf": "mem-from-etcd",
+			},
+		},
+		{
+			backend:  "team-consul",
+			priority: 10,
+			index:    1,
+			values: map[string]string{
+				"/datakit/confd/host/cpu.conf":  "cpu-from-consul",
+				"/datakit/confd/host/disk.conf": "disk-from-consul",
+			},
+		},
+		{
+			backend: "redis-2",
+			index:   2,
+			values: map[string]string{
+				"/datakit/confd/host/mem.conf": "mem-from-redis",
+			},
+		},
+	}
+
+	res := mergeValues(data)
+	require.Len(t, res, 3)
+
+	// higher priority wins
+	cpu := res["/datakit/confd/host/cpu.conf"]
+	assert.Equal(t, "cpu-from-consul", cpu.Value)
+	assert.Equal(t, "team-consul", cpu.Backend)
+	assert.Equal(t, 10, cpu.Priority)
+	assert.Equal(t, []string{"etcdv3-0"}, cpu.Shadowed)
+
+	// same priority, the first configured wins
+	mem := res["/datakit/confd/host/mem.conf"]
+	assert.Equal(t, "mem-from-etcd", mem.Value)
+	assert.Equal(t, []string{"redis-2"}, mem.Shadowed)
+
+	disk := res["/datakit/confd/host/disk.conf"]
+	assert.Equal(t, "disk-from-consul", disk.Value)
+	assert.Empty(t, disk.Shadowed)
+
+	// input data not modified
+	assert.Equal(t, "etcdv3-0", data[0].backend)
+
+	assert.Equal(t, []string{"team-consul", "etcdv3-0", "redis-2"}, func() (arr []string) {
+		for _, bv := range sortedBackends(data) {
+			arr = append(arr, bv.backend)
+		}
+		return
+	}())
+}
+
+func TestDiffValues(t *testing.T) {
+	last := map[string]*MergedValue{
+		"/a": {Key: "/a", Value: "1", Backend: "etcd"},
+		"/b": {Key: "/b", Value: "1", Backend: "etcd"},
+		"/c": {Key: "/c", Value: "1", Backend: "etcd"},
+		"/d": {Key: "/d", Value: "1", Backend: "etcd"},
+	}
+
+	cur := map[string]*MergedValue{
+		"/a": {Key: "/a", Value: "1", Backend: "etcd"},
+		"/b": {Key: "/b", Value: "2", Backend: "etcd"},
+		"/c": {Key: "/c", Value: "1", Backend: "consul"},
+		"/e": {Key: "/e", Value: "1", Backend: "consul"},
+	}
+
+	assert.Equal(t, []string{
+		"update /b from etcd",
+		"override /c: etcd -> consul",
+		"add /e from consul",
+		"remove /d from etcd",
+	}, diffValues(last, cur))
+
+	assert.Empty(t, diffValues(cur, cur))
+	assert.Len(t, diffValues(nil, cur), 4)
+}
+
+type fakeStoreClient struct {
+	values map[string]string
+}
+
+func (c *fakeStoreClient) GetValues(_ []string) (map[string]string, error) {
+	return c.values, nil
+}
+
+func (c *fakeStoreClient) WatchPrefix(_ string, _ []string, waitIndex uint64, _ chan bool) (uint64, error) {
+	return waitIndex, nil
+}
+
+func (c *fakeStoreClient) Close() {}
+
+func TestBuildClients(t *testing.T) {
+	arr := []*config.ConfdCfg{
+		{Enable: true, Backend: "etcdv3"},
+		{Enable: false, Backend: "consul"},
+		{Enable: true, Backend: "consul", Name: "team-consul", Priority: 10},
+		{Enable: true, Backend: "not-allowed"},
+		{Enable: true, Backend: "nacos", ConfdNamespace: "ns-confd", PipelineNamespace: "ns-pipeline"},
+	}
+
+	var cfgs []backends.Config
+	clients := buildClients(arr, func(cfg backends.Config) backends.StoreClient {
+		cfgs = append(cfgs, cfg)
+		return &fakeStoreClient{}
+	})
+
+	require.Len(t, clients, 6)
+	require.Len(t, cfgs, 4)
+
+	assert.Equal(t, "etcdv3-0", clients[0].name)
+	assert.Equal(t, "confd", clients[0].prefixKind)
+	assert.Equal(t, "etcdv3-0", clients[1].name)
+	assert.Equal(t, "pipeline", clients[1].prefixKind)
+
+	assert.Equal(t, "team-consul", clients[2].name)
+	assert.Equal(t, 10, clients[2].priority)
+	assert.Equal(t, 2, clients[2].index)
+
+	assert.Equal(t, "nacos-4", clients[4].name)
+	assert.Equal(t, "ns-confd", cfgs[2].Namespace)
+	assert.Equal(t, "ns-pipeline", cfgs[3].Namespace)
+}
+
+func TestGetValues(t *testing.T) {
+	clients := []clientStruct{
+		{
+			client:     &fakeStoreClient{values: map[string]string{"/datakit/confd/a": "a-0"}},
+			prefixKind: "confd",
+			name:       "etcdv3-0",
+		},
+		{
+			client:     &fakeStoreClient{values: map[string]string{"/datakit/pipeline/logging/a.p": "a"}},
+			prefixKind: "pipeline",
+			name:       "etcdv3-0",
+		},
+		{
+			client:     &fakeStoreClient{values: map[string]string{"/datakit/confd/a": "a-1"}},
+			prefixKind: "confd",
+			name:       "consul-1",
+			priority:   1,
+			index:      1,
+		},
+	}
+
+	data, err := getValues(clients, "confd")
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+
+	res := mergeValues(data)
+	assert.Equal(t, "a-1", res["/datakit/confd/a"].Value)
+	assert.Equal(t, []map[string]string{{"/datakit/confd/a": "a-1"}}, mergedToData(res))
+}
@@ -413,8 +413,9 @@ func (c *Config) ApplyMainConfig() error {
 		}
 	}
 
-	// remove deprecated UUID field in main configure
-	if c.UUIDDeprecated != "" {
+	// remove deprecated UUID field in main configure, but do not write back
+	// the resolved secrets.
+	if c.UUIDDeprecated != "" && !c.secretResolved {
 		c.UUIDDeprecated = "" // clear deprecated UUID field
 		buf := new(bytes.Buffer)
 		if err := bstoml.NewEncoder(buf).Encode(c); err != nil {
@@ -6,6 +6,14 @@
 package config
 
 type ConfdCfg struct {
+	// Name of the backend, used in logging and debugging, default to <backend>-<index>.
+	Name string `toml:"name,omitempty"`
+
+	// Priority of the backend. If the same key exists in multiple backends, the
+	// value from the backend with the highest priority takes effect. For the same
+	// priority, the backend configured first takes effect.
+	Priority int `toml:"priority,omitempty"`
+
 	Enable         bool     `toml:"enable"`          // is this backend enable
 	AuthToken      string   `toml:"auth_token"`      // space
 	AuthType       string   `toml:"auth_type"`       // space
@@ -13,13 +13,15 @@ import (
 	"strings"
 	"time"
 
-	fp "github.com/GuanceCloud/cliutils/filter"
 	"github.com/GuanceCloud/cliutils/logger"
 	"github.com/GuanceCloud/pipeline-go/offload"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/election"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/dataway"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/filter"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/recorder"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/secret"
 )
 
 func (c *Config) loadConfdEnvs() {
@@ -325,6 +327,18 @@ func (c *Config) loadDatawayEnvs() {
 		c.Dataway.GZip = false
 	}
 
+	if v := datakit.GetEnv("ENV_DATAWAY_ENABLE_CIRCUIT_BREAKER"); v != "" {
+		l.Info("ENV_DATAWAY_ENABLE_CIRCUIT_BREAKER set, circuit breaker enabled")
+		if c.Dataway.CircuitBreaker != nil {
+			c.Dataway.CircuitBreaker.Enable = true
+		}
+	}
+
+	if v := datakit.GetEnv("ENV_DATAWAY_COMPRESSION"); v != "" {
+		l.Infof("ENV_DATAWAY_COMPRESSION set to %q", v)
+		c.Dataway.Compression = v
+	}
+
 	if v := datakit.GetEnv("ENV_DATAWAY_MAX_RAW_BODY_SIZE"); v != "" {
 		value, err := strconv.ParseInt(v, 10, 64)
 		if err != nil {
@@ -550,6 +564,8 @@ func (c *Config) loadElectionEnvs() {
 		c.Election.Namespace = v
 	}
 
+	c.loadElectionBackendEnvs()
+
 	if v := datakit.GetEnv("ENV_ENABLE_ELECTION_NAMESPACE_TAG"); v != "" {
 		// add to global-env-tags
 		c.Election.EnableNamespaceTag = true
@@ -569,6 +585,49 @@ func (c *Config) loadElectionEnvs() {
 	}
 }
 
+func (c *Config) loadElectionBackendEnvs() {
+	if v := datakit.GetEnv("ENV_ELECTION_BACKEND"); v != "" {
+		c.Election.Backend = v
+	}
+
+	if v := datakit.GetEnv("ENV_ELECTION_LEASE_DURATION"); v != "" {
+		if du, err := time.ParseDuration(v); err != nil {
+			l.Warnf("invalid ENV_ELECTION_LEASE_DURATION: %s, ignored", err.Error())
+		} else {
+			c.Election.LeaseDuration = du
+		}
+	}
+
+	if v := datakit.GetEnv("ENV_ELECTION_RENEW_INTERVAL"); v != "" {
+		if du, err := time.ParseDuration(v); err != nil {
+			l.Warnf("invalid ENV_ELECTION_RENEW_INTERVAL: %s, ignored", err.Error())
+		} else {
+			c.Election.RenewInterval = du
+		}
+	}
+
+	if ns, name := datakit.GetEnv("ENV_ELECTION_K8S_LEASE_NAMESPACE"),
+		datakit.GetEnv("ENV_ELECTION_K8S_LEASE_NAME"); ns != "" || name != "" {
+		c.Election.Kubernetes = &election.KubernetesBackendCfg{
+			LeaseNamespace: ns,
+			LeaseName:      name,
+		}
+	}
+
+	if v := datakit.GetEnv("ENV_ELECTION_ETCD_ENDPOINTS"); v != "" {
+		c.Election.Etcd = &election.EtcdBackendCfg{
+			Endpoints: strings.Split(v, ","),
+			Username:  datakit.GetEnv("ENV_ELECTION_ETCD_USERNAME"),
+			Password:  datakit.GetEnv("ENV_ELECTION_ETCD_PASSWORD"),
+			Prefix:    datakit.GetEnv("ENV_ELECTION_ETCD_PREFIX"),
+		}
+	}
+
+	if v := datakit.GetEnv("ENV_ELECTION_FLOCK_PATH"); v != "" {
+		c.Election.Flock = &election.FlockBackendCfg{Path: v}
+	}
+}
+
 func (c *Config) loadRecorderEnvs() {
 	if v := datakit.GetEnv("ENV_ENABLE_RECORDER"); v == "" {
 		return
@@ -600,6 +659,46 @@ func (c *Config) loadRecorderEnvs() {
 	}
 }
 
+func (c *Config) loadRecorderReplayEnvs() {
+	if v := datakit.GetEnv("ENV_ENABLE_RECORDER_REPLAY"); v == "" {
+		return
+	}
+
+	if c.Recorder.Replay == nil {
+		c.Recorder.Replay = &recorder.Replay{Speed: 1.0, RebaseTime: true}
+	}
+
+	c.Recorder.Replay.Enabled = true
+
+	if v := datakit.GetEnv("ENV_RECORDER_REPLAY_PATH"); v != "" {
+		c.Recorder.Replay.Path = v
+	}
+
+	if v := datakit.GetEnv("ENV_RECORDER_REPLAY_INPUT"); v != "" {
+		c.Recorder.Replay.Input = v
+	}
+
+	if v := datakit.GetEnv("ENV_RECORDER_REPLAY_SPEED"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			c.Recorder.Replay.Speed = f
+		} else {
+			l.Warnf("invalid ENV_RECORDER_REPLAY_SPEED: %q, ignored", v)
+		}
+	}
+
+	if v := datakit.GetEnv("ENV_RECORDER_REPLAY_RATE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.Recorder.Replay.Rate = n
+		} else {
+			l.Warnf("invalid ENV_RECORDER_REPLAY_RATE: %q, ignored", v)
+		}
+	}
+
+	if v := datakit.GetEnv("ENV_RECORDER_REPLAY_LOOP"); v != "" {
+		c.Recorder.Replay.Loop = true
+	}
+}
+
 func (c *Config) loadIOEnvs() {
 	if v := datakit.GetEnv("ENV_IO_AUTO_TIMESTAMP_CORRECTION"); v != "" {
 		if b, err := strconv.ParseBool(v); err == nil {
@@ -661,15 +760,10 @@ func (c *Config) loadIOEnvs() {
 		} else {
 			for k, arr := range x {
 				for _, c := range arr {
-					arr, err := fp.GetConds(c)
-					if err != nil {
-						l.Warnf("parse filter condition failed %q: %q, ignored", k, c)
-					}
-
-					if len(arr) == 0 {
-						l.Warnf("empty filter conditions %q", c)
+					if err := filter.CheckRule(c); err != nil {
+						l.Warnf("parse filter rule failed %q: %q: %s, ignored", k, c, err)
 					} else {
-						l.Infof("filter condition ok %q", c)
+						l.Infof("filter rule ok %q", c)
 					}
 				}
 			}
@@ -755,6 +849,23 @@ func (c *Config) loadHTTPAPIEnvs() {
 			c.HTTPAPI.TLSConf.PrivKey = v
 		}
 	}
+
+	if v := datakit.GetEnv("ENV_HTTP_TLS_CA"); v != "" {
+		c.HTTPAPI.TLSConf.CA = v
+	}
+
+	if v := datakit.GetEnv("ENV_HTTP_AUTH_CREDENTIALS"); v != "" {
+		var x []*APICredential
+		if err := json.Unmarshal([]byte(v), &x); err != nil {
+			l.Warnf("invalid ENV_HTTP_AUTH_CREDENTIALS: %s, ignored", err)
+		} else {
+			c.HTTPAPI.Auth = &APIAuthConfig{
+				Enable:        true,
+				AllowLoopback: datakit.GetEnv("ENV_HTTP_AUTH_ALLOW_LOOPBACK") != "",
+				Credentials:   x,
+			}
+		}
+	}
 }
 
 func (c *Config) setNodenameAsHostname() {
@@ -808,6 +919,7 @@ func (c *Config) LoadEnvs() error {
 
 	c.loadIOEnvs()
 	c.loadRecorderEnvs()
+	c.loadRecorderReplayEnvs()
 	c.loadPipelineEnvs()
 	c.loadHTTPAPIEnvs()
 	c.loadElectionEnvs()
@@ -890,11 +1002,66 @@ func (c *Config) LoadEnvs() error {
 		c.Crypto.AESKeyFile = v
 	}
 
+	c.loadSecretEnvs()
+
 	c.loadConfdEnvs()
 
 	return nil
 }
 
+func (c *Config) loadSecretEnvs() {
+	getCfg := func() *secret.Config {
+		if c.Secret == nil {
+			c.Secret = &secret.Config{}
+		}
+		return c.Secret
+	}
+
+	if v := datakit.GetEnv("ENV_SECRET_TTL"); v != "" {
+		if du, err := time.ParseDuration(v); err != nil {
+			l.Warnf("invalid ENV_SECRET_TTL: %s, ignored", err.Error())
+		} else {
+			getCfg().TTL = du
+		}
+	}
+
+	getVaultCfg := func() *secret.VaultConfig {
+		cfg := getCfg()
+		if cfg.Vault == nil {
+			cfg.Vault = &secret.VaultConfig{}
+		}
+		return cfg.Vault
+	}
+
+	if v := datakit.GetEnv("ENV_SECRET_VAULT_ADDRESS"); v != "" {
+		getVaultCfg().Address = v
+	}
+
+	if v := datakit.GetEnv("ENV_SECRET_VAULT_TOKEN_FILE"); v != "" {
+		getVaultCfg().TokenFile = v
+	}
+
+	if v := datakit.GetEnv("ENV_SECRET_VAULT_NAMESPACE"); v != "" {
+		getVaultCfg().Namespace = v
+	}
+
+	if v := datakit.GetEnv("ENV_SECRET_VAULT_KV_VERSION"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil {
+			l.Warnf("invalid ENV_SECRET_VAULT_KV_VERSION: %s, ignored", err.Error())
+		} else {
+			getVaultCfg().KVVersion = n
+		}
+	}
+
+	if v := datakit.GetEnv("ENV_SECRET_K8S_NAMESPACE"); v != "" {
+		cfg := getCfg()
+		if cfg.Kubernetes == nil {
+			cfg.Kubernetes = &secret.KubernetesConfig{}
+		}
+		cfg.Kubernetes.Namespace = v
+	}
+}
+
 var (
 	nodeNamePrefix string
 	nodeNameSuffix string
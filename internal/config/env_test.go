@@ -13,8 +13,10 @@ import (
 
 	"github.com/GuanceCloud/pipeline-go/offload"
 	"github.com/stretchr/testify/assert"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/election"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/dataway"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/filter"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/secret"
 )
 
 func TestLoadEnv(t *testing.T) {
@@ -78,6 +80,57 @@ func TestLoadEnv(t *testing.T) {
 			}(),
 		},
 
+		{
+			name: "test-election-backend-envs",
+			envs: map[string]string{
+				"ENV_ENABLE_ELECTION":              "on",
+				"ENV_ELECTION_BACKEND":             "kubernetes",
+				"ENV_ELECTION_LEASE_DURATION":      "30s",
+				"ENV_ELECTION_RENEW_INTERVAL":      "bad-duration",
+				"ENV_ELECTION_K8S_LEASE_NAMESPACE": "datakit",
+				"ENV_ELECTION_ETCD_ENDPOINTS":      "http://etcd-1:2379,http://etcd-2:2379",
+				"ENV_ELECTION_FLOCK_PATH":          "/var/run/datakit/election.lock",
+			},
+
+			expect: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Election.Enable = true
+				cfg.Election.Backend = "kubernetes"
+				cfg.Election.LeaseDuration = 30 * time.Second
+				cfg.Election.Kubernetes = &election.KubernetesBackendCfg{LeaseNamespace: "datakit"}
+				cfg.Election.Etcd = &election.EtcdBackendCfg{Endpoints: []string{"http://etcd-1:2379", "http://etcd-2:2379"}}
+				cfg.Election.Flock = &election.FlockBackendCfg{Path: "/var/run/datakit/election.lock"}
+
+				return cfg
+			}(),
+		},
+
+		{
+			name: "test-secret-envs",
+			envs: map[string]string{
+				"ENV_SECRET_TTL":              "1m",
+				"ENV_SECRET_VAULT_ADDRESS":    "https://vault:8200",
+				"ENV_SECRET_VAULT_TOKEN_FILE": "/vault/token",
+				"ENV_SECRET_VAULT_KV_VERSION": "1",
+				"ENV_SECRET_K8S_NAMESPACE":    "datakit",
+			},
+
+			expect: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Secret = &secret.Config{
+					TTL: time.Minute,
+					Vault: &secret.VaultConfig{
+						Address:   "https://vault:8200",
+						TokenFile: "/vault/token",
+						KVVersion: 1,
+					},
+					Kubernetes: &secret.KubernetesConfig{Namespace: "datakit"},
+				}
+
+				return cfg
+			}(),
+		},
+
 		{
 			name: `bad-sinkers`,
 			envs: map[string]string{
@@ -529,6 +582,21 @@ func TestLoadEnv(t *testing.T) {
 			}(),
 		},
 
+		{
+			name: "dw-compression",
+			envs: map[string]string{
+				"ENV_DATAWAY_COMPRESSION": "zstd",
+			},
+
+			expect: func() *Config {
+				cfg := DefaultConfig()
+
+				cfg.Dataway.Compression = "zstd"
+
+				return cfg
+			}(),
+		},
+
 		{
 			name: "test-k8s-node-name",
 			envs: map[string]string{
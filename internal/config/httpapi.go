@@ -13,6 +13,7 @@ import (
 type TLSConfig struct {
 	Cert    string `toml:"cert"`
 	PrivKey string `toml:"privkey"`
+	CA      string `toml:"ca"` // CA to verify client certificates(mTLS)
 }
 
 // APIConfig used to unmarshal HTTP API server configurations.
@@ -32,6 +33,30 @@ type APIConfig struct {
 	CloseIdleConnection bool       `toml:"close_idle_connection"`
 	TLSConf             *TLSConfig `toml:"tls"`
 	AllowedCORSOrigins  []string   `toml:"allowed_cors_origins"`
+
+	Auth *APIAuthConfig `toml:"auth"`
+}
+
+// APIAuthConfig used to authenticate and authorize HTTP API requests.
+type APIAuthConfig struct {
+	Enable bool `toml:"enable"`
+
+	// Requests from loopback(or unix socket) skip the authentication,
+	// datakit commands(such as datakit monitor) requires it.
+	AllowLoopback bool `toml:"allow_loopback"`
+
+	Credentials []*APICredential `toml:"credentials"`
+}
+
+// APICredential is a bearer token or client certificate's common name with its scopes.
+type APICredential struct {
+	Token  string   `toml:"token" json:"token"`
+	CertCN string   `toml:"cert_cn" json:"cert_cn"`
+	Scopes []string `toml:"scopes" json:"scopes"`
+}
+
+func (conf *APIConfig) AuthEnabled() bool {
+	return conf.Auth != nil && conf.Auth.Enable
 }
 
 func defaultAPIConfig() *APIConfig {
@@ -49,6 +74,7 @@ func defaultAPIConfig() *APIConfig {
 		CloseIdleConnection: false,
 		TLSConf:             &TLSConfig{},
 		AllowedCORSOrigins:  []string{},
+		Auth:                &APIAuthConfig{},
 	}
 }
 
@@ -23,6 +23,7 @@ import (
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/dkstring"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/path"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/secret"
 )
 
 var (
@@ -79,6 +80,16 @@ func LoadCfg(c *Config, mcp string) error {
 		return err
 	}
 
+	secret.Setup(c.Secret)
+
+	// under docker mode, main configure comes from ENVs, and the secrets within
+	// ENVs are not resolved.
+	if !datakit.Docker {
+		if err := c.resolveMainSecrets(mcp); err != nil {
+			return err
+		}
+	}
+
 	l.Infof("apply main configure from %q...", mcp)
 
 	if err := c.ApplyMainConfig(); err != nil {
@@ -92,8 +103,13 @@ func LoadCfg(c *Config, mcp string) error {
 	}
 
 	defaultKV.LoadKV()
+	secret.Default().Start()
 
-	l.Infof("loaded main cfg: \n%s", c.String())
+	if c.secretResolved {
+		l.Infof("loaded main cfg, not shown for secrets resolved")
+	} else {
+		l.Infof("loaded main cfg: \n%s", c.String())
+	}
 
 	// clear all samples before loading
 	removeSamples()
@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	bstoml "github.com/BurntSushi/toml"
@@ -27,52 +28,92 @@ func reloadKVConf(changedConfs map[string]string) error {
 
 	// reload inputs in all changed config
 	for key, confData := range changedConfs {
-		// stop inputs && remove inputs
-		changedInputs := inputs.GetInputsByConfKey(key)
-		for _, i := range changedInputs {
-			if inp, ok := i.Input.(inputs.InputV2); ok {
-				inp.Terminate()
-			}
-			if _, ok := i.Input.(inputs.HTTPInput); ok {
-				containHTTPInput = true
-			}
-			inputs.RemoveInput(i.Name, i.Input)
+		inputsInfo, httpInput, err := reloadConfInputs("kv", key, confData)
+		if httpInput {
+			containHTTPInput = true
 		}
 
-		// start inputs
-		if inputsInfo, err := getInputsFromConfData(key, confData, inputs.AllInputs); err != nil {
+		if err != nil {
 			l.Warnf("getInputsFromConfData failed: %s, ignored", err.Error())
 			return nil
-		} else {
-			for inputName, inputArr := range inputsInfo {
-				l.Infof("kv reload, start input: %s", inputName)
-				for _, input := range inputArr {
-					kvInputReloadCount.WithLabelValues(input.Name).Inc()
-					kvInputLastReload.WithLabelValues(input.Name).Set(float64(time.Now().Unix()))
-					inputs.RunInput(input.Name, input)
-					inputs.AddInput(input.Name, input)
-				}
+		}
+
+		for _, inputArr := range inputsInfo {
+			for _, input := range inputArr {
+				kvInputReloadCount.WithLabelValues(input.Name).Inc()
+				kvInputLastReload.WithLabelValues(input.Name).Set(float64(time.Now().Unix()))
 			}
 		}
 	}
 
 	// restart http inputs
 	if containHTTPInput {
-		if restartHTTPServer != nil {
-			l.Info("restart http server because of kv changed")
-			restartHTTPServer()
-		} else {
-			l.Warn("restart http server not set")
-		}
+		restartHTTPInputs("kv")
 	}
 
 	return nil
 }
 
+var reloadInputsMu sync.Mutex
+
+// reloadConfInputs stops and removes inputs loaded from conf key, then starts
+// inputs within confData. It also reports whether any HTTP input stopped.
+func reloadConfInputs(reason, key, confData string) (map[string][]*inputs.InputInfo, bool, error) {
+	reloadInputsMu.Lock()
+	defer reloadInputsMu.Unlock()
+
+	containHTTPInput := false
+
+	// stop inputs && remove inputs
+	changedInputs := inputs.GetInputsByConfKey(key)
+	for _, i := range changedInputs {
+		if inp, ok := i.Input.(inputs.InputV2); ok {
+			inp.Terminate()
+		}
+		if _, ok := i.Input.(inputs.HTTPInput); ok {
+			containHTTPInput = true
+		}
+		inputs.RemoveInput(i.Name, i.Input)
+	}
+
+	// start inputs
+	inputsInfo, err := getInputsFromConfData(key, confData, inputs.AllInputs)
+	if err != nil {
+		return nil, containHTTPInput, err
+	}
+
+	for inputName, inputArr := range inputsInfo {
+		l.Infof("%s reload, start input: %s", reason, inputName)
+		for _, input := range inputArr {
+			inputs.RunInput(input.Name, input)
+			inputs.AddInput(input.Name, input)
+		}
+	}
+
+	return inputsInfo, containHTTPInput, nil
+}
+
+func restartHTTPInputs(reason string) {
+	if restartHTTPServer != nil {
+		l.Infof("restart http server because of %s changed", reason)
+		restartHTTPServer()
+	} else {
+		l.Warn("restart http server not set")
+	}
+}
+
 func getInputsFromConfData(confKey string, confData string, ipts map[string]inputs.Creator) (map[string][]*inputs.InputInfo, error) {
 	var res map[string]interface{}
 	ret := map[string][]*inputs.InputInfo{}
-	if _, err := bstoml.Decode(confData, &res); err != nil {
+
+	resolved, err := resolveInputSecrets(confKey, confData)
+	if err != nil {
+		l.Warnf("resolveInputSecrets: %s, ignored", err)
+		return nil, err
+	}
+
+	if _, err := bstoml.Decode(resolved, &res); err != nil {
+		// log the unresolved conf to avoid leaking secrets
 		l.Warnf("bstoml.Decode: %s, ignored, confData:\n%s", err, confData)
 		return nil, err
 	}
@@ -135,6 +176,12 @@ func getInputsFromConfData(confKey string, confData string, ipts map[string]inpu
 
 // LoadSingleConf load single conf data with kv replace.
 func LoadSingleConf(confData string, ipts map[string]inputs.Creator) (map[string][]*inputs.InputInfo, error) {
+	return loadSingleConf(confData, "", ipts)
+}
+
+// loadSingleConf load single conf data, the source is the path of the conf
+// file, it's empty if the conf not loaded from file.
+func loadSingleConf(confData, source string, ipts map[string]inputs.Creator) (map[string][]*inputs.InputInfo, error) {
 	var err error
 	parsedConfData := confData
 	isTemplate := IsKVTemplate(confData)
@@ -147,6 +194,10 @@ func LoadSingleConf(confData string, ipts map[string]inputs.Creator) (map[string
 	}
 
 	confKey := cliutils.XID("kv_config_")
+	if source != "" {
+		setSecretConfSource(confKey, source)
+	}
+
 	defer func() {
 		// add kv config to kvConfig if it is a template, even if it is not a valid kv template.
 		// because the kv template may be invalid firstly, and it may be valid later.
@@ -232,7 +283,7 @@ func LoadSingleConfFile(fp string, ipts map[string]inputs.Creator, skipChecksum
 
 	data = feedEnvs(data)
 	data = decodeEncs(data)
-	return LoadSingleConf(string(data), ipts)
+	return loadSingleConf(string(data), fp, ipts)
 }
 
 // LoadInputConf read all inputs configures(toml) from @root,
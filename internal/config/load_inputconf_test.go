@@ -213,8 +213,27 @@ percpu = false
 				},
 			},
 		},
+
+		{
+			name: "secret-ref",
+			conf: `[inputs.cpu]
+interval = "secret://env/DK_TEST_CPU_INTERVAL"
+percpu = true`,
+			expectInputs: map[string][]inputs.Input{
+				"cpu": {&cpu{Interval: "12s", Percpu: true}},
+			},
+		},
+
+		{
+			name: "secret-ref-not-found",
+			conf: `[inputs.cpu]
+interval = "secret://env/DK_TEST_NOT_SET"
+percpu = true`,
+		},
 	}
 
+	t.Setenv("DK_TEST_CPU_INTERVAL", "12s")
+
 	creators := map[string]inputs.Creator{
 		"cpu": func() inputs.Input {
 			return &cpu{}
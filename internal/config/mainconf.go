@@ -21,6 +21,7 @@ import (
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/pipeline/plval"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/recorder"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/resourcelimit"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/secret"
 )
 
 type pointPool struct {
@@ -118,10 +119,16 @@ type Config struct {
 	// crypto
 	Crypto *configCrpto `toml:"crypto,omitempty"`
 
+	// secret providers for secret://... references
+	Secret *secret.Config `toml:"secret,omitempty"`
+
 	RemoteJob *io.RemoteJob `toml:"remote_job,omitempty"`
 
 	hostname    string
 	cmdlineMode bool
+
+	// secret references within datakit.conf resolved
+	secretResolved bool
 }
 
 func EmptyConfig() *Config {
@@ -179,6 +186,10 @@ func DefaultConfig() *Config {
 			Duration:   time.Minute * 30,
 			Inputs:     []string{},
 			Categories: []string{},
+			Replay: &recorder.Replay{
+				Speed:      1.0,
+				RebaseTime: true,
+			},
 		},
 
 		Dataway: dataway.NewDefaultDataway(),
@@ -17,6 +17,8 @@ var (
 	kvInputReloadCount prometheus.CounterVec
 	kvInputLastReload  prometheus.GaugeVec
 
+	secretInputReloadCount *prometheus.CounterVec
+
 	setUlimitVec *prometheus.GaugeVec
 )
 
@@ -84,6 +86,16 @@ func metricsSetup() {
 		},
 		[]string{"source"},
 	)
+
+	secretInputReloadCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "datakit",
+			Subsystem: "secret",
+			Name:      "input_reload_total",
+			Help:      "Input reload count on secret rotation",
+		},
+		[]string{"source"},
+	)
 }
 
 func Metrics() []prometheus.Collector {
@@ -94,6 +106,7 @@ func Metrics() []prometheus.Collector {
 		kvLastUpdate,
 		kvInputLastReload,
 		kvInputReloadCount,
+		secretInputReloadCount,
 	}
 }
 
@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/secret"
+)
+
+// resolveMainSecrets resolves secret references within datakit.conf. The
+// resolved values only live in memory, datakit.conf is not changed.
+//
+// Secrets within datakit.conf are resolved only once on startup, they are not
+// watched, so rotated secrets take effect after restart.
+func (c *Config) resolveMainSecrets(p string) error {
+	data, err := os.ReadFile(filepath.Clean(p))
+	if err != nil {
+		return fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	if !secret.HasRef(string(data)) {
+		return nil
+	}
+
+	resolved, err := secret.Default().Resolve(string(data))
+	if err != nil {
+		return fmt.Errorf("resolve secret in %s: %w", p, err)
+	}
+
+	if err := c.doLoadMainTOML(resolved); err != nil {
+		return err
+	}
+
+	c.secretResolved = true
+	l.Infof("secrets in %q resolved", p)
+	return nil
+}
+
+// resolveInputSecrets resolves secret references within input conf, and
+// reloads the inputs of the conf on secret rotation.
+func resolveInputSecrets(confKey, confData string) (string, error) {
+	m := secret.Default()
+
+	if !secret.HasRef(confData) {
+		m.Unwatch(confKey)
+		return confData, nil
+	}
+
+	resolved, err := m.Resolve(confData)
+	if err != nil {
+		return "", err
+	}
+
+	m.Watch(confKey, confData, reloadSecretConf)
+	return resolved, nil
+}
+
+var (
+	secretConfSourcesMu sync.Mutex
+	// conf key -> path of the conf file
+	secretConfSources = map[string]string{}
+)
+
+func setSecretConfSource(confKey, source string) {
+	secretConfSourcesMu.Lock()
+	defer secretConfSourcesMu.Unlock()
+
+	secretConfSources[confKey] = source
+}
+
+func getSecretConfSource(confKey string) string {
+	secretConfSourcesMu.Lock()
+	defer secretConfSourcesMu.Unlock()
+
+	return secretConfSources[confKey]
+}
+
+// latestSecretConf returns the latest conf of confKey. For conf loaded from
+// file, the conf file is re-read to pick up changes after loading, or the
+// conf(such as from confd) cached on latest load is used.
+func latestSecretConf(confKey, confData string) (string, error) {
+	fp := getSecretConfSource(confKey)
+	if fp == "" {
+		return confData, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(fp))
+	if err != nil {
+		return "", fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	confData = string(decodeEncs(feedEnvs(data)))
+	if IsKVTemplate(confData) {
+		if confData, err = defaultKV.ReplaceKV(confData); err != nil {
+			return "", fmt.Errorf("defaultKV.ReplaceKV: %w", err)
+		}
+	}
+
+	return confData, nil
+}
+
+// reloadSecretConf reloads inputs of the conf on secret rotation.
+func reloadSecretConf(confKey, confData string) error {
+	confData, err := latestSecretConf(confKey, confData)
+	if err != nil {
+		return err
+	}
+
+	inputsInfo, httpInput, err := reloadConfInputs("secret", confKey, confData)
+	if httpInput {
+		restartHTTPInputs("secret")
+	}
+
+	if err != nil {
+		return fmt.Errorf("getInputsFromConfData: %w", err)
+	}
+
+	for _, inputArr := range inputsInfo {
+		for _, input := range inputArr {
+			secretInputReloadCount.WithLabelValues(input.Name).Inc()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestSecretConf(t *testing.T) {
+	t.Run("re-read-conf-file", func(t *testing.T) {
+		fp := filepath.Join(t.TempDir(), "mysql.conf")
+		require.NoError(t, os.WriteFile(fp, []byte(`pass = "secret://env/PASS_1"`), 0o600))
+
+		setSecretConfSource("conf-file", fp)
+
+		// conf file changed after loading
+		require.NoError(t, os.WriteFile(fp, []byte(`pass = "secret://env/PASS_2"`), 0o600))
+
+		conf, err := latestSecretConf("conf-file", `pass = "secret://env/PASS_1"`)
+		require.NoError(t, err)
+		assert.Equal(t, `pass = "secret://env/PASS_2"`, conf)
+
+		// conf file removed
+		require.NoError(t, os.Remove(fp))
+		_, err = latestSecretConf("conf-file", `pass = "secret://env/PASS_1"`)
+		assert.Error(t, err)
+	})
+
+	t.Run("not-from-file", func(t *testing.T) {
+		conf, err := latestSecretConf("conf-confd", `pass = "secret://env/PASS_1"`)
+		require.NoError(t, err)
+		assert.Equal(t, `pass = "secret://env/PASS_1"`, conf)
+	})
+}
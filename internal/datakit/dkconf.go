@@ -157,6 +157,18 @@ ulimit = 64000
   [http_api.tls]
     # cert = "path/to/certificate/file"
     # privkey = "path/to/private_key/file"
+    # ca = "path/to/ca/file" # verify client certificates(mTLS)
+
+  # Authenticate API requests with bearer token or client certificate, available scopes are write/query/admin.
+  [http_api.auth]
+    enable = false
+    # allow_loopback = false # requests from localhost skip authentication
+    # [[http_api.auth.credentials]]
+    #   token = "some-random-token"
+    #   scopes = ["write"]
+    # [[http_api.auth.credentials]]
+    #   cert_cn = "sidecar"
+    #   scopes = ["write", "query"]
 
 ################################################
 # io configures
@@ -183,7 +195,10 @@ ulimit = 64000
   # NOTE: Most of the time, you should use web-side filter, it's a debug helper for developers.
   #[io.filters]
   #  logging = [
-  #   "{ source = 'datakit' or f1 IN [ 1, 2, 3] }"
+  #   "{ source = 'datakit' or f1 IN [ 1, 2, 3] }",
+  #   "sample(10) { status = 'info' }",      # keep 1-in-10 matched points
+  #   "rate(100, host) { source = 'nginx' }", # keep at most 100 points per second on each host
+  #   "mask_fields(password, token)",        # mask these fields/tags, drop_fields() also available
   #  ]
   #  metric = [
   #    "{ measurement IN ['datakit', 'disk'] }",
@@ -196,6 +211,33 @@ ulimit = 64000
   #    "{ service = re("abc.*") AND some_tag CONTAIN ['def_.*'] }",
   #  ]
 
+  # Extra outputs besides Dataway, available kinds are file/kafka/otlp/prometheus_remote_write.
+  # Each sink got its own WAL under cache/sink-wal.
+  #[[io.sinks]]
+  #  name       = "local-file"
+  #  kind       = "file"
+  #  categories = ["L", "M"]     # empty for all categories
+  #  path       = "/path/to/sink/dir"
+  #  encoding   = "line-protocol" # or pbjson
+  #  max_size_mb = 32
+  #  max_backups = 5
+  #
+  #[[io.sinks]]
+  #  kind       = "kafka"
+  #  categories = ["logging"]
+  #  brokers    = ["localhost:9092"]
+  #  topic      = "" # default datakit-<category>
+  #
+  #[[io.sinks]]
+  #  kind       = "otlp"
+  #  url        = "http://localhost:4318"
+  #  #headers   = { "Authorization" = "Bearer xxx" }
+  #
+  #[[io.sinks]]
+  #  kind       = "prometheus_remote_write"
+  #  categories = ["M"]
+  #  url        = "http://localhost:9090/api/v1/write"
+
 [recorder]
   enabled = false
   #path = "/path/to/point-data/dir"
@@ -214,6 +256,16 @@ ulimit = 64000
     #"object",
   ]
 
+  # replay recorded data through IO, as if they are collected by inputs
+  [recorder.replay]
+    enabled = false
+    #path = "/path/to/point-data/dir"
+    #input = "" # feed all data as the input, if empty, use the input recorded in data file name
+    speed = 1.0 # multiple of the recording pace, 0 means as fast as possible
+    rate = 0    # max points fed per second, 0 means no limit
+    loop = false
+    rebase_time = true # shift point's time to now
+
 ################################################
 # Dataway configure
 ################################################
@@ -254,6 +306,10 @@ ulimit = 64000
   # do NOT disable gzip or your get large network payload.
   gzip = true
 
+  # HTTP body compression, candidates are gzip/zstd/snappy/none.
+  # If not set, use gzip or none according to gzip option.
+  #compression = "gzip"
+
   max_raw_body_size = 1048576 # max body size(before gizp) in bytes
 
   # Customer tag or field keys that will extract from exist points
@@ -278,6 +334,15 @@ ulimit = 64000
     #fail_cache_clean_interval = "30s" # duration for clean fail uploaded data
     #no_drop_categories = ["L"]        # category list that disable drop data when disk cache full
 
+  # Circuit breaker on each Dataway URL: after continuous upload failures, stop
+  # sending to the Dataway for a while(exponential backoff with jitter), data
+  # are cached or dropped during the time, the same as failed uploading.
+  [dataway.circuit_breaker]
+    enable            = false
+    failure_threshold = 5      # open the breaker after 5 continuous failures
+    min_backoff       = "5s"
+    max_backoff       = "5m"
+
 
 ################################################
 # Datakit logging configure
@@ -330,6 +395,28 @@ ulimit = 64000
   # If enabled, every data point will add a tag with election_namespace = <your-election-namespace>
   enable_namespace_tag = false
 
+  # Election backend: dataway(default)/kubernetes/etcd/flock. The kubernetes/etcd/flock
+  # backends are self-hosted, and they do not require Dataway.
+  # backend = "dataway"
+  # lease_duration = "15s"
+  # renew_interval = "5s"
+
+  # Elect on Kubernetes coordination.k8s.io Lease.
+  # [election.kubernetes]
+  #   lease_namespace = "" # default to the namespace of DataKit Pod
+  #   lease_name      = "" # default to datakit-election-<namespace>
+
+  # Elect on etcd lease.
+  # [election.etcd]
+  #   endpoints = ["http://127.0.0.1:2379"]
+  #   username  = ""
+  #   password  = ""
+  #   prefix    = "/datakit/election"
+
+  # Elect on a local file lock, for DataKits on the same host.
+  # [election.flock]
+  #   path = "" # default to <datakit-data-dir>/election-<namespace>.lock
+
   # Like global_host_tags, but only for data points that are remotely collected(such as MySQL/Nginx).
   [election.tags]
     #  project = "my-project"
@@ -392,6 +479,21 @@ ulimit = 64000
   aes_key = ""
   aes_Key_file = ""
 
+################################################
+# secret providers for secret://<provider>/<path>[#<key>]
+################################################
+#[secret]
+#  ttl = "5m"
+#
+#  [secret.vault]
+#    address = "https://vault.example.com:8200"
+#    token_file = "/vault/secrets/token"
+#    namespace = ""
+#    kv_version = 2
+#
+#  [secret.kubernetes]
+#    namespace = "datakit"
+
 [remote_job]
   enable=false
   envs = ["OSS_BUCKET_HOST=host","OSS_ACCESS_KEY_ID=key","OSS_ACCESS_KEY_SECRET=secret","OSS_BUCKET_NAME=bucket"]
@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package election
+
+import (
+	"context"
+	"time"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/metrics"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+)
+
+const (
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewInterval = 5 * time.Second
+)
+
+// Backend is a self-hosted election backend, such as Kubernetes Lease, etcd or
+// file lock, the election works without Dataway.
+type Backend interface {
+	// Campaign tries to acquire the leadership, or renew it if already elected.
+	// It returns the ID of current leader(empty if unknown) and whether
+	// we are the leader.
+	Campaign(ctx context.Context, id string) (leader string, elected bool, err error)
+
+	// Resign releases the leadership if elected.
+	Resign(ctx context.Context, id string) error
+
+	// Name returns the name of the backend.
+	Name() string
+}
+
+// backendElection runs the election on a Backend, the plugins are paused and
+// resumed in the same way as the Dataway election.
+type backendElection struct {
+	*leaderElection
+
+	backend   Backend
+	electedAt time.Time
+	lastRenew time.Time
+}
+
+func newBackendElection(opt *option, plugins map[string][]inputs.ElectionInput) *backendElection {
+	if opt.leaseDuration <= 0 {
+		opt.leaseDuration = defaultLeaseDuration
+	}
+
+	if opt.renewInterval <= 0 {
+		opt.renewInterval = defaultRenewInterval
+	}
+
+	// renew several times before the lease expired
+	if opt.renewInterval >= opt.leaseDuration {
+		opt.renewInterval = opt.leaseDuration / 3
+	}
+
+	return &backendElection{
+		leaderElection: newLeaderElection(opt, plugins),
+		backend:        opt.backend,
+	}
+}
+
+func (x *backendElection) Run() {
+	defer func() {
+		electionStatusVec.WithLabelValues(
+			CurrentElected,
+			x.id,
+			x.namespace,
+			x.status.String(),
+		).Set(float64(x.status))
+	}()
+
+	x.pausePlugins()
+	electionInputs.WithLabelValues(x.namespace).Set(float64(len(x.plugins)))
+
+	tick := time.NewTicker(x.renewInterval)
+	defer tick.Stop()
+
+	for {
+		x.runOnce()
+
+		select {
+		case <-datakit.Exit.Wait():
+			x.resign()
+			return
+
+		case <-tick.C:
+		}
+	}
+}
+
+func (x *backendElection) runOnce() {
+	var (
+		electedTime int64
+		start       = time.Now()
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), x.renewInterval)
+	defer cancel()
+
+	leader, elected, err := x.backend.Campaign(ctx, x.id)
+	if err != nil {
+		log.Warnf("%s election: %s", x.backend.Name(), err)
+		metrics.FeedLastError("election", err.Error())
+
+		// we can not renew the leadership, step down after the lease expired
+		// to avoid collecting by multiple leaders.
+		if x.status == statusSuccess && time.Since(x.lastRenew) >= x.leaseDuration {
+			log.Warnf("%s election: leadership lost for renew failed", x.backend.Name())
+			x.stepDown()
+		}
+		return
+	}
+
+	defer func() {
+		electionVec.WithLabelValues(
+			x.namespace,
+			x.status.String(),
+		).Observe(float64(time.Since(start)) / float64(time.Second))
+
+		if x.status == statusSuccess {
+			electedTime = x.electedAt.Unix()
+		}
+
+		electionStatusVec.WithLabelValues(
+			CurrentElected,
+			x.id,
+			x.namespace,
+			x.status.String(),
+		).Set(float64(electedTime))
+	}()
+
+	if CurrentElected != leader {
+		CurrentElected = leader
+		electionStatusVec.Reset() // cleanup election status metrics
+	}
+
+	switch {
+	case elected && x.status != statusSuccess:
+		log.Infof("%s election: %s elected", x.backend.Name(), x.id)
+		electionStatusVec.Reset()
+
+		x.status = statusSuccess
+		x.electedAt = time.Now()
+		x.resumePlugins()
+
+	case !elected && x.status == statusSuccess:
+		log.Infof("%s election: leadership lost, current leader is %q", x.backend.Name(), leader)
+		x.stepDown()
+	}
+
+	if elected {
+		x.lastRenew = time.Now()
+	}
+}
+
+func (x *backendElection) stepDown() {
+	electionStatusVec.Reset() // cleanup election status if election fail
+	x.status = statusFail
+	x.pausePlugins()
+}
+
+func (x *backendElection) resign() {
+	if x.status != statusSuccess {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), x.renewInterval)
+	defer cancel()
+
+	if err := x.backend.Resign(ctx, x.id); err != nil {
+		log.Warnf("%s election: resign: %s", x.backend.Name(), err)
+	}
+}
+
+// pausedElection keeps the election inputs paused and never elects, it's used
+// when the election backend is misconfigured, so no DataKit run these inputs
+// instead of silently electing on other backend.
+type pausedElection struct {
+	*leaderElection
+}
+
+func newPausedElection(opt *option, plugins map[string][]inputs.ElectionInput) *pausedElection {
+	return &pausedElection{leaderElection: newLeaderElection(opt, plugins)}
+}
+
+func (x *pausedElection) Run() {
+	x.pausePlugins()
+
+	electionInputs.WithLabelValues(x.namespace).Set(float64(len(x.plugins)))
+	electionStatusVec.WithLabelValues(
+		CurrentElected,
+		x.id,
+		x.namespace,
+		x.status.String(),
+	).Set(float64(x.status))
+
+	log.Errorf("%d election inputs paused: %s", len(x.plugins), x.backendErr)
+}
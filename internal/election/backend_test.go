@@ -0,0 +1,289 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package election
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+)
+
+type countElectionInput struct {
+	paused, resumed int
+}
+
+func (inp *countElectionInput) Pause() error  { inp.paused++; return nil }
+func (inp *countElectionInput) Resume() error { inp.resumed++; return nil }
+
+type fakeBackend struct {
+	leader  string
+	elected bool
+	err     error
+	resigns int
+}
+
+func (b *fakeBackend) Name() string { return "fake" }
+
+func (b *fakeBackend) Campaign(_ context.Context, _ string) (string, bool, error) {
+	return b.leader, b.elected, b.err
+}
+
+func (b *fakeBackend) Resign(_ context.Context, _ string) error {
+	b.resigns++
+	return nil
+}
+
+func TestBackendElection(t *testing.T) {
+	t.Run("elected-and-lost", func(t *testing.T) {
+		inp := &countElectionInput{}
+		b := &fakeBackend{}
+
+		x := newBackendElection(&option{
+			id:        "dk-1",
+			namespace: "ns",
+			backend:   b,
+		}, map[string][]inputs.ElectionInput{"fake": {inp}})
+
+		assert.Equal(t, defaultLeaseDuration, x.leaseDuration)
+		assert.Equal(t, defaultRenewInterval, x.renewInterval)
+
+		b.leader = "dk-2"
+		x.runOnce()
+		assert.Equal(t, statusFail, x.status)
+		assert.Equal(t, "dk-2", CurrentElected)
+		assert.Equal(t, 0, inp.resumed)
+
+		b.leader, b.elected = "dk-1", true
+		x.runOnce()
+		assert.Equal(t, statusSuccess, x.status)
+		assert.Equal(t, 1, inp.resumed)
+
+		// renew do not resume again
+		x.runOnce()
+		assert.Equal(t, 1, inp.resumed)
+
+		b.leader, b.elected = "dk-2", false
+		x.runOnce()
+		assert.Equal(t, statusFail, x.status)
+		assert.Equal(t, 1, inp.paused)
+
+		x.resign()
+		assert.Equal(t, 0, b.resigns)
+	})
+
+	t.Run("renew-failed", func(t *testing.T) {
+		inp := &countElectionInput{}
+		b := &fakeBackend{leader: "dk-1", elected: true}
+
+		x := newBackendElection(&option{
+			id:            "dk-1",
+			namespace:     "ns",
+			backend:       b,
+			leaseDuration: time.Hour,
+			renewInterval: 2 * time.Hour,
+		}, map[string][]inputs.ElectionInput{"fake": {inp}})
+
+		assert.Equal(t, 20*time.Minute, x.renewInterval)
+
+		x.runOnce()
+		assert.Equal(t, statusSuccess, x.status)
+
+		// still within the lease
+		b.err = errors.New("backend unavailable")
+		x.runOnce()
+		assert.Equal(t, statusSuccess, x.status)
+		assert.Equal(t, 0, inp.paused)
+
+		// lease expired
+		x.lastRenew = time.Now().Add(-2 * time.Hour)
+		x.runOnce()
+		assert.Equal(t, statusFail, x.status)
+		assert.Equal(t, 1, inp.paused)
+	})
+
+	t.Run("resign-on-exit", func(t *testing.T) {
+		b := &fakeBackend{leader: "dk-1", elected: true}
+		x := newBackendElection(&option{id: "dk-1", namespace: "ns", backend: b}, nil)
+
+		x.runOnce()
+		x.resign()
+		assert.Equal(t, 1, b.resigns)
+	})
+
+	t.Run("invalid-backend", func(t *testing.T) {
+		inp := &countElectionInput{}
+
+		opt := option{}
+		WithBackendError(errors.New("unknown election backend"))(&opt)
+		assert.Equal(t, modeBackend, opt.mode)
+
+		x := newPausedElection(&opt, map[string][]inputs.ElectionInput{"fake": {inp}})
+		x.Run()
+		assert.Equal(t, statusFail, x.status)
+		assert.Equal(t, 1, inp.paused)
+		assert.Equal(t, 0, inp.resumed)
+	})
+}
+
+func TestNewBackend(t *testing.T) {
+	t.Run("dataway", func(t *testing.T) {
+		b, err := (&ElectionCfg{}).NewBackend()
+		assert.NoError(t, err)
+		assert.Nil(t, b)
+
+		b, err = (&ElectionCfg{Backend: BackendDataway}).NewBackend()
+		assert.NoError(t, err)
+		assert.Nil(t, b)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := (&ElectionCfg{Backend: "zookeeper"}).NewBackend()
+		assert.Error(t, err)
+	})
+
+	t.Run("etcd-without-config", func(t *testing.T) {
+		_, err := (&ElectionCfg{Backend: BackendEtcd}).NewBackend()
+		assert.Error(t, err)
+	})
+}
+
+var leaseResource = schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}
+
+// fakeLeaseClient is an in-memory lease store with resourceVersion conflict checking.
+type fakeLeaseClient struct {
+	leases  map[string]*coordinationv1.Lease
+	version int
+}
+
+func (c *fakeLeaseClient) Get(_ context.Context, name string, _ metav1.GetOptions) (*coordinationv1.Lease, error) {
+	l, ok := c.leases[name]
+	if !ok {
+		return nil, k8serrors.NewNotFound(leaseResource, name)
+	}
+	return l.DeepCopy(), nil
+}
+
+func (c *fakeLeaseClient) Create(_ context.Context, l *coordinationv1.Lease, _ metav1.CreateOptions) (*coordinationv1.Lease, error) {
+	if _, ok := c.leases[l.Name]; ok {
+		return nil, k8serrors.NewAlreadyExists(leaseResource, l.Name)
+	}
+	c.version++
+	l = l.DeepCopy()
+	l.ResourceVersion = strconv.Itoa(c.version)
+	c.leases[l.Name] = l
+	return l, nil
+}
+
+func (c *fakeLeaseClient) Update(_ context.Context, l *coordinationv1.Lease, _ metav1.UpdateOptions) (*coordinationv1.Lease, error) {
+	old, ok := c.leases[l.Name]
+	if !ok {
+		return nil, k8serrors.NewNotFound(leaseResource, l.Name)
+	}
+	if old.ResourceVersion != l.ResourceVersion {
+		return nil, k8serrors.NewConflict(leaseResource, l.Name, errors.New("resource version changed"))
+	}
+	c.version++
+	l = l.DeepCopy()
+	l.ResourceVersion = strconv.Itoa(c.version)
+	c.leases[l.Name] = l
+	return l, nil
+}
+
+func TestLeaseBackend(t *testing.T) {
+	ctx := context.Background()
+	cli := &fakeLeaseClient{leases: map[string]*coordinationv1.Lease{}}
+
+	b1 := &leaseBackend{client: cli, name: "datakit-election-default", leaseDuration: 15 * time.Second}
+	b2 := &leaseBackend{client: cli, name: "datakit-election-default", leaseDuration: 15 * time.Second}
+
+	leader, elected, err := b1.Campaign(ctx, "dk-1")
+	require.NoError(t, err)
+	assert.True(t, elected)
+	assert.Equal(t, "dk-1", leader)
+
+	leader, elected, err = b2.Campaign(ctx, "dk-2")
+	require.NoError(t, err)
+	assert.False(t, elected)
+	assert.Equal(t, "dk-1", leader)
+
+	// renew
+	leader, elected, err = b1.Campaign(ctx, "dk-1")
+	require.NoError(t, err)
+	assert.True(t, elected)
+	assert.Equal(t, "dk-1", leader)
+
+	// take over the expired lease
+	expired := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+	cli.leases[b1.name].Spec.RenewTime = &expired
+
+	leader, elected, err = b2.Campaign(ctx, "dk-2")
+	require.NoError(t, err)
+	assert.True(t, elected)
+	assert.Equal(t, "dk-2", leader)
+	assert.Equal(t, int32(1), *cli.leases[b1.name].Spec.LeaseTransitions)
+
+	_, elected, err = b1.Campaign(ctx, "dk-1")
+	require.NoError(t, err)
+	assert.False(t, elected)
+
+	// resign by non-leader is a no-op
+	require.NoError(t, b1.Resign(ctx, "dk-1"))
+	assert.Equal(t, "dk-2", *cli.leases[b1.name].Spec.HolderIdentity)
+
+	require.NoError(t, b2.Resign(ctx, "dk-2"))
+	assert.Nil(t, cli.leases[b1.name].Spec.HolderIdentity)
+
+	leader, elected, err = b1.Campaign(ctx, "dk-1")
+	require.NoError(t, err)
+	assert.True(t, elected)
+	assert.Equal(t, "dk-1", leader)
+}
+
+func TestFlockBackend(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("flock backend not supported on windows")
+	}
+
+	ctx := context.Background()
+	cfg := &FlockBackendCfg{Path: filepath.Join(t.TempDir(), "election.lock")}
+
+	b1, err := newFlockBackend(cfg, "default")
+	require.NoError(t, err)
+	b2, err := newFlockBackend(cfg, "default")
+	require.NoError(t, err)
+
+	leader, elected, err := b1.Campaign(ctx, "dk-1")
+	require.NoError(t, err)
+	assert.True(t, elected)
+	assert.Equal(t, "dk-1", leader)
+
+	leader, elected, err = b2.Campaign(ctx, "dk-2")
+	require.NoError(t, err)
+	assert.False(t, elected)
+	assert.Equal(t, "dk-1", leader)
+
+	require.NoError(t, b1.Resign(ctx, "dk-1"))
+
+	leader, elected, err = b2.Campaign(ctx, "dk-2")
+	require.NoError(t, err)
+	assert.True(t, elected)
+	assert.Equal(t, "dk-2", leader)
+
+	require.NoError(t, b2.Resign(ctx, "dk-2"))
+}
@@ -69,6 +69,14 @@ func Start(opts ...ElectionOption) {
 		electionInstance = newTaskElection(&opt, inputs.GetElectionInputs())
 		opt.namespace = "N/A"
 		log.Info("election mode with Operator")
+	case modeBackend:
+		if opt.backendErr != nil {
+			electionInstance = newPausedElection(&opt, inputs.GetElectionInputs())
+			break
+		}
+
+		electionInstance = newBackendElection(&opt, inputs.GetElectionInputs())
+		log.Infof("election mode with %s", opt.backend.Name())
 	default:
 		log.Info("invalid election mode, election not enabled")
 		return
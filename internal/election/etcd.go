@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package election
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	dknet "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/net"
+)
+
+const (
+	defaultEtcdPrefix      = "/datakit/election"
+	defaultEtcdDialTimeout = 5 * time.Second
+)
+
+// EtcdBackendCfg is the configure of election on etcd.
+type EtcdBackendCfg struct {
+	Endpoints []string `toml:"endpoints"`
+	Username  string   `toml:"username"`
+	Password  string   `toml:"password"`
+
+	// Prefix of the election key, the key is <prefix>/<election-namespace>.
+	Prefix string `toml:"prefix"`
+
+	TLSClientConfig *dknet.TLSClientConfig `toml:"tls_config,omitempty"`
+}
+
+type etcdBackend struct {
+	cli     *clientv3.Client
+	key     string
+	ttl     int64
+	leaseID clientv3.LeaseID
+}
+
+func newEtcdBackend(cfg *EtcdBackendCfg, electionNamespace string, leaseDuration time.Duration) (*etcdBackend, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, fmt.Errorf("etcd endpoints required")
+	}
+
+	conf := clientv3.Config{
+		Endpoints:   cfg.Endpoints,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		DialTimeout: defaultEtcdDialTimeout,
+	}
+
+	if cfg.TLSClientConfig != nil {
+		tlsConfig, err := cfg.TLSClientConfig.TLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("etcd tls config: %w", err)
+		}
+		conf.TLS = tlsConfig
+	}
+
+	cli, err := clientv3.New(conf)
+	if err != nil {
+		return nil, fmt.Errorf("etcd client: %w", err)
+	}
+
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = defaultEtcdPrefix
+	}
+
+	ttl := int64(leaseDuration / time.Second)
+	if ttl < 1 {
+		ttl = 1
+	}
+
+	return &etcdBackend{
+		cli: cli,
+		key: path.Join(prefix, electionNamespace),
+		ttl: ttl,
+	}, nil
+}
+
+func (b *etcdBackend) Name() string { return BackendEtcd }
+
+// Campaign puts the election key with our lease if the key not exist, the key
+// is removed by etcd once the lease expired.
+func (b *etcdBackend) Campaign(ctx context.Context, id string) (string, bool, error) {
+	if b.leaseID != clientv3.NoLease {
+		if _, err := b.cli.KeepAliveOnce(ctx, b.leaseID); err != nil {
+			if !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+				return "", false, fmt.Errorf("keepalive lease: %w", err)
+			}
+			b.leaseID = clientv3.NoLease
+		}
+	}
+
+	if b.leaseID == clientv3.NoLease {
+		resp, err := b.cli.Grant(ctx, b.ttl)
+		if err != nil {
+			return "", false, fmt.Errorf("grant lease: %w", err)
+		}
+		b.leaseID = resp.ID
+	}
+
+	resp, err := b.cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(b.key), "=", 0)).
+		Then(clientv3.OpPut(b.key, id, clientv3.WithLease(b.leaseID))).
+		Else(clientv3.OpGet(b.key)).
+		Commit()
+	if err != nil {
+		return "", false, fmt.Errorf("txn on %s: %w", b.key, err)
+	}
+
+	if resp.Succeeded {
+		return id, true, nil
+	}
+
+	for _, r := range resp.Responses {
+		for _, kv := range r.GetResponseRange().GetKvs() {
+			return string(kv.Value), clientv3.LeaseID(kv.Lease) == b.leaseID, nil
+		}
+	}
+
+	// the key removed just now, try next time
+	return "", false, nil
+}
+
+func (b *etcdBackend) Resign(ctx context.Context, _ string) error {
+	if b.leaseID == clientv3.NoLease {
+		return nil
+	}
+
+	// the key attached to the lease is deleted along with the lease
+	_, err := b.cli.Revoke(ctx, b.leaseID)
+	b.leaseID = clientv3.NoLease
+	return err
+}
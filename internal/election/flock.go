@@ -0,0 +1,23 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package election
+
+import (
+	"path/filepath"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+)
+
+// FlockBackendCfg is the configure of election on a local file lock, it's used
+// for the DataKits running on the same host(or sharing the same file system).
+type FlockBackendCfg struct {
+	// Path of the lock file, default to <datakit-data-dir>/election-<election-namespace>.lock.
+	Path string `toml:"path"`
+}
+
+func defaultFlockPath(electionNamespace string) string {
+	return filepath.Join(datakit.DataDir, "election-"+electionNamespace+".lock")
+}
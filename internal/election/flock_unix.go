@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+//go:build !windows
+// +build !windows
+
+package election
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+type flockBackend struct {
+	path string
+	f    *os.File
+}
+
+func newFlockBackend(cfg *FlockBackendCfg, electionNamespace string) (*flockBackend, error) {
+	p := cfg.Path
+	if p == "" {
+		p = defaultFlockPath(electionNamespace)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return nil, err
+	}
+
+	return &flockBackend{path: p}, nil
+}
+
+func (b *flockBackend) Name() string { return BackendFlock }
+
+// Campaign tries to lock the file exclusively, the lock is held until resigned
+// or the process exited. The leader ID is written to the file.
+func (b *flockBackend) Campaign(_ context.Context, id string) (string, bool, error) {
+	if b.f != nil {
+		return id, true, nil
+	}
+
+	f, err := os.OpenFile(b.path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return "", false, err
+	}
+
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		_ = f.Close() //nolint:errcheck
+
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			leader, _ := os.ReadFile(b.path)
+			return strings.TrimSpace(string(leader)), false, nil
+		}
+		return "", false, fmt.Errorf("flock %s: %w", b.path, err)
+	}
+
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(id), 0) //nolint:errcheck
+	}
+
+	b.f = f
+	return id, true, nil
+}
+
+func (b *flockBackend) Resign(_ context.Context, _ string) error {
+	if b.f == nil {
+		return nil
+	}
+
+	f := b.f
+	b.f = nil
+
+	_ = f.Truncate(0) //nolint:errcheck
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_UN); err != nil {
+		_ = f.Close() //nolint:errcheck
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+//go:build windows
+// +build windows
+
+package election
+
+import (
+	"fmt"
+)
+
+type flockBackend struct {
+	Backend
+}
+
+func newFlockBackend(_ *FlockBackendCfg, _ string) (*flockBackend, error) {
+	return nil, fmt.Errorf("flock election backend not supported on windows")
+}
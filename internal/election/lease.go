@@ -0,0 +1,178 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package election
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationclientv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+
+	k8sclient "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/kubernetes/client"
+)
+
+const (
+	defaultLeaseNamespace = "datakit"
+
+	//nolint:gosec
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// KubernetesBackendCfg is the configure of election on Kubernetes coordination.k8s.io Lease.
+type KubernetesBackendCfg struct {
+	// Namespace of the Lease, default to the namespace of DataKit Pod.
+	LeaseNamespace string `toml:"lease_namespace"`
+
+	// Name of the Lease, default to datakit-election-<election-namespace>.
+	LeaseName string `toml:"lease_name"`
+}
+
+// leaseClient is the subset of the Lease client used by leaseBackend.
+type leaseClient interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*coordinationv1.Lease, error)
+	Create(ctx context.Context, lease *coordinationv1.Lease, opts metav1.CreateOptions) (*coordinationv1.Lease, error)
+	Update(ctx context.Context, lease *coordinationv1.Lease, opts metav1.UpdateOptions) (*coordinationv1.Lease, error)
+}
+
+type leaseBackend struct {
+	client        leaseClient
+	name          string
+	leaseDuration time.Duration
+}
+
+func newLeaseBackend(cfg *KubernetesBackendCfg, electionNamespace string, leaseDuration time.Duration) (*leaseBackend, error) {
+	ns := cfg.LeaseNamespace
+	if ns == "" {
+		if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil && len(data) > 0 {
+			ns = strings.TrimSpace(string(data))
+		} else {
+			ns = defaultLeaseNamespace
+		}
+	}
+
+	name := cfg.LeaseName
+	if name == "" {
+		name = "datakit-election-" + strings.ToLower(electionNamespace)
+	}
+
+	restConfig, err := k8sclient.DefaultConfigInCluster()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes config: %w", err)
+	}
+
+	cli, err := coordinationclientv1.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client: %w", err)
+	}
+
+	return &leaseBackend{
+		client:        cli.Leases(ns),
+		name:          name,
+		leaseDuration: leaseDuration,
+	}, nil
+}
+
+func (b *leaseBackend) Name() string { return BackendKubernetes }
+
+func (b *leaseBackend) Campaign(ctx context.Context, id string) (string, bool, error) {
+	now := metav1.NewMicroTime(time.Now())
+	durationSeconds := int32(b.leaseDuration / time.Second)
+
+	lease, err := b.client.Get(ctx, b.name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return "", false, fmt.Errorf("get lease %s: %w", b.name, err)
+		}
+
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: b.name},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &id,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+
+		if _, err := b.client.Create(ctx, lease, metav1.CreateOptions{}); err != nil {
+			if k8serrors.IsAlreadyExists(err) { // created by others
+				return "", false, nil
+			}
+			return "", false, fmt.Errorf("create lease %s: %w", b.name, err)
+		}
+		return id, true, nil
+	}
+
+	holder := ""
+	if lease.Spec.HolderIdentity != nil {
+		holder = *lease.Spec.HolderIdentity
+	}
+
+	if holder != "" && holder != id && !leaseExpired(lease, now.Time) {
+		return holder, false, nil
+	}
+
+	lease = lease.DeepCopy()
+	if holder != id {
+		var transitions int32
+		if lease.Spec.LeaseTransitions != nil {
+			transitions = *lease.Spec.LeaseTransitions
+		}
+		if holder != "" {
+			transitions++
+		}
+
+		lease.Spec.HolderIdentity = &id
+		lease.Spec.AcquireTime = &now
+		lease.Spec.LeaseTransitions = &transitions
+	}
+	lease.Spec.RenewTime = &now
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+
+	// the update fails on conflict if the lease updated by others
+	if _, err := b.client.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		if k8serrors.IsConflict(err) {
+			return holder, false, nil
+		}
+		return holder, false, fmt.Errorf("update lease %s: %w", b.name, err)
+	}
+
+	return id, true, nil
+}
+
+func (b *leaseBackend) Resign(ctx context.Context, id string) error {
+	lease, err := b.client.Get(ctx, b.name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != id {
+		return nil
+	}
+
+	lease = lease.DeepCopy()
+	lease.Spec.HolderIdentity = nil
+	lease.Spec.AcquireTime = nil
+	lease.Spec.RenewTime = nil
+
+	_, err = b.client.Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+func leaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+
+	expire := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return now.After(expire)
+}
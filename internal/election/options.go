@@ -5,12 +5,19 @@
 
 package election
 
+import "time"
+
 type option struct {
 	enabled       bool
 	namespace, id string
 	nodeWhitelist []string
 	puller        Puller
 	mode          electionMode
+
+	backend       Backend
+	backendErr    error
+	leaseDuration time.Duration
+	renewInterval time.Duration
 }
 
 type ElectionOption func(opt *option)
@@ -53,9 +60,43 @@ func WithOperatorPuller(p Puller) ElectionOption {
 	}
 }
 
+// WithBackend elect on the self-hosted backend instead of Dataway.
+func WithBackend(b Backend) ElectionOption {
+	return func(opt *option) {
+		opt.backend = b
+		opt.mode = modeBackend
+	}
+}
+
+// WithBackendError set the error of creating the election backend, the election
+// inputs keep paused instead of electing on other backend.
+func WithBackendError(err error) ElectionOption {
+	return func(opt *option) {
+		opt.backendErr = err
+		opt.mode = modeBackend
+	}
+}
+
+// WithLeaseDuration set how long the leadership keeps without renewing, only
+// used by backend election.
+func WithLeaseDuration(du time.Duration) ElectionOption {
+	return func(opt *option) {
+		opt.leaseDuration = du
+	}
+}
+
+// WithRenewInterval set the interval of renewing the leadership, only used by
+// backend election.
+func WithRenewInterval(du time.Duration) ElectionOption {
+	return func(opt *option) {
+		opt.renewInterval = du
+	}
+}
+
 type electionMode int
 
 const (
 	modeDataway electionMode = iota + 1
 	modeOperator
+	modeBackend
 )
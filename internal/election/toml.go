@@ -5,6 +5,19 @@
 
 package election
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	BackendDataway    = "dataway"
+	BackendKubernetes = "kubernetes"
+	BackendEtcd       = "etcd"
+	BackendFlock      = "flock"
+)
+
 // ElectionCfg defined election configure in datakit.conf.
 type ElectionCfg struct {
 	Enable             bool     `toml:"enable"`
@@ -13,4 +26,60 @@ type ElectionCfg struct {
 
 	Namespace string            `toml:"namespace"`
 	Tags      map[string]string `toml:"tags"`
+
+	// Backend of the election, one of dataway(default), kubernetes, etcd and flock.
+	Backend       string        `toml:"backend,omitempty"`
+	LeaseDuration time.Duration `toml:"lease_duration,omitempty"`
+	RenewInterval time.Duration `toml:"renew_interval,omitempty"`
+
+	Kubernetes *KubernetesBackendCfg `toml:"kubernetes,omitempty"`
+	Etcd       *EtcdBackendCfg       `toml:"etcd,omitempty"`
+	Flock      *FlockBackendCfg      `toml:"flock,omitempty"`
+}
+
+// NewBackend create the self-hosted election backend, it returns nil if the
+// election is on Dataway or datakit-operator.
+func (c *ElectionCfg) NewBackend() (Backend, error) {
+	leaseDuration := c.LeaseDuration
+	if leaseDuration <= 0 {
+		leaseDuration = defaultLeaseDuration
+	}
+
+	var (
+		b   Backend
+		err error
+	)
+
+	switch strings.ToLower(c.Backend) {
+	case "", BackendDataway:
+		return nil, nil
+
+	case BackendKubernetes:
+		cfg := c.Kubernetes
+		if cfg == nil {
+			cfg = &KubernetesBackendCfg{}
+		}
+		b, err = newLeaseBackend(cfg, c.Namespace, leaseDuration)
+
+	case BackendEtcd:
+		if c.Etcd == nil {
+			return nil, fmt.Errorf("missing [election.etcd] configure")
+		}
+		b, err = newEtcdBackend(c.Etcd, c.Namespace, leaseDuration)
+
+	case BackendFlock:
+		cfg := c.Flock
+		if cfg == nil {
+			cfg = &FlockBackendCfg{}
+		}
+		b, err = newFlockBackend(cfg, c.Namespace)
+
+	default:
+		return nil, fmt.Errorf("unknown election backend %q", c.Backend)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
 }
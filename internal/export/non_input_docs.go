@@ -207,6 +207,21 @@ func envDataway() []*inputs.ENVInfo {
 			DescZh:  "数据上传时单包（未压缩）大小",
 		},
 
+		{
+			ENVName: "ENV_DATAWAY_COMPRESSION",
+			Type:    doc.String,
+			Default: `gzip`,
+			Desc:    "Set the compression of the upload body (optional list: `gzip`/`zstd`/`snappy`/`none`)",
+			DescZh:  "设置上传数据的压缩算法（可选列表：`gzip`/`zstd`/`snappy`/`none`）",
+		},
+
+		{
+			ENVName: "ENV_DATAWAY_ENABLE_CIRCUIT_BREAKER",
+			Type:    doc.Boolean,
+			Desc:    "Enable circuit breaker on Dataway URLs",
+			DescZh:  "开启 Dataway 地址上的熔断机制",
+		},
+
 		{
 			ENVName: "ENV_DATAWAY_CONTENT_ENCODING",
 			Type:    doc.String,
@@ -374,6 +389,74 @@ func envElect() []*inputs.ENVInfo {
 			Desc:    "List of node names that are allowed to participate in elections [:octicons-tag-24: Version-1.35.0](changelog.md#cl-1.35.0)",
 			DescZh:  "允许参加选举的节点名称列表 [:octicons-tag-24: Version-1.35.0](changelog.md#cl-1.35.0)",
 		},
+		{
+			ENVName: "ENV_ELECTION_BACKEND",
+			Type:    doc.String,
+			Default: "dataway",
+			Desc:    "[Election backend](election.md#self-hosted-backend), one of `dataway`/`kubernetes`/`etcd`/`flock` [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "[选举后端](election.md#self-hosted-backend)，可选 `dataway`/`kubernetes`/`etcd`/`flock` [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+		{
+			ENVName: "ENV_ELECTION_LEASE_DURATION",
+			Type:    doc.TimeDuration,
+			Default: "15s",
+			Desc:    "Lease duration of the self-hosted election backend, the leader steps down if it can not renew within the duration [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "自托管选举后端的租约时长，Leader 在该时长内无法续约则主动退出 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+		{
+			ENVName: "ENV_ELECTION_RENEW_INTERVAL",
+			Type:    doc.TimeDuration,
+			Default: "5s",
+			Desc:    "Renew interval of the self-hosted election backend [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "自托管选举后端的续约间隔 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+		{
+			ENVName: "ENV_ELECTION_K8S_LEASE_NAMESPACE",
+			Type:    doc.String,
+			Example: "datakit",
+			Desc:    "Namespace of the Kubernetes Lease, default to the namespace of DataKit Pod [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "Kubernetes Lease 所在的命名空间，默认为 DataKit Pod 所在的命名空间 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+		{
+			ENVName: "ENV_ELECTION_K8S_LEASE_NAME",
+			Type:    doc.String,
+			Example: "datakit-election-default",
+			Desc:    "Name of the Kubernetes Lease, default to `datakit-election-<namespace>` [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "Kubernetes Lease 名称，默认为 `datakit-election-<namespace>` [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+		{
+			ENVName: "ENV_ELECTION_ETCD_ENDPOINTS",
+			Type:    doc.List,
+			Example: "http://etcd-0:2379,http://etcd-1:2379",
+			Desc:    "Endpoints of etcd election backend, multiple endpoints are divided by English commas [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "etcd 选举后端地址，多个地址之间以英文逗号分割 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+		{
+			ENVName: "ENV_ELECTION_ETCD_USERNAME",
+			Type:    doc.String,
+			Desc:    "Username of etcd election backend [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "etcd 选举后端用户名 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+		{
+			ENVName: "ENV_ELECTION_ETCD_PASSWORD",
+			Type:    doc.String,
+			Desc:    "Password of etcd election backend [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "etcd 选举后端密码 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+		{
+			ENVName: "ENV_ELECTION_ETCD_PREFIX",
+			Type:    doc.String,
+			Default: "/datakit/election",
+			Desc:    "Key prefix of etcd election backend [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "etcd 选举后端的 key 前缀 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+		{
+			ENVName: "ENV_ELECTION_FLOCK_PATH",
+			Type:    doc.String,
+			Example: "/usr/local/datakit/data/election-default.lock",
+			Desc:    "Lock file path of flock election backend, default to `election-<namespace>.lock` under DataKit data directory [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "flock 选举后端的锁文件路径，默认为 DataKit 数据目录下的 `election-<namespace>.lock` [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
 	}
 
 	for idx := range infos {
@@ -455,6 +538,30 @@ func envHTTPAPI() []*inputs.ENVInfo {
 			DescZh:  "配置 DataKit HTTP Server 上的 TLS key 路径 [:octicons-tag-24: Version-1.29.0](changelog.md#cl-1.29.0)",
 		},
 
+		{
+			ENVName: "ENV_HTTP_TLS_CA",
+			Type:    doc.String,
+			Default: "-",
+			Desc:    "Set CA path to verify client certificates(mTLS) [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "配置用于校验客户端证书（mTLS）的 CA 路径 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+
+		{
+			ENVName: "ENV_HTTP_AUTH_CREDENTIALS",
+			Type:    doc.JSON,
+			Example: `[{"token":"<some-random-token>","scopes":["write"]},{"cert_cn":"sidecar","scopes":["admin"]}]`,
+			Desc:    "Enable [HTTP API authentication](datakit-conf.md#http-api-auth) with these credentials [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "开启 [HTTP API 认证](datakit-conf.md#http-api-auth)并配置认证凭证 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+
+		{
+			ENVName: "ENV_HTTP_AUTH_ALLOW_LOOPBACK",
+			Type:    doc.Boolean,
+			Default: "-",
+			Desc:    "Requests from localhost skip HTTP API authentication [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+			DescZh:  "来自 localhost 的请求跳过 HTTP API 认证 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)",
+		},
+
 		{
 			ENVName: "ENV_REQUEST_RATE_LIMIT",
 			Type:    doc.Float,
@@ -698,6 +805,12 @@ func envRecorder() []*inputs.ENVInfo {
 		{ENVName: "ENV_RECORDER_DURATION", Type: doc.TimeDuration, Default: "30m", Desc: "Set recorder duration(since DataKit start). After the duration, the recorder will stop to write data to file", DescZh: "设置数据录制时长（自 DataKit 启动以后），一旦超过该时长，则不再录制"},
 		{ENVName: "ENV_RECORDER_INPUTS", Type: doc.List, Example: "cpu,mem,disk", Desc: "Set allowed input names for recording, split by comma", DescZh: "设置录制的采集器名称列表，以英文逗号分割"},
 		{ENVName: "ENV_RECORDER_CATEGORIES", Type: doc.List, Example: "metric,logging,object", Desc: "Set allowed categories for recording, split by comma, full list of categories see [here](apis.md#category)", DescZh: "设置录制的数据分类列表，以英文逗号分割，完整的 Category 列表参见[这里](apis.md#category)"},
+		{ENVName: "ENV_ENABLE_RECORDER_REPLAY", Type: doc.Boolean, Default: "false", Desc: "To enable replaying recorded data through IO [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)", DescZh: "开启经由 IO 回放录制的数据 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)"},
+		{ENVName: "ENV_RECORDER_REPLAY_PATH", Type: doc.String, Default: "*[DataKit install path]/recorder*", Desc: "Set the path of recorded data to replay", DescZh: "设置回放数据的目录"},
+		{ENVName: "ENV_RECORDER_REPLAY_INPUT", Type: doc.String, Example: "cpu", Desc: "Feed all replayed data as the input, if empty, use the input recorded in data file name", DescZh: "以该采集器的名义送入所有回放数据，为空则使用数据文件名中记录的采集器名"},
+		{ENVName: "ENV_RECORDER_REPLAY_SPEED", Type: doc.Float, Default: "1.0", Desc: "Multiple of the recording pace, 0 means replay as fast as possible", DescZh: "录制速度的倍数，0 表示尽可能快地回放"},
+		{ENVName: "ENV_RECORDER_REPLAY_RATE", Type: doc.Int, Default: "0", Desc: "Max points fed per second, 0 means no limit", DescZh: "每秒送入的最大点数，0 表示不限制"},
+		{ENVName: "ENV_RECORDER_REPLAY_LOOP", Type: doc.Boolean, Default: "false", Desc: "Replay again and again until DataKit exit", DescZh: "循环回放，直到 DataKit 退出"},
 	}
 
 	for idx := range infos {
@@ -719,6 +832,12 @@ func envOthers() []*inputs.ENVInfo {
 		{ENVName: "ENV_PIPELINE_DISABLE_APPEND_RUN_INFO", Type: doc.Boolean, Default: "`false`", Desc: "Disable appending the Pipeline run info", DescZh: "禁用追加 Pipeline 运行信息"},
 		{ENVName: "ENV_CRYPTO_AES_KEY", Type: doc.String, Example: "`0123456789abcdef`", Desc: "The crypto key(len 16)", DescZh: "AES 加解密的 key 长度是 16"},
 		{ENVName: "ENV_CRYPTO_AES_KEY_FILE", Type: doc.String, Example: "`/usr/local/datakit/enc4mysql`", Desc: "File path for storing AES encryption and decryption key", DescZh: "AES 加解密的 key 存放的文件路径"},
+		{ENVName: "ENV_SECRET_TTL", Type: doc.TimeDuration, Default: "5m", Desc: "Cache TTL of the resolved `secret://` references [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)", DescZh: "`secret://` 引用解析结果的缓存时长 [:octicons-tag-24: Version-1.85.0](changelog-2025.md#cl-1.85.0)"},
+		{ENVName: "ENV_SECRET_VAULT_ADDRESS", Type: doc.String, Example: "`https://vault.example.com:8200`", Desc: "Address of HashiCorp Vault for `secret://vault/...`", DescZh: "`secret://vault/...` 使用的 HashiCorp Vault 地址"},
+		{ENVName: "ENV_SECRET_VAULT_TOKEN_FILE", Type: doc.String, Example: "`/vault/secrets/token`", Desc: "File path of the Vault token, re-read on each request", DescZh: "Vault token 文件路径，每次请求都会重新读取"},
+		{ENVName: "ENV_SECRET_VAULT_NAMESPACE", Type: doc.String, Desc: "Vault namespace", DescZh: "Vault namespace"},
+		{ENVName: "ENV_SECRET_VAULT_KV_VERSION", Type: doc.Int, Default: "2", Desc: "Version of Vault KV secrets engine, 1 or 2", DescZh: "Vault KV 引擎版本，1 或 2"},
+		{ENVName: "ENV_SECRET_K8S_NAMESPACE", Type: doc.String, Desc: "Default namespace of `secret://k8s/...`, default to the namespace of DataKit Pod", DescZh: "`secret://k8s/...` 的默认 namespace，默认为 DataKit Pod 所在的 namespace"},
 
 		{ENVName: "ENV_LOGGING_MAX_OPEN_FILES", Type: doc.Int, Example: "`1000`", Desc: "Specify the maximum number of open files for logging collection, if the value is -1 then there is no limit, default 500", DescZh: "指定日志采集的最大文件个数，如果值是 -1 则没有限制，默认值 500"},
 	}
@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package httpapi
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	uhttp "github.com/GuanceCloud/cliutils/network/http"
+	"github.com/gin-gonic/gin"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/config"
+)
+
+// API scopes. The admin scope grants all other scopes.
+const (
+	ScopeWrite = "write"
+	ScopeQuery = "query"
+	ScopeAdmin = "admin"
+)
+
+type scopeSet map[string]bool
+
+func newScopeSet(scopes []string) (scopeSet, error) {
+	ss := scopeSet{}
+	for _, s := range scopes {
+		switch s = strings.ToLower(strings.TrimSpace(s)); s {
+		case ScopeWrite, ScopeQuery, ScopeAdmin:
+			ss[s] = true
+		default:
+			return nil, fmt.Errorf("unknown scope %q", s)
+		}
+	}
+
+	if len(ss) == 0 {
+		return nil, fmt.Errorf("scopes required")
+	}
+
+	return ss, nil
+}
+
+func (ss scopeSet) allow(scope string) bool {
+	return ss[ScopeAdmin] || ss[scope]
+}
+
+// routeScope get the scope required by the API, empty scope means the API is open to all.
+func routeScope(method, path string) string {
+	switch {
+	case path == "/v1/ping", path == "/v1/ntp":
+		return ""
+
+	case path == "/restart",
+		path == "/v1/object/labels",
+		path == "/v1/pipeline/debug",
+		path == "/v1/dialtesting/debug",
+		path == "/v1/env_variable",
+		path == "/v1/sourcemap",
+		strings.HasPrefix(path, "/v1/dca/"):
+		return ScopeAdmin
+
+	case strings.HasPrefix(path, "/v1/global/"):
+		if method == http.MethodGet {
+			return ScopeQuery
+		}
+		return ScopeAdmin
+
+	case path == "/v1/query/raw",
+		path == "/v1/workspace",
+		path == "/v1/sourcemap/check",
+		path == "/metrics":
+		return ScopeQuery
+
+	default: // APIs to upload data, such as /v1/write/:category and tracing APIs
+		return ScopeWrite
+	}
+}
+
+type apiAuth struct {
+	allowLoopback bool
+	tokens        map[string]scopeSet
+	certs         map[string]scopeSet // client certificate common name -> scopes
+}
+
+func newAPIAuth(conf *config.APIAuthConfig) (*apiAuth, error) {
+	auth := &apiAuth{
+		allowLoopback: conf.AllowLoopback,
+		tokens:        map[string]scopeSet{},
+		certs:         map[string]scopeSet{},
+	}
+
+	for _, c := range conf.Credentials {
+		if c.Token == "" && c.CertCN == "" {
+			return nil, fmt.Errorf("token or cert_cn required for API credential")
+		}
+
+		ss, err := newScopeSet(c.Scopes)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Token != "" {
+			auth.tokens[c.Token] = ss
+		}
+
+		if c.CertCN != "" {
+			auth.certs[c.CertCN] = ss
+		}
+	}
+
+	if len(auth.tokens) == 0 && len(auth.certs) == 0 {
+		return nil, fmt.Errorf("no API credential configured")
+	}
+
+	return auth, nil
+}
+
+// scopes get scopes of the request on its bearer token or client certificate.
+func (auth *apiAuth) scopes(req *http.Request) (scopeSet, bool) {
+	if token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "); token != "" {
+		for k, ss := range auth.tokens {
+			if subtle.ConstantTimeCompare([]byte(k), []byte(token)) == 1 {
+				return ss, true
+			}
+		}
+	}
+
+	// only verified client certificate accepted.
+	if req.TLS != nil && len(req.TLS.VerifiedChains) > 0 && len(req.TLS.PeerCertificates) > 0 {
+		if ss, ok := auth.certs[req.TLS.PeerCertificates[0].Subject.CommonName]; ok {
+			return ss, true
+		}
+	}
+
+	return nil, false
+}
+
+func apiAuthMiddleware(auth *apiAuth) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := routeScope(c.Request.Method, c.Request.URL.Path)
+		if scope == "" || (auth.allowLoopback && isLoopbackClient(c)) {
+			c.Next()
+			return
+		}
+
+		ss, ok := auth.scopes(c.Request)
+		if !ok {
+			uhttp.HttpErr(c, uhttp.Errorf(ErrUnauthorized, "api %s requires authentication", c.Request.URL.Path))
+			c.Abort()
+			return
+		}
+
+		if !ss.allow(scope) {
+			uhttp.HttpErr(c, uhttp.Errorf(ErrForbidden, "api %s requires scope %q", c.Request.URL.Path, scope))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// clientTLSConfig setup TLS config to verify client certificates if CA configured.
+func clientTLSConfig(conf *config.TLSConfig) (*tls.Config, error) {
+	if conf == nil || conf.CA == "" {
+		return nil, nil
+	}
+
+	ca, err := os.ReadFile(conf.CA)
+	if err != nil {
+		return nil, fmt.Errorf("read CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("invalid CA %q", conf.CA)
+	}
+
+	return &tls.Config{
+		ClientCAs: pool,
+		// client without certificate may authenticate with bearer token.
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
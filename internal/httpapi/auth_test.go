@@ -0,0 +1,240 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package httpapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	T "testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/config"
+)
+
+func TestRouteScope(t *T.T) {
+	cases := []struct {
+		method, path, scope string
+	}{
+		{http.MethodGet, "/v1/ping", ""},
+		{http.MethodPost, "/v1/write/metric", ScopeWrite},
+		{http.MethodPost, "/v0.4/traces", ScopeWrite},
+		{http.MethodPost, "/v1/query/raw", ScopeQuery},
+		{http.MethodGet, "/metrics", ScopeQuery},
+		{http.MethodGet, "/v1/global/host/tags", ScopeQuery},
+		{http.MethodPost, "/v1/global/host/tags", ScopeAdmin},
+		{http.MethodDelete, "/v1/global/election/tags", ScopeAdmin},
+		{http.MethodGet, "/restart", ScopeAdmin},
+		{http.MethodGet, "/v1/dca/reload", ScopeAdmin},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.scope, routeScope(tc.method, tc.path), "%s %s", tc.method, tc.path)
+	}
+}
+
+func newAuthRouter(t *T.T, conf *config.APIAuthConfig) *gin.Engine {
+	t.Helper()
+
+	auth, err := newAPIAuth(conf)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(apiAuthMiddleware(auth))
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/v1/ping", ok)
+	router.POST("/v1/write/:category", ok)
+	router.POST("/v1/query/raw", ok)
+	router.GET("/restart", ok)
+	router.POST("/v1/global/host/tags", ok)
+
+	return router
+}
+
+func TestAPIAuth(t *T.T) {
+	t.Run("invalid-conf", func(t *T.T) {
+		_, err := newAPIAuth(&config.APIAuthConfig{Enable: true})
+		assert.Error(t, err)
+
+		_, err = newAPIAuth(&config.APIAuthConfig{
+			Credentials: []*config.APICredential{{Token: "abc", Scopes: []string{"unknown"}}},
+		})
+		assert.Error(t, err)
+
+		_, err = newAPIAuth(&config.APIAuthConfig{
+			Credentials: []*config.APICredential{{Scopes: []string{ScopeWrite}}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("token", func(t *T.T) {
+		router := newAuthRouter(t, &config.APIAuthConfig{
+			Enable: true,
+			Credentials: []*config.APICredential{
+				{Token: "writer", Scopes: []string{ScopeWrite}},
+				{Token: "reader", Scopes: []string{ScopeQuery}},
+				{Token: "admin", Scopes: []string{ScopeAdmin}},
+			},
+		})
+
+		cases := []struct {
+			method, path, token string
+			code                int
+		}{
+			{http.MethodGet, "/v1/ping", "", http.StatusOK},
+			{http.MethodPost, "/v1/write/metric", "", http.StatusUnauthorized},
+			{http.MethodPost, "/v1/write/metric", "invalid", http.StatusUnauthorized},
+			{http.MethodPost, "/v1/write/metric", "writer", http.StatusOK},
+			{http.MethodPost, "/v1/query/raw", "writer", http.StatusForbidden},
+			{http.MethodGet, "/restart", "writer", http.StatusForbidden},
+			{http.MethodPost, "/v1/global/host/tags", "writer", http.StatusForbidden},
+			{http.MethodPost, "/v1/query/raw", "reader", http.StatusOK},
+			{http.MethodPost, "/v1/write/metric", "reader", http.StatusForbidden},
+			{http.MethodGet, "/restart", "admin", http.StatusOK},
+			{http.MethodPost, "/v1/global/host/tags", "admin", http.StatusOK},
+			{http.MethodPost, "/v1/write/metric", "admin", http.StatusOK},
+		}
+
+		for _, tc := range cases {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tc.code, w.Code, "%s %s with token %q", tc.method, tc.path, tc.token)
+		}
+	})
+
+	t.Run("loopback", func(t *T.T) {
+		for _, allow := range []bool{true, false} {
+			router := newAuthRouter(t, &config.APIAuthConfig{
+				Enable:        true,
+				AllowLoopback: allow,
+				Credentials:   []*config.APICredential{{Token: "admin", Scopes: []string{ScopeAdmin}}},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/restart", nil)
+			req.RemoteAddr = "127.0.0.1:12345"
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if allow {
+				assert.Equal(t, http.StatusOK, w.Code)
+			} else {
+				assert.Equal(t, http.StatusUnauthorized, w.Code)
+			}
+		}
+	})
+
+	t.Run("mtls", func(t *T.T) {
+		dir := t.TempDir()
+
+		caCert, caKey := genCert(t, "test-ca", nil, nil)
+		caFile := filepath.Join(dir, "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}), 0o600))
+
+		tlsConf, err := clientTLSConfig(&config.TLSConfig{CA: caFile})
+		require.NoError(t, err)
+
+		serverCert, serverKey := genCert(t, "127.0.0.1", caCert, caKey)
+		tlsConf.Certificates = []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}}
+
+		ts := httptest.NewUnstartedServer(newAuthRouter(t, &config.APIAuthConfig{
+			Enable: true,
+			Credentials: []*config.APICredential{
+				{CertCN: "sidecar", Scopes: []string{ScopeWrite}},
+				{Token: "admin", Scopes: []string{ScopeAdmin}},
+			},
+		}))
+		ts.TLS = tlsConf
+		ts.StartTLS()
+		defer ts.Close()
+
+		pool := x509.NewCertPool()
+		pool.AddCert(caCert)
+
+		newClient := func(cn string) *http.Client {
+			conf := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+			if cn != "" {
+				cert, key := genCert(t, cn, caCert, caKey)
+				conf.Certificates = []tls.Certificate{{Certificate: [][]byte{cert.Raw}, PrivateKey: key}}
+			}
+			return &http.Client{Transport: &http.Transport{TLSClientConfig: conf}}
+		}
+
+		do := func(cli *http.Client, method, path, token string) int {
+			req, err := http.NewRequest(method, ts.URL+path, nil)
+			require.NoError(t, err)
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			resp, err := cli.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close() //nolint:errcheck
+			return resp.StatusCode
+		}
+
+		assert.Equal(t, http.StatusOK, do(newClient("sidecar"), http.MethodPost, "/v1/write/metric", ""))
+		assert.Equal(t, http.StatusForbidden, do(newClient("sidecar"), http.MethodGet, "/restart", ""))
+		assert.Equal(t, http.StatusUnauthorized, do(newClient("unknown"), http.MethodPost, "/v1/write/metric", ""))
+
+		// client without certificate authenticated by token
+		assert.Equal(t, http.StatusUnauthorized, do(newClient(""), http.MethodGet, "/restart", ""))
+		assert.Equal(t, http.StatusOK, do(newClient(""), http.MethodGet, "/restart", "admin"))
+	})
+}
+
+// genCert generate a certificate signed by parent, or a self-signed CA if parent is nil.
+func genCert(t *T.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
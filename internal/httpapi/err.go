@@ -41,6 +41,8 @@ var (
 	ErrDKVersionUptoDate       = newErr(errors.New("up to date"), http.StatusNotModified)
 
 	ErrPublicAccessDisabled = newErr(errors.New("public access disabled"), http.StatusForbidden)
+	ErrUnauthorized         = newErr(errors.New("unauthorized"), http.StatusUnauthorized)
+	ErrForbidden            = newErr(errors.New("forbidden"), http.StatusForbidden)
 	ErrReachLimit           = newErr(errors.New("reach max API limit"), http.StatusTooManyRequests)
 
 	ErrInvalidJSON = newErr(errors.New("invalid JSON"), http.StatusBadRequest)
@@ -220,6 +220,17 @@ func setupRouter(hs *httpServerConf) *gin.Engine {
 		router.Use(apiWhiteListMiddleware(hs.apiConfig.PublicAPIs))
 	}
 
+	if hs.apiConfig.AuthEnabled() {
+		auth, err := newAPIAuth(hs.apiConfig.Auth)
+		if err != nil {
+			// deny all non-public APIs on invalid auth config
+			l.Errorf("invalid HTTP API auth config: %s, all APIs except ping/ntp denied", err)
+			auth = &apiAuth{}
+		}
+
+		router.Use(apiAuthMiddleware(auth))
+	}
+
 	applyRegistedAPIs(router)
 
 	createDCARouter(router, hs)
@@ -325,6 +336,14 @@ func HTTPStart(hs *httpServerConf) {
 		srv.ReadTimeout = hs.timeout
 	}
 
+	if hs.apiConfig.HTTPSEnabled() {
+		if tlsConf, err := clientTLSConfig(hs.apiConfig.TLSConf); err != nil {
+			l.Errorf("setup client certificate verification failed: %s, ignored", err)
+		} else {
+			srv.TLSConfig = tlsConf
+		}
+	}
+
 	g.Go(func(ctx context.Context) error {
 		tryStartServer(hs, srv, true, semReload, semReloadCompleted)
 		l.Info("http server exit")
@@ -153,46 +153,41 @@ func (x *dkIO) compact(c *compactor) {
 }
 
 func (x *dkIO) doCompact(points []*point.Point, cat point.Category, indexName string) error {
-	if x.dw == nil {
-		return fmt.Errorf("dataway not set")
-	}
-
-	if len(points) == 0 {
-		return nil
-	}
-
-	opts := []dataway.WriteOption{
-		dataway.WithPoints(points),
-		// max cache size(in memory) upload as a batch
-		dataway.WithBatchSize(x.compactAt),
-		dataway.WithCategory(cat),
-	}
-
+	var opts []dataway.WriteOption
 	if indexName != "" {
 		opts = append(opts, dataway.WithStorageIndex(indexName))
 	}
 
-	return x.dw.Write(opts...)
+	return x.write(points, cat, opts...)
 }
 
 // compactAndUpload build body then upload to dataway directly.
 func (x *dkIO) compactAndUpload(points []*point.Point, cat point.Category) error {
-	if x.dw == nil {
-		return fmt.Errorf("dataway not set")
-	}
+	return x.write(points, cat,
+		dataway.WithNoWAL(true), // send body directly(without WAL)
+		dataway.WithCompressDuringBuildBody(true),
+	)
+}
 
+// write fan out points to extra sinks, then write them to dataway.
+func (x *dkIO) write(points []*point.Point, cat point.Category, extraOpts ...dataway.WriteOption) error {
 	if len(points) == 0 {
 		return nil
 	}
 
+	// fan out points to extra sinks, points are queued to sinks' WAL within Write().
+	x.sinker.Write(cat, points)
+
+	if x.dw == nil {
+		return fmt.Errorf("dataway not set")
+	}
+
 	opts := []dataway.WriteOption{
 		dataway.WithPoints(points),
 		// max cache size(in memory) upload as a batch
 		dataway.WithBatchSize(x.compactAt),
 		dataway.WithCategory(cat),
-		dataway.WithNoWAL(true), // send body directly(without WAL)
-		dataway.WithGzipDuringBuildBody(true),
 	}
 
-	return x.dw.Write(opts...)
+	return x.dw.Write(append(opts, extraOpts...)...)
 }
@@ -16,7 +16,6 @@ import (
 
 type (
 	walFrom  int8
-	gzipFlag int8
 	bufOnwer int8
 )
 
@@ -25,10 +24,6 @@ const (
 	walFromDisk   walFrom = 1
 	walFromNotSet walFrom = -1
 
-	gzipRaw    gzipFlag = 0
-	gzipSet    gzipFlag = 1
-	gzipNotSet gzipFlag = -1
-
 	bufOnwerOthers bufOnwer = 0
 	bufOnwerSelf   bufOnwer = 1
 
@@ -57,9 +52,9 @@ type body struct {
 
 	chksum string
 
-	selfBuffer bufOnwer // buffer that belongs to itself, and we should not drop it when putback
-	gzon       gzipFlag
-	from       walFrom
+	selfBuffer  bufOnwer // buffer that belongs to itself, and we should not drop it when putback
+	compression compressAlgo
+	from        walFrom
 }
 
 func (b *body) reset() {
@@ -82,7 +77,7 @@ func (b *body) reset() {
 	// and WAL protobuf marshal, their len(x) is always it's capacity. If len(x) changed,
 	// this will **panic** body encoding and protobuf marshal.
 
-	b.gzon = gzipNotSet
+	b.compression = compressNone
 	b.from = walFromNotSet
 }
 
@@ -123,6 +118,76 @@ func (b *body) loadCache(data []byte) error {
 		l.Warnf("invalid body: %s", b.pretty())
 	}
 
+	b.compression = compressNone
+	for _, h := range b.headers() {
+		if h.Key == headerContentEncoding {
+			if algo, err := compressAlgoStr(h.Value); err != nil {
+				l.Warnf("invalid body: %s", err)
+			} else {
+				b.compression = algo
+			}
+			return nil
+		}
+	}
+
+	// body cached by older version do not have Content-Encoding header, but they are gzipped.
+	b.compression = detectCompress(b.buf())
+
+	return nil
+}
+
+// compress compress the payload with algo. The compressed payload is
+// copied into b.sendBuf, and a Content-Encoding header is attached, so the
+// compression still available after the body been dumped into WAL or fail-cache.
+func (b *body) compress(algo compressAlgo) error {
+	if algo == compressNone || b.compression != compressNone {
+		return nil // compression not enabled or already compressed.
+	}
+
+	var (
+		start = time.Now()
+		z     = getZipper()
+		raw   = len(b.buf())
+	)
+
+	defer putZipper(z)
+
+	zbuf, err := z.zip(algo, b.buf())
+	if err != nil {
+		return fmt.Errorf("%s: %w", algo, err)
+	}
+
+	if len(zbuf) > len(b.sendBuf) { // incompressible payload, send it in raw
+		l.Warnf("%s: compressed payload(%d bytes) exceed send buffer(%d bytes), send raw payload", algo, len(zbuf), len(b.sendBuf))
+		return nil
+	}
+
+	ncopy := copy(b.sendBuf, zbuf)
+	l.Debugf("copy %d(origin: %d) %s bytes to buf", ncopy, raw, algo)
+
+	b.CacheData.Payload = b.sendBuf[:ncopy]
+	b.CacheData.Headers = append(b.CacheData.Headers, &HTTPHeader{Key: headerContentEncoding, Value: algo.contentEncoding()})
+	b.compression = algo
+
+	buildBodyCostVec.WithLabelValues(
+		b.cat().String(),
+		b.enc().String(),
+		algo.String(),
+	).Observe(float64(time.Since(start)) / float64(time.Second))
+
+	buildBodyBatchBytesVec.WithLabelValues(
+		b.cat().String(),
+		b.enc().String(),
+		algo.String(),
+	).Observe(float64(ncopy))
+
+	if raw > 0 {
+		compressRatioVec.WithLabelValues(
+			b.cat().String(),
+			algo.String(),
+		).Observe(float64(ncopy) / float64(raw))
+	}
+
 	return nil
 }
 
@@ -141,8 +206,8 @@ func (b *body) dump() ([]byte, error) {
 }
 
 func (b *body) String() string {
-	return fmt.Sprintf("from: %s, enc: %s, cat: %s, gzon: %v, headers: %d, pts: %d, buf bytes: %d, chksum: %s, rawLen: %d, cap: %d",
-		b.from, b.enc(), b.cat(), b.gzon, len(b.headers()), b.npts(), len(b.buf()), b.chksum, b.rawLen(), cap(b.sendBuf))
+	return fmt.Sprintf("from: %s, enc: %s, cat: %s, compression: %s, headers: %d, pts: %d, buf bytes: %d, chksum: %s, rawLen: %d, cap: %d",
+		b.from, b.enc(), b.cat(), b.compression, len(b.headers()), b.npts(), len(b.buf()), b.chksum, b.rawLen(), cap(b.sendBuf))
 }
 
 func (b *body) expired(ttl time.Duration) bool {
@@ -156,7 +221,7 @@ func (b *body) pretty() string {
 	arr = append(arr, fmt.Sprintf("\n%p from: %s", b, b.from))
 	arr = append(arr, fmt.Sprintf("enc: %d/%s", b.enc(), b.enc()))
 	arr = append(arr, fmt.Sprintf("cat: %d/%s", b.cat(), b.cat()))
-	arr = append(arr, fmt.Sprintf("gzon: %d", b.gzon))
+	arr = append(arr, fmt.Sprintf("compression: %s", b.compression))
 	arr = append(arr, fmt.Sprintf("#buf: %d", len(b.buf())))
 	arr = append(arr, fmt.Sprintf("#send-buf: %d", len(b.sendBuf)))
 	arr = append(arr, fmt.Sprintf("#mars-buf: %d", len(b.sendBuf)))
@@ -261,20 +326,6 @@ func (w *writer) buildPointsBody() error {
 		b.from = walFromMem
 		b.CacheData.Payload = encodeBytes
 
-		if w.gzipDuringBuildBody {
-			gz := getZipper()
-			defer putZipper(gz)
-
-			if zbuf, err := gz.zip(b.buf()); err != nil {
-				l.Errorf("gzip: %s", err.Error())
-				return err
-			} else {
-				ncopy := copy(b.sendBuf, zbuf)
-				l.Debugf("copy %d(origin: %d) zipped bytes to buf", ncopy, len(b.buf()))
-				b.CacheData.Payload = b.sendBuf[:ncopy]
-			}
-		}
-
 		b.CacheData.Category = int32(w.category)
 		b.CacheData.Pts = int32(nptsArr[parts])
 		b.CacheData.RawLen = int32(len(encodeBytes))
@@ -302,6 +353,13 @@ func (w *writer) buildPointsBody() error {
 			w.httpEncoding.String(),
 		).Observe(float64(b.npts()))
 
+		if w.compressDuringBuildBody {
+			if err := b.compress(w.compression); err != nil {
+				l.Errorf("compress: %s", err.Error())
+				return err
+			}
+		}
+
 		if w.bcb != nil {
 			if err := w.bcb(w, b); err != nil {
 				l.Warnf("compact %d points on category %q failed: %q, ignored",
@@ -322,7 +322,7 @@ func TestBuildBody(t *T.T) {
 					err error
 				)
 
-				if x.gzon == 1 {
+				if x.compression == compressGzip {
 					raw, err = uhttp.Unzip(x.buf())
 					require.NoError(t, err)
 				}
@@ -380,7 +380,7 @@ func TestBuildBody(t *T.T) {
 					raw = x.buf()
 					err error
 				)
-				if x.gzon == 1 {
+				if x.compression == compressGzip {
 					raw, err = uhttp.Unzip(x.buf())
 					if err != nil {
 						assert.NoError(t, err)
@@ -410,7 +410,7 @@ func BenchmarkBuildBody(b *T.B) {
 		name  string
 		pts   []*point.Point
 		batch int
-		gz    gzipFlag
+		algo  compressAlgo
 		enc   point.Encoding
 	}{
 		{
@@ -460,7 +460,23 @@ func BenchmarkBuildBody(b *T.B) {
 			pts:   r.Rand(1024),
 			batch: 1024,
 			enc:   point.Protobuf,
-			gz:    1,
+			algo:  compressGzip,
+		},
+
+		{
+			name:  "zstd-1k-pts-on-protobuf-batch1024",
+			pts:   r.Rand(1024),
+			batch: 1024,
+			enc:   point.Protobuf,
+			algo:  compressZstd,
+		},
+
+		{
+			name:  "snappy-1k-pts-on-protobuf-batch1024",
+			pts:   r.Rand(1024),
+			batch: 1024,
+			enc:   point.Protobuf,
+			algo:  compressSnappy,
 		},
 
 		{
@@ -490,7 +506,8 @@ func BenchmarkBuildBody(b *T.B) {
 			WithBatchSize(bc.batch)(w)
 			WithPoints(bc.pts)(w)
 			WithHTTPEncoding(bc.enc)(w)
-			WithGzip(bc.gz)(w)
+			withCompression(bc.algo)(w)
+			WithCompressDuringBuildBody(bc.algo != compressNone)(w)
 
 			for i := 0; i < b.N; i++ {
 				w.buildPointsBody()
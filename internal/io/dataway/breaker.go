@@ -0,0 +1,222 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package dataway
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerMinBackoff       = 5 * time.Second
+	defaultBreakerMaxBackoff       = 5 * time.Minute
+)
+
+// CircuitBreakerConf configures the circuit breaker on each Dataway endpoint.
+type CircuitBreakerConf struct {
+	Enable bool `toml:"enable"`
+
+	// Open the breaker after continuous failures.
+	FailureThreshold int `toml:"failure_threshold"`
+
+	// The open duration grows exponentially from MinBackoff to MaxBackoff.
+	MinBackoff time.Duration `toml:"min_backoff"`
+	MaxBackoff time.Duration `toml:"max_backoff"`
+}
+
+type breakerState int32
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerClosed:
+		return "closed"
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// breaker is a circuit breaker on endpoint.
+//
+//   - closed: all requests allowed. After continuous failures, the breaker opened.
+//   - open: all requests rejected(bodies are cached to WAL) until the backoff expired,
+//     then the breaker half-opened.
+//   - half-open: only a single probe request allowed. If the probe ok, the breaker closed,
+//     or opened again with doubled backoff.
+//
+// The backoff applied with jitter, so DataKits within the same cluster will not
+// retry Dataway in lockstep.
+type breaker struct {
+	mtx sync.Mutex
+
+	id    string
+	state breakerState
+
+	failures,
+	threshold int
+
+	backoff,
+	minBackoff,
+	maxBackoff time.Duration
+
+	openUntil time.Time
+	probing   bool
+
+	rnd *rand.Rand
+	now func() time.Time
+}
+
+func newBreaker(id string, c *CircuitBreakerConf) *breaker {
+	if c == nil || !c.Enable {
+		return nil
+	}
+
+	b := &breaker{
+		id:         id,
+		threshold:  c.FailureThreshold,
+		minBackoff: c.MinBackoff,
+		maxBackoff: c.MaxBackoff,
+		rnd:        rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+		now:        time.Now,
+	}
+
+	if b.threshold <= 0 {
+		b.threshold = defaultBreakerFailureThreshold
+	}
+
+	if b.minBackoff <= 0 {
+		b.minBackoff = defaultBreakerMinBackoff
+	}
+
+	if b.maxBackoff < b.minBackoff {
+		b.maxBackoff = defaultBreakerMaxBackoff
+		if b.maxBackoff < b.minBackoff {
+			b.maxBackoff = b.minBackoff
+		}
+	}
+
+	b.setState(breakerClosed)
+
+	return b
+}
+
+// allow check if we can send request to the endpoint.
+func (b *breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Before(b.openUntil) {
+			return false
+		}
+
+		l.Infof("circuit breaker on %s half-opened", b.id)
+		b.setState(breakerHalfOpen)
+		b.probing = true
+		return true
+
+	case breakerHalfOpen:
+		if b.probing { // only 1 probe request allowed
+			return false
+		}
+
+		b.probing = true
+		return true
+
+	default:
+		return true
+	}
+}
+
+// done report the result of the request allowed by allow().
+func (b *breaker) done(ok bool) {
+	if b == nil {
+		return
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if ok {
+		if b.state != breakerClosed {
+			l.Infof("circuit breaker on %s closed", b.id)
+		}
+
+		b.failures = 0
+		b.backoff = 0
+		b.probing = false
+		b.setState(breakerClosed)
+		return
+	}
+
+	b.failures++
+
+	switch b.state {
+	case breakerClosed:
+		if b.failures >= b.threshold {
+			b.open()
+		}
+
+	case breakerHalfOpen:
+		b.probing = false
+		b.open()
+
+	default: // requests allowed before the breaker opened, ignored
+	}
+}
+
+func (b *breaker) open() {
+	if b.backoff == 0 {
+		b.backoff = b.minBackoff
+	} else {
+		b.backoff *= 2
+	}
+
+	if b.backoff > b.maxBackoff {
+		b.backoff = b.maxBackoff
+	}
+
+	// equal jitter: wait within [backoff/2, backoff]
+	half := b.backoff / 2
+	du := half + time.Duration(b.rnd.Int63n(int64(b.backoff-half)+1))
+
+	b.openUntil = b.now().Add(du)
+	b.setState(breakerOpen)
+
+	circuitBreakerOpenVec.WithLabelValues(b.id).Inc()
+	l.Warnf("circuit breaker on %s opened after %d failures, retry after %s", b.id, b.failures, du)
+}
+
+func (b *breaker) setState(s breakerState) {
+	b.state = s
+	circuitBreakerStateVec.WithLabelValues(b.id).Set(float64(s))
+}
+
+func (b *breaker) getState() breakerState {
+	if b == nil {
+		return breakerClosed
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.state
+}
@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package dataway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	T "testing"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/diskcache"
+	"github.com/GuanceCloud/cliutils/metrics"
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *T.T) {
+	t.Cleanup(metricsReset)
+
+	t.Run("disabled", func(t *T.T) {
+		var b *breaker
+		assert.Nil(t, newBreaker("ep", nil))
+		assert.Nil(t, newBreaker("ep", &CircuitBreakerConf{}))
+
+		// nil breaker always allowed
+		assert.True(t, b.allow())
+		b.done(false)
+		assert.Equal(t, breakerClosed, b.getState())
+	})
+
+	t.Run("open-half-open-close", func(t *T.T) {
+		now := time.Now()
+
+		b := newBreaker("ep", &CircuitBreakerConf{
+			Enable:           true,
+			FailureThreshold: 2,
+			MinBackoff:       time.Second,
+			MaxBackoff:       4 * time.Second,
+		})
+		b.now = func() time.Time { return now }
+
+		assert.True(t, b.allow())
+		b.done(false)
+		assert.Equal(t, breakerClosed, b.getState())
+
+		assert.True(t, b.allow())
+		b.done(false)
+		assert.Equal(t, breakerOpen, b.getState())
+		assert.Equal(t, time.Second, b.backoff)
+
+		// opened with jitter
+		du := b.openUntil.Sub(now)
+		assert.True(t, du >= time.Second/2 && du <= time.Second, "got %s", du)
+		assert.False(t, b.allow())
+
+		// backoff expired: only 1 probe allowed
+		now = now.Add(time.Second)
+		assert.True(t, b.allow())
+		assert.Equal(t, breakerHalfOpen, b.getState())
+		assert.False(t, b.allow())
+
+		// probe failed: backoff doubled
+		b.done(false)
+		assert.Equal(t, breakerOpen, b.getState())
+		assert.Equal(t, 2*time.Second, b.backoff)
+
+		// backoff capped by max-backoff
+		for i := 0; i < 3; i++ {
+			now = now.Add(time.Minute)
+			assert.True(t, b.allow())
+			b.done(false)
+		}
+		assert.Equal(t, 4*time.Second, b.backoff)
+
+		// probe ok: closed
+		now = now.Add(time.Minute)
+		assert.True(t, b.allow())
+		b.done(true)
+		assert.Equal(t, breakerClosed, b.getState())
+		assert.Equal(t, time.Duration(0), b.backoff)
+		assert.True(t, b.allow())
+	})
+}
+
+func TestCircuitBreakerOnFlush(t *T.T) {
+	var (
+		requests int64
+		status   int64 = http.StatusServiceUnavailable
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.WriteHeader(int(atomic.LoadInt64(&status)))
+	}))
+	defer ts.Close()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(Metrics()...)
+
+	t.Cleanup(func() {
+		metricsReset()
+		diskcache.ResetMetrics()
+	})
+
+	t.Run("disabled-by-default", func(t *T.T) {
+		dw := NewDefaultDataway()
+		dw.WAL.Path = t.TempDir()
+		require.NoError(t, dw.Init(WithURLs(ts.URL)))
+		assert.Nil(t, dw.eps[0].breaker)
+	})
+
+	dw := NewDefaultDataway()
+	dw.WAL.Path = t.TempDir()
+	dw.CircuitBreaker = &CircuitBreakerConf{
+		Enable:           true,
+		FailureThreshold: 1,
+		MinBackoff:       time.Hour, // do not half-open during testing
+	}
+
+	require.NoError(t, dw.Init(WithURLs(ts.URL)))
+	require.NoError(t, dw.setupWAL())
+
+	ep := dw.eps[0]
+	dc := ep.fc.disk.(*diskcache.DiskCache)
+
+	write := func(cat point.Category) {
+		w := getWriter(WithPoints(point.RandPoints(10)),
+			WithCategory(cat),
+			WithBodyCallback(func(w *writer, b *body) error {
+				return dw.doFlush(w, b)
+			}),
+			WithHTTPEncoding(dw.contentEncoding))
+		defer putWriter(w)
+
+		require.NoError(t, w.buildPointsBody())
+	}
+
+	write(point.Metric) // 5xx: the breaker opened
+	assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+	assert.Equal(t, breakerOpen, ep.breaker.getState())
+
+	// breaker open: not requested, and metric dropped as failed uploading
+	write(point.Metric)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+	require.NoError(t, dc.Rotate())
+	assert.Equal(t, int64(0), dc.Size())
+
+	// breaker open: logging cached as failed uploading
+	write(point.Logging)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+	require.NoError(t, dc.Rotate())
+	assert.True(t, dc.Size() > 0)
+
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+
+	m := metrics.GetMetricOnLabels(mfs, "datakit_io_dataway_circuit_breaker_state", ep.id())
+	require.NotNil(t, m)
+	assert.Equal(t, float64(breakerOpen), m.GetGauge().GetValue())
+
+	m = metrics.GetMetricOnLabels(mfs, "datakit_io_dataway_circuit_breaker_open_total", ep.id())
+	require.NotNil(t, m)
+	assert.Equal(t, 1.0, m.GetCounter().GetValue())
+
+	f := dw.newFlusher(point.Logging)
+	assert.Error(t, f.cleanFailCache()) // still open
+
+	// backoff expired and dataway recovered: fail-cache cleaned by the probe.
+	atomic.StoreInt64(&status, http.StatusOK)
+	ep.breaker.mtx.Lock()
+	ep.breaker.openUntil = time.Now()
+	ep.breaker.mtx.Unlock()
+
+	assert.NoError(t, f.cleanFailCache())
+	assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+	assert.Equal(t, breakerClosed, ep.breaker.getState())
+
+	// 4xx is not a failure to the breaker: the Dataway is reachable.
+	atomic.StoreInt64(&status, http.StatusBadRequest)
+	write(point.Metric)
+	write(point.Metric)
+	assert.Equal(t, int64(4), atomic.LoadInt64(&requests))
+	assert.Equal(t, breakerClosed, ep.breaker.getState())
+}
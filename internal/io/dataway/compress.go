@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package dataway
+
+import (
+	bytes "bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	gzip "github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+type compressAlgo int8
+
+const (
+	compressNone compressAlgo = iota
+	compressGzip
+	compressZstd
+	compressSnappy
+)
+
+const headerContentEncoding = "Content-Encoding"
+
+func (c compressAlgo) String() string {
+	switch c {
+	case compressGzip:
+		return "gzip"
+	case compressZstd:
+		return "zstd"
+	case compressSnappy:
+		return "snappy"
+	case compressNone:
+		return "none"
+	default:
+		return "unknown"
+	}
+}
+
+// contentEncoding get the HTTP Content-Encoding of the algorithm.
+func (c compressAlgo) contentEncoding() string {
+	if c == compressNone {
+		return ""
+	}
+	return c.String()
+}
+
+func compressAlgoStr(s string) (compressAlgo, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "gzip":
+		return compressGzip, nil
+	case "zstd":
+		return compressZstd, nil
+	case "snappy":
+		return compressSnappy, nil
+	case "none", "identity":
+		return compressNone, nil
+	default:
+		return compressNone, fmt.Errorf("unknown compression %q", s)
+	}
+}
+
+// detectCompress detect compression of the payload. Only gzip detectable,
+// zstd and snappy payload always come with header Content-Encoding.
+func detectCompress(data []byte) compressAlgo {
+	if len(data) < 2 {
+		return compressNone
+	}
+
+	// See: https://stackoverflow.com/a/6059342/342348
+	if data[0] == 0x1f && data[1] == 0x8b {
+		return compressGzip
+	}
+
+	return compressNone
+}
+
+var (
+	zippool sync.Pool
+
+	zstdEncOnce sync.Once
+	zstdEnc     *zstd.Encoder
+)
+
+// getZstdEncoder get the global zstd encoder, it's EncodeAll() is safe
+// for concurrent use.
+func getZstdEncoder() *zstd.Encoder {
+	zstdEncOnce.Do(func() {
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+		if err != nil { // should not been here: no invalid options
+			panic(fmt.Sprintf("zstd.NewWriter: %s", err))
+		}
+		zstdEnc = enc
+	})
+
+	return zstdEnc
+}
+
+func getZipper() *zipper {
+	if x := zippool.Get(); x == nil {
+		buf := bytes.Buffer{}
+		w := gzip.NewWriter(&buf)
+		return &zipper{buf: &buf, w: w}
+	} else {
+		return x.(*zipper)
+	}
+}
+
+func putZipper(z *zipper) {
+	if z != nil {
+		// reset zip buffer and the writer.
+		z.buf.Reset()
+		z.w.Reset(z.buf)
+		z.out = z.out[:0]
+		zippool.Put(z)
+	}
+}
+
+type zipper struct {
+	buf *bytes.Buffer
+	w   *gzip.Writer
+
+	out []byte // output buffer for zstd/snappy
+}
+
+// zip compress data with algo. The returned bytes only valid before putZipper().
+func (z *zipper) zip(algo compressAlgo, data []byte) ([]byte, error) {
+	switch algo {
+	case compressGzip:
+		return z.gzip(data)
+	case compressZstd:
+		z.out = getZstdEncoder().EncodeAll(data, z.out[:0])
+		return z.out, nil
+	case compressSnappy:
+		if n := snappy.MaxEncodedLen(len(data)); cap(z.out) < n {
+			z.out = make([]byte, n)
+		}
+		z.out = snappy.Encode(z.out[:cap(z.out)], data)
+		return z.out, nil
+	case compressNone:
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown compression %d", algo)
+	}
+}
+
+// unzip decompress data with algo.
+func unzip(algo compressAlgo, data []byte) ([]byte, error) {
+	switch algo {
+	case compressGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close() //nolint:errcheck
+
+		return io.ReadAll(r)
+	case compressZstd:
+		dec, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+
+		return dec.DecodeAll(data, nil)
+	case compressSnappy:
+		return snappy.Decode(nil, data)
+	case compressNone:
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown compression %d", algo)
+	}
+}
+
+func (z *zipper) gzip(data []byte) ([]byte, error) {
+	if _, err := z.w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := z.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	if err := z.w.Close(); err != nil {
+		return nil, err
+	}
+
+	return z.buf.Bytes(), nil
+}
@@ -16,10 +16,152 @@ import (
 	kgzip "github.com/klauspost/compress/gzip"
 	"github.com/klauspost/compress/s2"
 	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
 	pgzip "github.com/klauspost/pgzip"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func decompress(t *T.T, algo compressAlgo, data []byte) []byte {
+	t.Helper()
+
+	switch algo {
+	case compressGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		x, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return x
+	case compressZstd:
+		dec, err := zstd.NewReader(nil)
+		require.NoError(t, err)
+		defer dec.Close()
+		x, err := dec.DecodeAll(data, nil)
+		require.NoError(t, err)
+		return x
+	case compressSnappy:
+		x, err := snappy.Decode(nil, data)
+		require.NoError(t, err)
+		return x
+	default:
+		return data
+	}
+}
+
+func TestCompress(t *T.T) {
+	t.Run("algo-str", func(t *T.T) {
+		for _, algo := range []compressAlgo{compressNone, compressGzip, compressZstd, compressSnappy} {
+			x, err := compressAlgoStr(algo.String())
+			assert.NoError(t, err)
+			assert.Equal(t, algo, x)
+		}
+
+		x, err := compressAlgoStr(" ZSTD ")
+		assert.NoError(t, err)
+		assert.Equal(t, compressZstd, x)
+
+		_, err = compressAlgoStr("lz4")
+		assert.Error(t, err)
+
+		assert.Equal(t, "", compressNone.contentEncoding())
+	})
+
+	t.Run("zip", func(t *T.T) {
+		enc := point.GetEncoder(point.WithEncEncoding(point.Protobuf))
+		defer point.PutEncoder(enc)
+
+		arr, err := enc.Encode(point.RandPoints(100))
+		require.NoError(t, err)
+		require.Len(t, arr, 1)
+
+		for _, algo := range []compressAlgo{compressNone, compressGzip, compressZstd, compressSnappy} {
+			z := getZipper()
+			zbuf, err := z.zip(algo, arr[0])
+			require.NoError(t, err)
+
+			t.Logf("%s: %d -> %d", algo, len(arr[0]), len(zbuf))
+			assert.Equal(t, arr[0], decompress(t, algo, zbuf))
+			putZipper(z)
+		}
+	})
+
+	t.Run("body-dump-and-load", func(t *T.T) {
+		t.Cleanup(metricsReset)
+
+		for _, algo := range []compressAlgo{compressGzip, compressZstd, compressSnappy} {
+			t.Run(algo.String(), func(t *T.T) {
+				var (
+					pts = point.RandPoints(10)
+					raw []byte
+					x   *body
+				)
+
+				w := getWriter(WithPoints(pts),
+					WithCategory(point.Logging),
+					WithHTTPEncoding(point.Protobuf),
+					withCompression(algo),
+					WithCompressDuringBuildBody(true),
+					WithBodyCallback(func(_ *writer, b *body) error {
+						assert.Equal(t, algo, b.compression)
+
+						data, err := b.dump()
+						require.NoError(t, err)
+
+						// load the dumped body, the compression should kept
+						x = getNewBufferBody(withNewBuffer(1 << 20))
+						require.NoError(t, x.loadCache(data))
+						raw = decompress(t, algo, b.buf())
+						return nil
+					}))
+				defer putWriter(w)
+
+				require.NoError(t, w.buildPointsBody())
+				require.NotNil(t, x)
+				defer putBody(x)
+
+				assert.Equal(t, algo, x.compression)
+
+				// compress again is a no-op
+				n := len(x.buf())
+				require.NoError(t, x.compress(compressGzip))
+				assert.Equal(t, n, len(x.buf()))
+				assert.Equal(t, algo, x.compression)
+
+				dec := point.GetDecoder(point.WithDecEncoding(point.Protobuf))
+				defer point.PutDecoder(dec)
+				got, err := dec.Decode(raw)
+				require.NoError(t, err)
+				assert.Len(t, got, len(pts))
+			})
+		}
+	})
+
+	t.Run("legacy-gzip-cache", func(t *T.T) {
+		// body cached by older version: gzipped without Content-Encoding header
+		b := getNewBufferBody(withNewBuffer(1 << 10))
+		defer putBody(b)
+
+		z := getZipper()
+		defer putZipper(z)
+
+		zbuf, err := z.zip(compressGzip, []byte("hello world"))
+		require.NoError(t, err)
+
+		b.CacheData.Payload = zbuf
+		b.CacheData.Category = int32(point.Logging)
+		b.CacheData.PayloadType = int32(point.LineProtocol)
+
+		data, err := b.dump()
+		require.NoError(t, err)
+
+		x := getNewBufferBody(withNewBuffer(1 << 10))
+		defer putBody(x)
+
+		require.NoError(t, x.loadCache(data))
+		assert.Equal(t, compressGzip, x.compression)
+	})
+}
+
 func TestEqualGZip(t *T.T) {
 	r := point.NewRander()
 	pts := r.Rand(1000)
@@ -45,7 +187,7 @@ func TestEqualGZip(t *T.T) {
 	pgzBytes := buf.Bytes()
 	t.Logf("raw data: %d bytes, pgzip: %d", len(arr[0]), len(pgzBytes))
 
-	assert.Equal(t, gzipSet, isGzip(pgzBytes))
+	assert.Equal(t, compressGzip, detectCompress(pgzBytes))
 
 	// unzip pgzBytes with go's gzip
 	gzr := bytes.NewBuffer(pgzBytes)
@@ -69,7 +211,7 @@ func TestEqualGZip(t *T.T) {
 	kgzBytes := buf.Bytes()
 	t.Logf("raw data: %d bytes, kgzip: %d", len(arr[0]), len(kgzBytes))
 
-	assert.Equal(t, gzipSet, isGzip(kgzBytes))
+	assert.Equal(t, compressGzip, detectCompress(kgzBytes))
 
 	// unzip pgzBytes with go's gzip
 	gzr = bytes.NewBuffer(kgzBytes)
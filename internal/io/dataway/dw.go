@@ -104,6 +104,12 @@ func NewDefaultDataway(opts ...DWOption) *Dataway {
 			Path:                   filepath.Join(datakit.CacheDir, "dw-wal"),
 			FailCacheCleanInterval: time.Second * 30,
 		},
+		CircuitBreaker: &CircuitBreakerConf{
+			Enable:           false,
+			FailureThreshold: defaultBreakerFailureThreshold,
+			MinBackoff:       defaultBreakerMinBackoff,
+			MaxBackoff:       defaultBreakerMaxBackoff,
+		},
 	}
 
 	for _, opt := range opts {
@@ -150,19 +156,24 @@ type Dataway struct {
 	IdleTimeout          time.Duration `toml:"idle_timeout"`
 	DropExpiredPackageAt time.Duration `toml:"drop_expired_package_at"`
 
-	GZip bool `toml:"gzip"`
+	GZip bool `toml:"gzip"` // Deprecated: use Compression
+
+	// Compression of HTTP body, gzip/zstd/snappy/none. If not set, use gzip or
+	// none according to GZip.
+	Compression string `toml:"compression,omitempty"`
+	compression compressAlgo
 
 	EnableHTTPTrace    bool `toml:"enable_httptrace"`
 	EnableSinker       bool `toml:"enable_sinker"`
 	InsecureSkipVerify bool `toml:"tls_insecure"`
 
-	GlobalCustomerKeys []string `toml:"global_customer_keys"`
-	WAL                *WALConf `toml:"wal"`
+	GlobalCustomerKeys []string            `toml:"global_customer_keys"`
+	WAL                *WALConf            `toml:"wal"`
+	CircuitBreaker     *CircuitBreakerConf `toml:"circuit_breaker"`
 
 	eps []*endPoint
 
-	walq    map[point.Category]*WALQueue
-	walFail *WALQueue
+	walq map[point.Category]*WALQueue
 
 	locker     sync.RWMutex
 	dnsCachers []*dnsCacher
@@ -257,6 +268,24 @@ func TagHeaderValue(tags map[string]string) string {
 	return strings.Join(arr, ",")
 }
 
+func (dw *Dataway) setupCompression() {
+	if dw.Compression == "" {
+		if dw.GZip {
+			dw.compression = compressGzip
+		} else {
+			dw.compression = compressNone
+		}
+		return
+	}
+
+	if algo, err := compressAlgoStr(dw.Compression); err != nil {
+		l.Warnf("%s, set to gzip", err)
+		dw.compression = compressGzip
+	} else {
+		dw.compression = algo
+	}
+}
+
 var defaultInvalidDatawayURL = "https://guance.openway.com?token=YOUR-WORKSPACE-TOKEN"
 
 func (dw *Dataway) doInit() error {
@@ -267,6 +296,7 @@ func (dw *Dataway) doInit() error {
 	}
 
 	dw.contentEncoding = point.EncodingStr(dw.ContentEncoding)
+	dw.setupCompression()
 
 	// set default raw body size to 10MB
 	if dw.MaxRawBodySize == 0 {
@@ -321,6 +351,7 @@ func (dw *Dataway) doInit() error {
 			withHTTPIdleTimeout(dw.IdleTimeout),
 			withMaxRetryCount(dw.MaxRetryCount),
 			withRetryDelay(dw.RetryDelay),
+			withCircuitBreaker(dw.CircuitBreaker),
 		)
 		if err != nil {
 			l.Errorf("init dataway url %s failed: %s", u, err.Error())
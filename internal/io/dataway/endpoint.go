@@ -7,7 +7,9 @@ package dataway
 
 import (
 	"bytes"
+	"crypto/md5" //nolint:gosec
 	"crypto/tls"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -38,6 +40,13 @@ type endPoint struct {
 
 	httpCli *http.Client
 
+	// fc is the fail-cache of current endpoint, body failed to POST
+	// to current endpoint are cached here and retried later.
+	fc *WALQueue
+
+	// circuit breaker on the endpoint, nil if disabled.
+	breaker *breaker
+
 	// optionals
 	proxy       string
 	apis        []string
@@ -58,6 +67,19 @@ func (ep *endPoint) String() string {
 		ep.host, ep.token, strings.Join(ep.apis, ","))
 }
 
+// id used to identify the endpoint within metrics and logging. Token not included,
+// but a short hash of it appended, so endpoints on the same host with different
+// tokens are distinguishable.
+func (ep *endPoint) id() string {
+	h := md5.Sum([]byte(ep.token)) //nolint:gosec
+	return ep.host + "#" + hex.EncodeToString(h[:4])
+}
+
+// hash get the hash of the endpoint's host and token.
+func (ep *endPoint) hash() string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(ep.host+ep.token))) //nolint:gosec
+}
+
 type endPointOption func(*endPoint)
 
 func withAPIs(arr []string) endPointOption {
@@ -129,6 +151,12 @@ func withRetryDelay(delay time.Duration) endPointOption {
 	}
 }
 
+func withCircuitBreaker(c *CircuitBreakerConf) endPointOption {
+	return func(ep *endPoint) {
+		ep.breaker = newBreaker(ep.id(), c)
+	}
+}
+
 func withProxy(proxy string) endPointOption {
 	return func(ep *endPoint) {
 		ep.proxy = proxy
@@ -277,6 +305,11 @@ func (ep *endPoint) writePointData(w *writer, b *body) error {
 		}
 	}
 
+	// the breaker opened: do not send the request, the body will be cached and retried later.
+	if !ep.breaker.allow() {
+		return errCircuitOpen
+	}
+
 	defer func() {
 		if w.cacheClean { // ignore metrics on cache clean operation
 			l.Debug("on cache clean, no metric applied")
@@ -307,6 +340,7 @@ func (ep *endPoint) writePointData(w *writer, b *body) error {
 	l.Debugf("post %d bytes to %s...", len(b.buf()), requrl)
 	req, err := http.NewRequest("POST", requrl, bytes.NewBuffer(b.buf()))
 	if err != nil {
+		ep.breaker.done(false)
 		l.Error("new request to %s: %s", requrl, err)
 		return err
 	}
@@ -314,8 +348,8 @@ func (ep *endPoint) writePointData(w *writer, b *body) error {
 	req.Header.Set("X-Points", fmt.Sprintf("%d", b.npts()))
 	req.Header.Set("Content-Length", fmt.Sprintf("%d", len(b.buf())))
 	req.Header.Set("Content-Type", b.enc().HTTPContentType())
-	if w.gzip == 1 {
-		req.Header.Set("Content-Encoding", "gzip")
+	if ce := b.compression.contentEncoding(); ce != "" {
+		req.Header.Set(headerContentEncoding, ce)
 	}
 
 	// add package id
@@ -335,6 +369,12 @@ func (ep *endPoint) writePointData(w *writer, b *body) error {
 	}
 
 	resp, err := ep.sendReq(req)
+
+	// 4xx is a success to the breaker: the Dataway is reachable and rejects the
+	// body itself(bad token, beyond usage and so on), retrying will not help and
+	// the body is dropped.
+	ep.breaker.done(err == nil && resp != nil)
+
 	// NOTE: resp maybe not nil, we need HTTP status info to fill HTTP metrics before exit.
 	if resp != nil {
 		httpCodeStr = http.StatusText(resp.StatusCode)
@@ -358,7 +398,7 @@ func (ep *endPoint) writePointData(w *writer, b *body) error {
 
 	switch resp.StatusCode / 100 {
 	case 2:
-		l.Debugf("post %d bytes to %s ok(gz: %v)", len(b.buf()), requrl, w.gzip)
+		l.Debugf("post %d bytes to %s ok(compression: %s)", len(b.buf()), requrl, b.compression)
 
 		// Send data ok, it means the error `beyond-usage` error is cleared by kodo server,
 		// we have to clear the hint in monitor too.
@@ -467,6 +507,11 @@ func (ep *endPoint) sendReq(req *http.Request) (resp *http.Response, err error)
 
 	delay := ep.retryDelay
 
+	jitter := delay
+	if jitter <= 0 {
+		jitter = retry.DefaultMaxJitter
+	}
+
 	// We must set retry > 0, or the request will fail immediately.
 	maxRetry := uint(ep.maxRetryCount)
 	if maxRetry == 0 {
@@ -517,6 +562,9 @@ func (ep *endPoint) sendReq(req *http.Request) (resp *http.Response, err error)
 
 		retry.Attempts(maxRetry),
 		retry.Delay(delay),
+		// exponential backoff with jitter, avoid retrying in lockstep among DataKits.
+		retry.DelayType(retry.CombineDelay(retry.BackOffDelay, retry.RandomDelay)),
+		retry.MaxJitter(jitter),
 
 		retry.OnRetry(func(n uint, err error) {
 			l.Warnf("on %dth retry for %s, error: %s(%s)", n, req.URL, err, reflect.TypeOf(err))
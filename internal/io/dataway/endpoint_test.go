@@ -129,7 +129,7 @@ test-2 f1=1i,f2=false 123
 		ep, err := newEndpoint(urlstr, withAPIs([]string{datakit.Metric}))
 		assert.NoError(t, err)
 
-		w := getWriter(WithGzip(1),
+		w := getWriter(withCompression(compressGzip),
 			WithPoints([]*point.Point{
 				point.NewPoint("test-1", point.NewKVs(map[string]any{"f1": 1, "f2": false}), point.WithTime(time.Unix(0, 123))),
 				point.NewPoint("test-2", point.NewKVs(map[string]any{"f1": 1, "f2": false}), point.WithTime(time.Unix(0, 123))),
@@ -206,7 +206,7 @@ test-2 f1=1i,f2=false 123
 				point.NewPoint("test-2", point.NewKVs(map[string]any{"f1": 1, "f2": false}), point.WithTime(time.Unix(0, 123))),
 			}),
 			WithHTTPEncoding(point.LineProtocol),
-			WithGzip(1),
+			withCompression(compressGzip),
 		)
 		defer putWriter(w)
 
@@ -281,7 +281,7 @@ test-2 f1=1i,f2=false 123
 				point.NewPoint("test-2", point.NewKVs(map[string]any{"f1": 1, "f2": false}), point.WithTime(time.Unix(0, 123))),
 			}),
 			WithHTTPEncoding(point.LineProtocol),
-			WithGzip(1))
+			withCompression(compressGzip))
 		defer putWriter(w)
 
 		reg := prometheus.NewRegistry()
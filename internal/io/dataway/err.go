@@ -12,4 +12,5 @@ import (
 var (
 	errWritePoints4XX    = errors.New("write point 4xx")
 	errRequestTerminated = errors.New("no response and request maybe terminated")
+	errCircuitOpen       = errors.New("circuit breaker open")
 )
@@ -133,14 +133,8 @@ func (f *flusher) start() {
 }
 
 func (f *flusher) do(b *body, opts ...WriteOption) error {
-	gzOn := gzipNotSet
-	if f.dw.GZip {
-		gzOn = gzipSet
-	}
-
 	w := getWriter(
 		WithHTTPEncoding(b.enc()),
-		WithGzip(gzOn),
 		// cache all data into fail-cache
 		WithCacheAll(true),
 		WithCategory(b.cat()),
@@ -162,41 +156,16 @@ func (dw *Dataway) doFlush(w *writer, b *body, opts ...WriteOption) error {
 		WithHTTPHeader(h.Key, h.Value)(w)
 	}
 
-	isGzip := "F"
-	if w.cacheClean {
-		isGzip = "T" // fail-cache always gzipped before HTTP POST
-	}
-
-	if dw.GZip && !w.cacheClean { // under cacheClean, all body has been gzipped during previous POST
-		var (
-			zstart = time.Now()
-			gz     = getZipper()
-		)
-
-		isGzip = "T"
-		defer putZipper(gz)
-
-		if zbuf, err := gz.zip(b.buf()); err != nil {
-			l.Errorf("gzip: %s", err.Error())
-			return err
-		} else {
-			ncopy := copy(b.sendBuf, zbuf)
-			l.Debugf("copy %d(origin: %d) zipped bytes to buf", ncopy, len(b.buf()))
-			b.CacheData.Payload = b.sendBuf[:ncopy]
+	defer func() {
+		compressed := "F" // keep the label value T/F for compatibility, no matter gzip/zstd/snappy
+		if b.compression != compressNone {
+			compressed = "T"
 		}
 
-		buildBodyCostVec.WithLabelValues(
-			w.category.String(),
-			w.httpEncoding.String(),
-			"gzip",
-		).Observe(float64(time.Since(zstart)) / float64(time.Second))
-	}
-
-	defer func() {
 		// NOTE: for multiple dw.eps, here only 1 flush metric.
 		walWorkerFlush.WithLabelValues(
 			b.cat().Alias(),
-			isGzip,
+			compressed,
 			b.from.String()).Observe(float64(len(b.buf())))
 
 		// b always comes from pool, no matter from disk queue or mem queue.
@@ -204,6 +173,13 @@ func (dw *Dataway) doFlush(w *writer, b *body, opts ...WriteOption) error {
 		putBody(b)
 	}()
 
+	// Body from fail-cache or compressed during body-building are
+	// compressed already, it's compression comes with the body.
+	if err := b.compress(dw.compression); err != nil {
+		l.Errorf("compress: %s", err.Error())
+		return err
+	}
+
 	// drop expired packages
 	if b.expired(dw.DropExpiredPackageAt) {
 		l.Warnf("drop expired package %s", b.pretty())
@@ -211,7 +187,12 @@ func (dw *Dataway) doFlush(w *writer, b *body, opts ...WriteOption) error {
 		return nil
 	}
 
-	for _, ep := range dw.eps {
+	eps := dw.eps
+	if w.ep != nil { // only flush to the specified endpoint, such as cleaning fail-cache of the endpoint
+		eps = []*endPoint{w.ep}
+	}
+
+	for _, ep := range eps {
 		if err := ep.writePointData(w, b); err != nil {
 			// 4xx error do not cache data.
 			if errors.Is(err, errWritePoints4XX) {
@@ -219,12 +200,17 @@ func (dw *Dataway) doFlush(w *writer, b *body, opts ...WriteOption) error {
 				continue // current endpoint POST 4xx ignored, but other endpoint maybe ok.
 			}
 
-			l.Errorf("writePointData: %s", err)
+			circuitOpen := errors.Is(err, errCircuitOpen)
+			if circuitOpen {
+				l.Debugf("circuit breaker on %s open, cache the body", ep.id())
+			} else {
+				l.Errorf("writePointData on %s: %s", ep.id(), err)
+			}
 
 			// For a exist failed-cache, we do not need to re-cache it.
 			// and make it fail, the diskcache will rollback and Get() the same data again.
 			if w.cacheClean {
-				return fmt.Errorf("clean fail-cache failed: %w", err)
+				return fmt.Errorf("clean fail-cache on %s failed: %w", ep.id(), err)
 			}
 
 			//nolint:exhaustive
@@ -245,10 +231,12 @@ func (dw *Dataway) doFlush(w *writer, b *body, opts ...WriteOption) error {
 			default: // other categories are default cached.
 			}
 
-			if err := dw.dumpFailCache(b); err != nil {
-				l.Errorf("dumpFailCache %v pts on %s: %s", b.npts, w.category, err)
+			// Only cache the body to the fail-cache of current endpoint, other
+			// endpoints that POST ok will not receive the body again.
+			if err := ep.dumpFailCache(b); err != nil {
+				l.Errorf("dumpFailCache %v pts on %s to %s: %s", b.npts, w.category, ep.id(), err)
 			} else {
-				l.Debugf("dumping %q to failcache ok", b)
+				l.Debugf("dumping %q to failcache of %s ok", b, ep.id())
 			}
 		}
 	}
@@ -256,29 +244,60 @@ func (dw *Dataway) doFlush(w *writer, b *body, opts ...WriteOption) error {
 	return nil
 }
 
-func (dw *Dataway) dumpFailCache(b *body) error {
+func (ep *endPoint) dumpFailCache(b *body) error {
+	if ep.fc == nil {
+		return fmt.Errorf("fail-cache on %s not set", ep.id())
+	}
+
 	if x, err := b.dump(); err != nil {
 		return err
 	} else {
-		return dw.walFail.disk.Put(x) // directly put dumpped body to disk-queue, not mem-queue.
+		defer func() {
+			failCacheSizeVec.WithLabelValues(ep.id()).Set(float64(ep.fc.disk.Size()))
+		}()
+		return ep.fc.disk.Put(x) // directly put dumpped body to disk-queue, not mem-queue.
 	}
 }
 
+// cleanFailCache retry cached body on each endpoint. Endpoints are cleaned
+// independently: a down endpoint will not block others' retrying.
 func (f *flusher) cleanFailCache() error {
-	return f.dw.walFail.DiskGet(func(b *body) error {
-		l.Debugf("clean body %s", b)
+	var lastErr error
+	for _, ep := range f.dw.eps {
+		if err := f.cleanEndpointFailCache(ep); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (f *flusher) cleanEndpointFailCache(ep *endPoint) error {
+	if ep.fc == nil {
+		return nil
+	}
+
+	defer func() {
+		failCacheSizeVec.WithLabelValues(ep.id()).Set(float64(ep.fc.disk.Size()))
+	}()
+
+	return ep.fc.DiskGet(func(b *body) error {
+		l.Debugf("clean body %s on %s", b, ep.id())
 
 		var ( // @b will reset within f.do(), pre-fetch it's meta for metric update.
 			cat  = b.cat()
 			size = len(b.buf())
 		)
 
-		if err := f.do(b, WithCacheClean(true), WithHTTPHeader("X-Fail-Cache-Retry", "1")); err != nil {
+		if err := f.do(b,
+			WithCacheClean(true),
+			withEndpoint(ep),
+			WithHTTPHeader("X-Fail-Cache-Retry", "1")); err != nil {
 			return err
 		}
 
 		// only update metric on clean-ok
-		flushFailCacheVec.WithLabelValues(cat.Alias()).Observe(float64(size))
+		flushFailCacheVec.WithLabelValues(cat.Alias(), ep.id()).Observe(float64(size))
 		return nil
 	}, withReusableBuffer(f.sendBuf, f.marshalBuf))
 }
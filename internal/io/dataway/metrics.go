@@ -54,7 +54,37 @@ var (
 				0.99: 0.001,
 			},
 		},
-		[]string{"category"},
+		[]string{"category", "endpoint"},
+	)
+
+	failCacheSizeVec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "datakit",
+			Subsystem: "io",
+			Name:      "dataway_fail_cache_bytes",
+			Help:      "Dataway fail-cache backlog bytes on each endpoint",
+		},
+		[]string{"endpoint"},
+	)
+
+	circuitBreakerStateVec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "datakit",
+			Subsystem: "io",
+			Name:      "dataway_circuit_breaker_state",
+			Help:      "Dataway circuit breaker state on each endpoint(0: closed, 1: open, 2: half-open)",
+		},
+		[]string{"endpoint"},
+	)
+
+	circuitBreakerOpenVec = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "datakit",
+			Subsystem: "io",
+			Name:      "dataway_circuit_breaker_open_total",
+			Help:      "Dataway circuit breaker opened count on each endpoint",
+		},
+		[]string{"endpoint"},
 	)
 
 	buildBodyCostVec = prometheus.NewSummaryVec(
@@ -73,6 +103,22 @@ var (
 		[]string{"category", "encoding", "stage"},
 	)
 
+	compressRatioVec = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace: "datakit",
+			Subsystem: "io",
+			Name:      "build_body_compress_ratio",
+			Help:      "Compressed/raw size ratio of HTTP body on each compression algorithm",
+
+			Objectives: map[float64]float64{
+				0.5:  0.05,
+				0.9:  0.01,
+				0.99: 0.001,
+			},
+		},
+		[]string{"category", "compression"},
+	)
+
 	buildBodyBatchCountVec = prometheus.NewSummaryVec(
 		prometheus.SummaryOpts{
 			Namespace: "datakit",
@@ -293,12 +339,16 @@ func Metrics() []prometheus.Collector {
 		apiSumVec,
 		httpRetry,
 		buildBodyCostVec,
+		compressRatioVec,
 		buildBodyBatchBytesVec,
 		buildBodyBatchPointsVec,
 		buildBodyBatchCountVec,
 		buildBodyPointsVec,
 		groupedRequestVec,
 		flushFailCacheVec,
+		failCacheSizeVec,
+		circuitBreakerStateVec,
+		circuitBreakerOpenVec,
 		walQueueMemLenVec,
 		flushDroppedPackageVec,
 	}
@@ -317,9 +367,13 @@ func metricsReset() {
 
 	httpRetry.Reset()
 	flushFailCacheVec.Reset()
+	failCacheSizeVec.Reset()
+	circuitBreakerStateVec.Reset()
+	circuitBreakerOpenVec.Reset()
 	walQueueMemLenVec.Reset()
 	flushDroppedPackageVec.Reset()
 	buildBodyCostVec.Reset()
+	compressRatioVec.Reset()
 	buildBodyBatchBytesVec.Reset()
 	buildBodyBatchPointsVec.Reset()
 	buildBodyBatchCountVec.Reset()
@@ -340,10 +394,14 @@ func doRegister() {
 		apiSumVec,
 
 		flushFailCacheVec,
+		failCacheSizeVec,
+		circuitBreakerStateVec,
+		circuitBreakerOpenVec,
 		walQueueMemLenVec,
 		flushDroppedPackageVec,
 		httpRetry,
 		buildBodyCostVec,
+		compressRatioVec,
 		buildBodyBatchBytesVec,
 		buildBodyBatchPointsVec,
 		buildBodyBatchCountVec,
@@ -7,6 +7,7 @@ package dataway
 
 import (
 	"errors"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -164,7 +165,6 @@ func (q *WALQueue) Get(opts ...bodyOpt) (*body, error) {
 	}
 
 	b.from = walFromDisk
-	b.gzon = isGzip(b.buf())
 
 	return b, nil
 }
@@ -186,7 +186,7 @@ func (q *WALQueue) DiskGet(fn walBodyCallback, opts ...bodyOpt) error {
 		}
 
 		b.from = walFromDisk
-		b.gzon = isGzip(b.buf())
+
 		if err := fn(b); err != nil {
 			l.Warnf("walBodyCallback: %s, we try again, ignored", err)
 			return err
@@ -255,9 +255,27 @@ func (dw *Dataway) setupWAL() error {
 		}
 	}
 
-	// setup fail-cache
+	// Setup fail-cache on each endpoint. The WAL above is still shared by all
+	// endpoints: a body within it is POSTed to every endpoint in a single flush,
+	// and only failed endpoints cache it to their own fail-cache, so healthy
+	// endpoints never receive it again.
+	dw.migrateLegacyFailCache()
+
+	for _, ep := range dw.eps {
+		if err := dw.setupFailCache(ep); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setupFailCache setup fail-cache for the endpoint. Each endpoint got it's own fail-cache,
+// so retrying cached body will only POST to the endpoint that failed, and other
+// endpoints will not receive duplicated data.
+func (dw *Dataway) setupFailCache(ep *endPoint) error {
 	if wal, err := dw.doSetupWAL(
-		diskcache.WithPath(filepath.Join(dw.WAL.Path, "fc")),
+		diskcache.WithPath(filepath.Join(dw.WAL.Path, failCacheDir(ep))),
 		diskcache.WithFILODrop(true), // under fail-cache, still drop data if WAL disk full(no matter which category)
 		diskcache.WithNoLock(true),
 		diskcache.WithNoPos(dw.WAL.NoPos),
@@ -265,11 +283,54 @@ func (dw *Dataway) setupWAL() error {
 		diskcache.WithCapacity(int64(dw.WAL.MaxCapacityGB*float64(1<<30)))); err != nil {
 		return err
 	} else {
-		dw.walFail = wal
+		ep.fc = wal
+		l.Infof("setup fail-cache on endpoint %s ok", ep.id())
 	}
+
 	return nil
 }
 
+const (
+	// legacyFailCacheDir is the fail-cache directory shared by all endpoints
+	// within older DataKit.
+	legacyFailCacheDir = "fc"
+
+	failCacheDirPrefix = "fc-"
+)
+
+// failCacheDir get fail-cache directory name of the endpoint. The name derived
+// from the endpoint's host and token, so reordering Dataway URLs will not
+// mismatch the fail-cache and the endpoint.
+func failCacheDir(ep *endPoint) string {
+	return failCacheDirPrefix + ep.hash()
+}
+
+// migrateLegacyFailCache move the legacy fail-cache to the 1st endpoint. We do
+// not know which endpoint the cached body failed on, the 1st one is the only
+// endpoint in most cases.
+func (dw *Dataway) migrateLegacyFailCache() {
+	if len(dw.eps) == 0 {
+		return
+	}
+
+	legacy := filepath.Join(dw.WAL.Path, legacyFailCacheDir)
+	if _, err := os.Stat(legacy); err != nil {
+		return
+	}
+
+	dir := filepath.Join(dw.WAL.Path, failCacheDir(dw.eps[0]))
+	if _, err := os.Stat(dir); err == nil {
+		l.Warnf("fail-cache %q exist, legacy fail-cache %q not migrated", dir, legacy)
+		return
+	}
+
+	if err := os.Rename(legacy, dir); err != nil {
+		l.Errorf("migrate legacy fail-cache %q to %q: %s", legacy, dir, err)
+	} else {
+		l.Infof("legacy fail-cache %q migrated to %q", legacy, dir)
+	}
+}
+
 func (dw *Dataway) isNoDropWAL(cat point.Category) bool {
 	if dw.WAL == nil { // all categories are drop if WAL full.
 		return false
@@ -6,6 +6,8 @@
 package dataway
 
 import (
+	"os"
+	"path/filepath"
 	sync "sync"
 	T "testing"
 	"time"
@@ -306,3 +308,46 @@ func TestNoDrop(t *T.T) {
 		assert.Equal(t, uint64(0), m.GetSummary().GetSampleCount()) // M not retried
 	})
 }
+
+func TestFailCacheDir(t *T.T) {
+	urls := []string{
+		"https://openway.guance.com?token=tkn_11111111111111111111",
+		"https://openway.guance.com?token=tkn_22222222222222222222",
+	}
+
+	t.Run(`stable-on-reorder`, func(t *T.T) {
+		dw := NewDefaultDataway()
+		dw.WAL.Path = t.TempDir()
+		require.NoError(t, dw.Init(WithURLs(urls...)))
+
+		dw2 := NewDefaultDataway()
+		dw2.WAL.Path = t.TempDir()
+		require.NoError(t, dw2.Init(WithURLs(urls[1], urls[0])))
+
+		assert.Equal(t, failCacheDir(dw.eps[0]), failCacheDir(dw2.eps[1]))
+		assert.Equal(t, failCacheDir(dw.eps[1]), failCacheDir(dw2.eps[0]))
+		assert.NotEqual(t, failCacheDir(dw.eps[0]), failCacheDir(dw.eps[1]))
+
+		// same host with different tokens got different id
+		assert.NotEqual(t, dw.eps[0].id(), dw.eps[1].id())
+		assert.NotContains(t, dw.eps[0].id(), "tkn_")
+	})
+
+	t.Run(`migrate-legacy`, func(t *T.T) {
+		dw := NewDefaultDataway()
+		dw.WAL.Path = t.TempDir()
+		require.NoError(t, dw.Init(WithURLs(urls...)))
+
+		legacy := filepath.Join(dw.WAL.Path, legacyFailCacheDir)
+		require.NoError(t, os.MkdirAll(legacy, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(legacy, "data.00000000000000000000000000000000"), []byte("x"), 0o600))
+
+		require.NoError(t, dw.setupWAL())
+
+		_, err := os.Stat(legacy)
+		assert.True(t, os.IsNotExist(err))
+
+		_, err = os.Stat(filepath.Join(dw.WAL.Path, failCacheDir(dw.eps[0]), "data.00000000000000000000000000000000"))
+		assert.NoError(t, err)
+	})
+}
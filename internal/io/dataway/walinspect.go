@@ -0,0 +1,331 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package dataway
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/diskcache"
+	"github.com/GuanceCloud/cliutils/point"
+)
+
+// Within diskcache, each entry is a 4-byte(little-endian) length header
+// following the dumped body, and rotated files end with diskcache.EOFHint.
+const (
+	walEntryHeaderLen = 4
+	walWriteFile      = "data"
+	walPosFile        = ".pos"
+	walLockFile       = ".lock"
+)
+
+// WALEntry is a dumped body within WAL or fail-cache.
+type WALEntry struct {
+	File   string // data file of the entry
+	Offset int64  // offset of the entry within the data file
+
+	Category    point.Category
+	Encoding    point.Encoding
+	Compression string
+	PkgTime     time.Time
+	NPoints     int
+	Size        int // payload bytes(compressed)
+
+	b *body
+}
+
+// Points decode the payload of the entry into points.
+func (e *WALEntry) Points() ([]*point.Point, error) {
+	raw, err := unzip(e.b.compression, e.b.buf())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.b.compression, err)
+	}
+
+	dec := point.GetDecoder(point.WithDecEncoding(e.Encoding))
+	defer point.PutDecoder(dec)
+
+	return dec.Decode(raw)
+}
+
+func (e *WALEntry) String() string {
+	return fmt.Sprintf("%s@%d: cat: %s, enc: %s, compression: %s, pts: %d, bytes: %d",
+		filepath.Base(e.File), e.Offset, e.Category, e.Encoding, e.Compression, e.NPoints, e.Size)
+}
+
+// WALStat is the queue size of WAL or fail-cache.
+type WALStat struct {
+	Files   int
+	Bytes   int64
+	Entries int
+	Points  int
+}
+
+// StatWAL count unconsumed entries within WAL dir.
+func StatWAL(dir string) (*WALStat, error) {
+	files, _, err := walDataFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &WALStat{Files: len(files)}
+	for _, f := range files {
+		if fi, err := os.Stat(f); err == nil {
+			st.Bytes += fi.Size()
+		}
+	}
+
+	if err := ScanWAL(dir, func(e *WALEntry) error {
+		st.Entries++
+		st.Points += e.NPoints
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
+
+// ScanWAL iterate all unconsumed entries within WAL dir(such as <wal-path>/metric
+// or <wal-path>/fc-<hash>) in FIFO order. The dir is read-only during scanning, so the
+// reading position of the running DataKit not affected.
+func ScanWAL(dir string, fn func(*WALEntry) error) error {
+	files, pos, err := walDataFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		var start int64
+		if pos != nil && filepath.Base(string(pos.name)) == filepath.Base(f) {
+			start = pos.seek
+		}
+
+		if err := scanWALFile(f, start, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func scanWALFile(f string, start int64, fn func(*WALEntry) error) error {
+	data, err := os.ReadFile(filepath.Clean(f))
+	if err != nil {
+		return err
+	}
+
+	off := start
+	for off+walEntryHeaderLen <= int64(len(data)) {
+		n := binary.LittleEndian.Uint32(data[off:])
+		if n == diskcache.EOFHint {
+			return nil
+		}
+
+		end := off + walEntryHeaderLen + int64(n)
+		if end > int64(len(data)) { // the entry is writing
+			return nil
+		}
+
+		b := &body{}
+		if err := b.loadCache(data[off+walEntryHeaderLen : end]); err != nil {
+			return fmt.Errorf("%s@%d: %w", f, off, err)
+		}
+
+		e := &WALEntry{
+			File:        f,
+			Offset:      off,
+			Category:    b.cat(),
+			Encoding:    b.enc(),
+			Compression: b.compression.String(),
+			NPoints:     int(b.npts()),
+			Size:        len(b.buf()),
+			b:           b,
+		}
+
+		if b.CacheData.PkgTime > 0 {
+			e.PkgTime = time.Unix(int64(b.CacheData.PkgTime), 0)
+		}
+
+		if err := fn(e); err != nil {
+			return err
+		}
+
+		off = end
+	}
+
+	return nil
+}
+
+type walPos struct {
+	seek int64
+	name []byte
+}
+
+// walDataFiles list data files within WAL dir in reading order, the writing
+// file always the last one.
+func walDataFiles(dir string) ([]string, *walPos, error) {
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		files   []string
+		writing string
+		pos     *walPos
+	)
+
+	for _, de := range des {
+		if de.IsDir() {
+			continue
+		}
+
+		switch de.Name() {
+		case walLockFile:
+		case walPosFile:
+			bin, err := os.ReadFile(filepath.Join(dir, de.Name()))
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if len(bin) > 8 { // see diskcache's pos.MarshalBinary()
+				pos = &walPos{
+					seek: int64(binary.LittleEndian.Uint64(bin)),
+					name: bin[8:],
+				}
+			}
+		case walWriteFile:
+			writing = filepath.Join(dir, de.Name())
+		default:
+			files = append(files, filepath.Join(dir, de.Name()))
+		}
+	}
+
+	sort.Strings(files)
+
+	if writing != "" {
+		files = append(files, writing)
+	}
+
+	return files, pos, nil
+}
+
+// PurgeWAL remove entries that purge returns true from WAL dir, and returns
+// the number of purged entries. Entries already consumed are purged too.
+//
+// DataKit should be stopped during purging.
+func PurgeWAL(dir string, purge func(*WALEntry) bool) (int, error) {
+	tmp := filepath.Clean(dir) + ".purging"
+	if err := os.RemoveAll(tmp); err != nil {
+		return 0, err
+	}
+
+	dc, err := diskcache.Open(diskcache.WithPath(tmp), diskcache.WithNoLock(true))
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		purged int
+		buf    []byte
+	)
+
+	if err := ScanWAL(dir, func(e *WALEntry) error {
+		if purge(e) {
+			purged++
+			return nil
+		}
+
+		if s := e.b.CacheData.Size(); len(buf) < s {
+			buf = make([]byte, s)
+		}
+
+		n, err := e.b.CacheData.MarshalToSizedBuffer(buf[:e.b.CacheData.Size()])
+		if err != nil {
+			return err
+		}
+
+		return dc.Put(buf[:n])
+	}); err != nil {
+		_ = dc.Close()
+		_ = os.RemoveAll(tmp)
+		return 0, err
+	}
+
+	if err := dc.Close(); err != nil {
+		return 0, err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, err
+	}
+
+	return purged, os.Rename(tmp, dir)
+}
+
+// ReplayWALEntry POST the entry to the dataway. Entries within fail-cache only
+// POST to the endpoint that owns the fail-cache, and entries within WAL POST to
+// all endpoints. The entry not cached to fail-cache if POST failed.
+func (dw *Dataway) ReplayWALEntry(e *WALEntry) error {
+	eps, err := dw.replayEndpoints(e)
+	if err != nil {
+		return err
+	}
+
+	w := getWriter(
+		WithHTTPEncoding(e.Encoding),
+		WithCategory(e.Category),
+		WithCacheClean(true), // not applied metrics
+		WithHTTPHeader("X-WAL-Replay", "1"),
+	)
+	defer putWriter(w)
+
+	if u := e.b.url(); u != "" {
+		WithDynamicURL(u)(w)
+	}
+
+	for _, h := range e.b.headers() {
+		WithHTTPHeader(h.Key, h.Value)(w)
+	}
+
+	var lastErr error
+	for _, ep := range eps {
+		if err := ep.writePointData(w, e.b); err != nil {
+			lastErr = fmt.Errorf("%s: %w", ep.id(), err)
+		}
+	}
+
+	return lastErr
+}
+
+// replayEndpoints get endpoints the entry should POST to.
+func (dw *Dataway) replayEndpoints(e *WALEntry) ([]*endPoint, error) {
+	queue := filepath.Base(filepath.Dir(e.File))
+
+	switch {
+	case queue == legacyFailCacheDir: // the legacy fail-cache migrated to the 1st endpoint
+		if len(dw.eps) > 0 {
+			return dw.eps[:1], nil
+		}
+
+	case strings.HasPrefix(queue, failCacheDirPrefix):
+		for _, ep := range dw.eps {
+			if failCacheDir(ep) == queue {
+				return []*endPoint{ep}, nil
+			}
+		}
+
+	default:
+		return dw.eps, nil
+	}
+
+	return nil, fmt.Errorf("no endpoint owns the fail-cache %q", queue)
+}
@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package dataway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	T "testing"
+
+	"github.com/GuanceCloud/cliutils/diskcache"
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWALInspect(t *T.T) {
+	var (
+		requests  int
+		encodings []string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		requests++
+		encodings = append(encodings, r.Header.Get(headerContentEncoding))
+		assert.Equal(t, "1", r.Header.Get("X-WAL-Replay"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	t.Cleanup(func() {
+		metricsReset()
+		diskcache.ResetMetrics()
+	})
+
+	dw := NewDefaultDataway()
+	dw.WAL.Path = t.TempDir()
+	dw.WAL.MemCap = -1 // disable mem-queue
+
+	require.NoError(t, dw.Init(WithURLs(ts.URL)))
+	require.NoError(t, dw.setupWAL())
+
+	cat := point.Logging
+	put := func(name string, n int, algo compressAlgo) {
+		var pts []*point.Point
+		for i := 0; i < n; i++ {
+			pts = append(pts, point.NewPoint(name, point.NewKVs(map[string]any{"f1": i})))
+		}
+
+		w := getWriter(WithPoints(pts),
+			WithCategory(cat),
+			WithBodyCallback(dw.enqueueBody),
+			withCompression(algo),
+			WithCompressDuringBuildBody(algo != compressNone),
+			WithHTTPEncoding(point.Protobuf))
+		defer putWriter(w)
+
+		require.NoError(t, w.buildPointsBody())
+	}
+
+	dir := filepath.Join(dw.WAL.Path, cat.String())
+
+	put("cpu", 10, compressGzip)
+	put("mem", 5, compressZstd)
+	require.NoError(t, dw.walq[cat].disk.(*diskcache.DiskCache).Rotate())
+	put("cpu", 3, compressNone) // within the writing file
+
+	// consume the 1st body, the .pos file updated.
+	f := dw.newFlusher(cat)
+	b, err := f.wal.Get(withReusableBuffer(f.sendBuf, f.marshalBuf))
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	assert.Equal(t, int32(10), b.npts())
+	putBody(b)
+
+	t.Run("stat", func(t *T.T) {
+		st, err := StatWAL(dir)
+		require.NoError(t, err)
+		assert.Equal(t, 2, st.Files)
+		assert.Equal(t, 2, st.Entries)
+		assert.Equal(t, 8, st.Points)
+	})
+
+	t.Run("scan", func(t *T.T) {
+		var entries []*WALEntry
+		require.NoError(t, ScanWAL(dir, func(e *WALEntry) error {
+			entries = append(entries, e)
+			return nil
+		}))
+
+		require.Len(t, entries, 2)
+
+		assert.Equal(t, "zstd", entries[0].Compression)
+		assert.Equal(t, cat, entries[0].Category)
+		assert.Equal(t, point.Protobuf, entries[0].Encoding)
+
+		pts, err := entries[0].Points()
+		require.NoError(t, err)
+		require.Len(t, pts, 5)
+		assert.Equal(t, "mem", pts[0].Name())
+
+		assert.Equal(t, "none", entries[1].Compression)
+		pts, err = entries[1].Points()
+		require.NoError(t, err)
+		require.Len(t, pts, 3)
+		assert.Equal(t, "cpu", pts[0].Name())
+	})
+
+	t.Run("replay", func(t *T.T) {
+		require.NoError(t, ScanWAL(dir, func(e *WALEntry) error {
+			return dw.ReplayWALEntry(e)
+		}))
+
+		assert.Equal(t, 2, requests)
+		assert.Equal(t, []string{"zstd", ""}, encodings)
+	})
+
+	t.Run("purge", func(t *T.T) {
+		n, err := PurgeWAL(dir, func(e *WALEntry) bool {
+			return e.Compression == "zstd"
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		var entries []*WALEntry
+		require.NoError(t, ScanWAL(dir, func(e *WALEntry) error {
+			entries = append(entries, e)
+			return nil
+		}))
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, 3, entries[0].NPoints)
+
+		// the purged WAL still readable by diskcache.
+		dc, err := diskcache.Open(diskcache.WithPath(dir), diskcache.WithNoLock(true))
+		require.NoError(t, err)
+		defer dc.Close() //nolint:errcheck
+
+		require.NoError(t, dc.Rotate())
+		require.NoError(t, dc.Get(func(x []byte) error {
+			b := &body{}
+			require.NoError(t, b.loadCache(x))
+			assert.Equal(t, int32(3), b.npts())
+			return nil
+		}))
+	})
+}
+
+func TestWALReplayFailCache(t *T.T) {
+	var reqs [2]int
+
+	newServer := func(i int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			reqs[i]++
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+
+	ts0, ts1 := newServer(0), newServer(1)
+	defer ts0.Close()
+	defer ts1.Close()
+
+	t.Cleanup(func() {
+		metricsReset()
+		diskcache.ResetMetrics()
+	})
+
+	dw := NewDefaultDataway()
+	dw.WAL.Path = t.TempDir()
+	dw.WAL.MemCap = -1 // disable mem-queue
+
+	require.NoError(t, dw.Init(WithURLs(
+		ts0.URL+"?token=tkn_11111111111111111111",
+		ts1.URL+"?token=tkn_22222222222222222222",
+	)))
+	require.NoError(t, dw.setupWAL())
+
+	cat := point.Logging
+	w := getWriter(WithPoints(point.RandPoints(10)),
+		WithCategory(cat),
+		WithBodyCallback(func(w *writer, b *body) error {
+			if err := dw.eps[1].dumpFailCache(b); err != nil { // only the 2nd endpoint failed
+				return err
+			}
+			return dw.enqueueBody(w, b)
+		}),
+		WithHTTPEncoding(point.Protobuf))
+	defer putWriter(w)
+
+	require.NoError(t, w.buildPointsBody())
+	require.NoError(t, dw.eps[1].fc.disk.(*diskcache.DiskCache).Rotate())
+	require.NoError(t, dw.walq[cat].disk.(*diskcache.DiskCache).Rotate())
+
+	replay := func(dir string) {
+		n := 0
+		require.NoError(t, ScanWAL(filepath.Join(dw.WAL.Path, dir), func(e *WALEntry) error {
+			n++
+			return dw.ReplayWALEntry(e)
+		}))
+		require.Equal(t, 1, n)
+	}
+
+	// fail-cache entry only POST to it's owner
+	replay(failCacheDir(dw.eps[1]))
+	assert.Equal(t, [2]int{0, 1}, reqs)
+
+	// WAL entry POST to all endpoints
+	replay(cat.String())
+	assert.Equal(t, [2]int{1, 2}, reqs)
+
+	// fail-cache of unknown endpoint
+	assert.Error(t, dw.ReplayWALEntry(&WALEntry{File: filepath.Join(dw.WAL.Path, "fc-unknown", "data")}))
+}
@@ -55,15 +55,17 @@ func WithCacheClean(on bool) WriteOption {
 	}
 }
 
-func WithGzip(on gzipFlag) WriteOption {
+func withCompression(algo compressAlgo) WriteOption {
 	return func(w *writer) {
-		w.gzip = on
+		w.compression = algo
 	}
 }
 
-func WithGzipDuringBuildBody(on bool) WriteOption {
+// WithCompressDuringBuildBody compress the body within body-building with
+// Dataway's compression.
+func WithCompressDuringBuildBody(on bool) WriteOption {
 	return func(w *writer) {
-		w.gzipDuringBuildBody = on
+		w.compressDuringBuildBody = on
 	}
 }
 
@@ -93,6 +95,13 @@ func WithBodyCallback(cb bodyCallback) WriteOption {
 	}
 }
 
+// withEndpoint set the only endpoint the body will POST to.
+func withEndpoint(ep *endPoint) WriteOption {
+	return func(w *writer) {
+		w.ep = ep
+	}
+}
+
 func WithNoWAL(on bool) WriteOption {
 	return func(w *writer) {
 		w.noWAL = on
@@ -113,14 +122,16 @@ type writer struct {
 
 	httpEncoding point.Encoding
 
-	gzip gzipFlag
+	compression compressAlgo
 	cacheClean,
 	cacheAll,
 	noWAL,
-	gzipDuringBuildBody bool
+	compressDuringBuildBody bool
 
 	httpHeaders map[string]string
 
+	ep *endPoint // if set, only POST to the endpoint
+
 	bcb bodyCallback
 }
 
@@ -129,14 +140,15 @@ func (w *writer) reset() {
 	w.dynamicURL = ""
 	w.indexName = ""
 	w.points = w.points[:0]
-	w.gzip = gzipNotSet
+	w.compression = compressNone
 	w.cacheClean = false
 	w.cacheAll = false
 	w.noWAL = false
-	w.gzipDuringBuildBody = false
+	w.compressDuringBuildBody = false
 	w.batchBytesSize = defaultBatchSize
 	w.batchSize = 0
 	w.bcb = nil
+	w.ep = nil
 
 	for k := range w.httpHeaders {
 		delete(w.httpHeaders, k)
@@ -170,16 +182,11 @@ func (dw *Dataway) groupPoints(ptg *ptGrouper,
 }
 
 func (dw *Dataway) Write(opts ...WriteOption) error {
-	gzOn := gzipNotSet
-	if dw.GZip {
-		gzOn = gzipSet
-	}
-
 	w := getWriter(
 		// set content encoding(protobuf/line-protocol/json)
 		WithHTTPEncoding(dw.contentEncoding),
-		// setup gzip on or off
-		WithGzip(gzOn),
+		// setup body compression
+		withCompression(dw.compression),
 		// set raw body size limit
 		WithMaxBodyCap(dw.MaxRawBodySize),
 	)
@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	T "testing"
 	"time"
 
@@ -30,7 +31,7 @@ func TestIsGZip(t *T.T) {
 		gz, err := datakit.GZip(data)
 		assert.NoError(t, err)
 
-		assert.Equal(t, gzipFlag(1), isGzip(gz))
+		assert.Equal(t, compressGzip, detectCompress(gz))
 	})
 }
 
@@ -82,7 +83,7 @@ func TestFailCache(t *T.T) {
 		assert.NoError(t, w.buildPointsBody())
 
 		// POST fail and the request dumpped to diskcache
-		dc := dw.walFail.disk.(*diskcache.DiskCache)
+		dc := dw.eps[0].fc.disk.(*diskcache.DiskCache)
 		assert.NoError(t, dc.Rotate()) // force rotate
 		require.True(t, dc.Size() > 0)
 
@@ -104,7 +105,7 @@ func TestFailCache(t *T.T) {
 		assert.Error(t, f.cleanFailCache()) // clean cache retry will fail: @ts still return 5XX
 
 		// we can also get the cache from fail-cache: the diskcache will rollback the failed Get().
-		assert.NoError(t, dw.walFail.DiskGet(func(b *body) error {
+		assert.NoError(t, dw.eps[0].fc.DiskGet(func(b *body) error {
 			defer putBody(b)
 
 			if len(b.buf()) == 0 {
@@ -112,7 +113,7 @@ func TestFailCache(t *T.T) {
 			}
 
 			// check cached data
-			assert.Equal(t, gzipFlag(1), isGzip(b.buf()))
+			assert.Equal(t, compressGzip, b.compression)
 			assert.Equal(t, cat, b.cat())
 			assert.Equal(t, point.Protobuf, b.enc())
 
@@ -141,6 +142,83 @@ func TestFailCache(t *T.T) {
 		assert.NoError(t, err)
 		t.Logf("metrics:\n%s", metrics.MetricFamily2Text(mfs))
 	})
+
+	t.Run(`failcache-on-multiple-endpoints`, func(t *T.T) {
+		var okReqs, failReqs int64
+
+		okts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&okReqs, 1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer okts.Close()
+
+		failOn := int64(1)
+		failts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&failReqs, 1)
+			if atomic.LoadInt64(&failOn) == 1 {
+				w.WriteHeader(http.StatusInternalServerError) // mocked dataway fail
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer failts.Close()
+
+		time.Sleep(time.Second)
+
+		t.Cleanup(func() {
+			metricsReset()
+			diskcache.ResetMetrics()
+		})
+
+		cat := point.Logging
+
+		dw := NewDefaultDataway()
+		dw.WAL.Path = t.TempDir()
+		dw.MaxRetryCount = 1
+
+		require.NoError(t, dw.Init(WithURLs(
+			fmt.Sprintf("%s?token=tkn_11111111111111111111", okts.URL),
+			fmt.Sprintf("%s?token=tkn_22222222222222222222", failts.URL),
+		)))
+		require.NoError(t, dw.setupWAL())
+		require.Len(t, dw.eps, 2)
+
+		w := getWriter(WithPoints(point.RandPoints(100)),
+			WithCategory(cat),
+			WithBodyCallback(func(w *writer, b *body) error {
+				return dw.doFlush(w, b)
+			}),
+			WithHTTPEncoding(dw.contentEncoding))
+		defer putWriter(w)
+
+		assert.NoError(t, w.buildPointsBody())
+		assert.Equal(t, int64(1), atomic.LoadInt64(&okReqs))
+		assert.Equal(t, int64(1), atomic.LoadInt64(&failReqs))
+
+		// only the failed endpoint got fail-cache
+		okdc := dw.eps[0].fc.disk.(*diskcache.DiskCache)
+		faildc := dw.eps[1].fc.disk.(*diskcache.DiskCache)
+		assert.NoError(t, okdc.Rotate())
+		assert.NoError(t, faildc.Rotate())
+		assert.Equal(t, int64(0), okdc.Size())
+		assert.True(t, faildc.Size() > 0)
+
+		f := dw.newFlusher(cat)
+
+		// still fail: the body rollback to fail-cache
+		assert.Error(t, f.cleanFailCache())
+		assert.Equal(t, int64(2), atomic.LoadInt64(&failReqs))
+
+		// endpoint recovered: body only POST to the failed endpoint
+		atomic.StoreInt64(&failOn, 0)
+		assert.NoError(t, f.cleanFailCache())
+		assert.Equal(t, int64(3), atomic.LoadInt64(&failReqs))
+		assert.Equal(t, int64(1), atomic.LoadInt64(&okReqs))
+
+		assert.Equal(t, diskcache.ErrNoData, faildc.BufGet(nil, func([]byte) error {
+			return nil // make sure no data available in fail-cache
+		}))
+	})
 }
 
 func TestWriteWithCache(t *T.T) {
@@ -195,7 +273,7 @@ func TestWriteWithCache(t *T.T) {
 		assert.NoError(t, w.buildPointsBody())
 
 		// check cache content
-		dc := dw.walFail.disk.(*diskcache.DiskCache)
+		dc := dw.eps[0].fc.disk.(*diskcache.DiskCache)
 		assert.NoError(t, dc.Rotate()) // force rotate
 
 		mfs, err := reg.Gather()
@@ -260,7 +338,7 @@ func TestWriteWithCache(t *T.T) {
 		assert.NoError(t, w.buildPointsBody())
 
 		// check cache content
-		dc := dw.walFail.disk.(*diskcache.DiskCache)
+		dc := dw.eps[0].fc.disk.(*diskcache.DiskCache)
 		dc.Size()
 		assert.NoError(t, dc.Rotate()) // force rotate
 
@@ -39,6 +39,12 @@ type filter struct {
 	conditions    map[string]fp.WhereConditions
 	rawConditions map[string]string
 
+	// the rule index of each condition within conditions, used for per-rule metrics.
+	condRules map[string][]string
+
+	// rules with action other than drop, such as sampling/rate-limit/fields-dropping.
+	rules map[string][]*rule
+
 	puller IPuller
 	md5    string
 
@@ -122,8 +128,9 @@ func (f *filter) doFilter(category point.Category, pts []*point.Point) ([]*point
 	// "/v1/write/metric" => "metric"
 	catStr := category.String()
 
-	conds, ok := f.conditions[catStr]
-	if !ok || conds == nil {
+	conds := f.conditions[catStr]
+	rules := f.rules[catStr]
+	if len(conds) == 0 && len(rules) == 0 {
 		l.Debugf("no condition filter for %s", catStr)
 		return pts, 0
 	}
@@ -136,14 +143,15 @@ func (f *filter) doFilter(category point.Category, pts []*point.Point) ([]*point
 		filterLatencyVec.WithLabelValues(catStr, f.rawConditions[catStr], f.source).Observe(float64(time.Since(start)) / float64(time.Second))
 	}()
 
+	data := getTFData()
+	defer putTFData(data)
+
 	for _, pt := range pts {
-		isFiltered, err := CheckPointFiltered(conds, category, pt)
-		if err != nil {
-			l.Errorf("pt.Fields: %s, ignored", err.Error())
-			datakit.PutbackPoints(pt)
-			continue // filter it!
-		}
-		if !isFiltered { // Pick those points that not matched filter rules.
+		data.Setup(category, pt)
+		keep := f.applyRules(catStr, conds, rules, data, pt)
+		data.reset()
+
+		if keep {
 			after = append(after, pt)
 		} else {
 			if datakit.LogSinkDetail {
@@ -154,7 +162,44 @@ func (f *filter) doFilter(category point.Category, pts []*point.Point) ([]*point
 		}
 	}
 
-	return after, len(conds)
+	return after, len(conds) + len(rules)
+}
+
+// applyRules apply drop conditions and other rules on the point, if the point
+// should be dropped, return false.
+func (f *filter) applyRules(catStr string,
+	conds fp.WhereConditions,
+	rules []*rule,
+	data *KVs,
+	pt *point.Point,
+) bool {
+	if len(conds) > 0 {
+		if j := conds.Eval(data); j >= 0 {
+			idx := ""
+			if j < len(f.condRules[catStr]) {
+				idx = f.condRules[catStr][j]
+			}
+
+			filterRulePtsVec.WithLabelValues(catStr, idx, string(actionDrop), f.source).Inc()
+			filterRuleDroppedPtsVec.WithLabelValues(catStr, idx, string(actionDrop), f.source).Inc()
+			return false
+		}
+	}
+
+	for _, r := range rules {
+		if !r.match(data) {
+			continue
+		}
+
+		filterRulePtsVec.WithLabelValues(catStr, r.index, string(r.action), f.source).Inc()
+
+		if !r.apply(pt) {
+			filterRuleDroppedPtsVec.WithLabelValues(catStr, r.index, string(r.action), f.source).Inc()
+			return false
+		}
+	}
+
+	return true
 }
 
 func FilterPts(category point.Category, pts []*point.Point) []*point.Point {
@@ -188,6 +233,8 @@ func newFilter(p IPuller) *filter {
 	return &filter{
 		conditions:    map[string]fp.WhereConditions{},
 		rawConditions: map[string]string{},
+		condRules:     map[string][]string{},
+		rules:         map[string][]*rule{},
 
 		puller: p,
 
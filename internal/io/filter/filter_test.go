@@ -105,6 +105,34 @@ func TestPull(t *testing.T) {
 	assert.Truef(t, f.pullInterval == time.Millisecond*time.Duration(round), "expect %ds, got %s", round, f.pullInterval)
 }
 
+func TestLocalFilter(t *testing.T) {
+	t.Cleanup(func() {
+		filterRulePtsVec.Reset()
+		filterRuleDroppedPtsVec.Reset()
+	})
+
+	f := newFilter(NewLocalFilter(map[string]FilterConditions{
+		"logging": {
+			`{ source = "test1" }`,
+		},
+	}))
+	f.source = sourceLocal
+	f.pull("")
+
+	// local filters should be loaded in the same way as remote filters
+	assert.Len(t, f.conditions["logging"], 1)
+
+	dec := point.GetDecoder(point.WithDecEncoding(point.LineProtocol))
+	defer point.PutDecoder(dec)
+
+	pts, err := dec.Decode([]byte(`test1 f1="1" 123
+test2 f1="1" 123`))
+	assert.NoError(t, err)
+
+	after, _ := f.doFilter(point.Logging, pts)
+	assert.Len(t, after, 1)
+}
+
 // go test -v -timeout 30s -run ^TestGetConds$ gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/filter
 func TestGetConds(t *testing.T) {
 	cases := []struct {
@@ -65,10 +65,14 @@ func getTFData() *KVs {
 }
 
 func putTFData(d *KVs) {
+	d.reset()
+	kvsPool.Put(d)
+}
+
+func (d *KVs) reset() {
 	d.pt = nil
 	d.extKVs = d.extKVs[:0]
 	d.cat = point.UnknownCategory
-	kvsPool.Put(d)
 }
 
 type KVs struct {
@@ -15,6 +15,7 @@ func NewLocalFilter(filters map[string]FilterConditions) *localFilter {
 	return &localFilter{filters: filters}
 }
 
+// Pull returns local filters in the same form of remote filters.
 func (f *localFilter) Pull(_ string) ([]byte, error) {
-	return json.Marshal(f.filters)
+	return json.Marshal(&Filters{Filters: f.filters})
 }
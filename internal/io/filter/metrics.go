@@ -12,7 +12,9 @@ import (
 
 var (
 	filterDroppedPtsVec,
-	filterPtsVec *prometheus.CounterVec
+	filterPtsVec,
+	filterRulePtsVec,
+	filterRuleDroppedPtsVec *prometheus.CounterVec
 
 	filtersUpdateCount prometheus.Counter
 
@@ -79,6 +81,36 @@ func setupMetrics() {
 		},
 	)
 
+	filterRulePtsVec = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "datakit",
+			Subsystem: "filter",
+			Name:      "rule_point_total",
+			Help:      "Points matched by each filter rule",
+		},
+		[]string{
+			"category",
+			"rule",
+			"action",
+			"source",
+		},
+	)
+
+	filterRuleDroppedPtsVec = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "datakit",
+			Subsystem: "filter",
+			Name:      "rule_point_dropped_total",
+			Help:      "Points dropped by each filter rule",
+		},
+		[]string{
+			"category",
+			"rule",
+			"action",
+			"source",
+		},
+	)
+
 	filterPullLatencyVec = prometheus.NewSummaryVec(
 		prometheus.SummaryOpts{
 			Namespace: "datakit",
@@ -127,6 +159,8 @@ func setupMetrics() {
 	metrics.MustRegister(
 		filterDroppedPtsVec,
 		filterPtsVec,
+		filterRulePtsVec,
+		filterRuleDroppedPtsVec,
 		filterParseErrorVec,
 		lastUpdate,
 		filterPullLatencyVec,
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -44,21 +45,50 @@ func (f *filter) refresh(body []byte) error {
 	}
 
 	f.md5 = bodymd5
+
 	// Clear old conditions: we refresh all conditions if any changed(new/delete
 	// conditons or refresh old conditions)
-	f.conditions = map[string]fp.WhereConditions{}
+	var (
+		conditions    = map[string]fp.WhereConditions{}
+		rawConditions = map[string]string{}
+		condRules     = map[string][]string{}
+		rules         = map[string][]*rule{}
+	)
+
 	for k, v := range filters.Filters {
-		conds, err := GetConds(v)
-		if err != nil {
-			l.Errorf("GetConds failed: %v", err)
-			return err
+		for i, x := range v {
+			r, conds, err := parseRule(x)
+			if err != nil {
+				l.Errorf("parseRule failed: %v", err)
+				return err
+			}
+
+			// label rule metrics by index, the raw rule may be too long and
+			// changed frequently.
+			idx := strconv.Itoa(i)
+			if r != nil {
+				r.index = idx
+				rules[k] = append(rules[k], r)
+				continue
+			}
+
+			conditions[k] = append(conditions[k], conds...)
+			for range conds {
+				condRules[k] = append(condRules[k], idx)
+			}
 		}
-		f.conditions[k] = conds
 
 		l.Debugf("set raw filter conditions %v on %s", v, k)
-		f.rawConditions[k] = strings.Join(v, " ")
+		rawConditions[k] = strings.Join(v, " ")
 	}
 
+	f.mtx.Lock()
+	f.conditions = conditions
+	f.rawConditions = rawConditions
+	f.condRules = condRules
+	f.rules = rules
+	f.mtx.Unlock()
+
 	if err := dump(body, f.dumpDir); err != nil {
 		l.Warnf("dump: %s, ignored", err)
 	}
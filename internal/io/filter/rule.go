@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	fp "github.com/GuanceCloud/cliutils/filter"
+	"github.com/GuanceCloud/cliutils/point"
+	"golang.org/x/time/rate"
+)
+
+type ruleAction string
+
+const (
+	actionDrop       ruleAction = "drop"
+	actionSample     ruleAction = "sample"
+	actionRate       ruleAction = "rate"
+	actionDropFields ruleAction = "drop_fields"
+	actionMaskFields ruleAction = "mask_fields"
+
+	maskValue = "******"
+
+	// max limiters within a rate rule, all limiters are reset if exceeded.
+	maxRateLimiters = 10000
+)
+
+// Rule in form of `action(args...) {where-conditions}`, and the conditions are optional.
+var ruleRegexp = regexp.MustCompile(`(?s)^(\w+)\s*\(([^)]*)\)\s*(.*)$`)
+
+// rule is a filter rule with action other than drop. Available rules:
+//
+//   - sample(N) {conditions}: only keep 1-in-N matched points
+//   - rate(N, tag1, tag2...) {conditions}: keep at most N points per second
+//     on each measurement and tag set
+//   - drop_fields(key1, key2...) {conditions}: drop these fields/tags of matched points
+//   - mask_fields(key1, key2...) {conditions}: mask string values of these fields/tags on matched points
+//
+// If no conditions set, the rule applied on all points of the category.
+type rule struct {
+	raw    string
+	index  string // index of the rule within the category, used as metric label
+	action ruleAction
+	conds  fp.WhereConditions
+
+	// for sample
+	sampleN int64
+	counter int64
+
+	// for rate
+	rateN    float64
+	rateTags []string
+	mtx      sync.Mutex
+	limiters map[string]*rate.Limiter
+
+	// for drop/mask fields
+	keys []string
+}
+
+// parseRule parse the filter rule. For rules without action, only conditions
+// returned, they are drop rules.
+func parseRule(s string) (*rule, fp.WhereConditions, error) {
+	s = strings.TrimSpace(s)
+
+	m := ruleRegexp.FindStringSubmatch(s)
+	if m == nil { // plain conditions
+		conds, err := GetConds([]string{s})
+		return nil, conds, err
+	}
+
+	r := &rule{
+		raw:    s,
+		action: ruleAction(m[1]),
+	}
+
+	var args []string
+	for _, arg := range strings.Split(m[2], ",") {
+		if arg = strings.Trim(strings.TrimSpace(arg), `"'`); arg != "" {
+			args = append(args, arg)
+		}
+	}
+
+	if c := strings.TrimSpace(m[3]); c != "" {
+		conds, err := GetConds([]string{c})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if r.action == actionDrop {
+			return nil, conds, nil
+		}
+
+		r.conds = conds
+	}
+
+	switch r.action {
+	case actionDrop:
+		return nil, nil, fmt.Errorf("conditions required for drop rule %q", s)
+
+	case actionSample:
+		if len(args) != 1 {
+			return nil, nil, fmt.Errorf("sample rule %q: only 1 argument required", s)
+		}
+
+		n, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || n <= 0 {
+			return nil, nil, fmt.Errorf("sample rule %q: invalid sample rate %q", s, args[0])
+		}
+		r.sampleN = n
+
+	case actionRate:
+		if len(args) == 0 {
+			return nil, nil, fmt.Errorf("rate rule %q: rate limit required", s)
+		}
+
+		n, err := strconv.ParseFloat(args[0], 64)
+		if err != nil || n <= 0 {
+			return nil, nil, fmt.Errorf("rate rule %q: invalid rate limit %q", s, args[0])
+		}
+		r.rateN = n
+		r.rateTags = args[1:]
+		r.limiters = map[string]*rate.Limiter{}
+
+	case actionDropFields, actionMaskFields:
+		if len(args) == 0 {
+			return nil, nil, fmt.Errorf("%s rule %q: keys required", r.action, s)
+		}
+		r.keys = args
+
+	default:
+		return nil, nil, fmt.Errorf("unknown action %q in rule %q", r.action, s)
+	}
+
+	return r, nil, nil
+}
+
+// match check if the rule applied to the point.
+func (r *rule) match(data fp.KVs) bool {
+	return len(r.conds) == 0 || filtered(r.conds, data)
+}
+
+// apply the rule on matched point, if the point should be dropped, return false.
+func (r *rule) apply(pt *point.Point) bool {
+	switch r.action { //nolint:exhaustive
+	case actionSample:
+		return (atomic.AddInt64(&r.counter, 1)-1)%r.sampleN == 0
+
+	case actionRate:
+		return r.limiter(pt).Allow()
+
+	case actionDropFields:
+		for _, k := range r.keys {
+			pt.Del(k)
+		}
+
+	case actionMaskFields:
+		kvs := pt.KVs()
+		for _, k := range r.keys {
+			kv := kvs.Get(k)
+			if kv == nil {
+				continue
+			}
+
+			if kv.IsTag {
+				pt.SetTag(k, maskValue)
+			} else if _, ok := kv.Raw().(string); ok { // only string field masked
+				pt.Set(k, maskValue)
+			}
+		}
+	}
+
+	return true
+}
+
+func (r *rule) limiter(pt *point.Point) *rate.Limiter {
+	var sb strings.Builder
+	sb.WriteString(pt.Name())
+	for _, k := range r.rateTags {
+		sb.WriteByte(0)
+		sb.WriteString(pt.GetTag(k))
+	}
+
+	key := sb.String()
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	lmt, ok := r.limiters[key]
+	if !ok {
+		if len(r.limiters) >= maxRateLimiters {
+			l.Warnf("too many(%d) limiters on rule %q, reset them", len(r.limiters), r.raw)
+			r.limiters = map[string]*rate.Limiter{}
+		}
+
+		burst := int(r.rateN)
+		if burst < 1 {
+			burst = 1
+		}
+
+		lmt = rate.NewLimiter(rate.Limit(r.rateN), burst)
+		r.limiters[key] = lmt
+	}
+
+	return lmt
+}
+
+// MatchRule check if the point matched by the filter rule, and returns action of the rule.
+func MatchRule(x string, category point.Category, pt *point.Point) (string, bool, error) {
+	r, conds, err := parseRule(x)
+	if err != nil {
+		return "", false, err
+	}
+
+	data := getTFData()
+	data.Setup(category, pt)
+	defer putTFData(data)
+
+	if r == nil {
+		return string(actionDrop), filtered(conds, data), nil
+	}
+
+	return string(r.action), r.match(data), nil
+}
+
+// CheckRule check if the filter rule valid.
+func CheckRule(x string) error {
+	_, _, err := parseRule(x)
+	return err
+}
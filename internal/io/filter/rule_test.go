@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package filter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/GuanceCloud/cliutils/metrics"
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	cases := []struct {
+		name   string
+		rule   string
+		action ruleAction
+		conds  int
+		fail   bool
+	}{
+		{name: "plain", rule: `{ source = "nginx" }`, action: actionDrop, conds: 1},
+		{name: "drop", rule: `drop() { source = "nginx" }`, action: actionDrop, conds: 1},
+		{name: "drop-without-conds", rule: `drop()`, fail: true},
+		{name: "sample", rule: `sample(10) { source = "nginx" }`, action: actionSample},
+		{name: "sample-all", rule: `sample(10)`, action: actionSample},
+		{name: "sample-invalid", rule: `sample(0)`, fail: true},
+		{name: "sample-too-many-args", rule: `sample(10, 2)`, fail: true},
+		{name: "rate", rule: `rate(100, "host", service) { source = "nginx" }`, action: actionRate},
+		{name: "rate-invalid", rule: `rate(abc)`, fail: true},
+		{name: "drop-fields", rule: `drop_fields(f1, t1)`, action: actionDropFields},
+		{name: "mask-fields-no-keys", rule: `mask_fields()`, fail: true},
+		{name: "unknown-action", rule: `unknown(1) { source = "nginx" }`, fail: true},
+		{name: "invalid-conds", rule: `sample(10) { source = }`, fail: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, conds, err := parseRule(tc.rule)
+			if tc.fail {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tc.action == actionDrop {
+				assert.Nil(t, r)
+				assert.Len(t, conds, tc.conds)
+				return
+			}
+
+			require.NotNil(t, r)
+			assert.Equal(t, tc.action, r.action)
+		})
+	}
+}
+
+type rulesPuller map[string]FilterConditions
+
+func (p rulesPuller) Pull(_ string) ([]byte, error) {
+	return json.Marshal(&Filters{Filters: p})
+}
+
+func TestRules(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(filterRulePtsVec, filterRuleDroppedPtsVec)
+
+	t.Cleanup(func() {
+		filterRulePtsVec.Reset()
+		filterRuleDroppedPtsVec.Reset()
+	})
+
+	f := newFilter(rulesPuller(map[string]FilterConditions{
+		"logging": {
+			`{ source = "dropped" }`,
+			`sample(5) { source = "sampled" }`,
+			`rate(2, host) { source = "limited" }`,
+			`drop_fields(password, t1) { source = "secret" }`,
+			`mask_fields(token, t2, num) { source = "secret" }`,
+		},
+	}))
+	f.source = sourceLocal
+	f.pull("")
+
+	newPoints := func(name string, n int, tags map[string]string) (pts []*point.Point) {
+		for i := 0; i < n; i++ {
+			kvs := point.NewKVs(map[string]any{
+				"password": "pwd",
+				"token":    "tkn",
+				"num":      123,
+				"f1":       i,
+			})
+			for k, v := range tags {
+				kvs = kvs.AddTag(k, v)
+			}
+			pts = append(pts, point.NewPoint(name, kvs))
+		}
+		return
+	}
+
+	t.Run("drop", func(t *testing.T) {
+		after, _ := f.doFilter(point.Logging, newPoints("dropped", 3, nil))
+		assert.Len(t, after, 0)
+	})
+
+	t.Run("sample", func(t *testing.T) {
+		after, _ := f.doFilter(point.Logging, newPoints("sampled", 10, nil))
+		assert.Len(t, after, 2)
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		pts := append(newPoints("limited", 5, map[string]string{"host": "h1"}),
+			newPoints("limited", 5, map[string]string{"host": "h2"})...)
+
+		after, _ := f.doFilter(point.Logging, pts)
+		assert.Len(t, after, 4) // 2 for each host
+	})
+
+	t.Run("drop-and-mask-fields", func(t *testing.T) {
+		after, _ := f.doFilter(point.Logging,
+			newPoints("secret", 1, map[string]string{"t1": "v1", "t2": "v2"}))
+		require.Len(t, after, 1)
+
+		pt := after[0]
+		assert.Nil(t, pt.Get("password"))
+		assert.Equal(t, "", pt.GetTag("t1"))
+		assert.Equal(t, maskValue, pt.Get("token"))
+		assert.Equal(t, maskValue, pt.GetTag("t2"))
+		assert.Equal(t, int64(123), pt.Get("num")) // non-string field not masked
+	})
+
+	t.Run("other-category", func(t *testing.T) {
+		after, _ := f.doFilter(point.Metric, newPoints("dropped", 3, nil))
+		assert.Len(t, after, 3)
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		mfs, err := reg.Gather()
+		require.NoError(t, err)
+
+		m := metrics.GetMetricOnLabels(mfs, "datakit_filter_rule_point_total",
+			"sample", "logging", "1", sourceLocal) // labels sorted by name
+		require.NotNil(t, m)
+		assert.Equal(t, 10.0, m.GetCounter().GetValue())
+
+		m = metrics.GetMetricOnLabels(mfs, "datakit_filter_rule_point_dropped_total",
+			"sample", "logging", "1", sourceLocal)
+		require.NotNil(t, m)
+		assert.Equal(t, 8.0, m.GetCounter().GetValue())
+
+		m = metrics.GetMetricOnLabels(mfs, "datakit_filter_rule_point_dropped_total",
+			"drop", "logging", "0", sourceLocal)
+		require.NotNil(t, m)
+		assert.Equal(t, 3.0, m.GetCounter().GetValue())
+
+		m = metrics.GetMetricOnLabels(mfs, "datakit_filter_rule_point_total",
+			"mask_fields", "logging", "4", sourceLocal)
+		require.NotNil(t, m)
+		assert.Equal(t, 1.0, m.GetCounter().GetValue())
+	})
+}
+
+func TestMatchRule(t *testing.T) {
+	pt := point.NewPoint("nginx", point.NewKVs(map[string]any{"f1": 1}))
+
+	action, matched, err := MatchRule(`sample(3) { source = "nginx" }`, point.Logging, pt)
+	require.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, "sample", action)
+
+	action, matched, err = MatchRule(`{ source = "mysql" }`, point.Logging, pt)
+	require.NoError(t, err)
+	assert.False(t, matched)
+	assert.Equal(t, "drop", action)
+
+	assert.Error(t, CheckRule(`sample(abc)`))
+}
@@ -16,6 +16,7 @@ import (
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/dataway"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/filter"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/sink"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/recorder"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/remotejob"
 )
@@ -41,6 +42,9 @@ type dkIO struct {
 	withCompactor bool
 
 	recorder *recorder.Recorder
+	sinker   *sink.Sinker // extra outputs besides dataway
+
+	compactors sync.WaitGroup
 
 	flushInterval time.Duration
 	availableCPUs,
@@ -104,7 +108,9 @@ func (x *dkIO) start() {
 			log.Infof("start %dth workers on %q", n, cat)
 			g := datakit.G("io/compactor/" + cat.Alias())
 			for i := 0; i < n; i++ {
+				x.compactors.Add(1)
 				g.Go(func(_ context.Context) error {
+					defer x.compactors.Done()
 					x.runCompactor(cat)
 					return nil
 				})
@@ -136,6 +142,21 @@ func (x *dkIO) start() {
 			}
 		}
 	}
+	if x.sinker != nil {
+		x.sinker.Start()
+
+		g := datakit.G("io/sink")
+		g.Go(func(_ context.Context) error {
+			<-datakit.Exit.Wait()
+
+			// compactors flush cached points to sinks on exit, wait them before closing sinks.
+			x.compactors.Wait()
+			x.sinker.Close()
+			log.Info("sinks closed")
+			return nil
+		})
+	}
+
 	log.Infof("remote_job x.remotemanager %v", x.remoteManager == nil)
 	if x.remoteManager != nil {
 		g := datakit.G("io/remote_job")
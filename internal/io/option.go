@@ -10,6 +10,7 @@ import (
 
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/dataway"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/filter"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/sink"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/recorder"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/remotejob"
 )
@@ -85,6 +86,21 @@ func WithDataway(dw dataway.IDataway) IOOption {
 	}
 }
 
+// WithSinks setup extra outputs besides dataway.
+func WithSinks(confs []*sink.Conf) IOOption {
+	return func(x *dkIO) {
+		if len(confs) == 0 {
+			return
+		}
+
+		if s, err := sink.NewSinker(confs); err != nil {
+			log.Warnf("invalid sinks: %s, ignored", err)
+		} else {
+			x.sinker = s
+		}
+	}
+}
+
 // WithFilters used to setup point filter.
 func WithFilters(filters map[string]filter.FilterConditions) IOOption {
 	return func(x *dkIO) {
@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package sink
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+)
+
+const (
+	extLineProtocol = ".lp"
+	extPBJson       = ".pbjson"
+)
+
+// fileSink write points to local rotated files, each category a file.
+type fileSink struct {
+	path       string
+	enc        point.Encoding
+	maxSizeMB  int
+	maxBackups int
+
+	mtx     sync.Mutex
+	writers map[point.Category]*lumberjack.Logger
+}
+
+func newFileSink(c *Conf) (Sink, error) {
+	fs := &fileSink{
+		path:       c.Path,
+		enc:        point.EncodingStr(c.Encoding),
+		maxSizeMB:  c.MaxSizeMB,
+		maxBackups: c.MaxBackups,
+		writers:    map[point.Category]*lumberjack.Logger{},
+	}
+
+	if fs.path == "" {
+		fs.path = filepath.Join(datakit.InstallDir, "sink", c.Name)
+	}
+
+	switch fs.enc { // nolint:exhaustive
+	case point.LineProtocol, point.PBJSON:
+	default:
+		return nil, fmt.Errorf("invalid file sink encoding %q, only line-protocol and pbjson allowed", c.Encoding)
+	}
+
+	if fs.maxSizeMB <= 0 {
+		fs.maxSizeMB = 32
+	}
+
+	if fs.maxBackups <= 0 {
+		fs.maxBackups = 5
+	}
+
+	if err := os.MkdirAll(fs.path, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+func (fs *fileSink) writer(cat point.Category) *lumberjack.Logger {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+
+	if w, ok := fs.writers[cat]; ok {
+		return w
+	}
+
+	ext := extLineProtocol
+	if fs.enc == point.PBJSON {
+		ext = extPBJson
+	}
+
+	w := &lumberjack.Logger{
+		Filename:   filepath.Join(fs.path, cat.String()+ext),
+		MaxSize:    fs.maxSizeMB,
+		MaxBackups: fs.maxBackups,
+	}
+
+	fs.writers[cat] = w
+	return w
+}
+
+func (fs *fileSink) Send(cat point.Category, pts []*point.Point) error {
+	var buf bytes.Buffer
+
+	for _, pt := range pts {
+		switch fs.enc { // nolint:exhaustive
+		case point.PBJSON:
+			j, err := pt.PBJson()
+			if err != nil {
+				l.Warnf("PBJson: %s, point ignored", err)
+				continue
+			}
+			buf.Write(j)
+
+		default:
+			buf.WriteString(pt.LineProto())
+		}
+
+		buf.WriteByte('\n')
+	}
+
+	_, err := fs.writer(cat).Write(buf.Bytes())
+	return err
+}
+
+func (fs *fileSink) Close() error {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+
+	for _, w := range fs.writers {
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nolint:gochecknoinits
+func init() {
+	Add(KindFile, newFileSink)
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package sink
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/httpcli"
+)
+
+// httpPoster is the HTTP client shared by HTTP based sinks.
+type httpPoster struct {
+	cli     *http.Client
+	headers map[string]string
+}
+
+func newHTTPPoster(c *Conf) (*httpPoster, error) {
+	if _, err := url.ParseRequestURI(c.URL); err != nil {
+		return nil, fmt.Errorf("invalid sink URL %q: %w", c.URL, err)
+	}
+
+	cli := httpcli.Cli(&httpcli.Options{
+		DialTimeout: c.Timeout,
+	})
+	cli.Timeout = c.Timeout
+
+	return &httpPoster{
+		cli:     cli,
+		headers: c.Headers,
+	}, nil
+}
+
+func (p *httpPoster) post(u string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	// user defined headers, such as Authorization
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.cli.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	switch resp.StatusCode / 100 {
+	case 2:
+		return nil
+	case 4: // retry 4xx is meaningless, drop the data
+		l.Warnf("post %d bytes to %s failed(HTTP: %s): %s, data dropped", len(body), u, resp.Status, string(respBody))
+		return nil
+	default:
+		return fmt.Errorf("post %d bytes to %s failed(HTTP: %s): %s", len(body), u, resp.Status, string(respBody))
+	}
+}
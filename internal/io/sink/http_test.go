@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package sink
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	T "testing"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	collogs "github.com/GuanceCloud/tracing-protos/opentelemetry-gen-go/collector/logs/v1"
+	colmetrics "github.com/GuanceCloud/tracing-protos/opentelemetry-gen-go/collector/metrics/v1"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOTLPSink(t *T.T) {
+	var (
+		logReq collogs.ExportLogsServiceRequest
+		mReq   colmetrics.ExportMetricsServiceRequest
+		status = http.StatusOK
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer tkn", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case otlpMetricsPath:
+			require.NoError(t, proto.Unmarshal(body, &mReq))
+		case otlpLogsPath:
+			require.NoError(t, proto.Unmarshal(body, &logReq))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	s, err := newOTLPSink(&Conf{URL: ts.URL + "/", Timeout: time.Second, Headers: map[string]string{"Authorization": "Bearer tkn"}})
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	t.Run("metric", func(t *T.T) {
+		pts := []*point.Point{
+			point.NewPoint("cpu",
+				point.NewKVs(map[string]any{"usage": 1.5, "cores": 4, "info": "not-number"}).AddTag("host", "h1"),
+				point.WithTime(now)),
+		}
+
+		require.NoError(t, s.Send(point.Metric, pts))
+
+		require.Len(t, mReq.ResourceMetrics, 1)
+		ms := mReq.ResourceMetrics[0].ScopeMetrics[0].Metrics
+		require.Len(t, ms, 2)
+
+		names := map[string]bool{}
+		for _, m := range ms {
+			names[m.Name] = true
+			dps := m.GetGauge().DataPoints
+			require.Len(t, dps, 1)
+			assert.Equal(t, uint64(now.UnixNano()), dps[0].TimeUnixNano)
+			assert.Equal(t, "host", dps[0].Attributes[0].Key)
+			assert.Equal(t, "h1", dps[0].Attributes[0].Value.GetStringValue())
+		}
+
+		assert.True(t, names["cpu_usage"])
+		assert.True(t, names["cpu_cores"])
+	})
+
+	t.Run("logging", func(t *T.T) {
+		pts := []*point.Point{
+			point.NewPoint("nginx",
+				point.NewKVs(map[string]any{"message": "hello", "status_code": 200}).AddTag("status", "info"),
+				point.WithTime(now)),
+		}
+
+		require.NoError(t, s.Send(point.Logging, pts))
+
+		records := logReq.ResourceLogs[0].ScopeLogs[0].LogRecords
+		require.Len(t, records, 1)
+		assert.Equal(t, "hello", records[0].Body.GetStringValue())
+		assert.Equal(t, "info", records[0].SeverityText)
+
+		attrs := map[string]bool{}
+		for _, kv := range records[0].Attributes {
+			attrs[kv.Key] = true
+		}
+		assert.True(t, attrs["measurement"])
+		assert.True(t, attrs["status_code"])
+		assert.False(t, attrs["message"])
+	})
+
+	t.Run("5xx", func(t *T.T) {
+		status = http.StatusServiceUnavailable
+		defer func() { status = http.StatusOK }()
+
+		assert.Error(t, s.Send(point.Logging, point.RandPoints(1)))
+	})
+
+	t.Run("4xx-dropped", func(t *T.T) {
+		status = http.StatusBadRequest
+		defer func() { status = http.StatusOK }()
+
+		assert.NoError(t, s.Send(point.Logging, point.RandPoints(1)))
+	})
+}
+
+func TestPromRWSink(t *T.T) {
+	var body []byte
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		x, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		body, err = snappy.Decode(nil, x)
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	s, err := newPromRWSink(&Conf{URL: ts.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	now := time.Now()
+	pts := []*point.Point{
+		point.NewPoint("disk",
+			point.NewKVs(map[string]any{"used": 100, "info": "str-ignored"}).AddTag("device", "/dev/sda").AddTag("host-name", "h1"),
+			point.WithTime(now)),
+	}
+
+	t.Run("non-metric-ignored", func(t *T.T) {
+		require.NoError(t, s.Send(point.Logging, pts))
+		assert.Nil(t, body)
+	})
+
+	t.Run("metric", func(t *T.T) {
+		require.NoError(t, s.Send(point.Metric, pts))
+		require.NotEmpty(t, body)
+
+		// decode WriteRequest
+		num, typ, n := protowire.ConsumeTag(body)
+		require.True(t, n > 0)
+		assert.Equal(t, protowire.Number(1), num)
+		assert.Equal(t, protowire.BytesType, typ)
+
+		series, m := protowire.ConsumeBytes(body[n:])
+		require.True(t, m > 0)
+		assert.Equal(t, len(body), n+m, "only 1 series expected")
+
+		var (
+			labels = map[string]string{}
+			value  float64
+			ts     int64
+		)
+
+		for len(series) > 0 {
+			num, _, n := protowire.ConsumeTag(series)
+			x, m := protowire.ConsumeBytes(series[n:])
+			series = series[n+m:]
+
+			switch num {
+			case 1: // label
+				_, _, n1 := protowire.ConsumeTag(x)
+				name, m1 := protowire.ConsumeString(x[n1:])
+				x = x[n1+m1:]
+				_, _, n2 := protowire.ConsumeTag(x)
+				val, _ := protowire.ConsumeString(x[n2:])
+				labels[name] = val
+			case 2: // sample
+				_, _, n1 := protowire.ConsumeTag(x)
+				v, m1 := protowire.ConsumeFixed64(x[n1:])
+				value = math.Float64frombits(v)
+				x = x[n1+m1:]
+				_, _, n2 := protowire.ConsumeTag(x)
+				tsv, _ := protowire.ConsumeVarint(x[n2:])
+				ts = int64(tsv)
+			}
+		}
+
+		assert.Equal(t, map[string]string{
+			"__name__":  "disk_used",
+			"device":    "/dev/sda",
+			"host_name": "h1",
+		}, labels)
+		assert.Equal(t, 100.0, value)
+		assert.Equal(t, now.UnixMilli(), ts)
+	})
+}
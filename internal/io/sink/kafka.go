@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/IBM/sarama"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+)
+
+// kafkaSink send each point as a kafka message, the message key is the
+// measurement name of the point.
+type kafkaSink struct {
+	brokers []string
+	topic   string
+	enc     point.Encoding
+	cfg     *sarama.Config
+
+	mtx      sync.Mutex
+	producer sarama.SyncProducer
+}
+
+func newKafkaSink(c *Conf) (Sink, error) {
+	if len(c.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka brokers not set")
+	}
+
+	ks := &kafkaSink{
+		brokers: c.Brokers,
+		topic:   c.Topic,
+		enc:     point.EncodingStr(c.Encoding),
+		cfg:     sarama.NewConfig(),
+	}
+
+	switch ks.enc { // nolint:exhaustive
+	case point.LineProtocol, point.PBJSON:
+	default:
+		return nil, fmt.Errorf("invalid kafka sink encoding %q, only line-protocol and pbjson allowed", c.Encoding)
+	}
+
+	ks.cfg.Producer.Return.Successes = true
+	ks.cfg.Producer.RequiredAcks = sarama.WaitForLocal
+	ks.cfg.Producer.Timeout = c.Timeout
+	ks.cfg.Net.DialTimeout = c.Timeout
+
+	return ks, nil
+}
+
+// topicOf get topic of the category, if topic not set, use datakit-<category> as topic.
+func (ks *kafkaSink) topicOf(cat point.Category) string {
+	if ks.topic != "" {
+		return ks.topic
+	}
+
+	return "datakit-" + cat.String()
+}
+
+// getProducer create producer lazily: kafka may not ready during
+// datakit startup, and points are queued in WAL before kafka ready.
+func (ks *kafkaSink) getProducer() (sarama.SyncProducer, error) {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	if ks.producer != nil {
+		return ks.producer, nil
+	}
+
+	p, err := sarama.NewSyncProducer(ks.brokers, ks.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ks.producer = p
+	return p, nil
+}
+
+func (ks *kafkaSink) Send(cat point.Category, pts []*point.Point) error {
+	p, err := ks.getProducer()
+	if err != nil {
+		return fmt.Errorf("kafka producer: %w", err)
+	}
+
+	topic := ks.topicOf(cat)
+	msgs := make([]*sarama.ProducerMessage, 0, len(pts))
+
+	for _, pt := range pts {
+		var val []byte
+
+		switch ks.enc { // nolint:exhaustive
+		case point.PBJSON:
+			if val, err = pt.PBJson(); err != nil {
+				l.Warnf("PBJson: %s, point ignored", err)
+				continue
+			}
+		default:
+			val = []byte(pt.LineProto())
+		}
+
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic: topic,
+			Key:   sarama.StringEncoder(pt.Name()),
+			Value: sarama.ByteEncoder(val),
+		})
+	}
+
+	return sendMessages(p, msgs)
+}
+
+// sendMessages send the messages and retry the failed ones only, for the others
+// already delivered to kafka. The retry stops on datakit exit, and the whole
+// batch will be sent again after restart.
+func sendMessages(p sarama.SyncProducer, msgs []*sarama.ProducerMessage) error {
+	for {
+		err := p.SendMessages(msgs)
+		if err == nil {
+			return nil
+		}
+
+		var perrs sarama.ProducerErrors
+		if !errors.As(err, &perrs) || len(perrs) == 0 {
+			return err
+		}
+
+		failed := make([]*sarama.ProducerMessage, 0, len(perrs))
+		for _, pe := range perrs {
+			failed = append(failed, pe.Msg)
+		}
+
+		l.Warnf("kafka: %d/%d messages failed: %s, retry them", len(failed), len(msgs), perrs[0].Err)
+		msgs = failed
+
+		select {
+		case <-datakit.Exit.Wait():
+			return err
+		case <-time.After(defaultRetryDelay):
+		}
+	}
+}
+
+func (ks *kafkaSink) Close() error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	if ks.producer != nil {
+		return ks.producer.Close()
+	}
+
+	return nil
+}
+
+// nolint:gochecknoinits
+func init() {
+	Add(KindKafka, newKafkaSink)
+}
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package sink
+
+import (
+	T "testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failOnceProducer fail the messages within fails on first send.
+type failOnceProducer struct {
+	sarama.SyncProducer
+
+	fails map[string]bool
+	sent  []string
+}
+
+func (p *failOnceProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	var errs sarama.ProducerErrors
+	for _, m := range msgs {
+		key, _ := m.Key.Encode()
+		if p.fails[string(key)] {
+			delete(p.fails, string(key))
+			errs = append(errs, &sarama.ProducerError{Msg: m, Err: sarama.ErrNotLeaderForPartition})
+			continue
+		}
+		p.sent = append(p.sent, string(key))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func TestKafkaSendMessages(t *T.T) {
+	old := defaultRetryDelay
+	defaultRetryDelay = time.Millisecond
+	t.Cleanup(func() { defaultRetryDelay = old })
+
+	var msgs []*sarama.ProducerMessage
+	for _, k := range []string{"m1", "m2", "m3"} {
+		msgs = append(msgs, &sarama.ProducerMessage{Key: sarama.StringEncoder(k)})
+	}
+
+	p := &failOnceProducer{fails: map[string]bool{"m2": true}}
+	require.NoError(t, sendMessages(p, msgs))
+
+	// only the failed message resent, no duplicates
+	assert.Equal(t, []string{"m1", "m3", "m2"}, p.sent)
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package sink
+
+import (
+	"github.com/GuanceCloud/cliutils/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	sinkPointVec = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "datakit",
+			Subsystem: "io",
+			Name:      "sink_point_total",
+			Help:      "Sink points, partitioned by sink name, category and send status(ok/fail/drop)",
+		},
+		[]string{"name", "category", "status"},
+	)
+
+	sinkWALSizeVec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "datakit",
+			Subsystem: "io",
+			Name:      "sink_wal_bytes",
+			Help:      "Sink WAL backlog bytes",
+		},
+		[]string{"name", "category"},
+	)
+
+	sinkSendCostVec = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace: "datakit",
+			Subsystem: "io",
+			Name:      "sink_send_cost_seconds",
+			Help:      "Sink send points cost",
+
+			Objectives: map[float64]float64{
+				0.5:  0.05,
+				0.9:  0.01,
+				0.99: 0.001,
+			},
+		},
+		[]string{"name", "category"},
+	)
+)
+
+// Metrics get all metrics about sink.
+func Metrics() []prometheus.Collector {
+	return []prometheus.Collector{
+		sinkPointVec,
+		sinkWALSizeVec,
+		sinkSendCostVec,
+	}
+}
+
+func metricsReset() {
+	sinkPointVec.Reset()
+	sinkWALSizeVec.Reset()
+	sinkSendCostVec.Reset()
+}
+
+// nolint:gochecknoinits
+func init() {
+	metrics.MustRegister(Metrics()...)
+}
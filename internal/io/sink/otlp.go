@@ -0,0 +1,224 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package sink
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GuanceCloud/cliutils/point"
+	collogs "github.com/GuanceCloud/tracing-protos/opentelemetry-gen-go/collector/logs/v1"
+	colmetrics "github.com/GuanceCloud/tracing-protos/opentelemetry-gen-go/collector/metrics/v1"
+	common "github.com/GuanceCloud/tracing-protos/opentelemetry-gen-go/common/v1"
+	logs "github.com/GuanceCloud/tracing-protos/opentelemetry-gen-go/logs/v1"
+	metrics "github.com/GuanceCloud/tracing-protos/opentelemetry-gen-go/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+)
+
+const (
+	otlpMetricsPath = "/v1/metrics"
+	otlpLogsPath    = "/v1/logs"
+)
+
+// otlpSink send points in OTLP/HTTP(protobuf). Points of metric category
+// are sent as gauges, each numeric field a gauge named as <measurement>_<field>.
+// Points of other categories are sent as logs.
+type otlpSink struct {
+	*httpPoster
+	url   string
+	scope *common.InstrumentationScope
+}
+
+func newOTLPSink(c *Conf) (Sink, error) {
+	p, err := newHTTPPoster(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &otlpSink{
+		httpPoster: p,
+		url:        strings.TrimSuffix(c.URL, "/"),
+		scope: &common.InstrumentationScope{
+			Name:    "datakit",
+			Version: datakit.Version,
+		},
+	}, nil
+}
+
+func (s *otlpSink) Send(cat point.Category, pts []*point.Point) error {
+	var (
+		body []byte
+		path string
+		err  error
+	)
+
+	switch cat { // nolint:exhaustive
+	case point.Metric, point.MetricDeprecated:
+		path = otlpMetricsPath
+		body, err = proto.Marshal(s.metricsRequest(pts))
+	default:
+		path = otlpLogsPath
+		body, err = proto.Marshal(s.logsRequest(cat, pts))
+	}
+
+	if err != nil {
+		return fmt.Errorf("proto.Marshal: %w", err)
+	}
+
+	return s.post(s.url+path, body, map[string]string{
+		"Content-Type": "application/x-protobuf",
+	})
+}
+
+func (s *otlpSink) metricsRequest(pts []*point.Point) *colmetrics.ExportMetricsServiceRequest {
+	var (
+		arr     []*metrics.Metric
+		indexed = map[string]*metrics.Metric{}
+	)
+
+	for _, pt := range pts {
+		attrs := tagAttributes(pt)
+		ts := uint64(pt.Time().UnixNano())
+
+		for _, kv := range pt.Fields() {
+			dp := &metrics.NumberDataPoint{
+				Attributes:   attrs,
+				TimeUnixNano: ts,
+			}
+
+			switch v := kv.Raw().(type) {
+			case int64:
+				dp.Value = &metrics.NumberDataPoint_AsInt{AsInt: v}
+			case uint64:
+				dp.Value = &metrics.NumberDataPoint_AsInt{AsInt: int64(v)}
+			case float64:
+				dp.Value = &metrics.NumberDataPoint_AsDouble{AsDouble: v}
+			case bool:
+				if v {
+					dp.Value = &metrics.NumberDataPoint_AsInt{AsInt: 1}
+				} else {
+					dp.Value = &metrics.NumberDataPoint_AsInt{AsInt: 0}
+				}
+			default: // non-numeric fields ignored
+				continue
+			}
+
+			name := pt.Name() + "_" + kv.Key
+			m, ok := indexed[name]
+			if !ok {
+				m = &metrics.Metric{
+					Name: name,
+					Data: &metrics.Metric_Gauge{Gauge: &metrics.Gauge{}},
+				}
+				indexed[name] = m
+				arr = append(arr, m)
+			}
+
+			g := m.Data.(*metrics.Metric_Gauge).Gauge
+			g.DataPoints = append(g.DataPoints, dp)
+		}
+	}
+
+	return &colmetrics.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metrics.ResourceMetrics{
+			{
+				ScopeMetrics: []*metrics.ScopeMetrics{
+					{Scope: s.scope, Metrics: arr},
+				},
+			},
+		},
+	}
+}
+
+func (s *otlpSink) logsRequest(cat point.Category, pts []*point.Point) *collogs.ExportLogsServiceRequest {
+	records := make([]*logs.LogRecord, 0, len(pts))
+
+	for _, pt := range pts {
+		attrs := tagAttributes(pt)
+		attrs = append(attrs,
+			&common.KeyValue{Key: "measurement", Value: strValue(pt.Name())},
+			&common.KeyValue{Key: "category", Value: strValue(cat.String())},
+		)
+
+		var body *common.AnyValue
+		for _, kv := range pt.Fields() {
+			if kv.Key == "message" {
+				if msg, ok := kv.Raw().(string); ok {
+					body = strValue(msg)
+					continue
+				}
+			}
+
+			if v := anyValue(kv.Raw()); v != nil {
+				attrs = append(attrs, &common.KeyValue{Key: kv.Key, Value: v})
+			}
+		}
+
+		if body == nil { // no message field, use line-protocol as body
+			body = strValue(pt.LineProto())
+		}
+
+		records = append(records, &logs.LogRecord{
+			TimeUnixNano:         uint64(pt.Time().UnixNano()),
+			ObservedTimeUnixNano: uint64(pt.Time().UnixNano()),
+			SeverityText:         pt.GetTag("status"),
+			Body:                 body,
+			Attributes:           attrs,
+		})
+	}
+
+	return &collogs.ExportLogsServiceRequest{
+		ResourceLogs: []*logs.ResourceLogs{
+			{
+				ScopeLogs: []*logs.ScopeLogs{
+					{Scope: s.scope, LogRecords: records},
+				},
+			},
+		},
+	}
+}
+
+func (s *otlpSink) Close() error {
+	return nil
+}
+
+func tagAttributes(pt *point.Point) (attrs []*common.KeyValue) {
+	for _, kv := range pt.Tags() {
+		attrs = append(attrs, &common.KeyValue{Key: kv.Key, Value: strValue(kv.GetS())})
+	}
+
+	return
+}
+
+func strValue(s string) *common.AnyValue {
+	return &common.AnyValue{Value: &common.AnyValue_StringValue{StringValue: s}}
+}
+
+func anyValue(x any) *common.AnyValue {
+	switch v := x.(type) {
+	case int64:
+		return &common.AnyValue{Value: &common.AnyValue_IntValue{IntValue: v}}
+	case uint64:
+		return &common.AnyValue{Value: &common.AnyValue_IntValue{IntValue: int64(v)}}
+	case float64:
+		return &common.AnyValue{Value: &common.AnyValue_DoubleValue{DoubleValue: v}}
+	case bool:
+		return &common.AnyValue{Value: &common.AnyValue_BoolValue{BoolValue: v}}
+	case string:
+		return strValue(v)
+	case []byte:
+		return &common.AnyValue{Value: &common.AnyValue_BytesValue{BytesValue: v}}
+	default:
+		return nil
+	}
+}
+
+// nolint:gochecknoinits
+func init() {
+	Add(KindOTLP, newOTLPSink)
+}
@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package sink
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// promRWSink send metric points via Prometheus remote-write protocol. Each
+// numeric field of the point is a time series named as <measurement>_<field>,
+// and tags of the point are labels of the time series.
+//
+// Only metric category accepted, points of other categories are ignored.
+type promRWSink struct {
+	*httpPoster
+	url string
+}
+
+func newPromRWSink(c *Conf) (Sink, error) {
+	p, err := newHTTPPoster(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &promRWSink{
+		httpPoster: p,
+		url:        c.URL,
+	}, nil
+}
+
+func (s *promRWSink) Send(cat point.Category, pts []*point.Point) error {
+	switch cat { // nolint:exhaustive
+	case point.Metric, point.MetricDeprecated:
+	default:
+		l.Debugf("category %s not supported by prometheus remote-write, %d points ignored", cat, len(pts))
+		return nil
+	}
+
+	req := writeRequest(pts)
+	if len(req) == 0 {
+		return nil
+	}
+
+	return s.post(s.url, snappy.Encode(nil, req), map[string]string{
+		"Content-Type":                      "application/x-protobuf",
+		"Content-Encoding":                  "snappy",
+		"X-Prometheus-Remote-Write-Version": "0.1.0",
+	})
+}
+
+func (s *promRWSink) Close() error {
+	return nil
+}
+
+type promLabel struct {
+	name, value string
+}
+
+// writeRequest encode points into protobuf of prometheus.WriteRequest:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label        { string name = 1; string value = 2; }
+//	message Sample       { double value = 1; int64 timestamp = 2; }
+func writeRequest(pts []*point.Point) (req []byte) {
+	for _, pt := range pts {
+		var labels []promLabel
+		for _, kv := range pt.Tags() {
+			labels = append(labels, promLabel{name: promName(kv.Key), value: kv.GetS()})
+		}
+
+		ts := pt.Time().UnixMilli()
+
+		for _, kv := range pt.Fields() {
+			var v float64
+			switch x := kv.Raw().(type) {
+			case int64:
+				v = float64(x)
+			case uint64:
+				v = float64(x)
+			case float64:
+				v = x
+			case bool:
+				if x {
+					v = 1
+				}
+			default: // non-numeric fields ignored
+				continue
+			}
+
+			arr := append([]promLabel{{name: "__name__", value: promName(pt.Name() + "_" + kv.Key)}}, labels...)
+			sort.Slice(arr, func(i, j int) bool { return arr[i].name < arr[j].name })
+
+			var series []byte
+			for _, lb := range arr {
+				var b []byte
+				b = protowire.AppendTag(b, 1, protowire.BytesType)
+				b = protowire.AppendString(b, lb.name)
+				b = protowire.AppendTag(b, 2, protowire.BytesType)
+				b = protowire.AppendString(b, lb.value)
+
+				series = protowire.AppendTag(series, 1, protowire.BytesType)
+				series = protowire.AppendBytes(series, b)
+			}
+
+			var sample []byte
+			sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+			sample = protowire.AppendFixed64(sample, math.Float64bits(v))
+			sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+			sample = protowire.AppendVarint(sample, uint64(ts))
+
+			series = protowire.AppendTag(series, 2, protowire.BytesType)
+			series = protowire.AppendBytes(series, sample)
+
+			req = protowire.AppendTag(req, 1, protowire.BytesType)
+			req = protowire.AppendBytes(req, series)
+		}
+	}
+
+	return req
+}
+
+// promName replace chars that not allowed in prometheus metric/label name with _.
+func promName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// nolint:gochecknoinits
+func init() {
+	Add(KindPromRW, newPromRWSink)
+}
@@ -0,0 +1,312 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+// Package sink implements outputs of points besides Dataway.
+package sink
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/logger"
+	"github.com/GuanceCloud/cliutils/point"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+)
+
+const (
+	KindFile   = "file"
+	KindKafka  = "kafka"
+	KindOTLP   = "otlp"
+	KindPromRW = "prometheus_remote_write"
+)
+
+var (
+	l = logger.DefaultSLogger("sink")
+
+	creators = map[string]Creator{}
+)
+
+// Sink is the output backend of points.
+type Sink interface {
+	// Send send points of category to the backend. If error returned,
+	// the points will be retried later.
+	Send(cat point.Category, pts []*point.Point) error
+
+	Close() error
+}
+
+// Creator create a Sink from the configure.
+type Creator func(c *Conf) (Sink, error)
+
+// Add register a sink creator of kind.
+func Add(kind string, c Creator) {
+	if _, ok := creators[kind]; ok {
+		panic(fmt.Sprintf("sink %q exist", kind))
+	}
+
+	creators[kind] = c
+}
+
+// Conf configure a sink in datakit.conf. Not all fields are used
+// by each kind of sink.
+type Conf struct {
+	Name       string   `toml:"name"`
+	Kind       string   `toml:"kind"`
+	Categories []string `toml:"categories"` // empty for all categories
+
+	// for file sink
+	Path       string `toml:"path,omitempty"`
+	Encoding   string `toml:"encoding,omitempty"` // line-protocol or pbjson
+	MaxSizeMB  int    `toml:"max_size_mb,omitempty"`
+	MaxBackups int    `toml:"max_backups,omitempty"`
+
+	// for kafka sink
+	Brokers []string `toml:"brokers,omitempty"`
+	Topic   string   `toml:"topic,omitempty"`
+
+	// for HTTP based sink(OTLP and Prometheus remote-write)
+	URL     string            `toml:"url,omitempty"`
+	Headers map[string]string `toml:"headers,omitempty"`
+	Timeout time.Duration     `toml:"timeout,omitempty"`
+
+	// WAL on each category
+	WALCapacityMB int `toml:"wal_capacity_mb,omitempty"`
+	WALWorkers    int `toml:"wal_workers,omitempty"`
+
+	cats map[point.Category]bool
+}
+
+func (c *Conf) setup() error {
+	if c.Name == "" {
+		c.Name = c.Kind
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = 30 * time.Second
+	}
+
+	if c.WALCapacityMB <= 0 {
+		c.WALCapacityMB = 1024
+	}
+
+	if c.WALWorkers <= 0 {
+		c.WALWorkers = 1
+	}
+
+	c.cats = map[point.Category]bool{}
+	for _, x := range c.Categories {
+		cat := point.CatString(x)
+		if cat == point.UnknownCategory {
+			cat = point.CatAlias(x)
+		}
+
+		if cat == point.UnknownCategory {
+			return fmt.Errorf("invalid category %q on sink %q", x, c.Name)
+		}
+
+		c.cats[cat] = true
+	}
+
+	return nil
+}
+
+func (c *Conf) categoryOK(cat point.Category) bool {
+	if len(c.cats) == 0 {
+		return true
+	}
+
+	return c.cats[cat]
+}
+
+type sinkInstance struct {
+	conf *Conf
+	sink Sink
+	walq map[point.Category]*walQueue
+}
+
+// Sinker manage all configured sinks.
+type Sinker struct {
+	instances []*sinkInstance
+	walPath   string
+	wg        sync.WaitGroup
+}
+
+// SinkerOption used to setup Sinker.
+type SinkerOption func(s *Sinker)
+
+// WithWALPath set the root path of all sinks' WAL.
+func WithWALPath(p string) SinkerOption {
+	return func(s *Sinker) {
+		if p != "" {
+			s.walPath = p
+		}
+	}
+}
+
+// NewSinker create sinks according to the configures.
+func NewSinker(confs []*Conf, opts ...SinkerOption) (*Sinker, error) {
+	l = logger.SLogger("sink")
+
+	s := &Sinker{
+		walPath: filepath.Join(datakit.CacheDir, "sink-wal"),
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+
+	names := map[string]bool{}
+
+	for _, c := range confs {
+		if err := c.setup(); err != nil {
+			return nil, err
+		}
+
+		if names[c.Name] {
+			return nil, fmt.Errorf("duplicated sink name %q", c.Name)
+		}
+		names[c.Name] = true
+
+		creator, ok := creators[c.Kind]
+		if !ok {
+			return nil, fmt.Errorf("sink kind %q not supported, available kinds: %v", c.Kind, Kinds())
+		}
+
+		sk, err := creator(c)
+		if err != nil {
+			return nil, fmt.Errorf("create sink %q: %w", c.Name, err)
+		}
+
+		si := &sinkInstance{
+			conf: c,
+			sink: sk,
+			walq: map[point.Category]*walQueue{},
+		}
+
+		for _, cat := range point.AllCategories() {
+			if !c.categoryOK(cat) {
+				continue
+			}
+
+			q, err := openWAL(filepath.Join(s.walPath, c.Name, cat.String()), int64(c.WALCapacityMB)*(1<<20))
+			if err != nil {
+				return nil, fmt.Errorf("open WAL for sink %q on %s: %w", c.Name, cat, err)
+			}
+
+			si.walq[cat] = q
+		}
+
+		l.Infof("add sink %q(%s) on %d categories", c.Name, c.Kind, len(si.walq))
+		s.instances = append(s.instances, si)
+	}
+
+	return s, nil
+}
+
+// Kinds list all available sink kinds.
+func Kinds() (arr []string) {
+	for k := range creators {
+		arr = append(arr, k)
+	}
+
+	sort.Strings(arr)
+	return
+}
+
+// Write queue points to WAL of all sinks that accept the category.
+//
+// Points are encoded into WAL before Write() return, so caller is
+// free to reuse the points after Write().
+func (s *Sinker) Write(cat point.Category, pts []*point.Point) {
+	if s == nil || len(pts) == 0 {
+		return
+	}
+
+	var (
+		data   [][]byte
+		encErr error
+	)
+
+	for _, si := range s.instances {
+		q, ok := si.walq[cat]
+		if !ok {
+			continue
+		}
+
+		if data == nil && encErr == nil {
+			enc := point.GetEncoder(point.WithEncEncoding(point.Protobuf))
+			data, encErr = enc.Encode(pts)
+			point.PutEncoder(enc)
+
+			if encErr == nil && len(data) == 0 {
+				encErr = fmt.Errorf("no data encoded")
+			}
+		}
+
+		if encErr != nil {
+			l.Warnf("encode %d points on %s for sink %q failed: %s, ignored", len(pts), cat, si.conf.Name, encErr)
+			sinkPointVec.WithLabelValues(si.conf.Name, cat.String(), "drop").Add(float64(len(pts)))
+			continue
+		}
+
+		for _, x := range data {
+			if err := q.put(x); err != nil {
+				l.Warnf("put %d points to WAL of sink %q failed: %s", len(pts), si.conf.Name, err)
+				sinkPointVec.WithLabelValues(si.conf.Name, cat.String(), "drop").Add(float64(len(pts)))
+				break
+			}
+		}
+	}
+}
+
+// Start start WAL flush workers on all sinks.
+func (s *Sinker) Start() {
+	if s == nil {
+		return
+	}
+
+	for _, si := range s.instances {
+		g := datakit.G("io/sink/" + si.conf.Name)
+		for cat, q := range si.walq {
+			for i := 0; i < si.conf.WALWorkers; i++ {
+				f := &flusher{cat: cat, q: q, si: si}
+				s.wg.Add(1)
+				g.Go(func(_ context.Context) error {
+					defer s.wg.Done()
+					f.run()
+					return nil
+				})
+			}
+		}
+	}
+}
+
+// Close wait all flush workers exit and close all sinks.
+func (s *Sinker) Close() {
+	if s == nil {
+		return
+	}
+
+	s.wg.Wait()
+
+	for _, si := range s.instances {
+		if err := si.sink.Close(); err != nil {
+			l.Warnf("close sink %q: %s, ignored", si.conf.Name, err)
+		}
+
+		for _, q := range si.walq {
+			if err := q.close(); err != nil {
+				l.Warnf("close WAL of sink %q: %s, ignored", si.conf.Name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package sink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	T "testing"
+
+	"github.com/GuanceCloud/cliutils/diskcache"
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSink struct {
+	fail bool
+	pts  map[point.Category][]*point.Point
+}
+
+func (s *mockSink) Send(cat point.Category, pts []*point.Point) error {
+	if s.fail {
+		return fmt.Errorf("mocked send failed")
+	}
+
+	s.pts[cat] = append(s.pts[cat], pts...)
+	return nil
+}
+
+func (s *mockSink) Close() error { return nil }
+
+func flushOnce(t *T.T, si *sinkInstance, cat point.Category) error {
+	t.Helper()
+
+	q := si.walq[cat]
+	require.NoError(t, q.dc.Rotate()) // force rotate to make data readable
+
+	f := &flusher{cat: cat, q: q, si: si}
+	return q.get(f.send)
+}
+
+func TestSinker(t *T.T) {
+	ms := &mockSink{pts: map[point.Category][]*point.Point{}}
+	Add("mock", func(*Conf) (Sink, error) { return ms, nil })
+	t.Cleanup(func() {
+		delete(creators, "mock")
+		metricsReset()
+	})
+
+	t.Run("category-filter", func(t *T.T) {
+		s, err := NewSinker([]*Conf{
+			{Kind: "mock", Categories: []string{"L", "metric"}},
+		}, WithWALPath(t.TempDir()))
+		require.NoError(t, err)
+		defer s.Close()
+
+		require.Len(t, s.instances, 1)
+		si := s.instances[0]
+		assert.Len(t, si.walq, 2)
+		assert.NotNil(t, si.walq[point.Logging])
+		assert.NotNil(t, si.walq[point.Metric])
+	})
+
+	t.Run("invalid-conf", func(t *T.T) {
+		_, err := NewSinker([]*Conf{{Kind: "mock", Categories: []string{"no-such-category"}}}, WithWALPath(t.TempDir()))
+		assert.Error(t, err)
+
+		_, err = NewSinker([]*Conf{{Kind: "no-such-kind"}}, WithWALPath(t.TempDir()))
+		assert.Error(t, err)
+
+		_, err = NewSinker([]*Conf{{Kind: "mock"}, {Kind: "mock"}}, WithWALPath(t.TempDir()))
+		assert.Error(t, err, "duplicated name")
+	})
+
+	t.Run("retry-on-fail", func(t *T.T) {
+		s, err := NewSinker([]*Conf{{Kind: "mock", Categories: []string{"L"}}}, WithWALPath(t.TempDir()))
+		require.NoError(t, err)
+		defer s.Close()
+
+		pts := point.RandPoints(10)
+		s.Write(point.Logging, pts)
+		s.Write(point.Metric, pts) // not accepted by the sink
+
+		si := s.instances[0]
+
+		ms.fail = true
+		assert.Error(t, flushOnce(t, si, point.Logging))
+		assert.Len(t, ms.pts[point.Logging], 0)
+
+		// data still in WAL and send ok
+		ms.fail = false
+		assert.NoError(t, flushOnce(t, si, point.Logging))
+		require.Len(t, ms.pts[point.Logging], len(pts))
+		for i := range pts {
+			assert.Equal(t, pts[i].LineProto(), ms.pts[point.Logging][i].LineProto())
+		}
+
+		assert.ErrorIs(t, flushOnce(t, si, point.Logging), diskcache.ErrNoData)
+	})
+
+	t.Run("continue-on-put-fail", func(t *T.T) {
+		ms.pts = map[point.Category][]*point.Point{}
+
+		s, err := NewSinker([]*Conf{
+			{Name: "a", Kind: "mock", Categories: []string{"L"}},
+			{Name: "b", Kind: "mock", Categories: []string{"L"}},
+		}, WithWALPath(t.TempDir()))
+		require.NoError(t, err)
+		defer s.Close()
+
+		// WAL of the 1st sink broken
+		require.NoError(t, s.instances[0].walq[point.Logging].close())
+
+		pts := point.RandPoints(10)
+		s.Write(point.Logging, pts)
+
+		assert.NoError(t, flushOnce(t, s.instances[1], point.Logging))
+		assert.Len(t, ms.pts[point.Logging], len(pts))
+	})
+}
+
+func TestFileSink(t *T.T) {
+	t.Cleanup(metricsReset)
+
+	cases := []struct {
+		enc, ext string
+	}{
+		{"line-protocol", extLineProtocol},
+		{"pbjson", extPBJson},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.enc, func(t *T.T) {
+			dir := t.TempDir()
+
+			s, err := NewSinker([]*Conf{
+				{Kind: KindFile, Path: dir, Encoding: tc.enc},
+			}, WithWALPath(t.TempDir()))
+			require.NoError(t, err)
+			defer s.Close()
+
+			pts := point.RandPoints(3)
+			s.Write(point.Logging, pts)
+			require.NoError(t, flushOnce(t, s.instances[0], point.Logging))
+
+			data, err := os.ReadFile(filepath.Join(dir, point.Logging.String()+tc.ext))
+			require.NoError(t, err)
+
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			assert.Len(t, lines, len(pts))
+		})
+	}
+
+	t.Run("invalid-encoding", func(t *T.T) {
+		_, err := newFileSink(&Conf{Path: t.TempDir(), Encoding: "json"})
+		assert.Error(t, err)
+	})
+}
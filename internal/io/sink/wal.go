@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package sink
+
+import (
+	"errors"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/diskcache"
+	"github.com/GuanceCloud/cliutils/point"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+)
+
+var (
+	defaultRotateAt   = 3 * time.Second
+	defaultRetryDelay = time.Second
+)
+
+// walQueue is the disk queue for points sending to sink. All points
+// are encoded in protobuf before put to the queue.
+type walQueue struct {
+	dc *diskcache.DiskCache
+}
+
+func openWAL(path string, capacity int64) (*walQueue, error) {
+	dc, err := diskcache.Open(
+		diskcache.WithPath(path),
+		diskcache.WithCapacity(capacity),
+		diskcache.WithNoLock(true),
+		diskcache.WithFILODrop(true), // drop new data if WAL full
+		diskcache.WithWakeup(defaultRotateAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &walQueue{dc: dc}, nil
+}
+
+func (q *walQueue) put(data []byte) error {
+	return q.dc.Put(data)
+}
+
+// get read next payload from WAL, if fn failed, the payload will
+// rollback and get again next time.
+func (q *walQueue) get(fn func([]byte) error) error {
+	return q.dc.Get(func(x []byte) error {
+		if len(x) == 0 {
+			return nil
+		}
+
+		return fn(x)
+	})
+}
+
+func (q *walQueue) close() error {
+	return q.dc.Close()
+}
+
+type flusher struct {
+	cat point.Category
+	q   *walQueue
+	si  *sinkInstance
+}
+
+func (f *flusher) run() {
+	name := f.si.conf.Name
+	l.Infof("sink flusher on %s/%s starting...", name, f.cat.Alias())
+
+	for {
+		select {
+		case <-datakit.Exit.Wait():
+			l.Infof("sink flusher on %s/%s exit", name, f.cat.Alias())
+			return
+		default:
+		}
+
+		err := f.q.get(f.send)
+
+		switch {
+		case err == nil: // pass
+		case errors.Is(err, diskcache.ErrNoData):
+			time.Sleep(time.Second) // sleep when there is nothing to flush.
+		default:
+			l.Warnf("sink %q send on %s: %s, retry later", name, f.cat.Alias(), err)
+			time.Sleep(defaultRetryDelay)
+		}
+
+		sinkWALSizeVec.WithLabelValues(name, f.cat.String()).Set(float64(f.q.dc.Size()))
+	}
+}
+
+func (f *flusher) send(data []byte) error {
+	dec := point.GetDecoder(point.WithDecEncoding(point.Protobuf))
+	defer point.PutDecoder(dec)
+
+	pts, err := dec.Decode(data)
+	if err != nil {
+		l.Warnf("decode %d bytes from WAL failed: %s, dropped", len(data), err)
+		return nil // drop bad data
+	}
+
+	var (
+		name  = f.si.conf.Name
+		start = time.Now()
+	)
+
+	if err := f.si.sink.Send(f.cat, pts); err != nil {
+		sinkPointVec.WithLabelValues(name, f.cat.String(), "fail").Add(float64(len(pts)))
+		return err
+	}
+
+	sinkPointVec.WithLabelValues(name, f.cat.String(), "ok").Add(float64(len(pts)))
+	sinkSendCostVec.WithLabelValues(name, f.cat.String()).Observe(float64(time.Since(start)) / float64(time.Second))
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/filter"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io/sink"
 )
 
 // IOConf configure io module in datakit.conf.
@@ -24,4 +25,6 @@ type IOConf struct {
 	AutoTimestampCorrection bool          `toml:"auto_timestamp_correction"`
 
 	Filters map[string]filter.FilterConditions `toml:"filters"`
+
+	Sinks []*sink.Conf `toml:"sinks,omitempty"`
 }
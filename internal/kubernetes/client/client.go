@@ -13,9 +13,11 @@ import (
 
 	"k8s.io/client-go/kubernetes"
 	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	autoscalingv2 "k8s.io/client-go/kubernetes/typed/autoscaling/v2"
 	batchv1 "k8s.io/client-go/kubernetes/typed/batch/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
-	extensionsv1beta1 "k8s.io/client-go/kubernetes/typed/extensions/v1beta1"
+	networkingv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	policyv1 "k8s.io/client-go/kubernetes/typed/policy/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/flowcontrol"
 	statsv1alpha1 "k8s.io/kubelet/pkg/apis/stats/v1alpha1"
@@ -46,10 +48,14 @@ type Client interface {
 	GetEndpoints(ns string) corev1.EndpointsInterface
 	GetServices(ns string) corev1.ServiceInterface
 	GetPods(ns string) corev1.PodInterface
-	GetIngress(ns string) extensionsv1beta1.IngressInterface
+	GetIngress(ns string) networkingv1.IngressInterface
 	GetEvents(ns string) corev1.EventInterface
 	GetPersistentVolumes() corev1.PersistentVolumeInterface
 	GetPersistentVolumeClaims(ns string) corev1.PersistentVolumeClaimInterface
+	GetHorizontalPodAutoscalers(ns string) autoscalingv2.HorizontalPodAutoscalerInterface
+	GetResourceQuotas(ns string) corev1.ResourceQuotaInterface
+	GetLimitRanges(ns string) corev1.LimitRangeInterface
+	GetPodDisruptionBudgets(ns string) policyv1.PodDisruptionBudgetInterface
 
 	// CRDs
 	GetLoggingConfigs() loggingv1alpha1.ClusterLoggingConfigInterface
@@ -186,8 +192,8 @@ func (c *client) GetPods(ns string) corev1.PodInterface {
 	return c.clientset.CoreV1().Pods(ns)
 }
 
-func (c *client) GetIngress(ns string) extensionsv1beta1.IngressInterface {
-	return c.clientset.ExtensionsV1beta1().Ingresses(ns)
+func (c *client) GetIngress(ns string) networkingv1.IngressInterface {
+	return c.clientset.NetworkingV1().Ingresses(ns)
 }
 
 func (c *client) GetEvents(ns string) corev1.EventInterface {
@@ -202,6 +208,22 @@ func (c *client) GetPersistentVolumeClaims(ns string) corev1.PersistentVolumeCla
 	return c.clientset.CoreV1().PersistentVolumeClaims(ns)
 }
 
+func (c *client) GetHorizontalPodAutoscalers(ns string) autoscalingv2.HorizontalPodAutoscalerInterface {
+	return c.clientset.AutoscalingV2().HorizontalPodAutoscalers(ns)
+}
+
+func (c *client) GetResourceQuotas(ns string) corev1.ResourceQuotaInterface {
+	return c.clientset.CoreV1().ResourceQuotas(ns)
+}
+
+func (c *client) GetLimitRanges(ns string) corev1.LimitRangeInterface {
+	return c.clientset.CoreV1().LimitRanges(ns)
+}
+
+func (c *client) GetPodDisruptionBudgets(ns string) policyv1.PodDisruptionBudgetInterface {
+	return c.clientset.PolicyV1().PodDisruptionBudgets(ns)
+}
+
 /// CRDSs
 
 func (c *client) GetLoggingConfigs() loggingv1alpha1.ClusterLoggingConfigInterface {
@@ -35,26 +35,46 @@ func NewInotify(patterns []string) (*Inotify, error) {
 		dirs = append(dirs, dir)
 	}
 
+	return NewDirInotify(dirs, fsnotify.Create)
+}
+
+// NewDirInotify watches the directories for the specified ops, the directories
+// are not pattern and not watched recursively.
+func NewDirInotify(dirs []string, ops fsnotify.Op) (*Inotify, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("unable create inotfiy, err: %w", err)
 	}
 
+	in := &Inotify{watcher}
 	for _, dir := range dirs {
-		err := watcher.AddWith(dir, fsnotify.WithOps(fsnotify.Create))
-		if err != nil {
+		if err := in.Add(dir, ops); err != nil {
 			_ = watcher.Close()
-			return nil, fmt.Errorf("failed to add watcher %s, err: %w", dir, err)
+			return nil, err
 		}
 	}
 
-	return &Inotify{watcher}, nil
+	return in, nil
+}
+
+// Add watches the directory for the specified ops.
+func (in *Inotify) Add(dir string, ops fsnotify.Op) error {
+	if err := in.watcher.AddWith(dir, fsnotify.WithOps(ops)); err != nil {
+		return fmt.Errorf("failed to add watcher %s, err: %w", dir, err)
+	}
+	return nil
 }
 
 func (in *Inotify) Events() chan fsnotify.Event {
 	return in.watcher.Events
 }
 
+// Errors returns the errors of the inotify(such as event queue overflow). The
+// errors are sent unbuffered, the event delivery blocks until the error is read.
+func (in *Inotify) Errors() chan error {
+	return in.watcher.Errors
+}
+
 func (in *Inotify) Close() error {
 	return in.watcher.Close()
 }
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package openfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type CompressType string
+
+const (
+	CompressNone CompressType = ""
+	CompressGzip CompressType = "gzip"
+	CompressZstd CompressType = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// DetectCompress detect compress type of the file by its magic number, the file offset is not changed.
+func DetectCompress(file *os.File) (CompressType, error) {
+	head := make([]byte, len(zstdMagic))
+
+	n, err := file.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return CompressNone, err
+	}
+
+	switch head = head[:n]; {
+	case bytes.HasPrefix(head, gzipMagic):
+		return CompressGzip, nil
+	case bytes.HasPrefix(head, zstdMagic):
+		return CompressZstd, nil
+	default:
+		return CompressNone, nil
+	}
+}
+
+// NewDecompressReader wrap rd with decompressor of typ, the returned reader must be closed.
+func NewDecompressReader(rd io.Reader, typ CompressType) (io.ReadCloser, error) {
+	switch typ {
+	case CompressGzip:
+		return gzip.NewReader(rd)
+	case CompressZstd:
+		dec, err := zstd.NewReader(rd, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	case CompressNone:
+		return io.NopCloser(rd), nil
+	default:
+		return nil, fmt.Errorf("unknown compress type %q", typ)
+	}
+}
+
+// FileCompressType detect compress type of the file path.
+func FileCompressType(path string) (CompressType, error) {
+	f, err := OpenFile(path)
+	if err != nil {
+		return CompressNone, err
+	}
+	defer f.Close() //nolint:errcheck,gosec
+
+	return DetectCompress(f)
+}
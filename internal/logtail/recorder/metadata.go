@@ -10,15 +10,20 @@ import "fmt"
 type MetaData struct {
 	Source string `json:"source"`
 	Offset int64  `json:"offset"`
+
+	// Completed set when the file will never be read again, such as compressed
+	// files which have been read to the end.
+	Completed bool `json:"completed,omitempty"`
 }
 
 func (m *MetaData) String() string {
-	return fmt.Sprintf("source: %s, offset: %d", m.Source, m.Offset)
+	return fmt.Sprintf("source: %s, offset: %d, completed: %v", m.Source, m.Offset, m.Completed)
 }
 
 func (m *MetaData) DeepCopy() MetaData {
 	return MetaData{
-		Source: m.Source,
-		Offset: m.Offset,
+		Source:    m.Source,
+		Offset:    m.Offset,
+		Completed: m.Completed,
 	}
 }
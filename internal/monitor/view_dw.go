@@ -6,6 +6,9 @@
 package monitor
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -27,7 +30,7 @@ func (app *monitorAPP) renderDatawayTable(mfs map[string]*dto.MetricFamily, colA
 		app.dwTable.SetTitle("Data[red]W[white]ay Info(no data collected)")
 		return
 	} else {
-		app.dwTable.SetTitle("Data[red]W[white]ay APIs")
+		app.dwTable.SetTitle("Data[red]W[white]ay APIs" + breakerInfo(mfs["datakit_io_dataway_circuit_breaker_state"]))
 	}
 
 	// set table header
@@ -99,3 +102,35 @@ func (app *monitorAPP) renderDatawayTable(mfs map[string]*dto.MetricFamily, colA
 		row++
 	}
 }
+
+// breakerInfo show endpoints that circuit breaker not closed.
+func breakerInfo(mf *dto.MetricFamily) string {
+	if mf == nil {
+		return ""
+	}
+
+	var arr []string
+	for _, m := range mf.Metric {
+		var ep string
+		for _, lp := range m.GetLabel() {
+			if lp.GetName() == "endpoint" {
+				ep = lp.GetValue()
+			}
+		}
+
+		switch m.GetGauge().GetValue() {
+		case 1:
+			arr = append(arr, fmt.Sprintf("%s [red]open[white]", ep))
+		case 2:
+			arr = append(arr, fmt.Sprintf("%s [yellow]half-open[white]", ep))
+		default: // closed
+		}
+	}
+
+	if len(arr) == 0 {
+		return ""
+	}
+
+	sort.Strings(arr)
+	return fmt.Sprintf("(circuit breaker: %s)", strings.Join(arr, ", "))
+}
@@ -68,6 +68,34 @@ func (c *TLSClientConfig) TLSConfig() (*tls.Config, error) {
 	return tlsConfig, nil
 }
 
+// TLSServerConfig returns a tls.Config used by servers, the certificate and key are required.
+// If CA certificates configured, client certificates are required and verified with them.
+func (c *TLSClientConfig) TLSServerConfig() (*tls.Config, error) {
+	if c == nil || c.Cert == "" || c.CertKey == "" {
+		return nil, fmt.Errorf("cert and cert_key required for TLS server")
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if err := loadCertificate(tlsConfig, c.Cert, c.CertKey); err != nil {
+		return nil, err
+	}
+
+	if len(c.CaCerts) != 0 {
+		pool, err := makeCertPool(c.CaCerts)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
 func makeCertPool(certFiles []string) (*x509.CertPool, error) {
 	pool := x509.NewCertPool()
 	for _, certFile := range certFiles {
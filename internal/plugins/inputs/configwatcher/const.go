@@ -38,6 +38,26 @@ const (
   ## The maximum file size (in bytes) for which to compute content diffs, default is 256KiB.
   max_diff_size = 262144
 
+  ## File integrity monitoring(FIM) mode. Changes are detected on inotify events in real time,
+  ## permissions, ownership, extended attributes and SHA-256 digests are also compared, and the
+  ## changes are reported as security data. The interval check still works as a fallback.
+  # enable_fim = false
+
+  ## The file to persist the FIM baseline, so changes made while DataKit was down are reported
+  ## at startup. Default is configwatcher_<source>.baseline.json under DataKit cache directory.
+  # baseline_path = ""
+
+  ## Severity(info/warning/error/critical) of FIM changes. The path (without mount_point) is matched
+  ## by the glob pattern, or by the directory prefix if the pattern ends with "/". The first matched
+  ## rule is applied, default severity is info.
+  # [[inputs.configwatcher.severity_rules]]
+  #   pattern = "/etc/passwd"
+  #   severity = "critical"
+  #
+  # [[inputs.configwatcher.severity_rules]]
+  #   pattern = "/etc/ssh/"
+  #   severity = "error"
+
   [inputs.configwatcher.tags]
   # some_tag = "some_value"
   # more_tag = "some_other_value"
@@ -0,0 +1,437 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package configwatcher
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/fsnotify/fsnotify"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+)
+
+const (
+	fimMeasurementName = "file_integrity"
+	fimCategory        = "system"
+
+	// inotify events within the interval are merged into one check.
+	fimBatchInterval = time.Second
+
+	fimInotifyOps = fsnotify.Create | fsnotify.Write | fsnotify.Remove | fsnotify.Rename | fsnotify.Chmod
+
+	severityInfo     = "info"
+	severityWarning  = "warning"
+	severityError    = "error"
+	severityCritical = "critical"
+)
+
+var baselineNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_\-]`)
+
+type severityRule struct {
+	// Pattern is a glob of filepath.Match, or a directory ends with `/` which
+	// matches all files under it.
+	Pattern  string `toml:"pattern"`
+	Severity string `toml:"severity"`
+}
+
+func (r *severityRule) match(path string) bool {
+	if strings.HasSuffix(r.Pattern, "/") {
+		return strings.HasPrefix(path, r.Pattern)
+	}
+	ok, _ := filepath.Match(r.Pattern, path)
+	return ok
+}
+
+func checkSeverityRules(rules []*severityRule) error {
+	for _, r := range rules {
+		switch r.Severity {
+		case severityInfo, severityWarning, severityError, severityCritical:
+		default:
+			return fmt.Errorf("invalid severity %q of pattern %q, should be one of info/warning/error/critical", r.Severity, r.Pattern)
+		}
+
+		if _, err := filepath.Match(r.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// severityOf returns the severity of the first matched rule, default is info.
+func severityOf(rules []*severityRule, path string) string {
+	for _, r := range rules {
+		if r.match(path) {
+			return r.Severity
+		}
+	}
+	return severityInfo
+}
+
+// checkIntegrity compares the SHA-256 digest and attributes of the file. The
+// ModTime is not used as it can be reset by `touch -r`.
+func (w *fileWatcher) checkIntegrity(last, cur *fileState) (changeEvent, bool) {
+	event := changeEvent{
+		path:        cur.path,
+		oldState:    last,
+		newState:    cur,
+		attrChanges: diffAttrs(last, cur),
+	}
+
+	contentChanged := last.size != cur.size
+	if last.sha256 != "" && cur.sha256 != "" {
+		contentChanged = contentChanged || last.sha256 != cur.sha256
+	} else {
+		// digest unavailable(e.g. permission denied)
+		contentChanged = contentChanged || !last.modTime.Equal(cur.modTime)
+	}
+
+	switch {
+	case contentChanged:
+		event.typ = modified
+		// content of the baseline loaded from disk is not available
+		if last.content != nil && cur.content != nil {
+			event.diff = w.generateDiff(last.content, cur.content)
+		}
+	case len(event.attrChanges) > 0:
+		event.typ = attrModified
+	default:
+		return event, false
+	}
+
+	return event, true
+}
+
+func diffAttrs(last, cur *fileState) (res []string) {
+	if last.mode != cur.mode {
+		res = append(res, fmt.Sprintf("mode: %s -> %s", last.mode, cur.mode))
+	}
+	if last.uid != cur.uid {
+		res = append(res, fmt.Sprintf("uid: %d -> %d", last.uid, cur.uid))
+	}
+	if last.gid != cur.gid {
+		res = append(res, fmt.Sprintf("gid: %d -> %d", last.gid, cur.gid))
+	}
+
+	var keys []string
+	for k := range last.xattrs {
+		keys = append(keys, k)
+	}
+	for k := range cur.xattrs {
+		if _, ok := last.xattrs[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	xattrValue := func(m map[string]string, k string) string {
+		if v, ok := m[k]; ok {
+			return fmt.Sprintf("%q", v)
+		}
+		return "<none>"
+	}
+
+	for _, k := range keys {
+		oldVal, newVal := xattrValue(last.xattrs, k), xattrValue(cur.xattrs, k)
+		if oldVal != newVal {
+			res = append(res, fmt.Sprintf("xattr %s: %s -> %s", k, oldVal, newVal))
+		}
+	}
+
+	return res
+}
+
+// formatXattrValue returns the printable value as is, others are hex encoded.
+func formatXattrValue(val []byte) string {
+	val = []byte(strings.TrimRight(string(val), "\x00"))
+
+	if utf8.Valid(val) && strings.IndexFunc(string(val), func(r rune) bool { return !unicode.IsPrint(r) }) == -1 {
+		return string(val)
+	}
+	return "0x" + hex.EncodeToString(val)
+}
+
+// baseline is the file states persisted on disk, so changes made while
+// DataKit was down can be reported at startup. File content is not persisted
+// for it may be sensitive(such as /etc/shadow).
+type baseline struct {
+	Paths []string                 `json:"paths"` // watched paths
+	Files map[string]*baselineFile `json:"files"`
+}
+
+type baselineFile struct {
+	Size    int64             `json:"size"`
+	ModTime time.Time         `json:"mod_time"`
+	Mode    os.FileMode       `json:"mode"`
+	UID     uint32            `json:"uid"`
+	GID     uint32            `json:"gid"`
+	Xattrs  map[string]string `json:"xattrs,omitempty"`
+	SHA256  string            `json:"sha256"`
+}
+
+func defaultBaselinePath(source string) string {
+	return datakit.JoinToCacheDir(fmt.Sprintf("%s_%s.baseline.json",
+		inputName, baselineNameRegexp.ReplaceAllString(source, "_")))
+}
+
+func loadBaseline(path string) (*baseline, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var b baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("unmarshal baseline %s: %w", path, err)
+	}
+	return &b, nil
+}
+
+func saveBaseline(path string, b *baseline) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	// write to a temporary file then rename, avoid a broken baseline on crash
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (w *fileWatcher) owns(path string) bool {
+	if path == w.path {
+		return true
+	}
+
+	if !strings.HasPrefix(path, w.path+string(filepath.Separator)) {
+		return false
+	}
+
+	return w.recursive || filepath.Dir(path) == w.path
+}
+
+// dump appends the current states of the watcher to the baseline.
+func (w *fileWatcher) dump(b *baseline) {
+	b.Paths = append(b.Paths, w.path)
+	for path, st := range w.lastStates {
+		b.Files[path] = &baselineFile{
+			Size:    st.size,
+			ModTime: st.modTime,
+			Mode:    st.mode,
+			UID:     st.uid,
+			GID:     st.gid,
+			Xattrs:  st.xattrs,
+			SHA256:  st.sha256,
+		}
+	}
+}
+
+// restore replaces the last states with the baseline, it returns false if the
+// path is not watched in the baseline, e.g. the path is newly configured.
+func (w *fileWatcher) restore(b *baseline) bool {
+	found := false
+	for _, p := range b.Paths {
+		if p == w.path {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	w.lastStates = make(map[string]*fileState)
+	for path, f := range b.Files {
+		if !w.owns(path) {
+			continue
+		}
+
+		w.lastStates[path] = &fileState{
+			path:    path,
+			exists:  true,
+			size:    f.Size,
+			modTime: f.ModTime,
+			mode:    f.Mode,
+			uid:     f.UID,
+			gid:     f.GID,
+			xattrs:  f.Xattrs,
+			sha256:  f.SHA256,
+		}
+	}
+
+	return true
+}
+
+// watchDirs returns the directories to be watched by inotify. For a file or a
+// nonexistent path, its parent directory is watched to catch creation and
+// replacement(such as `sed -i` or `vim` which rename a new file to it).
+func (w *fileWatcher) watchDirs() []string {
+	info, err := os.Stat(w.path)
+	if err != nil || !info.IsDir() {
+		if _, err := os.Stat(filepath.Dir(w.path)); err == nil {
+			return []string{filepath.Dir(w.path)}
+		}
+		return nil
+	}
+
+	return w.subDirs(w.path)
+}
+
+func (w *fileWatcher) subDirs(root string) (dirs []string) {
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error { //nolint:errcheck,gosec
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+
+		dirs = append(dirs, path)
+		if path != w.path && !w.recursive {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	return dirs
+}
+
+func generateFIMMessage(path string, event *changeEvent) string {
+	var sb strings.Builder
+
+	// nolint:exhaustive
+	switch event.typ {
+	case created:
+		fmt.Fprintf(&sb, "file %s was created", path)
+	case modified:
+		fmt.Fprintf(&sb, "file %s was modified", path)
+	case deleted:
+		fmt.Fprintf(&sb, "file %s was deleted", path)
+	case attrModified:
+		fmt.Fprintf(&sb, "attributes of file %s were modified", path)
+	default:
+		return "Unexpected Type"
+	}
+
+	if event.oldState != nil && event.newState != nil && event.oldState.sha256 != event.newState.sha256 {
+		fmt.Fprintf(&sb, "\nsha256: %s -> %s", valueOrNone(event.oldState.sha256), valueOrNone(event.newState.sha256))
+	}
+
+	if len(event.attrChanges) > 0 {
+		sb.WriteString("\nattribute changes:")
+		for _, c := range event.attrChanges {
+			sb.WriteString("\n- " + c)
+		}
+	}
+
+	if event.diff != "" {
+		sb.WriteString("\nchange details:\n" + event.diff)
+	}
+
+	return sb.String()
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// hostPath strips the mount point from the path.
+func (ipt *Input) hostPath(path string) string {
+	if ipt.MountPoint == "" {
+		return path
+	}
+
+	rel, err := filepath.Rel(ipt.MountPoint, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.Join("/", rel)
+}
+
+func (ipt *Input) buildSecurityPoint(event *changeEvent, t time.Time) *point.Point {
+	path := ipt.hostPath(event.path)
+
+	var kvs point.KVs
+	kvs = kvs.AddTag("title", fmt.Sprintf("%s %s in %s", ipt.Source, event.typ.String(), path))
+	kvs = kvs.AddTag("category", fimCategory)
+	kvs = kvs.AddTag("status", severityOf(ipt.SeverityRules, path))
+	kvs = kvs.AddTag("source", ipt.Source)
+	kvs = kvs.AddTag("path", path)
+	kvs = kvs.AddTag("change_type", event.typ.String())
+	kvs = append(kvs, point.NewTags(ipt.mergedTags)...)
+
+	kvs = kvs.Add("message", generateFIMMessage(path, event))
+	if event.diff != "" {
+		kvs = kvs.Add("diff", event.diff)
+	}
+
+	for prefix, st := range map[string]*fileState{"old_": event.oldState, "new_": event.newState} {
+		if st == nil || !st.exists {
+			continue
+		}
+
+		kvs = kvs.Add(prefix+"sha256", st.sha256)
+		kvs = kvs.Add(prefix+"mode", st.mode.String())
+		kvs = kvs.Add(prefix+"uid", int64(st.uid))
+		kvs = kvs.Add(prefix+"gid", int64(st.gid))
+	}
+
+	return point.NewPoint(fimMeasurementName, kvs, point.WithTimestamp(t.UnixNano()))
+}
+
+type fimMeasurement struct{}
+
+//nolint:lll
+func (*fimMeasurement) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name:   fimMeasurementName,
+		Desc:   "In FIM mode(`enable_fim = true`), changes of file content, permission, ownership and extended attributes are reported as security data.",
+		DescZh: "FIM 模式下（`enable_fim = true`），文件内容、权限、所有者以及扩展属性的变更将以安全巡检数据上报。",
+		Cat:    point.Security,
+		Tags: map[string]interface{}{
+			"title":       inputs.NewTagInfo("Title of the change."),
+			"category":    inputs.NewTagInfo("Always `system`."),
+			"status":      inputs.NewTagInfo("Severity of the change, one of `info/warning/error/critical`, see `severity_rules`."),
+			"source":      inputs.NewTagInfo("The `source` of the collector configuration."),
+			"path":        inputs.NewTagInfo("Path of the changed file, without the `mount_point`."),
+			"change_type": inputs.NewTagInfo("Type of the change, one of `Created/Modified/Deleted/AttrModified`."),
+		},
+		Fields: map[string]interface{}{
+			"message":    &inputs.FieldInfo{DataType: inputs.String, Type: inputs.UnknownType, Unit: inputs.UnknownUnit, Desc: "Details of the change, include the digest and attribute changes."},
+			"diff":       &inputs.FieldInfo{DataType: inputs.String, Type: inputs.UnknownType, Unit: inputs.UnknownUnit, Desc: "Diff text of file content, unavailable for files larger than `max_diff_size` or changed while DataKit was down."},
+			"old_sha256": &inputs.FieldInfo{DataType: inputs.String, Type: inputs.UnknownType, Unit: inputs.UnknownUnit, Desc: "SHA-256 digest of the file before the change."},
+			"new_sha256": &inputs.FieldInfo{DataType: inputs.String, Type: inputs.UnknownType, Unit: inputs.UnknownUnit, Desc: "SHA-256 digest of the file after the change."},
+			"old_mode":   &inputs.FieldInfo{DataType: inputs.String, Type: inputs.UnknownType, Unit: inputs.UnknownUnit, Desc: "File mode before the change, such as `-rw-r--r--`."},
+			"new_mode":   &inputs.FieldInfo{DataType: inputs.String, Type: inputs.UnknownType, Unit: inputs.UnknownUnit, Desc: "File mode after the change."},
+			"old_uid":    &inputs.FieldInfo{DataType: inputs.Int, Type: inputs.UnknownType, Unit: inputs.NoUnit, Desc: "Owner user ID before the change."},
+			"new_uid":    &inputs.FieldInfo{DataType: inputs.Int, Type: inputs.UnknownType, Unit: inputs.NoUnit, Desc: "Owner user ID after the change."},
+			"old_gid":    &inputs.FieldInfo{DataType: inputs.Int, Type: inputs.UnknownType, Unit: inputs.NoUnit, Desc: "Owner group ID before the change."},
+			"new_gid":    &inputs.FieldInfo{DataType: inputs.Int, Type: inputs.UnknownType, Unit: inputs.NoUnit, Desc: "Owner group ID after the change."},
+		},
+	}
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+//go:build linux
+// +build linux
+
+package configwatcher
+
+import (
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func fileOwner(info os.FileInfo) (uid, gid uint32) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return st.Uid, st.Gid
+	}
+	return 0, 0
+}
+
+// readXattrs returns the extended attributes of the file(not following
+// symlink), nil if there is no attribute or the filesystem not support it.
+func readXattrs(path string) map[string]string {
+	sz, err := unix.Llistxattr(path, nil)
+	if err != nil || sz <= 0 {
+		return nil
+	}
+
+	buf := make([]byte, sz)
+	if sz, err = unix.Llistxattr(path, buf); err != nil {
+		return nil
+	}
+
+	res := map[string]string{}
+	for _, name := range strings.Split(string(buf[:sz]), "\x00") {
+		if name == "" {
+			continue
+		}
+
+		vsz, err := unix.Lgetxattr(path, name, nil)
+		if err != nil {
+			continue
+		}
+
+		val := make([]byte, vsz)
+		if vsz > 0 {
+			if vsz, err = unix.Lgetxattr(path, name, val); err != nil {
+				continue
+			}
+		}
+
+		res[name] = formatXattrValue(val[:vsz])
+	}
+
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
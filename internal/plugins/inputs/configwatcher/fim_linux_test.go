@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+//go:build linux
+// +build linux
+
+package configwatcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestReadXattrs(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "a.conf")
+	require.NoError(t, os.WriteFile(filePath, []byte("a=1\n"), 0o644))
+
+	if err := unix.Setxattr(filePath, "user.checksum", []byte("abc"), 0); err != nil {
+		t.Skipf("xattr not supported: %s", err)
+	}
+
+	assert.Equal(t, map[string]string{"user.checksum": "abc"}, readXattrs(filePath))
+
+	watcher, err := newFileWatcher(filePath, withFIM(true))
+	require.NoError(t, err)
+
+	require.NoError(t, unix.Setxattr(filePath, "user.checksum", []byte{0xff, 0x01}, 0))
+
+	events, err := watcher.checkChanges()
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, attrModified, events[0].typ)
+	assert.Equal(t, []string{`xattr user.checksum: "abc" -> "0xff01"`}, events[0].attrChanges)
+}
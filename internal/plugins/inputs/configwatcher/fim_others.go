@@ -0,0 +1,15 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+//go:build !linux
+// +build !linux
+
+package configwatcher
+
+import "os"
+
+func fileOwner(os.FileInfo) (uid, gid uint32) { return 0, 0 }
+
+func readXattrs(string) map[string]string { return nil }
@@ -0,0 +1,279 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package configwatcher
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/logger"
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "passwd")
+	require.NoError(t, os.WriteFile(filePath, []byte("root:x:0:0::/root:/bin/bash\n"), 0o644))
+
+	watcher, err := newFileWatcher(tmpDir, withFIM(true), withMaxDiffSize(1024))
+	require.NoError(t, err)
+
+	st := watcher.getCurrentStates()[filePath]
+	require.NotNil(t, st)
+	assert.Len(t, st.sha256, 64)
+	assert.Equal(t, os.FileMode(0o644), st.mode.Perm())
+
+	t.Run("mode", func(t *testing.T) {
+		require.NoError(t, os.Chmod(filePath, 0o666))
+
+		events, err := watcher.checkChanges()
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, attrModified, events[0].typ)
+		assert.Equal(t, []string{"mode: -rw-r--r-- -> -rw-rw-rw-"}, events[0].attrChanges)
+	})
+
+	t.Run("content-with-mtime-reset", func(t *testing.T) {
+		info, err := os.Stat(filePath)
+		require.NoError(t, err)
+
+		// same size, and ModTime reset
+		require.NoError(t, os.WriteFile(filePath, []byte("root:x:0:0::/root:/bin/zsh\n"), 0o666))
+		require.NoError(t, os.Chtimes(filePath, info.ModTime(), info.ModTime()))
+
+		events, err := watcher.checkChanges()
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, modified, events[0].typ)
+		assert.NotEqual(t, events[0].oldState.sha256, events[0].newState.sha256)
+		assert.Contains(t, events[0].diff, "/bin/zsh")
+	})
+
+	t.Run("no-change", func(t *testing.T) {
+		events, err := watcher.checkChanges()
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
+
+func TestDiffAttrs(t *testing.T) {
+	last := &fileState{
+		mode:   0o644,
+		uid:    0,
+		gid:    0,
+		xattrs: map[string]string{"security.selinux": "system_u:object_r:etc_t:s0", "user.a": "1"},
+	}
+	cur := &fileState{
+		mode:   0o600,
+		uid:    1000,
+		gid:    0,
+		xattrs: map[string]string{"security.selinux": "unconfined_u:object_r:user_home_t:s0", "user.b": "2"},
+	}
+
+	assert.Equal(t, []string{
+		"mode: -rw-r--r-- -> -rw-------",
+		"uid: 0 -> 1000",
+		`xattr security.selinux: "system_u:object_r:etc_t:s0" -> "unconfined_u:object_r:user_home_t:s0"`,
+		`xattr user.a: "1" -> <none>`,
+		`xattr user.b: <none> -> "2"`,
+	}, diffAttrs(last, cur))
+
+	assert.Empty(t, diffAttrs(last, last))
+}
+
+func TestFormatXattrValue(t *testing.T) {
+	assert.Equal(t, "system_u:object_r:etc_t:s0", formatXattrValue([]byte("system_u:object_r:etc_t:s0\x00")))
+	assert.Equal(t, "0x0100000201", formatXattrValue([]byte{1, 0, 0, 2, 1}))
+	assert.Equal(t, "", formatXattrValue(nil))
+}
+
+func TestSeverityRules(t *testing.T) {
+	rules := []*severityRule{
+		{Pattern: "/etc/passwd", Severity: severityCritical},
+		{Pattern: "/etc/ssh/", Severity: severityError},
+		{Pattern: "/etc/*.conf", Severity: severityWarning},
+	}
+	require.NoError(t, checkSeverityRules(rules))
+
+	for path, expect := range map[string]string{
+		"/etc/passwd":                severityCritical,
+		"/etc/ssh/sshd_config":       severityError,
+		"/etc/ssh/sshd_config.d/a":   severityError,
+		"/etc/resolv.conf":           severityWarning,
+		"/etc/nginx/nginx.conf":      severityInfo,
+		"/var/spool/cron/crontabs/a": severityInfo,
+	} {
+		assert.Equal(t, expect, severityOf(rules, path), path)
+	}
+
+	assert.Error(t, checkSeverityRules([]*severityRule{{Pattern: "/etc/passwd", Severity: "fatal"}}))
+	assert.Error(t, checkSeverityRules([]*severityRule{{Pattern: "/etc/[", Severity: severityInfo}}))
+}
+
+func TestBaseline(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataDir := filepath.Join(tmpDir, "data")
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "sub"), os.ModePerm))
+
+	fileA := filepath.Join(dataDir, "a.conf")
+	fileB := filepath.Join(dataDir, "sub", "b.conf")
+	require.NoError(t, os.WriteFile(fileA, []byte("a=1\n"), 0o644))
+	require.NoError(t, os.WriteFile(fileB, []byte("b=1\n"), 0o644))
+
+	baselinePath := filepath.Join(tmpDir, "cache", "baseline.json")
+
+	b, err := loadBaseline(baselinePath)
+	require.NoError(t, err)
+	assert.Nil(t, b)
+
+	watcher, err := newFileWatcher(dataDir, withFIM(true), withRecursive(true), withMaxDiffSize(1024))
+	require.NoError(t, err)
+
+	b = &baseline{Files: make(map[string]*baselineFile)}
+	watcher.dump(b)
+	require.NoError(t, saveBaseline(baselinePath, b))
+
+	// changes while DataKit is down
+	require.NoError(t, os.WriteFile(fileA, []byte("a=2\n"), 0o644))
+	require.NoError(t, os.Chmod(fileB, 0o600))
+	fileC := filepath.Join(dataDir, "c.conf")
+	require.NoError(t, os.WriteFile(fileC, []byte("c=1\n"), 0o644))
+
+	// restart
+	b, err = loadBaseline(baselinePath)
+	require.NoError(t, err)
+	require.NotNil(t, b)
+
+	watcher, err = newFileWatcher(dataDir, withFIM(true), withRecursive(true), withMaxDiffSize(1024))
+	require.NoError(t, err)
+	require.True(t, watcher.restore(b))
+
+	events, err := watcher.checkChanges()
+	require.NoError(t, err)
+
+	res := map[string]changeEvent{}
+	for _, e := range events {
+		res[e.path] = e
+	}
+	require.Len(t, res, 3)
+
+	assert.Equal(t, modified, res[fileA].typ)
+	assert.Empty(t, res[fileA].diff) // content not persisted
+	assert.Equal(t, attrModified, res[fileB].typ)
+	assert.Equal(t, created, res[fileC].typ)
+
+	// path not in baseline
+	other, err := newFileWatcher(filepath.Join(tmpDir, "other"), withFIM(true))
+	require.NoError(t, err)
+	assert.False(t, other.restore(b))
+}
+
+func TestBuildSecurityPoint(t *testing.T) {
+	ipt := &Input{
+		Source:     "etc",
+		MountPoint: "/rootfs",
+		SeverityRules: []*severityRule{
+			{Pattern: "/etc/passwd", Severity: severityCritical},
+		},
+		mergedTags: map[string]string{"host": "host-01"},
+	}
+
+	event := &changeEvent{
+		typ:         attrModified,
+		path:        "/rootfs/etc/passwd",
+		oldState:    &fileState{exists: true, mode: 0o644, sha256: "abc"},
+		newState:    &fileState{exists: true, mode: 0o666, uid: 1000, sha256: "abc"},
+		attrChanges: []string{"mode: -rw-r--r-- -> -rw-rw-rw-", "uid: 0 -> 1000"},
+	}
+
+	pt := ipt.buildSecurityPoint(event, time.Now())
+	assert.Equal(t, fimMeasurementName, pt.Name())
+	assert.Equal(t, "/etc/passwd", pt.GetTag("path"))
+	assert.Equal(t, severityCritical, pt.GetTag("status"))
+	assert.Equal(t, fimCategory, pt.GetTag("category"))
+	assert.Equal(t, "AttrModified", pt.GetTag("change_type"))
+	assert.Equal(t, "host-01", pt.GetTag("host"))
+	assert.Equal(t, "-rw-rw-rw-", pt.Get("new_mode"))
+	assert.Equal(t, int64(1000), pt.Get("new_uid"))
+	assert.Equal(t, "attributes of file /etc/passwd were modified\nattribute changes:\n- mode: -rw-r--r-- -> -rw-rw-rw-\n- uid: 0 -> 1000",
+		pt.Get("message"))
+}
+
+func TestInputFIM(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("inotify only tested on linux")
+	}
+
+	tmpDir := t.TempDir()
+	dataDir := filepath.Join(tmpDir, "data")
+	require.NoError(t, os.MkdirAll(dataDir, os.ModePerm))
+	filePath := filepath.Join(dataDir, "a.conf")
+	require.NoError(t, os.WriteFile(filePath, []byte("a=1\n"), 0o644))
+
+	feeder := dkio.NewMockedFeeder()
+	ipt := &Input{
+		Source:       "test",
+		Paths:        []string{dataDir},
+		Interval:     time.Minute,
+		Recursive:    true,
+		MaxDiffSize:  1024,
+		EnableFIM:    true,
+		BaselinePath: filepath.Join(tmpDir, "baseline.json"),
+		feeder:       feeder,
+		tagger:       datakit.DefaultGlobalTagger(),
+		log:          logger.DefaultSLogger(inputName),
+	}
+
+	require.NoError(t, ipt.setup())
+	require.NotNil(t, ipt.inotify)
+	defer ipt.inotify.Close() //nolint:errcheck
+
+	// new directory is watched
+	subDir := filepath.Join(dataDir, "sub")
+	require.NoError(t, os.MkdirAll(subDir, os.ModePerm))
+	waitInotifyEvent(t, ipt, subDir)
+
+	require.NoError(t, os.WriteFile(filepath.Join(subDir, "b.conf"), []byte("b=1\n"), 0o644))
+	waitInotifyEvent(t, ipt, filepath.Join(subDir, "b.conf"))
+
+	ipt.checkPending()
+	pts, err := feeder.AnyPoints(time.Second)
+	require.NoError(t, err)
+	require.Len(t, pts, 1)
+	assert.Equal(t, fimMeasurementName, pts[0].Name())
+	assert.Equal(t, "Created", pts[0].GetTag("change_type"))
+
+	// baseline saved
+	b, err := loadBaseline(ipt.BaselinePath)
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	assert.Contains(t, b.Files, filepath.Join(subDir, "b.conf"))
+}
+
+func waitInotifyEvent(t *testing.T, ipt *Input, name string) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-ipt.inotify.Events():
+			ipt.handleInotifyEvent(ev)
+			if ev.Name == name && ev.Has(fsnotify.Create) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("wait inotify event of %s timeout", name)
+		}
+	}
+}
@@ -9,15 +9,18 @@ package configwatcher
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/GuanceCloud/cliutils"
 	"github.com/GuanceCloud/cliutils/logger"
 	"github.com/GuanceCloud/cliutils/point"
+	"github.com/fsnotify/fsnotify"
 
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/config"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
 	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/logtail/fileprovider"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
 )
 
@@ -30,9 +33,17 @@ type Input struct {
 	MaxDiffSize int64             `toml:"max_diff_size"`
 	Tags        map[string]string `toml:"tags"`
 
+	// FIM(file integrity monitoring) mode
+	EnableFIM     bool            `toml:"enable_fim"`
+	BaselinePath  string          `toml:"baseline_path"`
+	SeverityRules []*severityRule `toml:"severity_rules"`
+
 	fileWatchers []*fileWatcher
 	mergedTags   map[string]string
 
+	inotify *fileprovider.Inotify
+	pending map[*fileWatcher]struct{}
+
 	feeder  dkio.Feeder
 	tagger  datakit.GlobalTagger
 	semStop *cliutils.Sem
@@ -42,7 +53,7 @@ type Input struct {
 func (*Input) SampleConfig() string { return sampleConfig }
 
 func (*Input) SampleMeasurement() []inputs.Measurement {
-	return []inputs.Measurement{inputs.DefaultEmptyMeasurement}
+	return []inputs.Measurement{&fimMeasurement{}}
 }
 
 func (*Input) AvailableArchs() []string { return []string{datakit.OSLabelLinux} }
@@ -56,6 +67,24 @@ func (ipt *Input) Run() {
 	if err := ipt.setup(); err != nil {
 		ipt.log.Infof("init failure: %s", err)
 		ipt.log.Info("exit")
+		return
+	}
+
+	var (
+		inotifyEvents chan fsnotify.Event
+		inotifyErrors chan error
+		batch         <-chan time.Time
+	)
+
+	if ipt.EnableFIM {
+		// report changes made while DataKit was down
+		ipt.checkChanges()
+		ipt.saveBaseline()
+
+		if ipt.inotify != nil {
+			defer ipt.inotify.Close() //nolint:errcheck
+			inotifyEvents, inotifyErrors = ipt.inotify.Events(), ipt.inotify.Errors()
+		}
 	}
 
 	tick := time.NewTicker(ipt.Interval)
@@ -73,6 +102,20 @@ func (ipt *Input) Run() {
 
 		case <-tick.C:
 			ipt.checkChanges()
+
+		case ev := <-inotifyEvents:
+			if ipt.handleInotifyEvent(ev) && batch == nil {
+				batch = time.After(fimBatchInterval)
+			}
+
+		case err := <-inotifyErrors:
+			// events may be lost(such as queue overflow), check all the paths
+			ipt.log.Warnf("inotify: %s", err)
+			ipt.checkChanges()
+
+		case <-batch:
+			batch = nil
+			ipt.checkPending()
 		}
 	}
 }
@@ -87,10 +130,21 @@ func (ipt *Input) setup() error {
 		}
 	}
 
+	if ipt.EnableFIM {
+		if err := checkSeverityRules(ipt.SeverityRules); err != nil {
+			return err
+		}
+
+		if ipt.BaselinePath == "" {
+			ipt.BaselinePath = defaultBaselinePath(ipt.Source)
+		}
+	}
+
 	for _, path := range ipt.Paths {
 		w, err := newFileWatcher(filepath.Join(ipt.MountPoint, path),
 			withMaxDiffSize(ipt.MaxDiffSize),
 			withRecursive(ipt.Recursive),
+			withFIM(ipt.EnableFIM),
 		)
 		if err != nil {
 			return err
@@ -99,13 +153,101 @@ func (ipt *Input) setup() error {
 		ipt.fileWatchers = append(ipt.fileWatchers, w)
 	}
 
+	if ipt.EnableFIM {
+		ipt.setupFIM()
+	}
+
 	return nil
 }
 
+// setupFIM restores the baseline and watches the paths by inotify, the
+// interval check still works if inotify is unavailable.
+func (ipt *Input) setupFIM() {
+	ipt.pending = make(map[*fileWatcher]struct{})
+
+	b, err := loadBaseline(ipt.BaselinePath)
+	if err != nil {
+		ipt.log.Warnf("load baseline failed: %s, ignored", err)
+	}
+
+	if b != nil {
+		for _, w := range ipt.fileWatchers {
+			if !w.restore(b) {
+				ipt.log.Infof("path %s not found in baseline, use current states as baseline", w.path)
+			}
+		}
+	}
+
+	var dirs []string
+	for _, w := range ipt.fileWatchers {
+		dirs = append(dirs, w.watchDirs()...)
+	}
+
+	in, err := fileprovider.NewDirInotify(dirs, fimInotifyOps)
+	if err != nil {
+		ipt.log.Warnf("inotify unavailable: %s, check changes on interval only", err)
+		return
+	}
+	ipt.inotify = in
+}
+
+// handleInotifyEvent marks the watchers of the event as pending, it returns
+// false if no watcher concerned.
+func (ipt *Input) handleInotifyEvent(ev fsnotify.Event) bool {
+	concerned := false
+
+	for _, w := range ipt.fileWatchers {
+		if ev.Name != w.path && !strings.HasPrefix(ev.Name, w.path+string(filepath.Separator)) {
+			continue
+		}
+
+		concerned = true
+		ipt.pending[w] = struct{}{}
+
+		// watch the newly created directories
+		if ev.Has(fsnotify.Create) && (ev.Name == w.path || w.recursive) {
+			if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+				for _, dir := range w.subDirs(ev.Name) {
+					if err := ipt.inotify.Add(dir, fimInotifyOps); err != nil {
+						ipt.log.Warn(err)
+					}
+				}
+			}
+		}
+	}
+
+	return concerned
+}
+
+func (ipt *Input) checkPending() {
+	var watchers []*fileWatcher
+	for w := range ipt.pending {
+		watchers = append(watchers, w)
+	}
+	ipt.pending = make(map[*fileWatcher]struct{})
+
+	ipt.check(watchers)
+}
+
+func (ipt *Input) saveBaseline() {
+	b := &baseline{Files: make(map[string]*baselineFile)}
+	for _, w := range ipt.fileWatchers {
+		w.dump(b)
+	}
+
+	if err := saveBaseline(ipt.BaselinePath, b); err != nil {
+		ipt.log.Warnf("save baseline failed: %s", err)
+	}
+}
+
 func (ipt *Input) checkChanges() {
+	ipt.check(ipt.fileWatchers)
+}
+
+func (ipt *Input) check(watchers []*fileWatcher) {
 	var pts []*point.Point
 
-	for _, w := range ipt.fileWatchers {
+	for _, w := range watchers {
 		events, err := w.checkChanges()
 		if err != nil {
 			ipt.log.Warn(err)
@@ -117,6 +259,13 @@ func (ipt *Input) checkChanges() {
 				continue
 			}
 
+			// ModTime can be reset(`touch -r`) and it's not changed on
+			// attribute changes, use the detection time in FIM mode.
+			if ipt.EnableFIM {
+				pts = append(pts, ipt.buildSecurityPoint(&events[idx], time.Now()))
+				continue
+			}
+
 			t := time.Now()
 
 			if event.typ != deleted {
@@ -141,8 +290,17 @@ func (ipt *Input) checkChanges() {
 		}
 	}
 
+	category := point.KeyEvent
+	if ipt.EnableFIM {
+		category = point.Security
+
+		if len(pts) > 0 {
+			ipt.saveBaseline()
+		}
+	}
+
 	if err := ipt.feeder.Feed(
-		point.KeyEvent,
+		category,
 		pts,
 		dkio.WithSource(dkio.FeedSource(inputName, ipt.Source)),
 	); err != nil {
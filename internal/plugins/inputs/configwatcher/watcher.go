@@ -7,6 +7,7 @@
 package configwatcher
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -27,6 +28,13 @@ type fileState struct {
 	modTime time.Time
 	hash    string
 	content []byte
+
+	// attributes only tracked in FIM mode
+	mode   os.FileMode
+	uid    uint32
+	gid    uint32
+	xattrs map[string]string
+	sha256 string
 }
 
 type changeType int
@@ -36,6 +44,7 @@ const (
 	created
 	modified
 	deleted
+	attrModified
 )
 
 func (t changeType) String() string {
@@ -48,6 +57,8 @@ func (t changeType) String() string {
 		return "Modified"
 	case deleted:
 		return "Deleted"
+	case attrModified:
+		return "AttrModified"
 	default:
 		return ""
 	}
@@ -59,6 +70,10 @@ type changeEvent struct {
 	oldState *fileState
 	newState *fileState
 	diff     string
+
+	// attrChanges is the list of changed attributes in FIM mode, such as
+	// `mode: -rw-r--r-- -> -rwxrwxrwx`.
+	attrChanges []string
 }
 
 type fileWatcher struct {
@@ -68,12 +83,14 @@ type fileWatcher struct {
 	maxOpenFiles int
 	maxDiffSize  int64
 	recursive    bool
+	fim          bool
 }
 
 type option func(w *fileWatcher)
 
 func withMaxDiffSize(n int64) option { return func(w *fileWatcher) { w.maxDiffSize = n } }
 func withRecursive(b bool) option    { return func(w *fileWatcher) { w.recursive = b } }
+func withFIM(b bool) option          { return func(w *fileWatcher) { w.fim = b } }
 
 func newFileWatcher(path string, opts ...option) (*fileWatcher, error) {
 	absPath, err := filepath.Abs(path)
@@ -137,14 +154,25 @@ func (w *fileWatcher) scanPath() map[string]*fileState {
 			if err == nil {
 				state.hash = hash
 				state.content = content
+				if w.fim {
+					sum := sha256.Sum256(content)
+					state.sha256 = hex.EncodeToString(sum[:])
+				}
 			}
 		} else {
-			hash, err := w.calculateFileHash(path)
+			hash, sha, err := w.calculateFileHash(path)
 			if err == nil {
 				state.hash = hash
+				state.sha256 = sha
 			}
 		}
 
+		if w.fim {
+			state.mode = info.Mode()
+			state.uid, state.gid = fileOwner(info)
+			state.xattrs = readXattrs(path)
+		}
+
 		states[path] = state
 		return nil
 	}
@@ -166,19 +194,32 @@ func (w *fileWatcher) readFileContent(path string) ([]byte, string, error) {
 	return content, hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
-func (w *fileWatcher) calculateFileHash(path string) (string, error) {
+// calculateFileHash returns the xxhash of the file, and the SHA-256 digest in FIM mode.
+func (w *fileWatcher) calculateFileHash(path string) (string, string, error) {
 	file, err := os.Open(filepath.Clean(path))
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	defer file.Close() //nolint:errcheck,gosec
 
 	hasher := xxhash.New()
-	if _, err := io.Copy(hasher, file); err != nil {
-		return "", err
+	var dst io.Writer = hasher
+
+	shaHasher := sha256.New()
+	if w.fim {
+		dst = io.MultiWriter(hasher, shaHasher)
 	}
 
-	return hex.EncodeToString(hasher.Sum(nil)), nil
+	if _, err := io.Copy(dst, file); err != nil {
+		return "", "", err
+	}
+
+	var sha string
+	if w.fim {
+		sha = hex.EncodeToString(shaHasher.Sum(nil))
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), sha, nil
 }
 
 func (w *fileWatcher) checkChanges() ([]changeEvent, error) {
@@ -213,6 +254,10 @@ func (w *fileWatcher) checkChanges() ([]changeEvent, error) {
 				event.diff = w.generateDiff(nil, currentState.content)
 			}
 			events = append(events, event)
+		} else if lastState.exists && currentState.exists && w.fim {
+			if event, ok := w.checkIntegrity(lastState, currentState); ok {
+				events = append(events, event)
+			}
 		} else if lastState.exists && currentState.exists {
 			// 检查文件是否修改
 			if lastState.modTime != currentState.modTime || lastState.size != currentState.size {
@@ -108,6 +108,15 @@ func (*Input) SampleMeasurement() []inputs.Measurement {
 		&kubernetes.JobObject{},
 		&kubernetes.NodeMetric{},
 		&kubernetes.NodeObject{},
+		&kubernetes.IngressObject{},
+		&kubernetes.HorizontalPodAutoscalerMetric{},
+		&kubernetes.HorizontalPodAutoscalerObject{},
+		&kubernetes.NamespaceObject{},
+		&kubernetes.ResourceQuotaMetric{},
+		&kubernetes.ResourceQuotaObject{},
+		&kubernetes.LimitRangeObject{},
+		&kubernetes.PodDisruptionBudgetMetric{},
+		&kubernetes.PodDisruptionBudgetObject{},
 
 		&containerMetric{},
 		&containerObject{},
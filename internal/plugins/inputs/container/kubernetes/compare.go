@@ -292,6 +292,19 @@ func compareAnnotations(changeID changes.ChangeID, oldObj, newObj *metav1.Object
 	return
 }
 
+// compareMaps compares the map converted from resource spec, such as
+// ResourceQuota hard limits.
+func compareMaps(changeID changes.ChangeID, oldMap, newMap map[string]string) (res []FieldDiff) {
+	if equal, difftext := diff.Compare(oldMap, newMap); !equal {
+		res = append(res, FieldDiff{
+			ChangeID:        changeID,
+			ChangeValueList: diffMaps(oldMap, newMap),
+			DiffText:        difftext,
+		})
+	}
+	return
+}
+
 func diffMaps(oldMap, newMap map[string]string) []string {
 	var changeValueList []string
 
@@ -553,6 +566,16 @@ func resourceToStringSlice(resources apicorev1.ResourceList) []string {
 	return res
 }
 
+// resourceListToMap converts resources to map with the prefix, such as
+// `requests.cpu=500m`.
+func resourceListToMap(prefix string, resources apicorev1.ResourceList) map[string]string {
+	res := make(map[string]string, len(resources))
+	for name, quantity := range resources {
+		res[prefix+string(name)] = quantity.String()
+	}
+	return res
+}
+
 func formatAsDiffLines(key, oldVal, newVal string) string {
 	return fmt.Sprintf("- %s: %s\n+ %s: %s", key, oldVal, key, newVal)
 }
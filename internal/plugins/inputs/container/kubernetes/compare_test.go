@@ -0,0 +1,216 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package kubernetes
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiautoscalingv2 "k8s.io/api/autoscaling/v2"
+	apicorev1 "k8s.io/api/core/v1"
+	apinetworkingv1 "k8s.io/api/networking/v1"
+	apipolicyv1 "k8s.io/api/policy/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/changes"
+)
+
+func changeIDs(diffs []FieldDiff) []string {
+	var res []string
+	for _, df := range diffs {
+		res = append(res, string(df.ChangeID))
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestCompareIngress(t *testing.T) {
+	newIngress := func(host, svc string) *apinetworkingv1.Ingress {
+		return &apinetworkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: apinetworkingv1.IngressSpec{
+				Rules: []apinetworkingv1.IngressRule{
+					{
+						Host: host,
+						IngressRuleValue: apinetworkingv1.IngressRuleValue{
+							HTTP: &apinetworkingv1.HTTPIngressRuleValue{
+								Paths: []apinetworkingv1.HTTPIngressPath{
+									{
+										Path: "/api",
+										Backend: apinetworkingv1.IngressBackend{
+											Service: &apinetworkingv1.IngressServiceBackend{
+												Name: svc,
+												Port: apinetworkingv1.ServiceBackendPort{Number: 80},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	assert.Empty(t, compareIngress(newIngress("a.com", "svc-a"), newIngress("a.com", "svc-a")))
+
+	diffs := compareIngress(newIngress("a.com", "svc-a"), newIngress("a.com", "svc-b"))
+	require.Len(t, diffs, 1)
+	assert.Equal(t, changes.IngressRules, diffs[0].ChangeID)
+	assert.Equal(t, []string{"- a.com/api: service/svc-a:80 -> service/svc-b:80"}, diffs[0].ChangeValueList)
+	assert.Equal(t, "Ingress", diffs[0].OwnerKind)
+	assert.Equal(t, "web", diffs[0].OwnerName)
+
+	newVal := newIngress("b.com", "svc-a")
+	newVal.Labels = map[string]string{"app": "web"}
+	diffs = compareIngress(newIngress("a.com", "svc-a"), newVal)
+	assert.Equal(t, []string{string(changes.IngressLabels), string(changes.IngressRules)}, changeIDs(diffs))
+}
+
+func TestCompareHPA(t *testing.T) {
+	newHPA := func(minReplicas *int32, maxReplicas int32, target string) *apiautoscalingv2.HorizontalPodAutoscaler {
+		return &apiautoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: apiautoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: apiautoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: target},
+				MinReplicas:    minReplicas,
+				MaxReplicas:    maxReplicas,
+			},
+		}
+	}
+
+	one, two := int32(1), int32(2)
+
+	// MinReplicas default to 1
+	assert.Empty(t, compareHPA(newHPA(nil, 5, "web"), newHPA(&one, 5, "web")))
+
+	diffs := compareHPA(newHPA(&one, 5, "web"), newHPA(&two, 10, "web"))
+	require.Len(t, diffs, 1)
+	assert.Equal(t, changes.HPAReplicas, diffs[0].ChangeID)
+	assert.Equal(t, "1-5", diffs[0].OldValue)
+	assert.Equal(t, "2-10", diffs[0].NewValue)
+
+	diffs = compareHPA(newHPA(&one, 5, "web"), newHPA(&one, 5, "web-v2"))
+	require.Len(t, diffs, 1)
+	assert.Equal(t, changes.HPAScaleTarget, diffs[0].ChangeID)
+	assert.Equal(t, "Deployment/web-v2", diffs[0].NewValue)
+}
+
+func TestCompareResourceQuota(t *testing.T) {
+	newQuota := func(cpu string) *apicorev1.ResourceQuota {
+		return &apicorev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "default"},
+			Spec: apicorev1.ResourceQuotaSpec{
+				Hard: apicorev1.ResourceList{
+					apicorev1.ResourceRequestsCPU: apiresource.MustParse(cpu),
+					apicorev1.ResourcePods:        apiresource.MustParse("10"),
+				},
+			},
+		}
+	}
+
+	assert.Empty(t, compareResourceQuota(newQuota("2"), newQuota("2")))
+
+	diffs := compareResourceQuota(newQuota("2"), newQuota("4"))
+	require.Len(t, diffs, 1)
+	assert.Equal(t, changes.ResourceQuotaHard, diffs[0].ChangeID)
+	assert.Equal(t, []string{"- requests.cpu: 2 -> 4"}, diffs[0].ChangeValueList)
+	assert.Equal(t, "default", diffs[0].Namespace)
+}
+
+func TestCompareLimitRange(t *testing.T) {
+	newLimitRange := func(max string) *apicorev1.LimitRange {
+		return &apicorev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "default"},
+			Spec: apicorev1.LimitRangeSpec{
+				Limits: []apicorev1.LimitRangeItem{
+					{
+						Type: apicorev1.LimitTypeContainer,
+						Max:  apicorev1.ResourceList{apicorev1.ResourceCPU: apiresource.MustParse(max)},
+					},
+				},
+			},
+		}
+	}
+
+	assert.Equal(t, map[string]string{"Container.max.cpu": "2"}, limitRangeToMap(&newLimitRange("2").Spec))
+	assert.Empty(t, compareLimitRange(newLimitRange("2"), newLimitRange("2")))
+
+	diffs := compareLimitRange(newLimitRange("2"), newLimitRange("500m"))
+	require.Len(t, diffs, 1)
+	assert.Equal(t, changes.LimitRangeLimits, diffs[0].ChangeID)
+	assert.Equal(t, []string{"- Container.max.cpu: 2 -> 500m"}, diffs[0].ChangeValueList)
+}
+
+func TestComparePDB(t *testing.T) {
+	newPDB := func(minAvailable, maxUnavailable *intstr.IntOrString) *apipolicyv1.PodDisruptionBudget {
+		return &apipolicyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: apipolicyv1.PodDisruptionBudgetSpec{
+				MinAvailable:   minAvailable,
+				MaxUnavailable: maxUnavailable,
+			},
+		}
+	}
+
+	one := intstr.FromInt(1)
+	half := intstr.FromString("50%")
+
+	assert.Empty(t, comparePDB(newPDB(&one, nil), newPDB(&one, nil)))
+
+	diffs := comparePDB(newPDB(&one, nil), newPDB(nil, &half))
+	require.Len(t, diffs, 1)
+	assert.Equal(t, changes.PDBBudget, diffs[0].ChangeID)
+	assert.Equal(t, "minAvailable=1", diffs[0].OldValue)
+	assert.Equal(t, "maxUnavailable=50%", diffs[0].NewValue)
+}
+
+func TestResourceQuotaMetricPoints(t *testing.T) {
+	r := &resourcequota{cfg: &Config{}}
+
+	list := &apicorev1.ResourceQuotaList{
+		Items: []apicorev1.ResourceQuota{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "default"},
+				Status: apicorev1.ResourceQuotaStatus{
+					Hard: apicorev1.ResourceList{
+						apicorev1.ResourceLimitsCPU:    apiresource.MustParse("2"),
+						apicorev1.ResourceLimitsMemory: apiresource.MustParse("1Gi"),
+					},
+					Used: apicorev1.ResourceList{
+						apicorev1.ResourceLimitsCPU:    apiresource.MustParse("500m"),
+						apicorev1.ResourceLimitsMemory: apiresource.MustParse("256Mi"),
+					},
+				},
+			},
+		},
+	}
+
+	pts := r.buildMetricPoints(list, 0)
+	require.Len(t, pts, 2)
+
+	for _, pt := range pts {
+		switch pt.GetTag("resource") {
+		case "limits.cpu":
+			assert.Equal(t, 2.0, pt.Get("hard"))
+			assert.Equal(t, 0.5, pt.Get("used"))
+			assert.Equal(t, 25.0, pt.Get("used_percent"))
+		case "limits.memory":
+			assert.Equal(t, float64(1<<30), pt.Get("hard"))
+			assert.Equal(t, float64(256<<20), pt.Get("used"))
+		default:
+			t.Errorf("unexpected resource %q", pt.GetTag("resource"))
+		}
+		assert.Equal(t, "quota", pt.GetTag("resourcequota"))
+	}
+
+	assert.Equal(t, "limits.cpu=2,limits.memory=1Gi", joinResourceList(list.Items[0].Status.Hard))
+}
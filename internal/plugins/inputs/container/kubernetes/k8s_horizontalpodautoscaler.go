@@ -0,0 +1,334 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/changes"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/container/pointutil"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/ntp"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+
+	apiautoscalingv2 "k8s.io/api/autoscaling/v2"
+	apicorev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	hpaType              = "HorizontalPodAutoscaler"
+	hpaMetricMeasurement = "kube_horizontalpodautoscaler"
+	hpaObjectClass       = "kubernetes_horizontalpodautoscalers"
+	hpaObjectResourceKey = "horizontalpodautoscaler_name"
+)
+
+//nolint:gochecknoinits
+func init() {
+	registerResource("horizontalpodautoscaler", false, newHPA)
+}
+
+type hpa struct {
+	client  k8sClient
+	cfg     *Config
+	counter map[string]int
+}
+
+func newHPA(client k8sClient, cfg *Config) resource {
+	return &hpa{client: client, cfg: cfg, counter: make(map[string]int)}
+}
+
+func (h *hpa) gatherMetric(ctx context.Context, timestamp int64) {
+	var continued string
+	for {
+		list, err := h.client.GetHorizontalPodAutoscalers(allNamespaces).List(ctx, newListOptions(emptyFieldSelector, continued))
+		if err != nil {
+			klog.Warn(err)
+			break
+		}
+		continued = list.Continue
+
+		pts := h.buildMetricPoints(list, timestamp)
+		feedMetric("k8s-horizontalpodautoscaler-metric", h.cfg.Feeder, pts, true)
+
+		if continued == "" {
+			break
+		}
+	}
+	processCounter(h.cfg, "horizontalpodautoscaler", h.counter, timestamp)
+}
+
+func (h *hpa) gatherObject(ctx context.Context) {
+	var continued string
+	for {
+		list, err := h.client.GetHorizontalPodAutoscalers(allNamespaces).List(ctx, newListOptions(emptyFieldSelector, continued))
+		if err != nil {
+			klog.Warn(err)
+			break
+		}
+		continued = list.Continue
+
+		pts := h.buildObjectPoints(list)
+		feedObject("k8s-horizontalpodautoscaler-object", h.cfg.Feeder, pts, true)
+
+		if continued == "" {
+			break
+		}
+	}
+}
+
+func (h *hpa) addChangeInformer(informerFactory informers.SharedInformerFactory) {
+	informer := informerFactory.Autoscaling().V2().HorizontalPodAutoscalers()
+	if informer == nil {
+		klog.Warn("cannot get horizontalpodautoscaler informer")
+		return
+	}
+
+	addFunc := func(newObj interface{}) {
+		obj, ok := newObj.(*apiautoscalingv2.HorizontalPodAutoscaler)
+		if !ok {
+			klog.Warnf("converting to HorizontalPodAutoscaler object failed, %v", newObj)
+			return
+		}
+		if obj.CreationTimestamp.After(controllerStartTime) {
+			diffs := createNoChangedFieldDiffs(changes.HPACreate, obj.Namespace, hpaType, obj.Name)
+			objectChangeCountVec.WithLabelValues(hpaType, "create").Inc()
+			processChange(h.cfg, hpaObjectClass, hpaObjectResourceKey, diffs, obj)
+		}
+	}
+
+	deleteFunc := func(oldObj interface{}) {
+		obj, ok := oldObj.(*apiautoscalingv2.HorizontalPodAutoscaler)
+		if !ok {
+			klog.Warnf("converting to HorizontalPodAutoscaler object failed, %v", oldObj)
+			return
+		}
+
+		diffs := createNoChangedFieldDiffs(changes.HPADelete, obj.Namespace, hpaType, obj.Name)
+		objectChangeCountVec.WithLabelValues(hpaType, "delete").Inc()
+		processChange(h.cfg, hpaObjectClass, hpaObjectResourceKey, diffs, obj)
+	}
+
+	updateFunc := func(oldObj, newObj interface{}) {
+		objectChangeCountVec.WithLabelValues(hpaType, "update").Inc()
+
+		oldHPAObj, ok := oldObj.(*apiautoscalingv2.HorizontalPodAutoscaler)
+		if !ok {
+			klog.Warnf("converting to HorizontalPodAutoscaler object failed, %v", oldObj)
+			return
+		}
+
+		newHPAObj, ok := newObj.(*apiautoscalingv2.HorizontalPodAutoscaler)
+		if !ok {
+			klog.Warnf("converting to HorizontalPodAutoscaler object failed, %v", newObj)
+			return
+		}
+
+		diffs := compareHPA(oldHPAObj, newHPAObj)
+		if len(diffs) != 0 {
+			objectChangeCountVec.WithLabelValues(hpaType, "spec-changed").Inc()
+			processChange(h.cfg, hpaObjectClass, hpaObjectResourceKey, diffs, newHPAObj)
+		}
+	}
+
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(newObj interface{}) {
+			addFunc(newObj)
+		},
+		DeleteFunc: func(oldObj interface{}) {
+			deleteFunc(oldObj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			updateFunc(oldObj, newObj)
+		},
+	})
+}
+
+func (h *hpa) buildMetricPoints(list *apiautoscalingv2.HorizontalPodAutoscalerList, timestamp int64) []*point.Point {
+	var pts []*point.Point
+	opts := append(point.DefaultMetricOptions(), point.WithTimestamp(timestamp))
+
+	for _, item := range list.Items {
+		var kvs point.KVs
+
+		kvs = kvs.AddTag("uid", string(item.UID))
+		kvs = kvs.AddTag("horizontalpodautoscaler", item.Name)
+		kvs = kvs.AddTag("namespace", item.Namespace)
+		kvs = kvs.AddTag("scale_target_kind", item.Spec.ScaleTargetRef.Kind)
+		kvs = kvs.AddTag("scale_target_name", item.Spec.ScaleTargetRef.Name)
+
+		kvs = kvs.Add("replicas_current", item.Status.CurrentReplicas)
+		kvs = kvs.Add("replicas_desired", item.Status.DesiredReplicas)
+		kvs = kvs.Add("replicas_max", item.Spec.MaxReplicas)
+		if item.Spec.MinReplicas != nil {
+			kvs = kvs.Add("replicas_min", *item.Spec.MinReplicas)
+		}
+
+		kvs = append(kvs, pointutil.LabelsToPointKVs(item.Labels, h.cfg.LabelAsTagsForMetric.All, h.cfg.LabelAsTagsForMetric.Keys)...)
+		kvs = append(kvs, point.NewTags(h.cfg.ExtraTags)...)
+		pt := point.NewPoint(hpaMetricMeasurement, kvs, opts...)
+		pts = append(pts, pt)
+
+		h.counter[item.Namespace]++
+	}
+
+	return pts
+}
+
+func (h *hpa) buildObjectPoints(list *apiautoscalingv2.HorizontalPodAutoscalerList) []*point.Point {
+	var pts []*point.Point
+	opts := append(point.DefaultObjectOptions(), point.WithTime(ntp.Now()))
+
+	for idx, item := range list.Items {
+		var kvs point.KVs
+
+		kvs = kvs.AddTag("name", string(item.UID))
+		kvs = kvs.AddTag("uid", string(item.UID))
+		kvs = kvs.AddTag(hpaObjectResourceKey, item.Name)
+		kvs = kvs.AddTag("namespace", item.Namespace)
+		kvs = kvs.AddTag("scale_target_kind", item.Spec.ScaleTargetRef.Kind)
+		kvs = kvs.AddTag("scale_target_name", item.Spec.ScaleTargetRef.Name)
+
+		kvs = kvs.Add("age", time.Since(item.CreationTimestamp.Time).Milliseconds()/1e3)
+		kvs = kvs.Add("replicas_current", item.Status.CurrentReplicas)
+		kvs = kvs.Add("replicas_desired", item.Status.DesiredReplicas)
+		kvs = kvs.Add("replicas_max", item.Spec.MaxReplicas)
+		if item.Spec.MinReplicas != nil {
+			kvs = kvs.Add("replicas_min", *item.Spec.MinReplicas)
+		}
+		if item.Status.LastScaleTime != nil {
+			kvs = kvs.Add("last_scale_time", item.Status.LastScaleTime.Unix())
+		}
+
+		for _, cond := range item.Status.Conditions {
+			switch cond.Type {
+			case apiautoscalingv2.AbleToScale:
+				kvs = kvs.Add("able_to_scale", cond.Status == apicorev1.ConditionTrue)
+			case apiautoscalingv2.ScalingActive:
+				kvs = kvs.Add("scaling_active", cond.Status == apicorev1.ConditionTrue)
+			case apiautoscalingv2.ScalingLimited:
+				kvs = kvs.Add("scaling_limited", cond.Status == apicorev1.ConditionTrue)
+			}
+		}
+
+		if yamlStr := getCleanYAML(&list.Items[idx]); yamlStr != "" {
+			kvs = kvs.Add("yaml", yamlStr)
+		}
+		kvs = kvs.Add("annotations", pointutil.MapToJSON(filterAnnotations(item.Annotations)))
+		kvs = append(kvs, pointutil.ConvertDFLabels(item.Labels)...)
+
+		msg := pointutil.PointKVsToJSON(kvs)
+		kvs = kvs.Add("message", pointutil.TrimString(msg, maxMessageLength))
+
+		kvs = kvs.Del("annotations")
+		kvs = kvs.Del("yaml")
+
+		kvs = append(kvs, pointutil.ExtractSourceCodeFromAnnotations(item.Annotations)...) // add source_code
+		kvs = append(kvs, pointutil.LabelsToPointKVs(item.Labels, h.cfg.LabelAsTagsForNonMetric.All, h.cfg.LabelAsTagsForNonMetric.Keys)...)
+		kvs = append(kvs, point.NewTags(h.cfg.ExtraTags)...)
+		pt := point.NewPoint(hpaObjectClass, kvs, opts...)
+		pts = append(pts, pt)
+	}
+
+	return pts
+}
+
+func hpaReplicasRange(spec *apiautoscalingv2.HorizontalPodAutoscalerSpec) string {
+	minReplicas := int32(1) // default to 1 if not set
+	if spec.MinReplicas != nil {
+		minReplicas = *spec.MinReplicas
+	}
+	return fmt.Sprintf("%d-%d", minReplicas, spec.MaxReplicas)
+}
+
+func compareHPA(oldVal, newVal *apiautoscalingv2.HorizontalPodAutoscaler) []FieldDiff {
+	var res []FieldDiff
+	res = append(res, compareLabels(changes.HPALabels, &(oldVal.ObjectMeta), &(newVal.ObjectMeta))...)
+	res = append(res, compareAnnotations(changes.HPAAnnotations, &(oldVal.ObjectMeta), &(newVal.ObjectMeta))...)
+
+	if oldRange, newRange := hpaReplicasRange(&oldVal.Spec), hpaReplicasRange(&newVal.Spec); oldRange != newRange {
+		res = append(res, FieldDiff{
+			ChangeID: changes.HPAReplicas,
+			OldValue: oldRange,
+			NewValue: newRange,
+			DiffText: formatAsDiffLines("replicas", oldRange, newRange),
+		})
+	}
+
+	oldTarget := oldVal.Spec.ScaleTargetRef.Kind + "/" + oldVal.Spec.ScaleTargetRef.Name
+	newTarget := newVal.Spec.ScaleTargetRef.Kind + "/" + newVal.Spec.ScaleTargetRef.Name
+	if oldTarget != newTarget {
+		res = append(res, FieldDiff{
+			ChangeID: changes.HPAScaleTarget,
+			OldValue: oldTarget,
+			NewValue: newTarget,
+			DiffText: formatAsDiffLines("scaleTargetRef", oldTarget, newTarget),
+		})
+	}
+
+	fillOwnerInfoForDiffs(res, newVal.Namespace, hpaType, newVal.Name)
+	return res
+}
+
+type HorizontalPodAutoscalerMetric struct{}
+
+//nolint:lll
+func (*HorizontalPodAutoscalerMetric) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: hpaMetricMeasurement,
+		Desc: "The metric of the Kubernetes HorizontalPodAutoscaler.",
+		Cat:  point.Metric,
+		Tags: map[string]interface{}{
+			"uid":                     inputs.NewTagInfo("The UID of HorizontalPodAutoscaler."),
+			"horizontalpodautoscaler": inputs.NewTagInfo("Name must be unique within a namespace."),
+			"namespace":               inputs.NewTagInfo("Namespace defines the space within each name must be unique."),
+			"scale_target_kind":       inputs.NewTagInfo("The kind of the scaled resource, e.g. `Deployment`."),
+			"scale_target_name":       inputs.NewTagInfo("The name of the scaled resource."),
+			"cluster_name_k8s":        inputs.NewTagInfo("K8s cluster name(default is `default`). We can rename it in datakit.yaml on ENV_CLUSTER_NAME_K8S."),
+		},
+		Fields: map[string]interface{}{
+			"replicas_current": &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Current number of replicas of pods managed by this autoscaler."},
+			"replicas_desired": &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Desired number of replicas of pods managed by this autoscaler."},
+			"replicas_min":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "The lower limit for the number of replicas to which the autoscaler can scale down."},
+			"replicas_max":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "The upper limit for the number of replicas to which the autoscaler can scale up."},
+		},
+	}
+}
+
+type HorizontalPodAutoscalerObject struct{}
+
+//nolint:lll
+func (*HorizontalPodAutoscalerObject) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: hpaObjectClass,
+		Desc: "The object of the Kubernetes HorizontalPodAutoscaler.",
+		Cat:  point.Object,
+		Tags: map[string]interface{}{
+			"name":                         inputs.NewTagInfo("The UID of HorizontalPodAutoscaler."),
+			"uid":                          inputs.NewTagInfo("The UID of HorizontalPodAutoscaler."),
+			"horizontalpodautoscaler_name": inputs.NewTagInfo("Name must be unique within a namespace."),
+			"namespace":                    inputs.NewTagInfo("Namespace defines the space within each name must be unique."),
+			"scale_target_kind":            inputs.NewTagInfo("The kind of the scaled resource, e.g. `Deployment`."),
+			"scale_target_name":            inputs.NewTagInfo("The name of the scaled resource."),
+			"cluster_name_k8s":             inputs.NewTagInfo("K8s cluster name(default is `default`). We can rename it in datakit.yaml on ENV_CLUSTER_NAME_K8S."),
+		},
+		Fields: map[string]interface{}{
+			"age":              &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.DurationSecond, Desc: "Age (seconds)"},
+			"replicas_current": &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Current number of replicas of pods managed by this autoscaler."},
+			"replicas_desired": &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Desired number of replicas of pods managed by this autoscaler."},
+			"replicas_min":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "The lower limit for the number of replicas to which the autoscaler can scale down."},
+			"replicas_max":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "The upper limit for the number of replicas to which the autoscaler can scale up."},
+			"last_scale_time":  &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.TimestampSec, Desc: "The last time the autoscaler scaled the number of pods."},
+			"able_to_scale":    &inputs.FieldInfo{DataType: inputs.Bool, Unit: inputs.UnknownUnit, Desc: "Whether the autoscaler is able to fetch and update scales."},
+			"scaling_active":   &inputs.FieldInfo{DataType: inputs.Bool, Unit: inputs.UnknownUnit, Desc: "Whether the autoscaler is able to calculate the desired scales."},
+			"scaling_limited":  &inputs.FieldInfo{DataType: inputs.Bool, Unit: inputs.UnknownUnit, Desc: "Whether the desired scale is capped by the min/max replicas."},
+			"message":          &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "Object details"},
+		},
+	}
+}
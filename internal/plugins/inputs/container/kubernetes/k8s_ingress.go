@@ -0,0 +1,281 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/changes"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/container/pointutil"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/ntp"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+
+	apinetworkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	ingressType              = "Ingress"
+	ingressObjectClass       = "kubernetes_ingresses"
+	ingressObjectResourceKey = "ingress_name"
+)
+
+//nolint:gochecknoinits
+func init() {
+	registerResource("ingress", false, newIngress)
+}
+
+type ingress struct {
+	client k8sClient
+	cfg    *Config
+}
+
+func newIngress(client k8sClient, cfg *Config) resource {
+	return &ingress{client: client, cfg: cfg}
+}
+
+func (*ingress) gatherMetric(_ context.Context, _ int64) { /* nil */ }
+
+func (i *ingress) gatherObject(ctx context.Context) {
+	var continued string
+	for {
+		list, err := i.client.GetIngress(allNamespaces).List(ctx, newListOptions(emptyFieldSelector, continued))
+		if err != nil {
+			klog.Warn(err)
+			break
+		}
+		continued = list.Continue
+
+		pts := i.buildObjectPoints(list)
+		feedObject("k8s-ingress-object", i.cfg.Feeder, pts, true)
+
+		if continued == "" {
+			break
+		}
+	}
+}
+
+func (i *ingress) addChangeInformer(informerFactory informers.SharedInformerFactory) {
+	informer := informerFactory.Networking().V1().Ingresses()
+	if informer == nil {
+		klog.Warn("cannot get ingress informer")
+		return
+	}
+
+	addFunc := func(newObj interface{}) {
+		obj, ok := newObj.(*apinetworkingv1.Ingress)
+		if !ok {
+			klog.Warnf("converting to Ingress object failed, %v", newObj)
+			return
+		}
+		if obj.CreationTimestamp.After(controllerStartTime) {
+			diffs := createNoChangedFieldDiffs(changes.IngressCreate, obj.Namespace, ingressType, obj.Name)
+			objectChangeCountVec.WithLabelValues(ingressType, "create").Inc()
+			processChange(i.cfg, ingressObjectClass, ingressObjectResourceKey, diffs, obj)
+		}
+	}
+
+	deleteFunc := func(oldObj interface{}) {
+		obj, ok := oldObj.(*apinetworkingv1.Ingress)
+		if !ok {
+			klog.Warnf("converting to Ingress object failed, %v", oldObj)
+			return
+		}
+
+		diffs := createNoChangedFieldDiffs(changes.IngressDelete, obj.Namespace, ingressType, obj.Name)
+		objectChangeCountVec.WithLabelValues(ingressType, "delete").Inc()
+		processChange(i.cfg, ingressObjectClass, ingressObjectResourceKey, diffs, obj)
+	}
+
+	updateFunc := func(oldObj, newObj interface{}) {
+		objectChangeCountVec.WithLabelValues(ingressType, "update").Inc()
+
+		oldIngressObj, ok := oldObj.(*apinetworkingv1.Ingress)
+		if !ok {
+			klog.Warnf("converting to Ingress object failed, %v", oldObj)
+			return
+		}
+
+		newIngressObj, ok := newObj.(*apinetworkingv1.Ingress)
+		if !ok {
+			klog.Warnf("converting to Ingress object failed, %v", newObj)
+			return
+		}
+
+		diffs := compareIngress(oldIngressObj, newIngressObj)
+		if len(diffs) != 0 {
+			objectChangeCountVec.WithLabelValues(ingressType, "spec-changed").Inc()
+			processChange(i.cfg, ingressObjectClass, ingressObjectResourceKey, diffs, newIngressObj)
+		}
+	}
+
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(newObj interface{}) {
+			addFunc(newObj)
+		},
+		DeleteFunc: func(oldObj interface{}) {
+			deleteFunc(oldObj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			updateFunc(oldObj, newObj)
+		},
+	})
+}
+
+func (i *ingress) buildObjectPoints(list *apinetworkingv1.IngressList) []*point.Point {
+	var pts []*point.Point
+	opts := append(point.DefaultObjectOptions(), point.WithTime(ntp.Now()))
+
+	for idx, item := range list.Items {
+		var kvs point.KVs
+
+		kvs = kvs.AddTag("name", string(item.UID))
+		kvs = kvs.AddTag("uid", string(item.UID))
+		kvs = kvs.AddTag(ingressObjectResourceKey, item.Name)
+		kvs = kvs.AddTag("namespace", item.Namespace)
+
+		if item.Spec.IngressClassName != nil {
+			kvs = kvs.AddTag("ingress_class_name", *item.Spec.IngressClassName)
+		}
+
+		kvs = kvs.Add("age", time.Since(item.CreationTimestamp.Time).Milliseconds()/1e3)
+		kvs = kvs.Add("rules", len(item.Spec.Rules))
+		kvs = kvs.Add("hosts", strings.Join(ingressHosts(&list.Items[idx]), ","))
+		kvs = kvs.Add("tls_hosts", strings.Join(ingressTLSHosts(&list.Items[idx]), ","))
+		kvs = kvs.Add("load_balancer", strings.Join(ingressLoadBalancers(&list.Items[idx]), ","))
+
+		if yamlStr := getCleanYAML(&list.Items[idx]); yamlStr != "" {
+			kvs = kvs.Add("yaml", yamlStr)
+		}
+		kvs = kvs.Add("annotations", pointutil.MapToJSON(filterAnnotations(item.Annotations)))
+		kvs = append(kvs, pointutil.ConvertDFLabels(item.Labels)...)
+
+		msg := pointutil.PointKVsToJSON(kvs)
+		kvs = kvs.Add("message", pointutil.TrimString(msg, maxMessageLength))
+
+		kvs = kvs.Del("annotations")
+		kvs = kvs.Del("yaml")
+
+		kvs = append(kvs, pointutil.ExtractSourceCodeFromAnnotations(item.Annotations)...) // add source_code
+		kvs = append(kvs, pointutil.LabelsToPointKVs(item.Labels, i.cfg.LabelAsTagsForNonMetric.All, i.cfg.LabelAsTagsForNonMetric.Keys)...)
+		kvs = append(kvs, point.NewTags(i.cfg.ExtraTags)...)
+		pt := point.NewPoint(ingressObjectClass, kvs, opts...)
+		pts = append(pts, pt)
+	}
+
+	return pts
+}
+
+func ingressHosts(item *apinetworkingv1.Ingress) []string {
+	var hosts []string
+	for _, rule := range item.Spec.Rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+func ingressTLSHosts(item *apinetworkingv1.Ingress) []string {
+	var hosts []string
+	for _, tls := range item.Spec.TLS {
+		hosts = append(hosts, tls.Hosts...)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+func ingressLoadBalancers(item *apinetworkingv1.Ingress) []string {
+	var res []string
+	for _, lb := range item.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			res = append(res, lb.IP)
+		}
+		if lb.Hostname != "" {
+			res = append(res, lb.Hostname)
+		}
+	}
+	return res
+}
+
+// ingressRulesToMap converts the rules and default backend to map, the key is
+// the host and path, such as `example.com/api`, the value is the backend.
+func ingressRulesToMap(spec *apinetworkingv1.IngressSpec) map[string]string {
+	res := make(map[string]string)
+
+	if spec.DefaultBackend != nil {
+		res["<default>"] = ingressBackendToString(spec.DefaultBackend)
+	}
+
+	for _, rule := range spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for idx := range rule.HTTP.Paths {
+			path := &rule.HTTP.Paths[idx]
+			res[rule.Host+path.Path] = ingressBackendToString(&path.Backend)
+		}
+	}
+
+	return res
+}
+
+func ingressBackendToString(backend *apinetworkingv1.IngressBackend) string {
+	if backend.Service != nil {
+		if backend.Service.Port.Name != "" {
+			return fmt.Sprintf("service/%s:%s", backend.Service.Name, backend.Service.Port.Name)
+		}
+		return fmt.Sprintf("service/%s:%d", backend.Service.Name, backend.Service.Port.Number)
+	}
+	if backend.Resource != nil {
+		return fmt.Sprintf("%s/%s", backend.Resource.Kind, backend.Resource.Name)
+	}
+	return ""
+}
+
+func compareIngress(oldVal, newVal *apinetworkingv1.Ingress) []FieldDiff {
+	var res []FieldDiff
+	res = append(res, compareLabels(changes.IngressLabels, &(oldVal.ObjectMeta), &(newVal.ObjectMeta))...)
+	res = append(res, compareAnnotations(changes.IngressAnnotations, &(oldVal.ObjectMeta), &(newVal.ObjectMeta))...)
+	res = append(res, compareMaps(changes.IngressRules, ingressRulesToMap(&oldVal.Spec), ingressRulesToMap(&newVal.Spec))...)
+
+	fillOwnerInfoForDiffs(res, newVal.Namespace, ingressType, newVal.Name)
+	return res
+}
+
+type IngressObject struct{}
+
+//nolint:lll
+func (*IngressObject) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: ingressObjectClass,
+		Desc: "The object of the Kubernetes Ingress.",
+		Cat:  point.Object,
+		Tags: map[string]interface{}{
+			"name":               inputs.NewTagInfo("The UID of Ingress."),
+			"uid":                inputs.NewTagInfo("The UID of Ingress."),
+			"ingress_name":       inputs.NewTagInfo("Name must be unique within a namespace."),
+			"ingress_class_name": inputs.NewTagInfo("The name of the IngressClass cluster resource."),
+			"namespace":          inputs.NewTagInfo("Namespace defines the space within each name must be unique."),
+			"cluster_name_k8s":   inputs.NewTagInfo("K8s cluster name(default is `default`). We can rename it in datakit.yaml on ENV_CLUSTER_NAME_K8S."),
+		},
+		Fields: map[string]interface{}{
+			"age":           &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.DurationSecond, Desc: "Age (seconds)"},
+			"rules":         &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "The number of host rules."},
+			"hosts":         &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "The hosts of the rules, separated by comma."},
+			"tls_hosts":     &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "The hosts included in the TLS certificates, separated by comma."},
+			"load_balancer": &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "The IPs or hostnames of the load-balancer, separated by comma."},
+			"message":       &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "Object details"},
+		},
+	}
+}
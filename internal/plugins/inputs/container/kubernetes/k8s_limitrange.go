@@ -0,0 +1,226 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/changes"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/container/pointutil"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/ntp"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+
+	apicorev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	limitrangeType              = "LimitRange"
+	limitrangeObjectClass       = "kubernetes_limitranges"
+	limitrangeObjectResourceKey = "limitrange_name"
+)
+
+//nolint:gochecknoinits
+func init() {
+	registerResource("limitrange", false, newLimitrange)
+}
+
+type limitrange struct {
+	client k8sClient
+	cfg    *Config
+}
+
+func newLimitrange(client k8sClient, cfg *Config) resource {
+	return &limitrange{client: client, cfg: cfg}
+}
+
+func (*limitrange) gatherMetric(_ context.Context, _ int64) { /* nil */ }
+
+func (r *limitrange) gatherObject(ctx context.Context) {
+	var continued string
+	for {
+		list, err := r.client.GetLimitRanges(allNamespaces).List(ctx, newListOptions(emptyFieldSelector, continued))
+		if err != nil {
+			klog.Warn(err)
+			break
+		}
+		continued = list.Continue
+
+		pts := r.buildObjectPoints(list)
+		feedObject("k8s-limitrange-object", r.cfg.Feeder, pts, true)
+
+		if continued == "" {
+			break
+		}
+	}
+}
+
+func (r *limitrange) addChangeInformer(informerFactory informers.SharedInformerFactory) {
+	informer := informerFactory.Core().V1().LimitRanges()
+	if informer == nil {
+		klog.Warn("cannot get limitrange informer")
+		return
+	}
+
+	addFunc := func(newObj interface{}) {
+		obj, ok := newObj.(*apicorev1.LimitRange)
+		if !ok {
+			klog.Warnf("converting to LimitRange object failed, %v", newObj)
+			return
+		}
+		if obj.CreationTimestamp.After(controllerStartTime) {
+			diffs := createNoChangedFieldDiffs(changes.LimitRangeCreate, obj.Namespace, limitrangeType, obj.Name)
+			objectChangeCountVec.WithLabelValues(limitrangeType, "create").Inc()
+			processChange(r.cfg, limitrangeObjectClass, limitrangeObjectResourceKey, diffs, obj)
+		}
+	}
+
+	deleteFunc := func(oldObj interface{}) {
+		obj, ok := oldObj.(*apicorev1.LimitRange)
+		if !ok {
+			klog.Warnf("converting to LimitRange object failed, %v", oldObj)
+			return
+		}
+
+		diffs := createNoChangedFieldDiffs(changes.LimitRangeDelete, obj.Namespace, limitrangeType, obj.Name)
+		objectChangeCountVec.WithLabelValues(limitrangeType, "delete").Inc()
+		processChange(r.cfg, limitrangeObjectClass, limitrangeObjectResourceKey, diffs, obj)
+	}
+
+	updateFunc := func(oldObj, newObj interface{}) {
+		objectChangeCountVec.WithLabelValues(limitrangeType, "update").Inc()
+
+		oldLimitRangeObj, ok := oldObj.(*apicorev1.LimitRange)
+		if !ok {
+			klog.Warnf("converting to LimitRange object failed, %v", oldObj)
+			return
+		}
+
+		newLimitRangeObj, ok := newObj.(*apicorev1.LimitRange)
+		if !ok {
+			klog.Warnf("converting to LimitRange object failed, %v", newObj)
+			return
+		}
+
+		diffs := compareLimitRange(oldLimitRangeObj, newLimitRangeObj)
+		if len(diffs) != 0 {
+			objectChangeCountVec.WithLabelValues(limitrangeType, "spec-changed").Inc()
+			processChange(r.cfg, limitrangeObjectClass, limitrangeObjectResourceKey, diffs, newLimitRangeObj)
+		}
+	}
+
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(newObj interface{}) {
+			addFunc(newObj)
+		},
+		DeleteFunc: func(oldObj interface{}) {
+			deleteFunc(oldObj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			updateFunc(oldObj, newObj)
+		},
+	})
+}
+
+func (r *limitrange) buildObjectPoints(list *apicorev1.LimitRangeList) []*point.Point {
+	var pts []*point.Point
+	opts := append(point.DefaultObjectOptions(), point.WithTime(ntp.Now()))
+
+	for idx, item := range list.Items {
+		var kvs point.KVs
+
+		kvs = kvs.AddTag("name", string(item.UID))
+		kvs = kvs.AddTag("uid", string(item.UID))
+		kvs = kvs.AddTag(limitrangeObjectResourceKey, item.Name)
+		kvs = kvs.AddTag("namespace", item.Namespace)
+
+		kvs = kvs.Add("age", time.Since(item.CreationTimestamp.Time).Milliseconds()/1e3)
+
+		limits := []string{}
+		for k, v := range limitRangeToMap(&list.Items[idx].Spec) {
+			limits = append(limits, k+"="+v)
+		}
+		sort.Strings(limits)
+		kvs = kvs.Add("limits", strings.Join(limits, ","))
+
+		if yamlStr := getCleanYAML(&list.Items[idx]); yamlStr != "" {
+			kvs = kvs.Add("yaml", yamlStr)
+		}
+		kvs = kvs.Add("annotations", pointutil.MapToJSON(filterAnnotations(item.Annotations)))
+		kvs = append(kvs, pointutil.ConvertDFLabels(item.Labels)...)
+
+		msg := pointutil.PointKVsToJSON(kvs)
+		kvs = kvs.Add("message", pointutil.TrimString(msg, maxMessageLength))
+
+		kvs = kvs.Del("annotations")
+		kvs = kvs.Del("yaml")
+
+		kvs = append(kvs, pointutil.LabelsToPointKVs(item.Labels, r.cfg.LabelAsTagsForNonMetric.All, r.cfg.LabelAsTagsForNonMetric.Keys)...)
+		kvs = append(kvs, point.NewTags(r.cfg.ExtraTags)...)
+		pt := point.NewPoint(limitrangeObjectClass, kvs, opts...)
+		pts = append(pts, pt)
+	}
+
+	return pts
+}
+
+// limitRangeToMap converts the limits to map, the key is `<type>.<kind>.<resource>`,
+// such as `Container.max.cpu`.
+func limitRangeToMap(spec *apicorev1.LimitRangeSpec) map[string]string {
+	res := make(map[string]string)
+
+	merge := func(prefix string, resources apicorev1.ResourceList) {
+		for k, v := range resourceListToMap(prefix, resources) {
+			res[k] = v
+		}
+	}
+
+	for _, item := range spec.Limits {
+		prefix := string(item.Type) + "."
+		merge(prefix+"max.", item.Max)
+		merge(prefix+"min.", item.Min)
+		merge(prefix+"default.", item.Default)
+		merge(prefix+"defaultRequest.", item.DefaultRequest)
+		merge(prefix+"maxLimitRequestRatio.", item.MaxLimitRequestRatio)
+	}
+
+	return res
+}
+
+func compareLimitRange(oldVal, newVal *apicorev1.LimitRange) []FieldDiff {
+	res := compareMaps(changes.LimitRangeLimits, limitRangeToMap(&oldVal.Spec), limitRangeToMap(&newVal.Spec))
+
+	fillOwnerInfoForDiffs(res, newVal.Namespace, limitrangeType, newVal.Name)
+	return res
+}
+
+type LimitRangeObject struct{}
+
+//nolint:lll
+func (*LimitRangeObject) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: limitrangeObjectClass,
+		Desc: "The object of the Kubernetes LimitRange.",
+		Cat:  point.Object,
+		Tags: map[string]interface{}{
+			"name":             inputs.NewTagInfo("The UID of LimitRange."),
+			"uid":              inputs.NewTagInfo("The UID of LimitRange."),
+			"limitrange_name":  inputs.NewTagInfo("Name must be unique within a namespace."),
+			"namespace":        inputs.NewTagInfo("Namespace defines the space within each name must be unique."),
+			"cluster_name_k8s": inputs.NewTagInfo("K8s cluster name(default is `default`). We can rename it in datakit.yaml on ENV_CLUSTER_NAME_K8S."),
+		},
+		Fields: map[string]interface{}{
+			"age":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.DurationSecond, Desc: "Age (seconds)"},
+			"limits":  &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "The limits enforced, e.g. `Container.default.cpu=500m,Container.max.cpu=2`."},
+			"message": &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "Object details"},
+		},
+	}
+}
@@ -0,0 +1,197 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/changes"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/container/pointutil"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/ntp"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+
+	apicorev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	namespaceType              = "Namespace"
+	namespaceObjectClass       = "kubernetes_namespaces"
+	namespaceObjectResourceKey = "namespace_name"
+)
+
+//nolint:gochecknoinits
+func init() {
+	registerResource("namespace", false, newNamespace)
+}
+
+type namespace struct {
+	client k8sClient
+	cfg    *Config
+}
+
+func newNamespace(client k8sClient, cfg *Config) resource {
+	return &namespace{client: client, cfg: cfg}
+}
+
+func (*namespace) gatherMetric(_ context.Context, _ int64) { /* nil */ }
+
+func (n *namespace) gatherObject(ctx context.Context) {
+	var continued string
+	for {
+		list, err := n.client.GetNamespaces().List(ctx, newListOptions(emptyFieldSelector, continued))
+		if err != nil {
+			klog.Warn(err)
+			break
+		}
+		continued = list.Continue
+
+		pts := n.buildObjectPoints(list)
+		feedObject("k8s-namespace-object", n.cfg.Feeder, pts, true)
+
+		if continued == "" {
+			break
+		}
+	}
+}
+
+func (n *namespace) addChangeInformer(informerFactory informers.SharedInformerFactory) {
+	informer := informerFactory.Core().V1().Namespaces()
+	if informer == nil {
+		klog.Warn("cannot get namespace informer")
+		return
+	}
+
+	addFunc := func(newObj interface{}) {
+		obj, ok := newObj.(*apicorev1.Namespace)
+		if !ok {
+			klog.Warnf("converting to Namespace object failed, %v", newObj)
+			return
+		}
+		if obj.CreationTimestamp.After(controllerStartTime) {
+			diffs := createNoChangedFieldDiffs(changes.NamespaceCreate, obj.Name, namespaceType, obj.Name)
+			objectChangeCountVec.WithLabelValues(namespaceType, "create").Inc()
+			processChange(n.cfg, namespaceObjectClass, namespaceObjectResourceKey, diffs, obj)
+		}
+	}
+
+	deleteFunc := func(oldObj interface{}) {
+		obj, ok := oldObj.(*apicorev1.Namespace)
+		if !ok {
+			klog.Warnf("converting to Namespace object failed, %v", oldObj)
+			return
+		}
+
+		diffs := createNoChangedFieldDiffs(changes.NamespaceDelete, obj.Name, namespaceType, obj.Name)
+		objectChangeCountVec.WithLabelValues(namespaceType, "delete").Inc()
+		processChange(n.cfg, namespaceObjectClass, namespaceObjectResourceKey, diffs, obj)
+	}
+
+	updateFunc := func(oldObj, newObj interface{}) {
+		objectChangeCountVec.WithLabelValues(namespaceType, "update").Inc()
+
+		oldNamespaceObj, ok := oldObj.(*apicorev1.Namespace)
+		if !ok {
+			klog.Warnf("converting to Namespace object failed, %v", oldObj)
+			return
+		}
+
+		newNamespaceObj, ok := newObj.(*apicorev1.Namespace)
+		if !ok {
+			klog.Warnf("converting to Namespace object failed, %v", newObj)
+			return
+		}
+
+		diffs := compareNamespace(oldNamespaceObj, newNamespaceObj)
+		if len(diffs) != 0 {
+			objectChangeCountVec.WithLabelValues(namespaceType, "spec-changed").Inc()
+			processChange(n.cfg, namespaceObjectClass, namespaceObjectResourceKey, diffs, newNamespaceObj)
+		}
+	}
+
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(newObj interface{}) {
+			addFunc(newObj)
+		},
+		DeleteFunc: func(oldObj interface{}) {
+			deleteFunc(oldObj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			updateFunc(oldObj, newObj)
+		},
+	})
+}
+
+func (n *namespace) buildObjectPoints(list *apicorev1.NamespaceList) []*point.Point {
+	var pts []*point.Point
+	opts := append(point.DefaultObjectOptions(), point.WithTime(ntp.Now()))
+
+	for idx, item := range list.Items {
+		var kvs point.KVs
+
+		kvs = kvs.AddTag("name", string(item.UID))
+		kvs = kvs.AddTag("uid", string(item.UID))
+		kvs = kvs.AddTag(namespaceObjectResourceKey, item.Name)
+		kvs = kvs.AddTag("namespace", item.Name)
+		kvs = kvs.AddTag("phase", string(item.Status.Phase))
+
+		kvs = kvs.Add("age", time.Since(item.CreationTimestamp.Time).Milliseconds()/1e3)
+
+		if yamlStr := getCleanYAML(&list.Items[idx]); yamlStr != "" {
+			kvs = kvs.Add("yaml", yamlStr)
+		}
+		kvs = kvs.Add("annotations", pointutil.MapToJSON(filterAnnotations(item.Annotations)))
+		kvs = append(kvs, pointutil.ConvertDFLabels(item.Labels)...)
+
+		msg := pointutil.PointKVsToJSON(kvs)
+		kvs = kvs.Add("message", pointutil.TrimString(msg, maxMessageLength))
+
+		kvs = kvs.Del("annotations")
+		kvs = kvs.Del("yaml")
+
+		kvs = append(kvs, pointutil.LabelsToPointKVs(item.Labels, n.cfg.LabelAsTagsForNonMetric.All, n.cfg.LabelAsTagsForNonMetric.Keys)...)
+		kvs = append(kvs, point.NewTags(n.cfg.ExtraTags)...)
+		pt := point.NewPoint(namespaceObjectClass, kvs, opts...)
+		pts = append(pts, pt)
+	}
+
+	return pts
+}
+
+func compareNamespace(oldVal, newVal *apicorev1.Namespace) []FieldDiff {
+	var res []FieldDiff
+	res = append(res, compareLabels(changes.NamespaceLabels, &(oldVal.ObjectMeta), &(newVal.ObjectMeta))...)
+	res = append(res, compareAnnotations(changes.NamespaceAnnotations, &(oldVal.ObjectMeta), &(newVal.ObjectMeta))...)
+
+	fillOwnerInfoForDiffs(res, newVal.Name, namespaceType, newVal.Name)
+	return res
+}
+
+type NamespaceObject struct{}
+
+//nolint:lll
+func (*NamespaceObject) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: namespaceObjectClass,
+		Desc: "The object of the Kubernetes Namespace.",
+		Cat:  point.Object,
+		Tags: map[string]interface{}{
+			"name":             inputs.NewTagInfo("The UID of Namespace."),
+			"uid":              inputs.NewTagInfo("The UID of Namespace."),
+			"namespace_name":   inputs.NewTagInfo("Name of the Namespace."),
+			"namespace":        inputs.NewTagInfo("Name of the Namespace, same as `namespace_name`."),
+			"phase":            inputs.NewTagInfo("The current lifecycle phase of the namespace.(Active/Terminating)"),
+			"cluster_name_k8s": inputs.NewTagInfo("K8s cluster name(default is `default`). We can rename it in datakit.yaml on ENV_CLUSTER_NAME_K8S."),
+		},
+		Fields: map[string]interface{}{
+			"age":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.DurationSecond, Desc: "Age (seconds)"},
+			"message": &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "Object details"},
+		},
+	}
+}
@@ -0,0 +1,308 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/changes"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/container/pointutil"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/ntp"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+
+	apipolicyv1 "k8s.io/api/policy/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	pdbType              = "PodDisruptionBudget"
+	pdbMetricMeasurement = "kube_poddisruptionbudget"
+	pdbObjectClass       = "kubernetes_poddisruptionbudgets"
+	pdbObjectResourceKey = "poddisruptionbudget_name"
+)
+
+//nolint:gochecknoinits
+func init() {
+	registerResource("poddisruptionbudget", false, newPDB)
+}
+
+type pdb struct {
+	client  k8sClient
+	cfg     *Config
+	counter map[string]int
+}
+
+func newPDB(client k8sClient, cfg *Config) resource {
+	return &pdb{client: client, cfg: cfg, counter: make(map[string]int)}
+}
+
+func (p *pdb) gatherMetric(ctx context.Context, timestamp int64) {
+	var continued string
+	for {
+		list, err := p.client.GetPodDisruptionBudgets(allNamespaces).List(ctx, newListOptions(emptyFieldSelector, continued))
+		if err != nil {
+			klog.Warn(err)
+			break
+		}
+		continued = list.Continue
+
+		pts := p.buildMetricPoints(list, timestamp)
+		feedMetric("k8s-poddisruptionbudget-metric", p.cfg.Feeder, pts, true)
+
+		if continued == "" {
+			break
+		}
+	}
+	processCounter(p.cfg, "poddisruptionbudget", p.counter, timestamp)
+}
+
+func (p *pdb) gatherObject(ctx context.Context) {
+	var continued string
+	for {
+		list, err := p.client.GetPodDisruptionBudgets(allNamespaces).List(ctx, newListOptions(emptyFieldSelector, continued))
+		if err != nil {
+			klog.Warn(err)
+			break
+		}
+		continued = list.Continue
+
+		pts := p.buildObjectPoints(list)
+		feedObject("k8s-poddisruptionbudget-object", p.cfg.Feeder, pts, true)
+
+		if continued == "" {
+			break
+		}
+	}
+}
+
+func (p *pdb) addChangeInformer(informerFactory informers.SharedInformerFactory) {
+	informer := informerFactory.Policy().V1().PodDisruptionBudgets()
+	if informer == nil {
+		klog.Warn("cannot get poddisruptionbudget informer")
+		return
+	}
+
+	addFunc := func(newObj interface{}) {
+		obj, ok := newObj.(*apipolicyv1.PodDisruptionBudget)
+		if !ok {
+			klog.Warnf("converting to PodDisruptionBudget object failed, %v", newObj)
+			return
+		}
+		if obj.CreationTimestamp.After(controllerStartTime) {
+			diffs := createNoChangedFieldDiffs(changes.PDBCreate, obj.Namespace, pdbType, obj.Name)
+			objectChangeCountVec.WithLabelValues(pdbType, "create").Inc()
+			processChange(p.cfg, pdbObjectClass, pdbObjectResourceKey, diffs, obj)
+		}
+	}
+
+	deleteFunc := func(oldObj interface{}) {
+		obj, ok := oldObj.(*apipolicyv1.PodDisruptionBudget)
+		if !ok {
+			klog.Warnf("converting to PodDisruptionBudget object failed, %v", oldObj)
+			return
+		}
+
+		diffs := createNoChangedFieldDiffs(changes.PDBDelete, obj.Namespace, pdbType, obj.Name)
+		objectChangeCountVec.WithLabelValues(pdbType, "delete").Inc()
+		processChange(p.cfg, pdbObjectClass, pdbObjectResourceKey, diffs, obj)
+	}
+
+	updateFunc := func(oldObj, newObj interface{}) {
+		objectChangeCountVec.WithLabelValues(pdbType, "update").Inc()
+
+		oldPDBObj, ok := oldObj.(*apipolicyv1.PodDisruptionBudget)
+		if !ok {
+			klog.Warnf("converting to PodDisruptionBudget object failed, %v", oldObj)
+			return
+		}
+
+		newPDBObj, ok := newObj.(*apipolicyv1.PodDisruptionBudget)
+		if !ok {
+			klog.Warnf("converting to PodDisruptionBudget object failed, %v", newObj)
+			return
+		}
+
+		diffs := comparePDB(oldPDBObj, newPDBObj)
+		if len(diffs) != 0 {
+			objectChangeCountVec.WithLabelValues(pdbType, "spec-changed").Inc()
+			processChange(p.cfg, pdbObjectClass, pdbObjectResourceKey, diffs, newPDBObj)
+		}
+	}
+
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(newObj interface{}) {
+			addFunc(newObj)
+		},
+		DeleteFunc: func(oldObj interface{}) {
+			deleteFunc(oldObj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			updateFunc(oldObj, newObj)
+		},
+	})
+}
+
+func (p *pdb) buildMetricPoints(list *apipolicyv1.PodDisruptionBudgetList, timestamp int64) []*point.Point {
+	var pts []*point.Point
+	opts := append(point.DefaultMetricOptions(), point.WithTimestamp(timestamp))
+
+	for _, item := range list.Items {
+		var kvs point.KVs
+
+		kvs = kvs.AddTag("uid", string(item.UID))
+		kvs = kvs.AddTag("poddisruptionbudget", item.Name)
+		kvs = kvs.AddTag("namespace", item.Namespace)
+
+		kvs = kvs.Add("disruptions_allowed", item.Status.DisruptionsAllowed)
+		kvs = kvs.Add("current_healthy", item.Status.CurrentHealthy)
+		kvs = kvs.Add("desired_healthy", item.Status.DesiredHealthy)
+		kvs = kvs.Add("expected_pods", item.Status.ExpectedPods)
+
+		kvs = append(kvs, pointutil.LabelsToPointKVs(item.Labels, p.cfg.LabelAsTagsForMetric.All, p.cfg.LabelAsTagsForMetric.Keys)...)
+		kvs = append(kvs, point.NewTags(p.cfg.ExtraTags)...)
+		pt := point.NewPoint(pdbMetricMeasurement, kvs, opts...)
+		pts = append(pts, pt)
+
+		p.counter[item.Namespace]++
+	}
+
+	return pts
+}
+
+func (p *pdb) buildObjectPoints(list *apipolicyv1.PodDisruptionBudgetList) []*point.Point {
+	var pts []*point.Point
+	opts := append(point.DefaultObjectOptions(), point.WithTime(ntp.Now()))
+
+	for idx, item := range list.Items {
+		var kvs point.KVs
+
+		kvs = kvs.AddTag("name", string(item.UID))
+		kvs = kvs.AddTag("uid", string(item.UID))
+		kvs = kvs.AddTag(pdbObjectResourceKey, item.Name)
+		kvs = kvs.AddTag("namespace", item.Namespace)
+
+		kvs = kvs.Add("age", time.Since(item.CreationTimestamp.Time).Milliseconds()/1e3)
+		kvs = kvs.Add("disruptions_allowed", item.Status.DisruptionsAllowed)
+		kvs = kvs.Add("current_healthy", item.Status.CurrentHealthy)
+		kvs = kvs.Add("desired_healthy", item.Status.DesiredHealthy)
+		kvs = kvs.Add("expected_pods", item.Status.ExpectedPods)
+
+		if item.Spec.MinAvailable != nil {
+			kvs = kvs.Add("min_available", item.Spec.MinAvailable.String())
+		}
+		if item.Spec.MaxUnavailable != nil {
+			kvs = kvs.Add("max_unavailable", item.Spec.MaxUnavailable.String())
+		}
+
+		if yamlStr := getCleanYAML(&list.Items[idx]); yamlStr != "" {
+			kvs = kvs.Add("yaml", yamlStr)
+		}
+		kvs = kvs.Add("annotations", pointutil.MapToJSON(filterAnnotations(item.Annotations)))
+		kvs = append(kvs, pointutil.ConvertDFLabels(item.Labels)...)
+
+		msg := pointutil.PointKVsToJSON(kvs)
+		kvs = kvs.Add("message", pointutil.TrimString(msg, maxMessageLength))
+
+		kvs = kvs.Del("annotations")
+		kvs = kvs.Del("yaml")
+
+		// message 不包含 Selector 和 Labels
+		if item.Spec.Selector != nil {
+			kvs = append(kvs, point.NewTags(item.Spec.Selector.MatchLabels)...)
+		}
+
+		kvs = append(kvs, pointutil.LabelsToPointKVs(item.Labels, p.cfg.LabelAsTagsForNonMetric.All, p.cfg.LabelAsTagsForNonMetric.Keys)...)
+		kvs = append(kvs, point.NewTags(p.cfg.ExtraTags)...)
+		pt := point.NewPoint(pdbObjectClass, kvs, opts...)
+		pts = append(pts, pt)
+	}
+
+	return pts
+}
+
+// pdbBudget returns the budget of the PDB, such as `minAvailable=50%`.
+func pdbBudget(spec *apipolicyv1.PodDisruptionBudgetSpec) string {
+	switch {
+	case spec.MinAvailable != nil:
+		return fmt.Sprintf("minAvailable=%s", spec.MinAvailable)
+	case spec.MaxUnavailable != nil:
+		return fmt.Sprintf("maxUnavailable=%s", spec.MaxUnavailable)
+	default:
+		return ""
+	}
+}
+
+func comparePDB(oldVal, newVal *apipolicyv1.PodDisruptionBudget) []FieldDiff {
+	var res []FieldDiff
+
+	if oldBudget, newBudget := pdbBudget(&oldVal.Spec), pdbBudget(&newVal.Spec); oldBudget != newBudget {
+		res = append(res, FieldDiff{
+			ChangeID: changes.PDBBudget,
+			OldValue: oldBudget,
+			NewValue: newBudget,
+			DiffText: formatAsDiffLines("budget", oldBudget, newBudget),
+		})
+	}
+
+	fillOwnerInfoForDiffs(res, newVal.Namespace, pdbType, newVal.Name)
+	return res
+}
+
+type PodDisruptionBudgetMetric struct{}
+
+//nolint:lll
+func (*PodDisruptionBudgetMetric) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: pdbMetricMeasurement,
+		Desc: "The metric of the Kubernetes PodDisruptionBudget.",
+		Cat:  point.Metric,
+		Tags: map[string]interface{}{
+			"uid":                 inputs.NewTagInfo("The UID of PodDisruptionBudget."),
+			"poddisruptionbudget": inputs.NewTagInfo("Name must be unique within a namespace."),
+			"namespace":           inputs.NewTagInfo("Namespace defines the space within each name must be unique."),
+			"cluster_name_k8s":    inputs.NewTagInfo("K8s cluster name(default is `default`). We can rename it in datakit.yaml on ENV_CLUSTER_NAME_K8S."),
+		},
+		Fields: map[string]interface{}{
+			"disruptions_allowed": &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Number of pod disruptions that are currently allowed."},
+			"current_healthy":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Current number of healthy pods."},
+			"desired_healthy":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Minimum desired number of healthy pods."},
+			"expected_pods":       &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Total number of pods counted by this disruption budget."},
+		},
+	}
+}
+
+type PodDisruptionBudgetObject struct{}
+
+//nolint:lll
+func (*PodDisruptionBudgetObject) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: pdbObjectClass,
+		Desc: "The object of the Kubernetes PodDisruptionBudget.",
+		Cat:  point.Object,
+		Tags: map[string]interface{}{
+			"name":                              inputs.NewTagInfo("The UID of PodDisruptionBudget."),
+			"uid":                               inputs.NewTagInfo("The UID of PodDisruptionBudget."),
+			"poddisruptionbudget_name":          inputs.NewTagInfo("Name must be unique within a namespace."),
+			"namespace":                         inputs.NewTagInfo("Namespace defines the space within each name must be unique."),
+			"cluster_name_k8s":                  inputs.NewTagInfo("K8s cluster name(default is `default`). We can rename it in datakit.yaml on ENV_CLUSTER_NAME_K8S."),
+			"&lt;ALL-SELECTOR-MATCH-LABELS&gt;": inputs.NewTagInfo("Represents the selector.matchLabels for Kubernetes resources"),
+		},
+		Fields: map[string]interface{}{
+			"age":                 &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.DurationSecond, Desc: "Age (seconds)"},
+			"disruptions_allowed": &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Number of pod disruptions that are currently allowed."},
+			"current_healthy":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Current number of healthy pods."},
+			"desired_healthy":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Minimum desired number of healthy pods."},
+			"expected_pods":       &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.NCount, Desc: "Total number of pods counted by this disruption budget."},
+			"min_available":       &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "The number or percentage of pods that must still be available after the eviction."},
+			"max_unavailable":     &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "The number or percentage of pods that can be unavailable after the eviction."},
+			"message":             &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "Object details"},
+		},
+	}
+}
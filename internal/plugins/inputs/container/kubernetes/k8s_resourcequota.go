@@ -0,0 +1,296 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/changes"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/container/pointutil"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/ntp"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+
+	apicorev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	resourcequotaType              = "ResourceQuota"
+	resourcequotaMetricMeasurement = "kube_resourcequota"
+	resourcequotaObjectClass       = "kubernetes_resourcequotas"
+	resourcequotaObjectResourceKey = "resourcequota_name"
+)
+
+//nolint:gochecknoinits
+func init() {
+	registerResource("resourcequota", false, newResourcequota)
+}
+
+type resourcequota struct {
+	client k8sClient
+	cfg    *Config
+}
+
+func newResourcequota(client k8sClient, cfg *Config) resource {
+	return &resourcequota{client: client, cfg: cfg}
+}
+
+func (r *resourcequota) gatherMetric(ctx context.Context, timestamp int64) {
+	var continued string
+	for {
+		list, err := r.client.GetResourceQuotas(allNamespaces).List(ctx, newListOptions(emptyFieldSelector, continued))
+		if err != nil {
+			klog.Warn(err)
+			break
+		}
+		continued = list.Continue
+
+		pts := r.buildMetricPoints(list, timestamp)
+		feedMetric("k8s-resourcequota-metric", r.cfg.Feeder, pts, true)
+
+		if continued == "" {
+			break
+		}
+	}
+}
+
+func (r *resourcequota) gatherObject(ctx context.Context) {
+	var continued string
+	for {
+		list, err := r.client.GetResourceQuotas(allNamespaces).List(ctx, newListOptions(emptyFieldSelector, continued))
+		if err != nil {
+			klog.Warn(err)
+			break
+		}
+		continued = list.Continue
+
+		pts := r.buildObjectPoints(list)
+		feedObject("k8s-resourcequota-object", r.cfg.Feeder, pts, true)
+
+		if continued == "" {
+			break
+		}
+	}
+}
+
+func (r *resourcequota) addChangeInformer(informerFactory informers.SharedInformerFactory) {
+	informer := informerFactory.Core().V1().ResourceQuotas()
+	if informer == nil {
+		klog.Warn("cannot get resourcequota informer")
+		return
+	}
+
+	addFunc := func(newObj interface{}) {
+		obj, ok := newObj.(*apicorev1.ResourceQuota)
+		if !ok {
+			klog.Warnf("converting to ResourceQuota object failed, %v", newObj)
+			return
+		}
+		if obj.CreationTimestamp.After(controllerStartTime) {
+			diffs := createNoChangedFieldDiffs(changes.ResourceQuotaCreate, obj.Namespace, resourcequotaType, obj.Name)
+			objectChangeCountVec.WithLabelValues(resourcequotaType, "create").Inc()
+			processChange(r.cfg, resourcequotaObjectClass, resourcequotaObjectResourceKey, diffs, obj)
+		}
+	}
+
+	deleteFunc := func(oldObj interface{}) {
+		obj, ok := oldObj.(*apicorev1.ResourceQuota)
+		if !ok {
+			klog.Warnf("converting to ResourceQuota object failed, %v", oldObj)
+			return
+		}
+
+		diffs := createNoChangedFieldDiffs(changes.ResourceQuotaDelete, obj.Namespace, resourcequotaType, obj.Name)
+		objectChangeCountVec.WithLabelValues(resourcequotaType, "delete").Inc()
+		processChange(r.cfg, resourcequotaObjectClass, resourcequotaObjectResourceKey, diffs, obj)
+	}
+
+	updateFunc := func(oldObj, newObj interface{}) {
+		objectChangeCountVec.WithLabelValues(resourcequotaType, "update").Inc()
+
+		oldQuotaObj, ok := oldObj.(*apicorev1.ResourceQuota)
+		if !ok {
+			klog.Warnf("converting to ResourceQuota object failed, %v", oldObj)
+			return
+		}
+
+		newQuotaObj, ok := newObj.(*apicorev1.ResourceQuota)
+		if !ok {
+			klog.Warnf("converting to ResourceQuota object failed, %v", newObj)
+			return
+		}
+
+		diffs := compareResourceQuota(oldQuotaObj, newQuotaObj)
+		if len(diffs) != 0 {
+			objectChangeCountVec.WithLabelValues(resourcequotaType, "spec-changed").Inc()
+			processChange(r.cfg, resourcequotaObjectClass, resourcequotaObjectResourceKey, diffs, newQuotaObj)
+		}
+	}
+
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(newObj interface{}) {
+			addFunc(newObj)
+		},
+		DeleteFunc: func(oldObj interface{}) {
+			deleteFunc(oldObj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			updateFunc(oldObj, newObj)
+		},
+	})
+}
+
+// buildMetricPoints builds one point for each resource in the quota, such as
+// `requests.cpu` and `pods`. CPU is in cores and memory/storage is in bytes.
+func (r *resourcequota) buildMetricPoints(list *apicorev1.ResourceQuotaList, timestamp int64) []*point.Point {
+	var pts []*point.Point
+	opts := append(point.DefaultMetricOptions(), point.WithTimestamp(timestamp))
+
+	for _, item := range list.Items {
+		for name, hard := range item.Status.Hard {
+			var kvs point.KVs
+
+			kvs = kvs.AddTag("uid", string(item.UID))
+			kvs = kvs.AddTag("resourcequota", item.Name)
+			kvs = kvs.AddTag("namespace", item.Namespace)
+			kvs = kvs.AddTag("resource", string(name))
+
+			hardValue := hard.AsApproximateFloat64()
+			kvs = kvs.Add("hard", hardValue)
+
+			if used, ok := item.Status.Used[name]; ok {
+				usedValue := used.AsApproximateFloat64()
+				kvs = kvs.Add("used", usedValue)
+				if hardValue > 0 {
+					kvs = kvs.Add("used_percent", usedValue/hardValue*100)
+				}
+			}
+
+			kvs = append(kvs, pointutil.LabelsToPointKVs(item.Labels, r.cfg.LabelAsTagsForMetric.All, r.cfg.LabelAsTagsForMetric.Keys)...)
+			kvs = append(kvs, point.NewTags(r.cfg.ExtraTags)...)
+			pt := point.NewPoint(resourcequotaMetricMeasurement, kvs, opts...)
+			pts = append(pts, pt)
+		}
+	}
+
+	return pts
+}
+
+func (r *resourcequota) buildObjectPoints(list *apicorev1.ResourceQuotaList) []*point.Point {
+	var pts []*point.Point
+	opts := append(point.DefaultObjectOptions(), point.WithTime(ntp.Now()))
+
+	for idx, item := range list.Items {
+		var kvs point.KVs
+
+		kvs = kvs.AddTag("name", string(item.UID))
+		kvs = kvs.AddTag("uid", string(item.UID))
+		kvs = kvs.AddTag(resourcequotaObjectResourceKey, item.Name)
+		kvs = kvs.AddTag("namespace", item.Namespace)
+
+		kvs = kvs.Add("age", time.Since(item.CreationTimestamp.Time).Milliseconds()/1e3)
+		kvs = kvs.Add("hard", joinResourceList(item.Status.Hard))
+		kvs = kvs.Add("used", joinResourceList(item.Status.Used))
+
+		scopes := []string{}
+		for _, scope := range item.Spec.Scopes {
+			scopes = append(scopes, string(scope))
+		}
+		sort.Strings(scopes)
+		kvs = kvs.Add("scopes", strings.Join(scopes, ","))
+
+		if yamlStr := getCleanYAML(&list.Items[idx]); yamlStr != "" {
+			kvs = kvs.Add("yaml", yamlStr)
+		}
+		kvs = kvs.Add("annotations", pointutil.MapToJSON(filterAnnotations(item.Annotations)))
+		kvs = append(kvs, pointutil.ConvertDFLabels(item.Labels)...)
+
+		msg := pointutil.PointKVsToJSON(kvs)
+		kvs = kvs.Add("message", pointutil.TrimString(msg, maxMessageLength))
+
+		kvs = kvs.Del("annotations")
+		kvs = kvs.Del("yaml")
+
+		kvs = append(kvs, pointutil.LabelsToPointKVs(item.Labels, r.cfg.LabelAsTagsForNonMetric.All, r.cfg.LabelAsTagsForNonMetric.Keys)...)
+		kvs = append(kvs, point.NewTags(r.cfg.ExtraTags)...)
+		pt := point.NewPoint(resourcequotaObjectClass, kvs, opts...)
+		pts = append(pts, pt)
+	}
+
+	return pts
+}
+
+// joinResourceList returns the sorted resources, such as `limits.cpu=2,pods=10`.
+func joinResourceList(resources apicorev1.ResourceList) string {
+	m := resourceListToMap("", resources)
+
+	res := make([]string, 0, len(m))
+	for k, v := range m {
+		res = append(res, k+"="+v)
+	}
+	sort.Strings(res)
+	return strings.Join(res, ",")
+}
+
+func compareResourceQuota(oldVal, newVal *apicorev1.ResourceQuota) []FieldDiff {
+	res := compareMaps(changes.ResourceQuotaHard, resourceListToMap("", oldVal.Spec.Hard), resourceListToMap("", newVal.Spec.Hard))
+
+	fillOwnerInfoForDiffs(res, newVal.Namespace, resourcequotaType, newVal.Name)
+	return res
+}
+
+type ResourceQuotaMetric struct{}
+
+//nolint:lll
+func (*ResourceQuotaMetric) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: resourcequotaMetricMeasurement,
+		Desc: "The metric of the Kubernetes ResourceQuota, one point for each resource of the quota.",
+		Cat:  point.Metric,
+		Tags: map[string]interface{}{
+			"uid":              inputs.NewTagInfo("The UID of ResourceQuota."),
+			"resourcequota":    inputs.NewTagInfo("Name must be unique within a namespace."),
+			"namespace":        inputs.NewTagInfo("Namespace defines the space within each name must be unique."),
+			"resource":         inputs.NewTagInfo("The name of the resource, e.g. `requests.cpu`, `limits.memory` and `pods`."),
+			"cluster_name_k8s": inputs.NewTagInfo("K8s cluster name(default is `default`). We can rename it in datakit.yaml on ENV_CLUSTER_NAME_K8S."),
+		},
+		Fields: map[string]interface{}{
+			"hard":         &inputs.FieldInfo{DataType: inputs.Float, Unit: inputs.UnknownUnit, Desc: "The enforced hard limit of the resource. CPU is in cores, memory and storage are in bytes."},
+			"used":         &inputs.FieldInfo{DataType: inputs.Float, Unit: inputs.UnknownUnit, Desc: "The current observed total usage of the resource in the namespace."},
+			"used_percent": &inputs.FieldInfo{DataType: inputs.Float, Unit: inputs.Percent, Desc: "The percentage of used to hard."},
+		},
+	}
+}
+
+type ResourceQuotaObject struct{}
+
+//nolint:lll
+func (*ResourceQuotaObject) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: resourcequotaObjectClass,
+		Desc: "The object of the Kubernetes ResourceQuota.",
+		Cat:  point.Object,
+		Tags: map[string]interface{}{
+			"name":               inputs.NewTagInfo("The UID of ResourceQuota."),
+			"uid":                inputs.NewTagInfo("The UID of ResourceQuota."),
+			"resourcequota_name": inputs.NewTagInfo("Name must be unique within a namespace."),
+			"namespace":          inputs.NewTagInfo("Namespace defines the space within each name must be unique."),
+			"cluster_name_k8s":   inputs.NewTagInfo("K8s cluster name(default is `default`). We can rename it in datakit.yaml on ENV_CLUSTER_NAME_K8S."),
+		},
+		Fields: map[string]interface{}{
+			"age":     &inputs.FieldInfo{DataType: inputs.Int, Unit: inputs.DurationSecond, Desc: "Age (seconds)"},
+			"hard":    &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "The enforced hard limits, e.g. `limits.cpu=2,pods=10`."},
+			"used":    &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "The current observed total usage, e.g. `limits.cpu=500m,pods=3`."},
+			"scopes":  &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "The scopes of the quota, separated by comma."},
+			"message": &inputs.FieldInfo{DataType: inputs.String, Unit: inputs.UnknownUnit, Desc: "Object details"},
+		},
+	}
+}
@@ -40,8 +40,9 @@ const (
 	// TraceIDUpper Tag used to propagate the higher-order 64 bits of a 128-bit trace id encoded as a
 	// lower-case hexadecimal string with no zero-padding or `0x` prefix.
 	TraceIDUpper = "_dd.p.tid"
+)
 
-	sampleConfig = `
+var sampleConfig = `
 [[inputs.ddtrace]]
   ## DDTrace Agent endpoints register by version respectively.
   ## Endpoints can be skipped listen by remove them from the list.
@@ -116,7 +117,7 @@ const (
   ## sampling_rate used to set global sampling rate.
   # [inputs.ddtrace.sampler]
   #   sampling_rate = 1.0
-
+` + itrace.AfterGatherExtensionSample(inputName) + `
   # [inputs.ddtrace.tags]
   #   key1 = "value1"
   #   key2 = "value2"
@@ -136,7 +137,6 @@ const (
   #   path = "./ddtrace_storage"
   #   capacity = 5120
 `
-)
 
 var (
 	log                = logger.DefaultSLogger(inputName)
@@ -150,6 +150,8 @@ var (
 )
 
 type Input struct {
+	itrace.AfterGatherExtension
+
 	Path                      string                       `toml:"path,omitempty"`           // deprecated
 	TraceSampleConfs          interface{}                  `toml:"sample_configs,omitempty"` // deprecated []*itrace.TraceSampleConfig
 	TraceSampleConf           interface{}                  `toml:"sample_config"`            // deprecated *itrace.TraceSampleConfig
@@ -197,6 +199,7 @@ func (*Input) SampleMeasurement() []inputs.Measurement {
 		&itrace.TraceMeasurement{Name: inputName},
 		&jvmTelemetry{},
 		&itrace.TracingMetricMeasurement{Source: "ddtrace", Name: "DDTrace"},
+		&itrace.REDMetricMeasurement{Name: "DDTrace"},
 	}
 }
 
@@ -328,6 +331,9 @@ func (ipt *Input) RegHTTPHandler() {
 		afterGather.AppendFilter(sampler.Sample)
 	}
 
+	// add tail sampler as the last filter, and RED metrics derived from all traces before filters
+	afterGatherRun = ipt.SetupAfterGather(inputName, afterGather, ipt.feeder, ipt.Tagger.HostTags())
+
 	log.Debugf("### register handlers %v for %s agent", ipt.Endpoints, inputName)
 	var isReg bool
 	for _, endpoint := range ipt.Endpoints {
@@ -377,6 +383,7 @@ func (ipt *Input) Run() {
 }
 
 func (ipt *Input) exit() {
+	ipt.CloseAfterGather()
 	traceOpts = []point.Option{}
 	if wkpool != nil {
 		wkpool.Shutdown()
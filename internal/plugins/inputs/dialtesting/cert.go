@@ -0,0 +1,230 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package dialtesting
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"time"
+
+	dt "github.com/GuanceCloud/cliutils/dialtesting"
+)
+
+var _ localTaskChild = (*certTask)(nil)
+
+const defaultCertPort = "443"
+
+type certSuccess struct {
+	ResponseTime string `json:"response_time,omitempty"`
+	respTime     time.Duration
+
+	DaysToExpiry []*dt.ValueSuccess `json:"days_to_expiry,omitempty"`
+}
+
+// certTask check the TLS certificate of the server.
+type certTask struct {
+	Host               string         `json:"host"`
+	Port               string         `json:"port"`        // default 443
+	ServerName         string         `json:"server_name"` // SNI, default host
+	Timeout            string         `json:"timeout"`
+	InsecureSkipVerify bool           `json:"insecure_skip_verify"` // do not fail on invalid certificate chain
+	SuccessWhen        []*certSuccess `json:"success_when"`
+	SuccessWhenLogic   string         `json:"success_when_logic"`
+
+	timeout     time.Duration
+	reqCost     time.Duration
+	reqError    string
+	destIP      string
+	tlsVersion  string
+	cert        *x509.Certificate
+	verifyError string
+}
+
+func (t *certTask) init() error {
+	var err error
+	if t.timeout, err = parseTimeout(t.Timeout); err != nil {
+		return err
+	}
+
+	if t.Port == "" {
+		t.Port = defaultCertPort
+	}
+
+	if len(t.SuccessWhen) == 0 {
+		return fmt.Errorf(`no any check rule`)
+	}
+
+	for _, checker := range t.SuccessWhen {
+		if checker.respTime, err = parseResponseTime(checker.ResponseTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *certTask) check() error {
+	if t.Host == "" {
+		return fmt.Errorf("host should not be empty")
+	}
+
+	return nil
+}
+
+func (t *certTask) serverName() string {
+	if t.ServerName != "" {
+		return t.ServerName
+	}
+	return t.Host
+}
+
+func (t *certTask) run(ctx context.Context) {
+	d := &tls.Dialer{
+		// the chain is verified later, so we can get the certificate even if it's invalid
+		Config: &tls.Config{ServerName: t.serverName(), InsecureSkipVerify: true}, //nolint:gosec
+	}
+
+	start := time.Now()
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(t.Host, t.Port))
+	t.reqCost = time.Since(start)
+
+	if err != nil {
+		t.reqError = err.Error()
+		return
+	}
+	defer conn.Close() //nolint:errcheck
+
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		t.destIP = addr.IP.String()
+	}
+
+	state := conn.(*tls.Conn).ConnectionState()
+	t.tlsVersion = tls.VersionName(state.Version)
+
+	if len(state.PeerCertificates) == 0 {
+		t.reqError = "no certificate presented by the server"
+		return
+	}
+
+	t.cert = state.PeerCertificates[0]
+
+	opts := x509.VerifyOptions{
+		DNSName:       t.serverName(),
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, c := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+
+	if _, err := t.cert.Verify(opts); err != nil {
+		t.verifyError = err.Error()
+		if !t.InsecureSkipVerify {
+			t.reqError = fmt.Sprintf("invalid certificate: %s", err.Error())
+		}
+	}
+}
+
+func (t *certTask) daysToExpiry() float64 {
+	if t.cert == nil {
+		return 0
+	}
+
+	return time.Until(t.cert.NotAfter).Hours() / 24
+}
+
+func (t *certTask) clear() {
+	t.reqCost = 0
+	t.reqError = ""
+	t.destIP = ""
+	t.tlsVersion = ""
+	t.cert = nil
+	t.verifyError = ""
+}
+
+func (t *certTask) checkResult() (reasons []string, succFlag bool) {
+	for _, chk := range t.SuccessWhen {
+		if reason, ok := checkResponseTime("TLS", t.reqCost, chk.respTime); reason != "" {
+			reasons = append(reasons, reason)
+		} else if ok {
+			succFlag = true
+		}
+
+		for _, v := range chk.DaysToExpiry {
+			if t.cert == nil {
+				reasons = append(reasons, "certificate days to expiry check failed: no certificate")
+				continue
+			}
+
+			if err := checkValueSuccess(v, t.daysToExpiry()); err != nil {
+				reasons = append(reasons, fmt.Sprintf("certificate days to expiry check failed: %s", err.Error()))
+			} else {
+				succFlag = true
+			}
+		}
+	}
+
+	return reasons, succFlag
+}
+
+func (t *certTask) getResults() (tags map[string]string, fields map[string]interface{}) {
+	tags = map[string]string{
+		"dest_host":   t.Host,
+		"dest_port":   t.Port,
+		"dest_ip":     t.destIP,
+		"server_name": t.serverName(),
+		"proto":       "tls",
+	}
+
+	fields = map[string]interface{}{
+		"response_time": int64(t.reqCost) / 1000, // us
+	}
+
+	if t.tlsVersion != "" {
+		fields["tls_version"] = t.tlsVersion
+	}
+
+	if t.cert != nil {
+		fields["days_to_expiry"] = t.daysToExpiry()
+		fields["cert_not_before"] = t.cert.NotBefore.Unix()
+		fields["cert_not_after"] = t.cert.NotAfter.Unix()
+		fields["cert_subject"] = t.cert.Subject.String()
+		fields["cert_issuer"] = t.cert.Issuer.String()
+	}
+
+	if t.verifyError != "" {
+		fields["verify_error"] = t.verifyError
+	}
+
+	return tags, fields
+}
+
+func (t *certTask) setReqError(err string)      { t.reqError = err }
+func (t *certTask) getReqError() string         { return t.reqError }
+func (t *certTask) getSuccessWhenLogic() string { return t.SuccessWhenLogic }
+func (t *certTask) getTimeout() time.Duration   { return t.timeout }
+func (t *certTask) getHostName() []string       { return []string{t.Host} }
+func (t *certTask) class() string               { return ClassCert }
+func (t *certTask) metricName() string          { return "cert_dial_testing" }
+
+func (t *certTask) renderTemplate(render func(string) (string, error)) error {
+	var err error
+	if t.Host, err = render(t.Host); err != nil {
+		return fmt.Errorf("render host failed: %w", err)
+	}
+
+	if t.Port, err = render(t.Port); err != nil {
+		return fmt.Errorf("render port failed: %w", err)
+	}
+
+	if t.ServerName, err = render(t.ServerName); err != nil {
+		return fmt.Errorf("render server name failed: %w", err)
+	}
+
+	return nil
+}
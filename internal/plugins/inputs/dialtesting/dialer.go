@@ -131,6 +131,12 @@ func newDialer(t dt.ITask, ipt *Input) *dialer {
 		info = (&icmpMeasurement{}).Info()
 	case dt.ClassWebsocket:
 		info = (&websocketMeasurement{}).Info()
+	case dt.ClassDNS:
+		info = (&dnsMeasurement{}).Info()
+	case ClassGRPC:
+		info = (&grpcMeasurement{}).Info()
+	case ClassCert:
+		info = (&certMeasurement{}).Info()
 	}
 
 	tags := make(map[string]string)
@@ -390,6 +396,11 @@ func (d *dialer) run() error {
 
 // checkInternalNetwork check whether the host is allowed to be tested.
 func (d *dialer) checkInternalNetwork() error {
+	if lt, ok := d.task.(*localTask); ok {
+		lt.checkHost = d.checkHostNames
+		return nil
+	}
+
 	d.task.SetBeforeRun(func(t *dt.Task) error {
 		if t == nil {
 			return nil
@@ -401,21 +412,25 @@ func (d *dialer) checkInternalNetwork() error {
 			return fmt.Errorf("get host name error: %w", err)
 		}
 
-		if len(hostNames) > 0 {
-			hostName := hostNames[0]
-			if isInternal, err := httpapi.IsInternalHost(hostName, d.ipt.DisabledInternalNetworkCIDRList); err != nil {
-				l.Errorf("dest host is not valid: %s", err.Error())
-			} else if isInternal {
-				return fmt.Errorf("dest host [%s] is not allowed to be tested", hostName)
-			}
-		}
-
-		return nil
+		return d.checkHostNames(hostNames)
 	})
 
 	return nil
 }
 
+func (d *dialer) checkHostNames(hostNames []string) error {
+	if len(hostNames) > 0 {
+		hostName := hostNames[0]
+		if isInternal, err := httpapi.IsInternalHost(hostName, d.ipt.DisabledInternalNetworkCIDRList); err != nil {
+			l.Errorf("dest host is not valid: %s", err.Error())
+		} else if isInternal {
+			return fmt.Errorf("dest host [%s] is not allowed to be tested", hostName)
+		}
+	}
+
+	return nil
+}
+
 func (d *dialer) feedIO() error {
 	u, err := url.Parse(d.task.PostURLStr())
 	if err != nil {
@@ -426,7 +441,8 @@ func (d *dialer) feedIO() error {
 	urlStr := u.String()
 
 	switch d.task.Class() {
-	case dt.ClassHTTP, dt.ClassTCP, dt.ClassICMP, dt.ClassWebsocket, dt.ClassMulti:
+	case dt.ClassHTTP, dt.ClassTCP, dt.ClassICMP, dt.ClassWebsocket, dt.ClassMulti,
+		dt.ClassDNS, ClassGRPC, ClassCert:
 		d.category = urlStr
 		d.pointsFeed(urlStr)
 	case dt.ClassHeadless:
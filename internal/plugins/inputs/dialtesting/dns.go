@@ -0,0 +1,275 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package dialtesting
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	dt "github.com/GuanceCloud/cliutils/dialtesting"
+)
+
+var _ localTaskChild = (*dnsTask)(nil)
+
+const defaultDNSPort = "53"
+
+type dnsSuccess struct {
+	ResponseTime string `json:"response_time,omitempty"`
+	respTime     time.Duration
+
+	// Answer passed if any of the answer records passed.
+	Answer      []*dt.SuccessOption `json:"answer,omitempty"`
+	AnswerCount []*dt.ValueSuccess  `json:"answer_count,omitempty"`
+}
+
+type dnsTask struct {
+	Host             string        `json:"host"`
+	RecordType       string        `json:"record_type"` // A/AAAA/CNAME/MX/NS/TXT/SRV/PTR, default A
+	Server           string        `json:"server"`      // DNS server, use system resolver if empty
+	Timeout          string        `json:"timeout"`
+	SuccessWhen      []*dnsSuccess `json:"success_when"`
+	SuccessWhenLogic string        `json:"success_when_logic"`
+
+	timeout  time.Duration
+	reqCost  time.Duration
+	reqError string
+	answers  []string
+}
+
+func (t *dnsTask) init() error {
+	var err error
+	if t.timeout, err = parseTimeout(t.Timeout); err != nil {
+		return err
+	}
+
+	if t.RecordType == "" {
+		t.RecordType = "A"
+	}
+	t.RecordType = strings.ToUpper(t.RecordType)
+
+	if len(t.SuccessWhen) == 0 {
+		return fmt.Errorf(`no any check rule`)
+	}
+
+	for _, checker := range t.SuccessWhen {
+		if checker.respTime, err = parseResponseTime(checker.ResponseTime); err != nil {
+			return err
+		}
+
+		if err := validateSuccessOptions(checker.Answer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *dnsTask) check() error {
+	if t.Host == "" {
+		return fmt.Errorf("host should not be empty")
+	}
+
+	switch strings.ToUpper(t.RecordType) {
+	case "", "A", "AAAA", "CNAME", "MX", "NS", "TXT", "SRV", "PTR":
+	default:
+		return fmt.Errorf("unsupported record type %q", t.RecordType)
+	}
+
+	return nil
+}
+
+func (t *dnsTask) resolver() *net.Resolver {
+	if t.Server == "" {
+		return net.DefaultResolver
+	}
+
+	server := t.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, defaultDNSPort)
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+func (t *dnsTask) run(ctx context.Context) {
+	var (
+		r       = t.resolver()
+		answers []string
+		err     error
+	)
+
+	start := time.Now()
+
+	switch t.RecordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if t.RecordType == "AAAA" {
+			network = "ip6"
+		}
+
+		var ips []net.IP
+		if ips, err = r.LookupIP(ctx, network, t.Host); err == nil {
+			for _, ip := range ips {
+				answers = append(answers, ip.String())
+			}
+		}
+
+	case "CNAME":
+		var cname string
+		if cname, err = r.LookupCNAME(ctx, t.Host); err == nil {
+			answers = append(answers, cname)
+		}
+
+	case "MX":
+		var mxs []*net.MX
+		if mxs, err = r.LookupMX(ctx, t.Host); err == nil {
+			for _, mx := range mxs {
+				answers = append(answers, fmt.Sprintf("%d %s", mx.Pref, mx.Host))
+			}
+		}
+
+	case "NS":
+		var nss []*net.NS
+		if nss, err = r.LookupNS(ctx, t.Host); err == nil {
+			for _, ns := range nss {
+				answers = append(answers, ns.Host)
+			}
+		}
+
+	case "TXT":
+		answers, err = r.LookupTXT(ctx, t.Host)
+
+	case "SRV":
+		var srvs []*net.SRV
+		if _, srvs, err = r.LookupSRV(ctx, "", "", t.Host); err == nil {
+			for _, srv := range srvs {
+				answers = append(answers, fmt.Sprintf("%d %d %d %s", srv.Priority, srv.Weight, srv.Port, srv.Target))
+			}
+		}
+
+	case "PTR":
+		answers, err = r.LookupAddr(ctx, t.Host)
+	}
+
+	t.reqCost = time.Since(start)
+
+	if err != nil {
+		t.reqError = err.Error()
+		return
+	}
+
+	sort.Strings(answers)
+	t.answers = answers
+}
+
+func (t *dnsTask) clear() {
+	t.reqCost = 0
+	t.reqError = ""
+	t.answers = nil
+}
+
+func (t *dnsTask) checkResult() (reasons []string, succFlag bool) {
+	for _, chk := range t.SuccessWhen {
+		if reason, ok := checkResponseTime("DNS", t.reqCost, chk.respTime); reason != "" {
+			reasons = append(reasons, reason)
+		} else if ok {
+			succFlag = true
+		}
+
+		for _, v := range chk.Answer {
+			var err error
+			if len(t.answers) == 0 {
+				err = fmt.Errorf("DNS answer: no answer record")
+			}
+
+			for _, answer := range t.answers {
+				if err = checkSuccessOption(v, answer, "DNS answer"); err == nil {
+					break
+				}
+			}
+
+			if err != nil {
+				reasons = append(reasons, err.Error())
+			} else {
+				succFlag = true
+			}
+		}
+
+		for _, v := range chk.AnswerCount {
+			if err := checkValueSuccess(v, float64(len(t.answers))); err != nil {
+				reasons = append(reasons, fmt.Sprintf("DNS answer count check failed: %s", err.Error()))
+			} else {
+				succFlag = true
+			}
+		}
+	}
+
+	return reasons, succFlag
+}
+
+func (t *dnsTask) getResults() (tags map[string]string, fields map[string]interface{}) {
+	server := t.Server
+	if server == "" {
+		server = "system"
+	}
+
+	tags = map[string]string{
+		"dest_host":   t.Host,
+		"dns_server":  server,
+		"record_type": t.RecordType,
+		"proto":       "dns",
+	}
+
+	fields = map[string]interface{}{
+		"response_time": int64(t.reqCost) / 1000, // us
+		"answer_count":  int64(len(t.answers)),
+		"answer":        strings.Join(t.answers, ","),
+	}
+
+	return tags, fields
+}
+
+func (t *dnsTask) setReqError(err string)      { t.reqError = err }
+func (t *dnsTask) getReqError() string         { return t.reqError }
+func (t *dnsTask) getSuccessWhenLogic() string { return t.SuccessWhenLogic }
+func (t *dnsTask) getTimeout() time.Duration   { return t.timeout }
+func (t *dnsTask) class() string               { return dt.ClassDNS }
+func (t *dnsTask) metricName() string          { return "dns_dial_testing" }
+
+// getHostName return the DNS server, the queried domain is not dialed.
+func (t *dnsTask) getHostName() []string {
+	if t.Server == "" {
+		return nil
+	}
+
+	if host, _, err := net.SplitHostPort(t.Server); err == nil {
+		return []string{host}
+	}
+	return []string{t.Server}
+}
+
+func (t *dnsTask) renderTemplate(render func(string) (string, error)) error {
+	var err error
+	if t.Host, err = render(t.Host); err != nil {
+		return fmt.Errorf("render host failed: %w", err)
+	}
+
+	if t.Server, err = render(t.Server); err != nil {
+		return fmt.Errorf("render server failed: %w", err)
+	}
+
+	return nil
+}
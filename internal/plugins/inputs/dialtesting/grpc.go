@@ -0,0 +1,216 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package dialtesting
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	dt "github.com/GuanceCloud/cliutils/dialtesting"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+var _ localTaskChild = (*grpcTask)(nil)
+
+type grpcSuccess struct {
+	ResponseTime string `json:"response_time,omitempty"`
+	respTime     time.Duration
+
+	// Status is the serving status of health checking, such as SERVING/NOT_SERVING/SERVICE_UNKNOWN.
+	Status []*dt.SuccessOption `json:"status,omitempty"`
+	// Code is the gRPC status code of the health checking call, such as OK/Unavailable/Unimplemented.
+	Code []*dt.SuccessOption `json:"code,omitempty"`
+}
+
+// grpcTask check the server with the standard gRPC health checking protocol.
+type grpcTask struct {
+	Server             string         `json:"server"`  // host:port
+	Service            string         `json:"service"` // empty for the overall health of the server
+	Timeout            string         `json:"timeout"`
+	EnableTLS          bool           `json:"enable_tls"`
+	InsecureSkipVerify bool           `json:"insecure_skip_verify"`
+	SuccessWhen        []*grpcSuccess `json:"success_when"`
+	SuccessWhenLogic   string         `json:"success_when_logic"`
+
+	timeout       time.Duration
+	reqCost       time.Duration
+	reqError      string
+	code          codes.Code
+	servingStatus string
+}
+
+func (t *grpcTask) init() error {
+	var err error
+	if t.timeout, err = parseTimeout(t.Timeout); err != nil {
+		return err
+	}
+
+	if len(t.SuccessWhen) == 0 {
+		return fmt.Errorf(`no any check rule`)
+	}
+
+	for _, checker := range t.SuccessWhen {
+		if checker.respTime, err = parseResponseTime(checker.ResponseTime); err != nil {
+			return err
+		}
+
+		if err := validateSuccessOptions(checker.Status); err != nil {
+			return err
+		}
+
+		if err := validateSuccessOptions(checker.Code); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *grpcTask) check() error {
+	if t.Server == "" {
+		return fmt.Errorf("server should not be empty")
+	}
+
+	if _, _, err := net.SplitHostPort(t.Server); err != nil {
+		return fmt.Errorf("invalid server %q: %w", t.Server, err)
+	}
+
+	return nil
+}
+
+func (t *grpcTask) run(ctx context.Context) {
+	creds := insecure.NewCredentials()
+	if t.EnableTLS {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: t.InsecureSkipVerify}) //nolint:gosec
+	}
+
+	start := time.Now()
+
+	conn, err := grpc.DialContext(ctx, t.Server, grpc.WithTransportCredentials(creds), grpc.WithBlock())
+	if err != nil {
+		t.reqCost = time.Since(start)
+		t.code = status.Code(err)
+		if ctx.Err() != nil {
+			t.code = codes.DeadlineExceeded
+		}
+		t.reqError = fmt.Sprintf("connect to %s failed: %s", t.Server, err.Error())
+		return
+	}
+	defer conn.Close() //nolint:errcheck
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: t.Service})
+	t.reqCost = time.Since(start)
+	t.code = status.Code(err)
+
+	if err != nil {
+		if t.code == codes.NotFound { // the service is unknown to the server
+			t.servingStatus = healthpb.HealthCheckResponse_SERVICE_UNKNOWN.String()
+		}
+
+		// if the gRPC status code is checked by user, the error is not treated as failure
+		if !t.hasCodeChecker() {
+			t.reqError = err.Error()
+		}
+		return
+	}
+
+	t.servingStatus = resp.GetStatus().String()
+}
+
+func (t *grpcTask) clear() {
+	t.reqCost = 0
+	t.reqError = ""
+	t.code = codes.OK
+	t.servingStatus = ""
+}
+
+func (t *grpcTask) checkResult() (reasons []string, succFlag bool) {
+	for _, chk := range t.SuccessWhen {
+		if reason, ok := checkResponseTime("gRPC", t.reqCost, chk.respTime); reason != "" {
+			reasons = append(reasons, reason)
+		} else if ok {
+			succFlag = true
+		}
+
+		for _, v := range chk.Status {
+			if err := checkSuccessOption(v, t.servingStatus, "gRPC health status"); err != nil {
+				reasons = append(reasons, err.Error())
+			} else {
+				succFlag = true
+			}
+		}
+
+		for _, v := range chk.Code {
+			if err := checkSuccessOption(v, t.code.String(), "gRPC status code"); err != nil {
+				reasons = append(reasons, err.Error())
+			} else {
+				succFlag = true
+			}
+		}
+	}
+
+	return reasons, succFlag
+}
+
+func (t *grpcTask) hasCodeChecker() bool {
+	for _, chk := range t.SuccessWhen {
+		if len(chk.Code) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *grpcTask) getResults() (tags map[string]string, fields map[string]interface{}) {
+	tags = map[string]string{
+		"server":       t.Server,
+		"grpc_service": t.Service,
+		"proto":        "grpc",
+	}
+
+	fields = map[string]interface{}{
+		"response_time": int64(t.reqCost) / 1000, // us
+		"grpc_code":     t.code.String(),
+		"grpc_status":   t.servingStatus,
+	}
+
+	return tags, fields
+}
+
+func (t *grpcTask) setReqError(err string)      { t.reqError = err }
+func (t *grpcTask) getReqError() string         { return t.reqError }
+func (t *grpcTask) getSuccessWhenLogic() string { return t.SuccessWhenLogic }
+func (t *grpcTask) getTimeout() time.Duration   { return t.timeout }
+func (t *grpcTask) class() string               { return ClassGRPC }
+func (t *grpcTask) metricName() string          { return "grpc_dial_testing" }
+
+func (t *grpcTask) getHostName() []string {
+	if host, _, err := net.SplitHostPort(t.Server); err == nil {
+		return []string{host}
+	}
+	return []string{t.Server}
+}
+
+func (t *grpcTask) renderTemplate(render func(string) (string, error)) error {
+	var err error
+	if t.Server, err = render(t.Server); err != nil {
+		return fmt.Errorf("render server failed: %w", err)
+	}
+
+	if t.Service, err = render(t.Service); err != nil {
+		return fmt.Errorf("render service failed: %w", err)
+	}
+
+	return nil
+}
@@ -390,6 +390,9 @@ func (*Input) SampleMeasurement() []inputs.Measurement {
 		&icmpMeasurement{},
 		&websocketMeasurement{},
 		&multiMeasurement{},
+		&dnsMeasurement{},
+		&grpcMeasurement{},
+		&certMeasurement{},
 	}
 }
 
@@ -682,8 +685,7 @@ func (ipt *Input) newTaskRun(t dt.ITask) (*dialer, error) {
 	case dt.ClassHTTP:
 	case dt.ClassHeadless:
 		return nil, fmt.Errorf("headless task deprecated")
-	case dt.ClassDNS:
-		// TODO
+	case dt.ClassDNS, ClassGRPC, ClassCert:
 	case dt.ClassTCP:
 		// TODO
 	case dt.ClassWebsocket:
@@ -852,15 +854,15 @@ func (ipt *Input) dispatchTasks(j []byte) error {
 			var t dt.ITask
 			var ct dt.TaskChild
 			var err error
+			var isLocal bool
 
 			switch k {
 			case dt.ClassHTTP:
 				ct = &dt.HTTPTask{}
 			case dt.ClassMulti:
 				ct = &dt.MultiTask{}
-			case dt.ClassDNS:
-				l.Warnf("DNS task deprecated, ignored")
-				continue
+			case dt.ClassDNS, ClassGRPC, ClassCert:
+				isLocal = true // these task classes are implemented within DataKit
 			case dt.ClassTCP:
 				ct = &dt.TCPTask{}
 			case dt.ClassWebsocket:
@@ -875,7 +877,7 @@ func (ipt *Input) dispatchTasks(j []byte) error {
 				l.Errorf("unknown task type: %s", k)
 			}
 
-			if ct == nil {
+			if ct == nil && !isLocal {
 				l.Warn("empty task, ignored")
 				continue
 			}
@@ -886,7 +888,13 @@ func (ipt *Input) dispatchTasks(j []byte) error {
 				continue
 			}
 
-			if t, err = dt.NewTask(j, ct); err != nil {
+			if isLocal {
+				t, err = newLocalTask(k, j)
+			} else {
+				t, err = dt.NewTask(j, ct)
+			}
+
+			if err != nil {
 				l.Warnf("newTask failed: %s, task json(%d bytes): '%s'", err.Error(), len(j), j)
 				continue
 			}
@@ -509,3 +509,282 @@ func (m *multiMeasurement) Info() *inputs.MeasurementInfo {
 		},
 	}
 }
+
+type dnsMeasurement struct{}
+
+//nolint:lll
+func (m *dnsMeasurement) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: "dns_dial_testing",
+		Cat:  point.DialTesting,
+		Tags: map[string]interface{}{
+			"name":            &inputs.TagInfo{Desc: "The name of the task"},
+			"dest_host":       &inputs.TagInfo{Desc: "The domain to be resolved"},
+			"dns_server":      &inputs.TagInfo{Desc: "The DNS server, `system` for the system resolver"},
+			"record_type":     &inputs.TagInfo{Desc: "The DNS record type, such as `A`, `AAAA`, `CNAME`, `MX`"},
+			"node_name":       &inputs.TagInfo{Desc: "The name of the node"},
+			"country":         &inputs.TagInfo{Desc: "The name of the country"},
+			"province":        &inputs.TagInfo{Desc: "The name of the province"},
+			"city":            &inputs.TagInfo{Desc: "The name of the city"},
+			"internal":        &inputs.TagInfo{Desc: "The boolean value, true for domestic and false for overseas"},
+			"isp":             &inputs.TagInfo{Desc: "ISP, such as `chinamobile`, `chinaunicom`, `chinatelecom`"},
+			"status":          &inputs.TagInfo{Desc: "The status of the task, either 'OK' or 'FAIL'"},
+			"proto":           &inputs.TagInfo{Desc: "The protocol of the task"},
+			"owner":           &inputs.TagInfo{Desc: "The owner name"}, // used for fees calculation
+			"datakit_version": &inputs.TagInfo{Desc: "The DataKit version"},
+			LabelDF:           &inputs.TagInfo{Desc: "The label of the task"},
+		},
+		Fields: map[string]interface{}{
+			"message": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The message string includes the response time or fail reason",
+			},
+			"task": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The raw task string",
+			},
+			"fail_reason": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The reason that leads to the failure of the task",
+			},
+			"response_time": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.DurationUS,
+				Desc:     "The time of the DNS resolving",
+			},
+			"answer": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The answer records separated by comma, such as `1.1.1.1,1.0.0.1` for `A` record, `10 mx.example.com.` for `MX` record",
+			},
+			"answer_count": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NCount,
+				Desc:     "The number of the answer records",
+			},
+			"success": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The number to specify whether is successful, 1 for success, -1 for failure",
+			},
+			"seq_number": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.Count,
+				Desc:     "The sequence number of the test",
+			},
+			"config_vars": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The configuration variables of the task",
+			},
+		},
+	}
+}
+
+type grpcMeasurement struct{}
+
+//nolint:lll
+func (m *grpcMeasurement) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: "grpc_dial_testing",
+		Cat:  point.DialTesting,
+		Tags: map[string]interface{}{
+			"name":            &inputs.TagInfo{Desc: "The name of the task"},
+			"server":          &inputs.TagInfo{Desc: "The address of the gRPC server"},
+			"grpc_service":    &inputs.TagInfo{Desc: "The service name of health checking, empty for the overall health of the server"},
+			"node_name":       &inputs.TagInfo{Desc: "The name of the node"},
+			"country":         &inputs.TagInfo{Desc: "The name of the country"},
+			"province":        &inputs.TagInfo{Desc: "The name of the province"},
+			"city":            &inputs.TagInfo{Desc: "The name of the city"},
+			"internal":        &inputs.TagInfo{Desc: "The boolean value, true for domestic and false for overseas"},
+			"isp":             &inputs.TagInfo{Desc: "ISP, such as `chinamobile`, `chinaunicom`, `chinatelecom`"},
+			"status":          &inputs.TagInfo{Desc: "The status of the task, either 'OK' or 'FAIL'"},
+			"proto":           &inputs.TagInfo{Desc: "The protocol of the task"},
+			"owner":           &inputs.TagInfo{Desc: "The owner name"}, // used for fees calculation
+			"datakit_version": &inputs.TagInfo{Desc: "The DataKit version"},
+			LabelDF:           &inputs.TagInfo{Desc: "The label of the task"},
+		},
+		Fields: map[string]interface{}{
+			"message": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The message string includes the response time or fail reason",
+			},
+			"task": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The raw task string",
+			},
+			"fail_reason": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The reason that leads to the failure of the task",
+			},
+			"response_time": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.DurationUS,
+				Desc:     "The time of the health checking, which contains the connecting time",
+			},
+			"grpc_status": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The serving status of health checking, such as `SERVING`, `NOT_SERVING`, `SERVICE_UNKNOWN`",
+			},
+			"grpc_code": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The gRPC status code of health checking, such as `OK`, `Unavailable`, `Unimplemented`",
+			},
+			"success": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The number to specify whether is successful, 1 for success, -1 for failure",
+			},
+			"seq_number": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.Count,
+				Desc:     "The sequence number of the test",
+			},
+			"config_vars": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The configuration variables of the task",
+			},
+		},
+	}
+}
+
+type certMeasurement struct{}
+
+//nolint:lll
+func (m *certMeasurement) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name: "cert_dial_testing",
+		Cat:  point.DialTesting,
+		Tags: map[string]interface{}{
+			"name":            &inputs.TagInfo{Desc: "The name of the task"},
+			"dest_host":       &inputs.TagInfo{Desc: "The name of the host to be monitored"},
+			"dest_port":       &inputs.TagInfo{Desc: "The port of the TLS connection"},
+			"dest_ip":         &inputs.TagInfo{Desc: "The IP address"},
+			"server_name":     &inputs.TagInfo{Desc: "The server name(SNI) used to verify the certificate"},
+			"node_name":       &inputs.TagInfo{Desc: "The name of the node"},
+			"country":         &inputs.TagInfo{Desc: "The name of the country"},
+			"province":        &inputs.TagInfo{Desc: "The name of the province"},
+			"city":            &inputs.TagInfo{Desc: "The name of the city"},
+			"internal":        &inputs.TagInfo{Desc: "The boolean value, true for domestic and false for overseas"},
+			"isp":             &inputs.TagInfo{Desc: "ISP, such as `chinamobile`, `chinaunicom`, `chinatelecom`"},
+			"status":          &inputs.TagInfo{Desc: "The status of the task, either 'OK' or 'FAIL'"},
+			"proto":           &inputs.TagInfo{Desc: "The protocol of the task"},
+			"owner":           &inputs.TagInfo{Desc: "The owner name"}, // used for fees calculation
+			"datakit_version": &inputs.TagInfo{Desc: "The DataKit version"},
+			LabelDF:           &inputs.TagInfo{Desc: "The label of the task"},
+		},
+		Fields: map[string]interface{}{
+			"message": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The message string includes the response time or fail reason",
+			},
+			"task": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The raw task string",
+			},
+			"fail_reason": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The reason that leads to the failure of the task",
+			},
+			"response_time": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.DurationUS,
+				Desc:     "The time of the TCP connecting and TLS handshake",
+			},
+			"days_to_expiry": &inputs.FieldInfo{
+				DataType: inputs.Float,
+				Type:     inputs.Gauge,
+				Unit:     inputs.DurationDay,
+				Desc:     "The days before the server certificate expires, negative if already expired",
+			},
+			"cert_not_before": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.TimestampSec,
+				Desc:     "The time the server certificate becomes valid",
+			},
+			"cert_not_after": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.TimestampSec,
+				Desc:     "The time the server certificate expires",
+			},
+			"cert_subject": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The subject of the server certificate",
+			},
+			"cert_issuer": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The issuer of the server certificate",
+			},
+			"tls_version": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The negotiated TLS version, such as `TLS 1.3`",
+			},
+			"verify_error": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The error of the certificate chain verifying, if any",
+			},
+			"success": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The number to specify whether is successful, 1 for success, -1 for failure",
+			},
+			"seq_number": &inputs.FieldInfo{
+				DataType: inputs.Int,
+				Type:     inputs.Gauge,
+				Unit:     inputs.Count,
+				Desc:     "The sequence number of the test",
+			},
+			"config_vars": &inputs.FieldInfo{
+				DataType: inputs.String,
+				Type:     inputs.Gauge,
+				Unit:     inputs.NoUnit,
+				Desc:     "The configuration variables of the task",
+			},
+		},
+	}
+}
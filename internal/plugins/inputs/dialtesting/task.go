@@ -0,0 +1,399 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package dialtesting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	dt "github.com/GuanceCloud/cliutils/dialtesting"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	ClassGRPC = "GRPC"
+	ClassCert = "CERT"
+
+	defaultLocalTaskTimeout = 10 * time.Second
+)
+
+var _ dt.ITask = (*localTask)(nil)
+
+// localTaskChild is the task class implemented within DataKit. The dialtesting SDK
+// does not allow external task classes, so these classes are wrapped by localTask.
+type localTaskChild interface {
+	init() error
+	check() error
+	run(ctx context.Context)
+	clear()
+	checkResult() (reasons []string, succFlag bool)
+	getResults() (tags map[string]string, fields map[string]interface{})
+	getReqError() string
+	setReqError(string)
+	getSuccessWhenLogic() string
+	getTimeout() time.Duration
+	getHostName() []string
+	renderTemplate(render func(string) (string, error)) error
+	class() string
+	metricName() string
+}
+
+// localTask implements dt.ITask, common task fields(name/frequency/post_url...)
+// are kept in the embedded dt.Task.
+type localTask struct {
+	*dt.Task
+
+	child    localTaskChild
+	newChild func() localTaskChild
+
+	// checkHost check whether the host is allowed to be tested.
+	checkHost func(hostNames []string) error
+}
+
+func newLocalTask(class, taskJSON string) (*localTask, error) {
+	var newChild func() localTaskChild
+
+	switch class {
+	case dt.ClassDNS:
+		newChild = func() localTaskChild { return &dnsTask{} }
+	case ClassGRPC:
+		newChild = func() localTaskChild { return &grpcTask{} }
+	case ClassCert:
+		newChild = func() localTaskChild { return &certTask{} }
+	default:
+		return nil, fmt.Errorf("unknown task type %s", class)
+	}
+
+	t := &localTask{Task: &dt.Task{}, newChild: newChild}
+
+	if err := json.Unmarshal([]byte(taskJSON), t.Task); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal failed: %w, task json: %s", err, taskJSON)
+	}
+
+	child := newChild()
+	if err := json.Unmarshal([]byte(taskJSON), child); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal failed: %w, task json: %s", err, taskJSON)
+	}
+
+	t.child = child
+	t.SetTaskJSONString(taskJSON)
+	t.SetIsTemplate(strings.Contains(taskJSON, "{{"))
+
+	return t, nil
+}
+
+func (t *localTask) Class() string {
+	return t.child.class()
+}
+
+func (t *localTask) MetricName() string {
+	return t.child.metricName()
+}
+
+func (t *localTask) Stop() {}
+
+func (t *localTask) String() string {
+	b, _ := json.Marshal(t.child)
+	return string(b)
+}
+
+func (t *localTask) GetHostName() ([]string, error) {
+	return t.child.getHostName(), nil
+}
+
+func (t *localTask) GetVariableValue(dt.Variable) (string, error) {
+	return "", fmt.Errorf("not support")
+}
+
+// SetBeforeRun not used, the callback requires the task class within dialtesting SDK, see checkHost.
+func (t *localTask) SetBeforeRun(func(*dt.Task) error) {}
+
+func (t *localTask) Check() error {
+	if t.ExternalID == "" {
+		return fmt.Errorf("external ID missing")
+	}
+
+	if t.GetScheduleType() == dt.ScheduleTypeCron {
+		if _, err := cron.ParseStandard(t.Crontab); err != nil {
+			return fmt.Errorf("invalid crontab: %w", err)
+		}
+	} else if _, err := time.ParseDuration(t.Frequency); err != nil {
+		return fmt.Errorf("invalid frequency: %w", err)
+	}
+
+	return t.CheckTask()
+}
+
+func (t *localTask) CheckTask() error {
+	if err := t.child.check(); err != nil {
+		return err
+	}
+
+	return t.init()
+}
+
+func (t *localTask) init() error {
+	if strings.EqualFold(t.CurStatus, dt.StatusStop) {
+		return nil
+	}
+
+	return t.child.init()
+}
+
+// RenderTemplateAndInit render config variables within the task, only variables are
+// available, template functions such as timestamp/date are not supported.
+func (t *localTask) RenderTemplateAndInit(globalVariables map[string]dt.Variable) error {
+	if !t.GetIsTemplate() {
+		return t.init()
+	}
+
+	fm := template.FuncMap{}
+	for _, vars := range [][]*dt.ConfigVar{t.ConfigVars, t.ExtractedVars, t.CustomVars} {
+		for _, v := range vars {
+			value := v.Value
+			if v.Type == dt.TypeVariableGlobal && v.ID != "" {
+				if gv, ok := globalVariables[v.ID]; ok {
+					value = gv.Value
+					v.Secure = gv.Secure
+				}
+			}
+
+			fm[v.Name] = func() string { return value }
+			v.Value = value
+		}
+	}
+
+	// always render from the raw task
+	child := t.newChild()
+	if err := json.Unmarshal([]byte(t.GetTaskJSONString()), child); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %w", err)
+	}
+
+	if err := child.renderTemplate(func(s string) (string, error) {
+		return t.GetParsedString(s, fm)
+	}); err != nil {
+		return fmt.Errorf("render template error: %w", err)
+	}
+
+	t.child = child
+	return t.init()
+}
+
+func (t *localTask) Run() error {
+	t.child.clear()
+
+	if t.checkHost != nil {
+		if err := t.checkHost(t.child.getHostName()); err != nil {
+			t.child.setReqError(err.Error())
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.child.getTimeout())
+	defer cancel()
+
+	t.child.run(ctx)
+	return nil
+}
+
+func (t *localTask) CheckResult() ([]string, bool) {
+	return t.child.checkResult()
+}
+
+// GetResults build the result with the same success model of dialtesting SDK:
+// for success_when_logic `or', any passed condition is OK, or all conditions must be passed.
+func (t *localTask) GetResults() (tags map[string]string, fields map[string]interface{}) {
+	tags, fields = t.child.getResults()
+
+	tags["name"] = t.Name
+	tags["status"] = "FAIL"
+	fields["success"] = int64(-1)
+
+	for k, v := range t.Tags {
+		tags[k] = v
+	}
+
+	message := map[string]interface{}{}
+
+	reqError := t.child.getReqError()
+	reasons, succFlag := t.child.checkResult()
+	if reqError != "" {
+		reasons = append(reasons, reqError)
+	}
+
+	switch t.child.getSuccessWhenLogic() {
+	case "or":
+		if succFlag && reqError == "" {
+			tags["status"] = "OK"
+			fields["success"] = int64(1)
+			message["response_time"] = fields["response_time"]
+		} else {
+			message["fail_reason"] = strings.Join(reasons, ";")
+			fields["fail_reason"] = strings.Join(reasons, ";")
+		}
+	default:
+		if len(reasons) != 0 {
+			message["fail_reason"] = strings.Join(reasons, ";")
+			fields["fail_reason"] = strings.Join(reasons, ";")
+		} else {
+			message["response_time"] = fields["response_time"]
+			tags["status"] = "OK"
+			fields["success"] = int64(1)
+		}
+	}
+
+	if data, err := json.Marshal(message); err != nil {
+		fields["message"] = err.Error()
+	} else if len(data) > dt.MaxMsgSize {
+		fields["message"] = string(data[:dt.MaxMsgSize])
+	} else {
+		fields["message"] = string(data)
+	}
+
+	vars := []dt.ConfigVar{}
+	for _, v := range t.ConfigVars {
+		variable := dt.ConfigVar{Name: v.Name, Secure: v.Secure}
+		if !v.Secure {
+			variable.Value = v.Value
+		}
+		vars = append(vars, variable)
+	}
+
+	data, _ := json.Marshal(vars)
+	fields["config_vars"] = string(data)
+	fields["task"] = t.String()
+
+	return tags, fields
+}
+
+func parseTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return defaultLocalTaskTimeout, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+// checkResponseTime check the request cost against the response_time condition.
+func checkResponseTime(class string, cost, target time.Duration) (reason string, ok bool) {
+	if target <= 0 {
+		return "", false
+	}
+
+	if cost > target {
+		return fmt.Sprintf("%s response time(%v) larger than %v", class, cost, target), false
+	}
+
+	return "", true
+}
+
+// checkSuccessOption check val against opt, same as the check within dialtesting SDK.
+func checkSuccessOption(opt *dt.SuccessOption, val, prompt string) error {
+	if opt.Is != "" {
+		if opt.Is != val {
+			return fmt.Errorf("%s: expect to be `%s', got `%s'", prompt, opt.Is, val)
+		}
+		return nil
+	}
+
+	if opt.IsNot != "" {
+		if opt.IsNot == val {
+			return fmt.Errorf("%s: shoud not be %s", prompt, opt.IsNot)
+		}
+		return nil
+	}
+
+	if opt.MatchRegex != "" {
+		re, err := regexp.Compile(opt.MatchRegex)
+		if err != nil {
+			return fmt.Errorf("%s: invalid regex `%s': %w", prompt, opt.MatchRegex, err)
+		}
+
+		if !re.MatchString(val) {
+			return fmt.Errorf("%s: regex `%s` match `%s' failed", prompt, opt.MatchRegex, val)
+		}
+	}
+
+	if opt.NotMatchRegex != "" {
+		re, err := regexp.Compile(opt.NotMatchRegex)
+		if err != nil {
+			return fmt.Errorf("%s: invalid regex `%s': %w", prompt, opt.NotMatchRegex, err)
+		}
+
+		if re.MatchString(val) {
+			return fmt.Errorf("%s: regex `%s' should not match `%s'", prompt, opt.NotMatchRegex, val)
+		}
+	}
+
+	if opt.Contains != "" && !strings.Contains(val, opt.Contains) {
+		return fmt.Errorf("%s: do not contains `%s', got `%s'", prompt, opt.Contains, val)
+	}
+
+	if opt.NotContains != "" && strings.Contains(val, opt.NotContains) {
+		return fmt.Errorf("%s: should not contains `%s', got `%s'", prompt, opt.NotContains, val)
+	}
+
+	return nil
+}
+
+// checkValueSuccess check val against v, same as the check within dialtesting SDK.
+func checkValueSuccess(v *dt.ValueSuccess, val float64) error {
+	switch v.Op {
+	case "eq":
+		if val != v.Target {
+			return fmt.Errorf("%v is not equal to target %v", val, v.Target)
+		}
+	case "lt":
+		if val >= v.Target {
+			return fmt.Errorf("%v is greater equal than target %v", val, v.Target)
+		}
+	case "leq":
+		if val > v.Target {
+			return fmt.Errorf("%v is greater than target %v", val, v.Target)
+		}
+	case "gt":
+		if val <= v.Target {
+			return fmt.Errorf("%v is less than target %v", val, v.Target)
+		}
+	case "geq":
+		if val < v.Target {
+			return fmt.Errorf("%v is less equal than target %v", val, v.Target)
+		}
+	default:
+		return fmt.Errorf("unknown op %q", v.Op)
+	}
+
+	return nil
+}
+
+func validateSuccessOptions(opts []*dt.SuccessOption) error {
+	for _, opt := range opts {
+		for _, re := range []string{opt.MatchRegex, opt.NotMatchRegex} {
+			if re == "" {
+				continue
+			}
+
+			if _, err := regexp.Compile(re); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseResponseTime(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	return time.ParseDuration(s)
+}
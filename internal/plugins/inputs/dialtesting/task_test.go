@@ -0,0 +1,259 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package dialtesting
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	dt "github.com/GuanceCloud/cliutils/dialtesting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func runLocalTask(t *testing.T, class, j string) (map[string]string, map[string]interface{}) {
+	t.Helper()
+
+	task, err := newLocalTask(class, j)
+	require.NoError(t, err)
+	require.NoError(t, task.Check())
+	require.NoError(t, task.RenderTemplateAndInit(nil))
+	require.NoError(t, task.Run())
+
+	return task.GetResults()
+}
+
+func TestDNSTask(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		tags, fields := runLocalTask(t, dt.ClassDNS, `{
+  "external_id": "dns-1",
+  "name": "dns-test",
+  "frequency": "10s",
+  "host": "localhost",
+  "tags": {"env": "test"},
+  "success_when": [{
+    "response_time": "1s",
+    "answer": [{"is": "127.0.0.1"}],
+    "answer_count": [{"op": "geq", "target": 1}]
+  }]
+}`)
+
+		assert.Equal(t, "OK", tags["status"], "fields: %+#v", fields)
+		assert.Equal(t, "dns-test", tags["name"])
+		assert.Equal(t, "A", tags["record_type"])
+		assert.Equal(t, "system", tags["dns_server"])
+		assert.Equal(t, "test", tags["env"])
+		assert.Equal(t, int64(1), fields["success"])
+		assert.Contains(t, fields["answer"], "127.0.0.1")
+		assert.NotEmpty(t, fields["task"])
+	})
+
+	t.Run("answer-mismatch", func(t *testing.T) {
+		tags, fields := runLocalTask(t, dt.ClassDNS, `{
+  "external_id": "dns-2",
+  "frequency": "10s",
+  "host": "localhost",
+  "success_when": [{"answer": [{"is": "1.1.1.1"}]}]
+}`)
+
+		assert.Equal(t, "FAIL", tags["status"])
+		assert.Contains(t, fields["fail_reason"], "DNS answer")
+	})
+
+	t.Run("server-unreachable", func(t *testing.T) {
+		tags, fields := runLocalTask(t, dt.ClassDNS, `{
+  "external_id": "dns-3",
+  "frequency": "10s",
+  "host": "example.com",
+  "server": "127.0.0.1:1",
+  "timeout": "500ms",
+  "success_when": [{"answer_count": [{"op": "geq", "target": 1}]}]
+}`)
+
+		assert.Equal(t, "FAIL", tags["status"])
+		assert.Equal(t, "127.0.0.1:1", tags["dns_server"])
+		assert.NotEmpty(t, fields["fail_reason"])
+	})
+
+	t.Run("invalid-record-type", func(t *testing.T) {
+		task, err := newLocalTask(dt.ClassDNS, `{"external_id": "dns-4", "frequency": "10s", "host": "localhost", "record_type": "XYZ"}`)
+		require.NoError(t, err)
+		assert.Error(t, task.Check())
+	})
+}
+
+func TestGRPCTask(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	hs := health.NewServer()
+	hs.SetServingStatus("ok-svc", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("down-svc", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(ln) //nolint:errcheck
+	defer srv.Stop()
+
+	cases := []struct {
+		name, service, successWhen string
+		status, grpcStatus, code   string
+	}{
+		{
+			name:        "serving",
+			service:     "ok-svc",
+			successWhen: `{"response_time": "1s", "status": [{"is": "SERVING"}]}`,
+			status:      "OK",
+			grpcStatus:  "SERVING",
+			code:        "OK",
+		},
+		{
+			name:        "not-serving",
+			service:     "down-svc",
+			successWhen: `{"status": [{"is": "SERVING"}]}`,
+			status:      "FAIL",
+			grpcStatus:  "NOT_SERVING",
+			code:        "OK",
+		},
+		{
+			name:        "unknown-service",
+			service:     "no-such-svc",
+			successWhen: `{"status": [{"is": "SERVING"}]}`,
+			status:      "FAIL",
+			grpcStatus:  "SERVICE_UNKNOWN",
+			code:        "NotFound",
+		},
+		{
+			name:        "expect-code",
+			service:     "no-such-svc",
+			successWhen: `{"code": [{"is": "NotFound"}]}`,
+			status:      "OK",
+			grpcStatus:  "SERVICE_UNKNOWN",
+			code:        "NotFound",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tags, fields := runLocalTask(t, ClassGRPC, fmt.Sprintf(`{
+  "external_id": "grpc-%s",
+  "frequency": "10s",
+  "server": %q,
+  "service": %q,
+  "timeout": "3s",
+  "success_when": [%s]
+}`, tc.name, ln.Addr().String(), tc.service, tc.successWhen))
+
+			assert.Equal(t, tc.status, tags["status"], "fields: %+#v", fields)
+			assert.Equal(t, tc.service, tags["grpc_service"])
+			assert.Equal(t, tc.grpcStatus, fields["grpc_status"])
+			assert.Equal(t, tc.code, fields["grpc_code"])
+		})
+	}
+}
+
+func TestCertTask(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	host, port, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	task := func(id string, skipVerify bool, successWhen string) string {
+		return fmt.Sprintf(`{
+  "external_id": %q,
+  "frequency": "10s",
+  "host": %q,
+  "port": %q,
+  "server_name": "example.com",
+  "insecure_skip_verify": %v,
+  "success_when": [%s]
+}`, id, host, port, skipVerify, successWhen)
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		tags, fields := runLocalTask(t, ClassCert, task("cert-1", true, `{"days_to_expiry": [{"op": "geq", "target": 30}]}`))
+
+		assert.Equal(t, "OK", tags["status"], "fields: %+#v", fields)
+		assert.Equal(t, "example.com", tags["server_name"])
+		assert.Equal(t, host, tags["dest_ip"])
+		assert.Greater(t, fields["days_to_expiry"], float64(30))
+		assert.Equal(t, ts.Certificate().NotAfter.Unix(), fields["cert_not_after"])
+		assert.NotEmpty(t, fields["verify_error"]) // self-signed
+	})
+
+	t.Run("expiring", func(t *testing.T) {
+		tags, fields := runLocalTask(t, ClassCert, task("cert-2", true, `{"days_to_expiry": [{"op": "geq", "target": 1000000}]}`))
+
+		assert.Equal(t, "FAIL", tags["status"])
+		assert.Contains(t, fields["fail_reason"], "days to expiry")
+	})
+
+	t.Run("untrusted", func(t *testing.T) {
+		tags, fields := runLocalTask(t, ClassCert, task("cert-3", false, `{"days_to_expiry": [{"op": "geq", "target": 30}]}`))
+
+		assert.Equal(t, "FAIL", tags["status"])
+		assert.Contains(t, fields["fail_reason"], "invalid certificate")
+	})
+
+	t.Run("template", func(t *testing.T) {
+		j := fmt.Sprintf(`{
+  "external_id": "cert-4",
+  "frequency": "10s",
+  "host": "{{host}}",
+  "port": %q,
+  "insecure_skip_verify": true,
+  "config_vars": [{"name": "host", "value": %q}],
+  "success_when": [{"response_time": "3s"}]
+}`, port, host)
+
+		tags, fields := runLocalTask(t, ClassCert, j)
+		assert.Equal(t, "OK", tags["status"], "fields: %+#v", fields)
+		assert.Equal(t, host, tags["dest_host"])
+	})
+
+	t.Run("internal-network", func(t *testing.T) {
+		task, err := newLocalTask(ClassCert, task("cert-5", true, `{"response_time": "3s"}`))
+		require.NoError(t, err)
+		require.NoError(t, task.RenderTemplateAndInit(nil))
+
+		task.checkHost = func(hostNames []string) error {
+			return fmt.Errorf("dest host [%s] is not allowed to be tested", hostNames[0])
+		}
+		require.NoError(t, task.Run())
+
+		tags, fields := task.GetResults()
+		assert.Equal(t, "FAIL", tags["status"])
+		assert.Contains(t, fields["fail_reason"], "not allowed")
+	})
+}
+
+func TestLocalTaskCheck(t *testing.T) {
+	task, err := newLocalTask(ClassGRPC, `{"external_id": "x", "frequency": "abc", "server": "localhost:50051"}`)
+	require.NoError(t, err)
+	assert.Error(t, task.Check())
+
+	task, err = newLocalTask(ClassGRPC, `{"external_id": "x", "frequency": "10s", "server": "localhost"}`)
+	require.NoError(t, err)
+	assert.Error(t, task.Check())
+
+	task, err = newLocalTask(ClassGRPC, `{"external_id": "x", "frequency": "10s", "server": "localhost:50051"}`)
+	require.NoError(t, err)
+	assert.Error(t, task.Check(), "no success_when")
+
+	_, err = newLocalTask("UNKNOWN", `{}`)
+	assert.Error(t, err)
+}
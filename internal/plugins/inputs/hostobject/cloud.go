@@ -23,6 +23,9 @@ const (
 	Azure       = "azure"
 	Hwcloud     = "hwcloud"
 	VolcEngine  = "volcengine"
+	GCP         = "gcp"
+	OCI         = "oci"
+	OpenStack   = "openstack"
 )
 
 var cloudCli = &http.Client{Timeout: 3 * time.Second}
@@ -110,6 +113,24 @@ func (ipt *Input) SyncCloudInfo(provider string) (map[string]interface{}, error)
 			p = &volcEcs{baseURL: volcMetaRootURL}
 		}
 		return p.Sync()
+	case GCP:
+		p := &gcp{baseURL: gcpMetaRootURL}
+		if url, ok := ipt.CloudMetaURL[GCP]; ok {
+			p.baseURL = url
+		}
+		return p.Sync()
+	case OCI:
+		p := &oci{baseURL: ociMetaRootURL}
+		if url, ok := ipt.CloudMetaURL[OCI]; ok {
+			p.baseURL = url
+		}
+		return p.Sync()
+	case OpenStack:
+		p := &openstack{baseURL: openstackMetaRootURL}
+		if url, ok := ipt.CloudMetaURL[OpenStack]; ok {
+			p.baseURL = url
+		}
+		return p.Sync()
 	default:
 		return nil, fmt.Errorf("unknown cloud_provider: %s", provider)
 	}
@@ -141,6 +162,13 @@ func (ipt *Input) matchCloudProvider(cloudProvider string) bool {
 			return true
 		}
 	}
+	// Hwcloud is built on OpenStack, and the region is only available on
+	// hwcloud, so other OpenStack clouds will not be detected as hwcloud.
+	if cloudProvider == Hwcloud {
+		if region, ok := fields["region"].(string); !ok || region == "" || region == Unavailable {
+			return false
+		}
+	}
 	if cloudProvider == Hwcloud || cloudProvider == AWS {
 		// Both of hwcloud and aws use the same URL. They can be distinguished by
 		// field 'availability-zone-id', which is present in aws but not hwcloud.
@@ -153,7 +181,9 @@ func (ipt *Input) matchCloudProvider(cloudProvider string) bool {
 }
 
 func (ipt *Input) SetCloudProvider() error {
-	cloudProviders := []string{Aliyun, AWS, Tencent, Azure, Hwcloud, VolcEngine}
+	// OpenStack should be the last one, some clouds(such as hwcloud) provide
+	// OpenStack compatible metadata too.
+	cloudProviders := []string{Aliyun, AWS, Tencent, Azure, Hwcloud, VolcEngine, GCP, OCI, OpenStack}
 	for _, cp := range cloudProviders {
 		if ipt.matchCloudProvider(cp) {
 			ipt.Tags["cloud_provider"] = cp
@@ -238,12 +268,26 @@ func metadataGet(metaURL string) []byte {
 }
 
 func metadataGetByHeader(metaURL string) []byte {
+	return metadataGetWithHeaders(metaURL, map[string]string{"Metadata": "true"})
+}
+
+func metaGetWithHeaders(metaURL string, headers map[string]string) string {
+	if x := metadataGetWithHeaders(metaURL, headers); x != nil {
+		return string(bytes.ReplaceAll(x, []byte{'\n'}, []byte{' '}))
+	}
+
+	return Unavailable
+}
+
+func metadataGetWithHeaders(metaURL string, headers map[string]string) []byte {
 	req, err := http.NewRequest("GET", metaURL, nil)
 	if err != nil {
 		l.Warn(err)
 		return nil
 	}
-	req.Header.Set("Metadata", "true")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 
 	return clientDo(req, metaURL)
 }
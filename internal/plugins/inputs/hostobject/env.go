@@ -130,7 +130,7 @@ func (ipt *Input) GetENVDoc() []*inputs.ENVInfo {
 			ENVName:   "CLOUD_PROVIDER",
 			ConfField: "none",
 			Type:      doc.String,
-			Example:   "`aliyun/aws/tencent/hwcloud/azure`",
+			Example:   "`aliyun/aws/tencent/hwcloud/azure/gcp/oci/openstack`",
 			Desc:      "Designate cloud service provider",
 			DescZh:    "指定云服务商",
 		},
@@ -165,6 +165,16 @@ func (ipt *Input) GetENVDoc() []*inputs.ENVInfo {
 			DescZh:    "禁止同步主机云信息",
 		},
 
+		{
+			FieldName: "EnableChangeEvent",
+			ENVName:   "ENABLE_CHANGE_EVENT",
+			ConfField: "enable_change_event",
+			Type:      doc.Boolean,
+			Default:   "false",
+			Desc:      "Enable host change event, see [here](#change-event)",
+			DescZh:    "开启主机变更事件，参见[这里](#change-event)",
+		},
+
 		{
 			ENVName:   "USE_NSENTER",
 			ConfField: "use_nsenter",
@@ -259,7 +269,7 @@ func (ipt *Input) ReadEnv(envs map[string]string) {
 		cloudProvider := dkstring.TrimString(tagsStr)
 		cloudProvider = strings.ToLower(cloudProvider)
 		switch cloudProvider {
-		case "aliyun", "tencent", "aws", "hwcloud", "azure":
+		case "aliyun", "tencent", "aws", "hwcloud", "azure", GCP, OCI, OpenStack:
 			ipt.Tags["cloud_provider"] = cloudProvider
 		}
 	} // ENV_CLOUD_PROVIDER
@@ -316,6 +326,15 @@ func (ipt *Input) ReadEnv(envs map[string]string) {
 		ipt.IgnoreMountpoints = str
 	}
 
+	if v, ok := envs["ENV_INPUT_HOSTOBJECT_ENABLE_CHANGE_EVENT"]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			l.Warnf("parse ENV_INPUT_HOSTOBJECT_ENABLE_CHANGE_EVENT to bool: %s, ignore", err)
+		} else {
+			ipt.EnableChangeEvent = b
+		}
+	}
+
 	if v := os.Getenv("ENV_INPUT_HOSTOBJECT_USE_NSENTER"); v != "" {
 		if b, _ := strconv.ParseBool(v); b {
 			ipt.UseNSEnterDiskstatsImpl = true
@@ -21,4 +21,12 @@ func TestReadEnv(t *T.T) {
 
 		assert.True(t, ipt.DisableCloudProviderSync)
 	})
+
+	t.Run("change-event", func(t *T.T) {
+		ipt := defaultInput()
+		assert.False(t, ipt.EnableChangeEvent)
+
+		ipt.ReadEnv(map[string]string{"ENV_INPUT_HOSTOBJECT_ENABLE_CHANGE_EVENT": "true"})
+		assert.True(t, ipt.EnableChangeEvent)
+	})
 }
@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package hostobject
+
+import "strings"
+
+const gcpMetaRootURL = "http://metadata.google.internal/computeMetadata/v1/instance"
+
+// GCP metadata server requires the header `Metadata-Flavor: Google`.
+var gcpMetaHeaders = map[string]string{"Metadata-Flavor": "Google"}
+
+type gcp struct {
+	baseURL string
+}
+
+func (x *gcp) Sync() (map[string]any, error) {
+	return map[string]any{
+		"cloud_provider":        GCP,
+		"description":           x.Description(),
+		"instance_id":           x.InstanceID(),
+		"instance_name":         x.InstanceName(),
+		"instance_type":         x.InstanceType(),
+		"instance_charge_type":  x.InstanceChargeType(),
+		"instance_network_type": x.InstanceNetworkType(),
+		"instance_status":       x.InstanceStatus(),
+		"security_group_id":     x.SecurityGroupID(),
+		"private_ip":            x.PrivateIP(),
+		"zone_id":               x.ZoneID(),
+		"region":                x.Region(),
+	}, nil
+}
+
+func (x *gcp) get(path string) string {
+	return metaGetWithHeaders(x.baseURL+path, gcpMetaHeaders)
+}
+
+func (x *gcp) Description() string {
+	return x.get("/description")
+}
+
+func (x *gcp) InstanceID() string {
+	return x.get("/id")
+}
+
+func (x *gcp) InstanceName() string {
+	return x.get("/name")
+}
+
+// InstanceType returns the machine type, the metadata is something like
+// `projects/123456789/machineTypes/e2-medium`.
+func (x *gcp) InstanceType() string {
+	return lastPathSegment(x.get("/machine-type"))
+}
+
+// InstanceChargeType returns the provisioning model, `STANDARD` or `SPOT`.
+func (x *gcp) InstanceChargeType() string {
+	return x.get("/scheduling/provisioning-model")
+}
+
+func (x *gcp) InstanceNetworkType() string {
+	return Unavailable
+}
+
+func (x *gcp) InstanceStatus() string {
+	return Unavailable
+}
+
+func (x *gcp) SecurityGroupID() string {
+	return Unavailable
+}
+
+func (x *gcp) PrivateIP() string {
+	return x.get("/network-interfaces/0/ip")
+}
+
+// ZoneID returns the zone, the metadata is something like
+// `projects/123456789/zones/us-central1-a`.
+func (x *gcp) ZoneID() string {
+	return lastPathSegment(x.get("/zone"))
+}
+
+// Region is the zone without the zone suffix, such as `us-central1` of
+// zone `us-central1-a`.
+func (x *gcp) Region() string {
+	zone := x.ZoneID()
+	if idx := strings.LastIndex(zone, "-"); zone != Unavailable && idx > 0 {
+		return zone[:idx]
+	}
+	return Unavailable
+}
+
+func lastPathSegment(s string) string {
+	if s == Unavailable {
+		return s
+	}
+	return s[strings.LastIndex(s, "/")+1:]
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package hostobject
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCPSync(t *testing.T) {
+	gcpMeta := map[string]string{
+		"/computeMetadata/v1/instance/id":                            "4520031799277581759",
+		"/computeMetadata/v1/instance/name":                          "gce-test",
+		"/computeMetadata/v1/instance/description":                   "",
+		"/computeMetadata/v1/instance/machine-type":                  "projects/123456789/machineTypes/e2-medium",
+		"/computeMetadata/v1/instance/scheduling/provisioning-model": "SPOT",
+		"/computeMetadata/v1/instance/network-interfaces/0/ip":       "10.128.0.2",
+		"/computeMetadata/v1/instance/zone":                          "projects/123456789/zones/us-central1-a",
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if v, ok := gcpMeta[r.URL.Path]; ok {
+			fmt.Fprint(w, v)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	ipt := defaultInput()
+	ipt.CloudMetaURL = map[string]string{GCP: ts.URL + "/computeMetadata/v1/instance"}
+
+	info, err := ipt.SyncCloudInfo(GCP)
+	require.NoError(t, err)
+
+	assert.Equal(t, GCP, info["cloud_provider"])
+	assert.Equal(t, "4520031799277581759", info["instance_id"])
+	assert.Equal(t, "gce-test", info["instance_name"])
+	assert.Equal(t, "e2-medium", info["instance_type"])
+	assert.Equal(t, "SPOT", info["instance_charge_type"])
+	assert.Equal(t, "10.128.0.2", info["private_ip"])
+	assert.Equal(t, "us-central1-a", info["zone_id"])
+	assert.Equal(t, "us-central1", info["region"])
+	assert.Equal(t, Unavailable, info["security_group_id"])
+
+	assert.True(t, ipt.matchCloudProvider(GCP))
+
+	// without the Metadata-Flavor header, nothing available
+	assert.Equal(t, Unavailable, metaGet(ts.URL+"/computeMetadata/v1/instance/id"))
+}
+
+func TestLastPathSegment(t *testing.T) {
+	assert.Equal(t, "e2-medium", lastPathSegment("projects/123456789/machineTypes/e2-medium"))
+	assert.Equal(t, "e2-medium", lastPathSegment("e2-medium"))
+	assert.Equal(t, Unavailable, lastPathSegment(Unavailable))
+}
@@ -0,0 +1,388 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package hostobject
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/google/uuid"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/changes"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/diff"
+	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
+	dkmetrics "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/metrics"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/ntp"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
+)
+
+const (
+	changeEventMeasurementName = "event"
+	defaultChangeLanguage      = changes.LangEn
+	snapshotFile               = "hostobject_snapshot.json"
+
+	// too many change values make the event message unreadable, the
+	// complete changes are still available in the diff field.
+	maxChangeValues = 50
+
+	categoryPackage = "package"
+	categoryPort    = "port"
+	categoryUser    = "user"
+	categoryCrontab = "crontab"
+	categoryKernel  = "kernel"
+)
+
+// hostSnapshot is the host configuration used for change detection. A nil map
+// means the item is unavailable on current host, it's not compared.
+type hostSnapshot struct {
+	PackageSource string            `json:"package_source"`
+	Packages      map[string]string `json:"packages"` // package name -> version
+	Ports         map[string]string `json:"ports"`    // <proto>/<addr>:<port> -> ""
+	Users         map[string]string `json:"users"`
+	Groups        map[string]string `json:"groups"`
+	Crontabs      map[string]string `json:"crontabs"` // file path -> content
+	KernelRelease string            `json:"kernel_release"`
+	KernelCmdline string            `json:"kernel_cmdline"`
+	Sysctl        map[string]string `json:"sysctl"`
+}
+
+// hostChange is the data used to render the host change manifest.
+type hostChange struct {
+	ChangeID changes.ChangeID
+	Category string
+
+	Host               string
+	Source             string
+	OldValue, NewValue string
+	ChangeValueList    string
+	DiffText           string
+}
+
+func (ipt *Input) gatherChangeEvents() {
+	snap, err := collectHostSnapshot(ipt.hostRoot)
+	if err != nil {
+		l.Warnf("collect host snapshot: %s", err)
+		return
+	}
+
+	if ipt.lastSnapshot == nil {
+		ipt.lastSnapshot = loadHostSnapshot(ipt.snapshotPath)
+	}
+
+	if ipt.lastSnapshot != nil {
+		if pts := ipt.buildChangeEventPoints(diffHostSnapshot(ipt.lastSnapshot, snap, datakit.DKHost)); len(pts) > 0 {
+			if err := ipt.feeder.Feed(point.KeyEvent, pts,
+				dkio.WithElection(false),
+				dkio.WithSource("hostobject-change-event")); err != nil {
+				ipt.feeder.FeedLastError(err.Error(),
+					dkmetrics.WithLastErrorInput(inputName),
+					dkmetrics.WithLastErrorCategory(point.KeyEvent),
+				)
+				l.Errorf("feed change event: %s", err)
+			}
+		}
+	}
+
+	ipt.lastSnapshot = snap
+	if err := saveHostSnapshot(ipt.snapshotPath, snap); err != nil {
+		l.Warnf("save host snapshot: %s", err)
+	}
+}
+
+func (ipt *Input) buildChangeEventPoints(hcs []*hostChange) []*point.Point {
+	var pts []*point.Point
+
+	for _, hc := range hcs {
+		title, message, err := changes.RenderHostTemplate(defaultChangeLanguage, hc.ChangeID, hc)
+		if err != nil {
+			l.Warnf("render host template fail, %s", err)
+			continue
+		}
+
+		var kvs point.KVs
+		kvs = kvs.AddTag("df_event_id", newChangeEventID())
+		kvs = kvs.AddTag("df_source", "change")
+		kvs = kvs.AddTag("df_status", "info")
+		kvs = kvs.AddTag("df_sub_status", "info")
+		kvs = kvs.AddTag("class", hostObjMeasurementName)
+		kvs = kvs.AddTag("name", hc.Host)
+		kvs = kvs.AddTag("category", hc.Category)
+
+		kvs = kvs.Add("df_title", title)
+		kvs = kvs.Add("df_message", message)
+		kvs = kvs.Add("diff", hc.DiffText)
+
+		for k, v := range ipt.mergedTags {
+			kvs = kvs.AddTag(k, v)
+		}
+
+		pts = append(pts, point.NewPoint(changeEventMeasurementName, kvs, point.WithTimestamp(ntp.Now().UnixNano())))
+	}
+
+	return pts
+}
+
+func newChangeEventID() string {
+	u, err := uuid.NewRandom()
+	if err != nil {
+		l.Warnf("cannot generate UUIDv4, err: %s", err)
+		return ""
+	}
+	return "event-" + strings.ToLower(u.String())
+}
+
+func loadHostSnapshot(path string) *hostSnapshot {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			l.Warnf("read host snapshot: %s, ignored", err)
+		}
+		return nil
+	}
+
+	var snap hostSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		l.Warnf("unmarshal host snapshot: %s, ignored", err)
+		return nil
+	}
+	return &snap
+}
+
+func saveHostSnapshot(path string, snap *hostSnapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+func diffHostSnapshot(oldSnap, newSnap *hostSnapshot, host string) (res []*hostChange) {
+	newChange := func(id changes.ChangeID, category string) *hostChange {
+		return &hostChange{ChangeID: id, Category: category, Host: host}
+	}
+
+	// packages
+	if oldSnap.Packages != nil && newSnap.Packages != nil {
+		added, removed, modified := diffKeys(oldSnap.Packages, newSnap.Packages)
+		_, difftext := diff.Compare(oldSnap.Packages, newSnap.Packages)
+
+		for _, x := range []struct {
+			id   changes.ChangeID
+			keys []string
+		}{
+			{changes.HostPackageInstall, added},
+			{changes.HostPackageRemove, removed},
+			{changes.HostPackageUpgrade, modified},
+		} {
+			if len(x.keys) == 0 {
+				continue
+			}
+			hc := newChange(x.id, categoryPackage)
+			hc.Source = newSnap.PackageSource
+			hc.ChangeValueList = formatChangeValues(x.keys, oldSnap.Packages, newSnap.Packages)
+			hc.DiffText = difftext
+			res = append(res, hc)
+		}
+	}
+
+	// listening ports
+	if oldSnap.Ports != nil && newSnap.Ports != nil {
+		added, removed, _ := diffKeys(oldSnap.Ports, newSnap.Ports)
+		_, difftext := diff.Compare(oldSnap.Ports, newSnap.Ports)
+
+		if len(added) > 0 {
+			hc := newChange(changes.HostPortOpen, categoryPort)
+			hc.ChangeValueList = formatChangeValues(added, nil, nil)
+			hc.DiffText = difftext
+			res = append(res, hc)
+		}
+		if len(removed) > 0 {
+			hc := newChange(changes.HostPortClose, categoryPort)
+			hc.ChangeValueList = formatChangeValues(removed, nil, nil)
+			hc.DiffText = difftext
+			res = append(res, hc)
+		}
+	}
+
+	// users and groups
+	for _, x := range []struct {
+		oldMap, newMap              map[string]string
+		addID, deleteID, modifiedID changes.ChangeID
+	}{
+		{oldSnap.Users, newSnap.Users, changes.HostUserAdd, changes.HostUserDelete, changes.HostUserModify},
+		{oldSnap.Groups, newSnap.Groups, changes.HostGroupAdd, changes.HostGroupDelete, changes.HostGroupModify},
+	} {
+		if x.oldMap == nil || x.newMap == nil {
+			continue
+		}
+
+		added, removed, modified := diffKeys(x.oldMap, x.newMap)
+		_, difftext := diff.Compare(x.oldMap, x.newMap)
+
+		for id, keys := range map[changes.ChangeID][]string{
+			x.addID:      added,
+			x.deleteID:   removed,
+			x.modifiedID: modified,
+		} {
+			if len(keys) == 0 {
+				continue
+			}
+			hc := newChange(id, categoryUser)
+			hc.ChangeValueList = formatChangeValues(keys, x.oldMap, x.newMap)
+			hc.DiffText = difftext
+			res = append(res, hc)
+		}
+	}
+
+	// crontabs
+	if oldSnap.Crontabs != nil && newSnap.Crontabs != nil {
+		added, removed, modified := diffKeys(oldSnap.Crontabs, newSnap.Crontabs)
+
+		var list, difftexts []string
+		for _, k := range added {
+			list = append(list, "- Add: "+k)
+			difftexts = append(difftexts, "# "+k+"\n"+diff.LineDiff("", newSnap.Crontabs[k]))
+		}
+		for _, k := range removed {
+			list = append(list, "- Delete: "+k)
+			difftexts = append(difftexts, "# "+k+"\n"+diff.LineDiff(oldSnap.Crontabs[k], ""))
+		}
+		for _, k := range modified {
+			list = append(list, "- Modify: "+k)
+			difftexts = append(difftexts, "# "+k+"\n"+diff.LineDiff(oldSnap.Crontabs[k], newSnap.Crontabs[k]))
+		}
+
+		if len(list) > 0 {
+			hc := newChange(changes.HostCrontab, categoryCrontab)
+			hc.ChangeValueList = strings.Join(list, "\n")
+			hc.DiffText = strings.Join(difftexts, "\n")
+			res = append(res, hc)
+		}
+	}
+
+	// kernel
+	for _, x := range []struct {
+		id               changes.ChangeID
+		oldVal, newValue string
+	}{
+		{changes.HostKernelRelease, oldSnap.KernelRelease, newSnap.KernelRelease},
+		{changes.HostKernelCmdline, oldSnap.KernelCmdline, newSnap.KernelCmdline},
+	} {
+		if x.oldVal == "" || x.newValue == "" || x.oldVal == x.newValue {
+			continue
+		}
+		hc := newChange(x.id, categoryKernel)
+		hc.OldValue = x.oldVal
+		hc.NewValue = x.newValue
+		hc.DiffText = diff.LineDiff(x.oldVal, x.newValue)
+		res = append(res, hc)
+	}
+
+	if oldSnap.Sysctl != nil && newSnap.Sysctl != nil {
+		if equal, difftext := diff.Compare(oldSnap.Sysctl, newSnap.Sysctl); !equal {
+			added, removed, modified := diffKeys(oldSnap.Sysctl, newSnap.Sysctl)
+			keys := append(append(added, removed...), modified...)
+			sort.Strings(keys)
+
+			hc := newChange(changes.HostSysctl, categoryKernel)
+			hc.ChangeValueList = formatChangeValues(keys, oldSnap.Sysctl, newSnap.Sysctl)
+			hc.DiffText = difftext
+			res = append(res, hc)
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool { return res[i].ChangeID < res[j].ChangeID })
+	return res
+}
+
+// diffKeys returns the sorted keys added, removed and modified in newMap.
+func diffKeys(oldMap, newMap map[string]string) (added, removed, modified []string) {
+	for k, newVal := range newMap {
+		if oldVal, ok := oldMap[k]; !ok {
+			added = append(added, k)
+		} else if oldVal != newVal {
+			modified = append(modified, k)
+		}
+	}
+
+	for k := range oldMap {
+		if _, ok := newMap[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(modified)
+	return
+}
+
+func formatChangeValues(keys []string, oldMap, newMap map[string]string) string {
+	var list []string
+
+	for idx, k := range keys {
+		if idx == maxChangeValues {
+			list = append(list, fmt.Sprintf("- ... and %d more", len(keys)-maxChangeValues))
+			break
+		}
+
+		oldVal, oldExist := oldMap[k]
+		newVal, newExist := newMap[k]
+
+		switch {
+		case oldExist && newExist:
+			list = append(list, fmt.Sprintf("- %s: %s -> %s", k, oldVal, newVal))
+		case newExist && newVal != "":
+			list = append(list, fmt.Sprintf("- Add: %s = %s", k, newVal))
+		case oldExist && oldVal != "":
+			list = append(list, fmt.Sprintf("- Delete: %s = %s", k, oldVal))
+		case oldExist:
+			list = append(list, "- Delete: "+k)
+		default:
+			list = append(list, "- "+k)
+		}
+	}
+
+	return strings.Join(list, "\n")
+}
+
+type changeEventMeasurement struct{}
+
+//nolint:lll
+func (*changeEventMeasurement) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name:   changeEventMeasurementName,
+		Desc:   "Changes to host configurations (packages, listening ports, users/groups, crontabs and kernel parameters) will trigger change events with the following tag and fields. For the complete list of change events, see [here](change-event.md#host).",
+		DescZh: "主机配置（软件包、监听端口、用户/用户组、定时任务以及内核参数）变更将触发如下形式的变更事件。完整的变更列表，参见[这里](change-event.md#host)。",
+		Cat:    point.KeyEvent,
+		Tags: map[string]interface{}{
+			"df_source":     inputs.NewTagInfo("The event source is always `change`."),
+			"df_event_id":   inputs.NewTagInfo("The event ID is generated by UUIDv4, e.g. `event-<lowercase UUIDv4>`."),
+			"df_status":     inputs.NewTagInfo("The event source is always `info`."),
+			"df_sub_status": inputs.NewTagInfo("Always `info`."),
+			"class":         inputs.NewTagInfo("Always `HOST`."),
+			"name":          inputs.NewTagInfo("Hostname"),
+			"category":      inputs.NewTagInfo("The category of the change, one of `package/port/user/crontab/kernel`."),
+		},
+		Fields: map[string]interface{}{
+			"df_title":   &inputs.FieldInfo{DataType: inputs.String, Type: inputs.UnknownType, Unit: inputs.UnknownUnit, Desc: "Title of the change, rendered from the change manifest."},
+			"df_message": &inputs.FieldInfo{DataType: inputs.String, Type: inputs.UnknownType, Unit: inputs.UnknownUnit, Desc: "Details of the change, rendered from the change manifest."},
+			"diff":       &inputs.FieldInfo{DataType: inputs.String, Type: inputs.UnknownType, Unit: inputs.UnknownUnit, Desc: "Diff text of host changes."},
+		},
+	}
+}
@@ -0,0 +1,405 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+//go:build linux
+// +build linux
+
+package hostobject
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rpmTimeout limit the time cost of rpm query during host snapshot.
+const rpmTimeout = 30 * time.Second
+
+var (
+	crontabFiles = []string{"/etc/crontab"}
+	crontabDirs  = []string{"/etc/cron.d", "/var/spool/cron", "/var/spool/cron/crontabs"}
+
+	// sysctl keys that change frequently by the kernel itself, kernel release
+	// and version are reported by the kernel release change.
+	volatileSysctlPrefixes = []string{
+		"fs.aio-nr",
+		"fs.dentry-state",
+		"fs.file-nr",
+		"fs.inode-nr",
+		"fs.inode-state",
+		"fs.quota.",
+		"kernel.ns_last_pid",
+		"kernel.osrelease",
+		"kernel.perf_event_max_sample_rate",
+		"kernel.pty.nr",
+		"kernel.random.",
+		"kernel.version",
+		"net.netfilter.nf_conntrack_count",
+	}
+
+	// per-interface sysctl keys, only `all` and `default` are kept, or
+	// there will be lots of changes on container hosts.
+	perInterfaceSysctlPrefixes = []string{
+		"net.ipv4.conf.",
+		"net.ipv4.neigh.",
+		"net.ipv6.conf.",
+		"net.ipv6.neigh.",
+		"net.mpls.conf.",
+	}
+)
+
+func collectHostSnapshot(hostRoot string) (*hostSnapshot, error) {
+	snap := &hostSnapshot{}
+
+	var err error
+	if snap.PackageSource, snap.Packages, err = collectPackages(hostRoot); err != nil {
+		l.Debugf("collect packages: %s, ignored", err)
+	}
+
+	if snap.Ports, err = collectListeningPorts(hostRoot); err != nil {
+		l.Debugf("collect listening ports: %s, ignored", err)
+	}
+
+	if snap.Users, err = parseColonFile(filepath.Join(hostRoot, "/etc/passwd"), 7, func(fields []string) string {
+		return fmt.Sprintf("uid=%s,gid=%s,home=%s,shell=%s", fields[2], fields[3], fields[5], fields[6])
+	}); err != nil {
+		l.Debugf("collect users: %s, ignored", err)
+	}
+
+	if snap.Groups, err = parseColonFile(filepath.Join(hostRoot, "/etc/group"), 4, func(fields []string) string {
+		return fmt.Sprintf("gid=%s,members=%s", fields[2], fields[3])
+	}); err != nil {
+		l.Debugf("collect groups: %s, ignored", err)
+	}
+
+	snap.Crontabs = collectCrontabs(hostRoot)
+
+	if data, err := os.ReadFile(filepath.Join(hostRoot, "/proc/sys/kernel/osrelease")); err == nil {
+		snap.KernelRelease = strings.TrimSpace(string(data))
+	}
+
+	if data, err := os.ReadFile(filepath.Join(hostRoot, "/proc/cmdline")); err == nil {
+		snap.KernelCmdline = strings.TrimSpace(string(data))
+	}
+
+	if snap.Sysctl, err = collectSysctl(hostRoot); err != nil {
+		l.Debugf("collect sysctl: %s, ignored", err)
+	}
+
+	return snap, nil
+}
+
+func collectPackages(hostRoot string) (string, map[string]string, error) {
+	if f, err := os.Open(filepath.Join(hostRoot, "/var/lib/dpkg/status")); err == nil {
+		defer f.Close() //nolint:errcheck,gosec
+		return "dpkg", parseDpkgStatus(f), nil
+	}
+
+	if _, err := os.Stat(filepath.Join(hostRoot, "/var/lib/rpm")); err == nil {
+		pkgs, err := queryRPM(hostRoot)
+		return "rpm", pkgs, err
+	}
+
+	return "", nil, fmt.Errorf("no dpkg or rpm database found")
+}
+
+// parseDpkgStatus parse the installed packages from dpkg status file.
+func parseDpkgStatus(r io.Reader) map[string]string {
+	pkgs := map[string]string{}
+
+	var name, version, status string
+	flush := func() {
+		if name != "" && strings.HasSuffix(status, " installed") {
+			addPackage(pkgs, name, version)
+		}
+		name, version, status = "", "", ""
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "Package: "):
+			name = strings.TrimPrefix(line, "Package: ")
+		case strings.HasPrefix(line, "Version: "):
+			version = strings.TrimPrefix(line, "Version: ")
+		case strings.HasPrefix(line, "Status: "):
+			status = strings.TrimPrefix(line, "Status: ")
+		}
+	}
+	flush()
+
+	return pkgs
+}
+
+func queryRPM(hostRoot string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rpmTimeout)
+	defer cancel()
+
+	args := []string{"-qa", "--queryformat", `%{NAME}\t%{VERSION}-%{RELEASE}.%{ARCH}\n`}
+	if hostRoot != "" {
+		args = append(args, "--root", hostRoot)
+	}
+
+	out, err := exec.CommandContext(ctx, "rpm", args...).Output() //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("rpm query: %w", err)
+	}
+
+	pkgs := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if parts := strings.SplitN(line, "\t", 2); len(parts) == 2 {
+			addPackage(pkgs, parts[0], parts[1])
+		}
+	}
+	return pkgs, nil
+}
+
+// addPackage add package version, multiple versions of the same package
+// (such as kernel and gpg-pubkey) are joined by comma.
+func addPackage(pkgs map[string]string, name, version string) {
+	if old, ok := pkgs[name]; ok {
+		versions := append(strings.Split(old, ","), version)
+		sort.Strings(versions)
+		version = strings.Join(versions, ",")
+	}
+	pkgs[name] = version
+}
+
+func collectListeningPorts(hostRoot string) (map[string]string, error) {
+	ports := map[string]string{}
+
+	// unconnected UDP client sockets are bound on ephemeral ports, they are not
+	// listening ports.
+	ephemeralLow, ephemeralHigh := 32768, 60999
+	if data, err := os.ReadFile(filepath.Join(hostRoot, "/proc/sys/net/ipv4/ip_local_port_range")); err == nil {
+		if fields := strings.Fields(string(data)); len(fields) == 2 {
+			low, err1 := strconv.Atoi(fields[0])
+			high, err2 := strconv.Atoi(fields[1])
+			if err1 == nil && err2 == nil {
+				ephemeralLow, ephemeralHigh = low, high
+			}
+		}
+	}
+
+	var lastErr error
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		data, err := os.ReadFile(filepath.Join(hostRoot, "/proc/net", proto))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		for _, sock := range parseProcNet(proto, data) {
+			if strings.HasPrefix(proto, "udp") && sock.port >= ephemeralLow && sock.port <= ephemeralHigh {
+				continue
+			}
+			ports[fmt.Sprintf("%s/%s", proto, net.JoinHostPort(sock.addr, strconv.Itoa(sock.port)))] = ""
+		}
+	}
+
+	if len(ports) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return ports, nil
+}
+
+type listenSocket struct {
+	addr string
+	port int
+}
+
+// parseProcNet parse listening sockets from /proc/net/{tcp,tcp6,udp,udp6}.
+func parseProcNet(proto string, data []byte) []listenSocket {
+	const (
+		stateTCPListen = "0A"
+		stateUDPClose  = "07"
+	)
+
+	var res []listenSocket
+	for idx, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if idx == 0 || len(fields) < 4 {
+			continue
+		}
+
+		state := fields[3]
+		if strings.HasPrefix(proto, "tcp") && state != stateTCPListen {
+			continue
+		}
+		if strings.HasPrefix(proto, "udp") && (state != stateUDPClose || !strings.HasSuffix(fields[2], ":0000")) {
+			continue
+		}
+
+		addr, port, err := parseHexAddr(fields[1])
+		if err != nil {
+			continue
+		}
+		res = append(res, listenSocket{addr: addr, port: port})
+	}
+
+	return res
+}
+
+func parseHexAddr(s string) (string, int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("invalid address %q", s)
+	}
+
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return "", 0, err
+	}
+
+	b, err := hex.DecodeString(parts[0])
+	if err != nil || (len(b) != net.IPv4len && len(b) != net.IPv6len) {
+		return "", 0, fmt.Errorf("invalid address %q", s)
+	}
+
+	// the address is stored as 32-bit words in host byte order(little endian)
+	for i := 0; i < len(b); i += 4 {
+		b[i], b[i+1], b[i+2], b[i+3] = b[i+3], b[i+2], b[i+1], b[i]
+	}
+
+	return net.IP(b).String(), int(port), nil
+}
+
+func parseColonFile(path string, minFields int, valueFn func([]string) string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ":")
+		if len(fields) < minFields {
+			continue
+		}
+		res[fields[0]] = valueFn(fields)
+	}
+	return res, nil
+}
+
+func collectCrontabs(hostRoot string) map[string]string {
+	files := append([]string{}, crontabFiles...)
+	for _, dir := range crontabDirs {
+		entries, err := os.ReadDir(filepath.Join(hostRoot, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				files = append(files, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	res := map[string]string{}
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(hostRoot, file))
+		if err != nil {
+			continue
+		}
+		res[file] = cleanCrontab(data)
+	}
+	return res
+}
+
+// cleanCrontab remove comments and blank lines in crontab.
+func cleanCrontab(data []byte) string {
+	var lines []string
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		lines = append(lines, string(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func collectSysctl(hostRoot string) (map[string]string, error) {
+	root := filepath.Join(hostRoot, "/proc/sys")
+
+	res := map[string]string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+
+		key := strings.ReplaceAll(rel, "/", ".")
+		if ignoreSysctl(key) {
+			return nil
+		}
+
+		if info, err := d.Info(); err != nil || info.Mode().Perm()&0o444 == 0 {
+			return nil // not readable
+		}
+
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil
+		}
+
+		res[key] = strings.Join(strings.Fields(string(data)), " ")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func ignoreSysctl(key string) bool {
+	for _, prefix := range volatileSysctlPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	for _, prefix := range perInterfaceSysctlPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			iface := strings.SplitN(strings.TrimPrefix(key, prefix), ".", 2)[0]
+			return iface != "all" && iface != "default"
+		}
+	}
+
+	return false
+}
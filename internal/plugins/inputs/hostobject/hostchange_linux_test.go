@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+//go:build linux
+// +build linux
+
+package hostobject
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDpkgStatus(t *testing.T) {
+	status := `Package: nginx
+Status: install ok installed
+Priority: optional
+Version: 1.18.0-0ubuntu1
+Description: small, powerful, scalable web/proxy server
+ Nginx ("engine X") is a high-performance web and reverse proxy server.
+
+Package: vim
+Status: deinstall ok config-files
+Version: 2:8.1.2269-1ubuntu5
+
+Package: libc6
+Status: install ok installed
+Version: 2.31-0ubuntu9.9
+Architecture: amd64
+
+Package: libc6
+Status: install ok installed
+Version: 2.31-0ubuntu9.7
+Architecture: i386
+`
+
+	assert.Equal(t, map[string]string{
+		"nginx": "1.18.0-0ubuntu1",
+		"libc6": "2.31-0ubuntu9.7,2.31-0ubuntu9.9",
+	}, parseDpkgStatus(strings.NewReader(status)))
+}
+
+func TestParseProcNet(t *testing.T) {
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 25227 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:0CEA 00000000:0000 0A 00000000:00000000 00:00000000 00000000   113        0 28790 1 0000000000000000 100 0 0 10 0
+   2: 0F02000A:0016 0202000A:C5E4 01 00000000:00000000 02:0004E1A4 00000000     0        0 73127 4 0000000000000000 20 4 29 10 -1
+`
+	assert.Equal(t, []listenSocket{
+		{addr: "0.0.0.0", port: 22},
+		{addr: "127.0.0.1", port: 3306},
+	}, parseProcNet("tcp", []byte(tcp)))
+
+	tcp6 := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000001000000:0277 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 30462 1 0000000000000000 100 0 0 10 0
+`
+	assert.Equal(t, []listenSocket{{addr: "::1", port: 631}}, parseProcNet("tcp6", []byte(tcp6)))
+
+	udp := `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  100: 3500007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 24470 2 0000000000000000 0
+  101: 0F02000A:9C40 08080808:0035 01 00000000:00000000 00:00000000 00000000     0        0 24471 2 0000000000000000 0
+`
+	assert.Equal(t, []listenSocket{{addr: "127.0.0.53", port: 53}}, parseProcNet("udp", []byte(udp)))
+}
+
+func TestIgnoreSysctl(t *testing.T) {
+	for key, ignored := range map[string]bool{
+		"vm.swappiness":                       false,
+		"net.ipv4.conf.all.forwarding":        false,
+		"net.ipv4.conf.default.rp_filter":     false,
+		"net.ipv4.conf.veth1a2b3c.rp_filter":  true,
+		"net.ipv6.neigh.eth0.retrans_time_ms": true,
+		"kernel.random.entropy_avail":         true,
+		"fs.file-nr":                          true,
+	} {
+		assert.Equal(t, ignored, ignoreSysctl(key), key)
+	}
+}
+
+func TestCollectHostSnapshot(t *testing.T) {
+	root := t.TempDir()
+
+	write := func(path, content string) {
+		path = filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	write("/var/lib/dpkg/status", "Package: nginx\nStatus: install ok installed\nVersion: 1.18.0\n")
+	write("/etc/passwd", "root:x:0:0:root:/root:/bin/bash\n# comment\nalice:x:1000:1000::/home/alice:/bin/sh\n")
+	write("/etc/group", "root:x:0:\ndocker:x:999:alice,bob\n")
+	write("/etc/crontab", "# m h dom mon dow user command\n\n17 * * * * root cd / && run-parts --report /etc/cron.hourly\n")
+	write("/etc/cron.d/backup", "0 2 * * * root /opt/backup.sh\n")
+	write("/proc/sys/kernel/osrelease", "5.4.0-100-generic\n")
+	write("/proc/sys/vm/swappiness", "60\n")
+	write("/proc/sys/kernel/random/entropy_avail", "256\n")
+	write("/proc/cmdline", "BOOT_IMAGE=/vmlinuz ro quiet\n")
+	write("/proc/net/tcp", "  sl  local_address rem_address   st\n   0: 00000000:0016 00000000:0000 0A\n")
+
+	snap, err := collectHostSnapshot(root)
+	require.NoError(t, err)
+
+	assert.Equal(t, "dpkg", snap.PackageSource)
+	assert.Equal(t, map[string]string{"nginx": "1.18.0"}, snap.Packages)
+	assert.Equal(t, map[string]string{"tcp/0.0.0.0:22": ""}, snap.Ports)
+	assert.Equal(t, map[string]string{
+		"root":  "uid=0,gid=0,home=/root,shell=/bin/bash",
+		"alice": "uid=1000,gid=1000,home=/home/alice,shell=/bin/sh",
+	}, snap.Users)
+	assert.Equal(t, "gid=999,members=alice,bob", snap.Groups["docker"])
+	assert.Equal(t, map[string]string{
+		"/etc/crontab":       "17 * * * * root cd / && run-parts --report /etc/cron.hourly",
+		"/etc/cron.d/backup": "0 2 * * * root /opt/backup.sh",
+	}, snap.Crontabs)
+	assert.Equal(t, "5.4.0-100-generic", snap.KernelRelease)
+	assert.Equal(t, "BOOT_IMAGE=/vmlinuz ro quiet", snap.KernelCmdline)
+	assert.Equal(t, map[string]string{"vm.swappiness": "60"}, snap.Sysctl)
+}
@@ -0,0 +1,18 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+//go:build !linux
+// +build !linux
+
+package hostobject
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func collectHostSnapshot(_ string) (*hostSnapshot, error) {
+	return nil, fmt.Errorf("host change not supported under %s", runtime.GOOS)
+}
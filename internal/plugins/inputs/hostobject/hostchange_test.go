@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package hostobject
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/changes"
+)
+
+func TestDiffHostSnapshot(t *testing.T) {
+	oldSnap := &hostSnapshot{
+		PackageSource: "dpkg",
+		Packages:      map[string]string{"nginx": "1.18.0", "curl": "7.68.0", "vim": "8.1"},
+		Ports:         map[string]string{"tcp/0.0.0.0:22": "", "tcp/0.0.0.0:80": ""},
+		Users:         map[string]string{"root": "uid=0,gid=0,home=/root,shell=/bin/bash"},
+		Groups:        map[string]string{"docker": "gid=999,members="},
+		Crontabs:      map[string]string{"/etc/crontab": "0 * * * * root run-parts /etc/cron.hourly"},
+		KernelRelease: "5.4.0-100-generic",
+		KernelCmdline: "ro quiet",
+		Sysctl:        map[string]string{"vm.swappiness": "60", "net.core.somaxconn": "4096"},
+	}
+
+	t.Run("no-change", func(t *testing.T) {
+		assert.Empty(t, diffHostSnapshot(oldSnap, oldSnap, "host-01"))
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		// items unavailable on new snapshot should not report as removed
+		assert.Empty(t, diffHostSnapshot(oldSnap, &hostSnapshot{}, "host-01"))
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		newSnap := &hostSnapshot{
+			PackageSource: "dpkg",
+			Packages:      map[string]string{"nginx": "1.20.0", "curl": "7.68.0", "redis": "6.0"},
+			Ports:         map[string]string{"tcp/0.0.0.0:22": "", "tcp/0.0.0.0:6379": ""},
+			Users: map[string]string{
+				"root":  "uid=0,gid=0,home=/root,shell=/bin/bash",
+				"alice": "uid=1000,gid=1000,home=/home/alice,shell=/bin/bash",
+			},
+			Groups:        map[string]string{"docker": "gid=999,members=alice"},
+			Crontabs:      map[string]string{"/etc/crontab": "0 * * * * root run-parts /etc/cron.hourly", "/etc/cron.d/backup": "0 2 * * * root /opt/backup.sh"},
+			KernelRelease: "5.4.0-110-generic",
+			KernelCmdline: "ro quiet",
+			Sysctl:        map[string]string{"vm.swappiness": "10", "net.core.somaxconn": "4096"},
+		}
+
+		hcs := diffHostSnapshot(oldSnap, newSnap, "host-01")
+
+		res := map[changes.ChangeID]*hostChange{}
+		for _, hc := range hcs {
+			assert.Equal(t, "host-01", hc.Host)
+			res[hc.ChangeID] = hc
+		}
+
+		require.Len(t, res, 10)
+
+		assert.Equal(t, "- Add: redis = 6.0", res[changes.HostPackageInstall].ChangeValueList)
+		assert.Equal(t, "dpkg", res[changes.HostPackageInstall].Source)
+		assert.Equal(t, "- Delete: vim = 8.1", res[changes.HostPackageRemove].ChangeValueList)
+		assert.Equal(t, "- nginx: 1.18.0 -> 1.20.0", res[changes.HostPackageUpgrade].ChangeValueList)
+		assert.Equal(t, categoryPackage, res[changes.HostPackageUpgrade].Category)
+
+		assert.Equal(t, "- tcp/0.0.0.0:6379", res[changes.HostPortOpen].ChangeValueList)
+		assert.Equal(t, "- tcp/0.0.0.0:80", res[changes.HostPortClose].ChangeValueList)
+
+		assert.Equal(t, "- Add: alice = uid=1000,gid=1000,home=/home/alice,shell=/bin/bash", res[changes.HostUserAdd].ChangeValueList)
+		assert.Equal(t, "- docker: gid=999,members= -> gid=999,members=alice", res[changes.HostGroupModify].ChangeValueList)
+
+		assert.Equal(t, "- Add: /etc/cron.d/backup", res[changes.HostCrontab].ChangeValueList)
+		assert.Contains(t, res[changes.HostCrontab].DiffText, "/opt/backup.sh")
+
+		assert.Equal(t, "5.4.0-100-generic", res[changes.HostKernelRelease].OldValue)
+		assert.Equal(t, "5.4.0-110-generic", res[changes.HostKernelRelease].NewValue)
+
+		assert.Equal(t, "- vm.swappiness: 60 -> 10", res[changes.HostSysctl].ChangeValueList)
+		assert.NotEmpty(t, res[changes.HostSysctl].DiffText)
+
+		// sorted by change ID
+		for i := 1; i < len(hcs); i++ {
+			assert.True(t, hcs[i-1].ChangeID <= hcs[i].ChangeID)
+		}
+	})
+}
+
+func TestFormatChangeValues(t *testing.T) {
+	var keys []string
+	newMap := map[string]string{}
+	for i := 0; i < maxChangeValues+10; i++ {
+		k := "pkg" + strings.Repeat("x", i)
+		keys = append(keys, k)
+		newMap[k] = "1.0"
+	}
+
+	list := strings.Split(formatChangeValues(keys, nil, newMap), "\n")
+	require.Len(t, list, maxChangeValues+1)
+	assert.Equal(t, "- ... and 10 more", list[maxChangeValues])
+}
+
+func TestHostSnapshotPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", snapshotFile)
+
+	assert.Nil(t, loadHostSnapshot(path))
+
+	snap := &hostSnapshot{
+		Packages: map[string]string{"nginx": "1.18.0"},
+		Ports:    map[string]string{},
+	}
+	require.NoError(t, saveHostSnapshot(path, snap))
+
+	got := loadHostSnapshot(path)
+	require.NotNil(t, got)
+	assert.Equal(t, snap.Packages, got.Packages)
+	assert.NotNil(t, got.Ports) // empty but available
+	assert.Nil(t, got.Users)    // unavailable
+}
+
+func TestBuildChangeEventPoints(t *testing.T) {
+	require.NoError(t, changes.LoadAllManifests())
+
+	ipt := defaultInput()
+	ipt.mergedTags = map[string]string{"some_tag": "some_value"}
+
+	pts := ipt.buildChangeEventPoints([]*hostChange{
+		{
+			ChangeID: changes.HostKernelRelease,
+			Category: categoryKernel,
+			Host:     "host-01",
+			OldValue: "5.4.0-100-generic",
+			NewValue: "5.4.0-110-generic",
+			DiffText: "-5.4.0-100-generic\n+5.4.0-110-generic",
+		},
+	})
+	require.Len(t, pts, 1)
+
+	pt := pts[0]
+	assert.Equal(t, changeEventMeasurementName, pt.Name())
+	assert.Equal(t, "change", pt.GetTag("df_source"))
+	assert.Equal(t, "HOST", pt.GetTag("class"))
+	assert.Equal(t, "host-01", pt.GetTag("name"))
+	assert.Equal(t, categoryKernel, pt.GetTag("category"))
+	assert.Equal(t, "some_value", pt.GetTag("some_tag"))
+	assert.True(t, strings.HasPrefix(pt.GetTag("df_event_id"), "event-"))
+	assert.Equal(t, "Host host-01 Kernel Release Change", pt.Get("df_title"))
+	assert.Contains(t, pt.Get("df_message"), "New release: 5.4.0-110-generic")
+}
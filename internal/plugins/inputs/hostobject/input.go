@@ -23,6 +23,7 @@ import (
 	diskutil "github.com/shirou/gopsutil/disk"
 	netutil "github.com/shirou/gopsutil/net"
 
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/changes"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/config"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/httpapi"
@@ -90,6 +91,10 @@ type Input struct {
 	CloudInfo                map[string]string `toml:"cloud_info,omitempty"`
 	lastSync                 time.Time
 
+	EnableChangeEvent bool `toml:"enable_change_event"`
+	lastSnapshot      *hostSnapshot
+	snapshotPath      string
+
 	netIOCounters  NetIOCounters
 	diskIOCounters DiskIOCounters
 	lastDiskIOInfo diskIOInfo
@@ -138,6 +143,10 @@ func (ipt *Input) Run() {
 			}
 		}
 
+		if ipt.EnableChangeEvent {
+			ipt.gatherChangeEvents()
+		}
+
 		select {
 		case <-datakit.Exit.Wait():
 			l.Infof("%s exit on sem", inputName)
@@ -171,6 +180,20 @@ func (ipt *Input) setup() {
 	ipt.mergedTags = inputs.MergeTags(ipt.tagger.HostTags(), ipt.Tags, "")
 	l.Debugf("merged tags: %+#v", ipt.mergedTags)
 
+	if ipt.EnableChangeEvent {
+		if runtime.GOOS != datakit.OSLinux {
+			l.Warnf("host change event not supported under %q, disabled", runtime.GOOS)
+			ipt.EnableChangeEvent = false
+		} else if err := changes.LoadAllManifests(); err != nil {
+			l.Errorf("load manifests fail, host change event disabled: %s", err)
+			ipt.EnableChangeEvent = false
+		}
+
+		if ipt.snapshotPath == "" {
+			ipt.snapshotPath = datakit.JoinToCacheDir(snapshotFile)
+		}
+	}
+
 	if ipt.IgnoreFSTypes != "" {
 		if re, err := regexp.Compile(ipt.IgnoreFSTypes); err != nil {
 			l.Warnf("regexp.Compile(%q): %s, ignored", ipt.IgnoreFSTypes, err.Error())
@@ -307,6 +330,7 @@ func (*Input) AvailableArchs() []string { return datakit.AllOS }
 func (*Input) SampleMeasurement() []inputs.Measurement {
 	return []inputs.Measurement{
 		&docMeasurement{},
+		&changeEventMeasurement{},
 	}
 }
 
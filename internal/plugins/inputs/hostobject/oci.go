@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package hostobject
+
+import "encoding/json"
+
+const ociMetaRootURL = "http://169.254.169.254/opc/v2"
+
+// OCI IMDS v2 requires the header `Authorization: Bearer Oracle`.
+var ociMetaHeaders = map[string]string{"Authorization": "Bearer Oracle"}
+
+type oci struct {
+	baseURL string
+
+	instance *ociInstance
+	vnics    []*ociVnic
+}
+
+type ociInstance struct {
+	ID                  string `json:"id"`
+	DisplayName         string `json:"displayName"`
+	Shape               string `json:"shape"`
+	State               string `json:"state"`
+	Region              string `json:"region"`
+	CanonicalRegionName string `json:"canonicalRegionName"`
+	AvailabilityDomain  string `json:"availabilityDomain"`
+}
+
+type ociVnic struct {
+	VnicID    string `json:"vnicId"`
+	PrivateIP string `json:"privateIp"`
+}
+
+func (x *oci) Sync() (map[string]any, error) {
+	x.instance = nil
+	if resp := metadataGetWithHeaders(x.baseURL+"/instance/", ociMetaHeaders); resp != nil {
+		instance := &ociInstance{}
+		if err := json.Unmarshal(resp, instance); err != nil {
+			l.Warnf("unmarshal OCI instance metadata failed: %s", err)
+		} else {
+			x.instance = instance
+		}
+	}
+
+	x.vnics = nil
+	if resp := metadataGetWithHeaders(x.baseURL+"/vnics/", ociMetaHeaders); resp != nil {
+		if err := json.Unmarshal(resp, &x.vnics); err != nil {
+			l.Warnf("unmarshal OCI vnics metadata failed: %s", err)
+		}
+	}
+
+	return map[string]any{
+		"cloud_provider":        OCI,
+		"description":           x.Description(),
+		"instance_id":           x.InstanceID(),
+		"instance_name":         x.InstanceName(),
+		"instance_type":         x.InstanceType(),
+		"instance_charge_type":  x.InstanceChargeType(),
+		"instance_network_type": x.InstanceNetworkType(),
+		"instance_status":       x.InstanceStatus(),
+		"security_group_id":     x.SecurityGroupID(),
+		"private_ip":            x.PrivateIP(),
+		"zone_id":               x.ZoneID(),
+		"region":                x.Region(),
+	}, nil
+}
+
+func (x *oci) Description() string {
+	return Unavailable
+}
+
+func (x *oci) InstanceID() string {
+	if x.instance != nil {
+		return x.instance.ID
+	}
+	return Unavailable
+}
+
+func (x *oci) InstanceName() string {
+	if x.instance != nil {
+		return x.instance.DisplayName
+	}
+	return Unavailable
+}
+
+func (x *oci) InstanceType() string {
+	if x.instance != nil {
+		return x.instance.Shape
+	}
+	return Unavailable
+}
+
+func (x *oci) InstanceChargeType() string {
+	return Unavailable
+}
+
+func (x *oci) InstanceNetworkType() string {
+	return Unavailable
+}
+
+func (x *oci) InstanceStatus() string {
+	if x.instance != nil {
+		return x.instance.State
+	}
+	return Unavailable
+}
+
+func (x *oci) SecurityGroupID() string {
+	return Unavailable
+}
+
+func (x *oci) PrivateIP() string {
+	if len(x.vnics) > 0 && x.vnics[0] != nil {
+		return x.vnics[0].PrivateIP
+	}
+	return Unavailable
+}
+
+func (x *oci) ZoneID() string {
+	if x.instance != nil {
+		return x.instance.AvailabilityDomain
+	}
+	return Unavailable
+}
+
+// Region prefer the canonical region name(such as `us-ashburn-1`) to the
+// short region key(such as `iad`).
+func (x *oci) Region() string {
+	if x.instance != nil {
+		if x.instance.CanonicalRegionName != "" {
+			return x.instance.CanonicalRegionName
+		}
+		return x.instance.Region
+	}
+	return Unavailable
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package hostobject
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ociInstanceData = `{
+  "availabilityDomain": "EMIr:US-ASHBURN-AD-1",
+  "faultDomain": "FAULT-DOMAIN-3",
+  "compartmentId": "ocid1.tenancy.oc1..aaaaaaaa",
+  "displayName": "oci-test",
+  "hostname": "oci-test",
+  "id": "ocid1.instance.oc1.iad.anuwcljt",
+  "image": "ocid1.image.oc1.iad.aaaaaaaa",
+  "region": "iad",
+  "canonicalRegionName": "us-ashburn-1",
+  "ociAdName": "iad-ad-1",
+  "shape": "VM.Standard.E4.Flex",
+  "state": "Running",
+  "timeCreated": 1600381928581
+}`
+	ociVnicsData = `[
+  {
+    "vnicId": "ocid1.vnic.oc1.iad.abuwcljs",
+    "privateIp": "10.0.3.6",
+    "vlanTag": 11,
+    "macAddr": "00:00:00:00:00:01",
+    "virtualRouterIp": "10.0.3.1",
+    "subnetCidrBlock": "10.0.3.0/24"
+  }
+]`
+)
+
+func TestOCISync(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer Oracle" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/opc/v2/instance/":
+			fmt.Fprint(w, ociInstanceData)
+		case "/opc/v2/vnics/":
+			fmt.Fprint(w, ociVnicsData)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	ipt := defaultInput()
+	ipt.CloudMetaURL = map[string]string{OCI: ts.URL + "/opc/v2"}
+
+	info, err := ipt.SyncCloudInfo(OCI)
+	require.NoError(t, err)
+
+	assert.Equal(t, OCI, info["cloud_provider"])
+	assert.Equal(t, "ocid1.instance.oc1.iad.anuwcljt", info["instance_id"])
+	assert.Equal(t, "oci-test", info["instance_name"])
+	assert.Equal(t, "VM.Standard.E4.Flex", info["instance_type"])
+	assert.Equal(t, "Running", info["instance_status"])
+	assert.Equal(t, "10.0.3.6", info["private_ip"])
+	assert.Equal(t, "EMIr:US-ASHBURN-AD-1", info["zone_id"])
+	assert.Equal(t, "us-ashburn-1", info["region"])
+
+	assert.True(t, ipt.matchCloudProvider(OCI))
+
+	// not an OCI instance
+	ipt.CloudMetaURL[OCI] = ts.URL + "/not-exist"
+	info, err = ipt.SyncCloudInfo(OCI)
+	require.NoError(t, err)
+	assert.Equal(t, Unavailable, info["instance_id"])
+	assert.Equal(t, Unavailable, info["private_ip"])
+	assert.False(t, ipt.matchCloudProvider(OCI))
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package hostobject
+
+import "encoding/json"
+
+// openstackMetaRootURL is the root of OpenStack metadata service, both the
+// OpenStack(/openstack/latest) and EC2 compatible(/latest/meta-data) API
+// are used.
+const openstackMetaRootURL = "http://169.254.169.254"
+
+type openstack struct {
+	baseURL string
+
+	meta *openstackMetaData
+}
+
+type openstackMetaData struct {
+	UUID             string `json:"uuid"`
+	Name             string `json:"name"`
+	AvailabilityZone string `json:"availability_zone"`
+}
+
+func (x *openstack) Sync() (map[string]any, error) {
+	x.meta = nil
+	if resp := metadataGet(x.baseURL + "/openstack/latest/meta_data.json"); resp != nil {
+		meta := &openstackMetaData{}
+		if err := json.Unmarshal(resp, meta); err != nil {
+			l.Warnf("unmarshal OpenStack metadata failed: %s", err)
+		} else {
+			x.meta = meta
+		}
+	}
+
+	return map[string]any{
+		"cloud_provider":        OpenStack,
+		"description":           x.Description(),
+		"instance_id":           x.InstanceID(),
+		"instance_name":         x.InstanceName(),
+		"instance_type":         x.InstanceType(),
+		"instance_charge_type":  x.InstanceChargeType(),
+		"instance_network_type": x.InstanceNetworkType(),
+		"instance_status":       x.InstanceStatus(),
+		"security_group_id":     x.SecurityGroupID(),
+		"private_ip":            x.PrivateIP(),
+		"zone_id":               x.ZoneID(),
+		"region":                x.Region(),
+	}, nil
+}
+
+func (x *openstack) Description() string {
+	return Unavailable
+}
+
+func (x *openstack) InstanceID() string {
+	if x.meta != nil && x.meta.UUID != "" {
+		return x.meta.UUID
+	}
+	return Unavailable
+}
+
+func (x *openstack) InstanceName() string {
+	if x.meta != nil {
+		return x.meta.Name
+	}
+	return Unavailable
+}
+
+func (x *openstack) InstanceType() string {
+	return metaGet(x.baseURL + "/latest/meta-data/instance-type")
+}
+
+func (x *openstack) InstanceChargeType() string {
+	return Unavailable
+}
+
+func (x *openstack) InstanceNetworkType() string {
+	return Unavailable
+}
+
+func (x *openstack) InstanceStatus() string {
+	return Unavailable
+}
+
+func (x *openstack) SecurityGroupID() string {
+	return metaGet(x.baseURL + "/latest/meta-data/security-groups")
+}
+
+func (x *openstack) PrivateIP() string {
+	return metaGet(x.baseURL + "/latest/meta-data/local-ipv4")
+}
+
+func (x *openstack) ZoneID() string {
+	if x.meta != nil {
+		return x.meta.AvailabilityZone
+	}
+	return Unavailable
+}
+
+// Region is not available in OpenStack metadata.
+func (x *openstack) Region() string {
+	return Unavailable
+}
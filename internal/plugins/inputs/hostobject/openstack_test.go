@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package hostobject
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const openstackMetaDataJSON = `{
+  "uuid": "d8e02d56-2648-49a3-bf97-6be8f1204f38",
+  "availability_zone": "nova",
+  "hostname": "openstack-test.novalocal",
+  "launch_index": 0,
+  "meta": {"role": "webservers"},
+  "name": "openstack-test",
+  "project_id": "f7ac731cc11f40efbc03a9f9e1d1d21f"
+}`
+
+func TestOpenStackSync(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openstack/latest/meta_data.json":
+			fmt.Fprint(w, openstackMetaDataJSON)
+		case "/latest/meta-data/instance-type":
+			fmt.Fprint(w, "m1.small")
+		case "/latest/meta-data/local-ipv4":
+			fmt.Fprint(w, "10.0.0.5")
+		case "/latest/meta-data/security-groups":
+			fmt.Fprint(w, "default")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	ipt := defaultInput()
+	ipt.CloudMetaURL = map[string]string{OpenStack: ts.URL}
+
+	info, err := ipt.SyncCloudInfo(OpenStack)
+	require.NoError(t, err)
+
+	assert.Equal(t, OpenStack, info["cloud_provider"])
+	assert.Equal(t, "d8e02d56-2648-49a3-bf97-6be8f1204f38", info["instance_id"])
+	assert.Equal(t, "openstack-test", info["instance_name"])
+	assert.Equal(t, "m1.small", info["instance_type"])
+	assert.Equal(t, "10.0.0.5", info["private_ip"])
+	assert.Equal(t, "default", info["security_group_id"])
+	assert.Equal(t, "nova", info["zone_id"])
+	assert.Equal(t, Unavailable, info["region"])
+
+	assert.True(t, ipt.matchCloudProvider(OpenStack))
+}
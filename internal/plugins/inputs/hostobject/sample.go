@@ -55,8 +55,12 @@ enable_cloud_aws_imds_v2 = false
 ## Enable AWS IPv6
 enable_cloud_aws_ipv6 = false
 
+## Enable host change event(Linux only): diff packages, listening ports, users/groups,
+## crontabs and kernel parameters between collections and report the changes as events.
+# enable_change_event = false
+
 ## [inputs.hostobject.tags] # (optional) custom tags
-  # cloud_provider = "aliyun" # aliyun/tencent/aws/hwcloud/azure/volcengine, probe automatically if not set
+  # cloud_provider = "aliyun" # aliyun/tencent/aws/hwcloud/azure/volcengine/gcp/oci/openstack, probe automatically if not set
   # some_tag = "some_value"
   # more_tag = "some_other_value"
   # ...
@@ -68,6 +72,9 @@ enable_cloud_aws_ipv6 = false
   # azure = ""
   # Hwcloud = ""
   # volcengine = ""
+  # gcp = ""        # e.g. http://metadata.google.internal/computeMetadata/v1/instance
+  # oci = ""        # e.g. http://169.254.169.254/opc/v2
+  # openstack = ""  # e.g. http://169.254.169.254
 
 ## [inputs.hostobject.cloud_meta_token_url]
   # aws = "yyy"   # URL for AWS Cloud metadata token
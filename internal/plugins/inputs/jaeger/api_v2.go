@@ -0,0 +1,422 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package jaeger
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/uber/jaeger-client-go/thrift-gen/jaeger"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Messages of Jaeger api_v2(model.proto and collector.proto of jaeger-idl), only
+// the fields used by DataKit are decoded, other fields are skipped. Fields with
+// unexpected wire type are read as zero value.
+
+// ValueType of api_v2 KeyValue, STRING is 0.
+const (
+	apiV2ValueBool    = 1
+	apiV2ValueInt64   = 2
+	apiV2ValueFloat64 = 3
+	apiV2ValueBinary  = 4
+)
+
+// SpanRefType of api_v2 SpanRef, same as thrift.
+const (
+	apiV2RefChildOf     = 0
+	apiV2RefFollowsFrom = 1
+)
+
+type apiV2KeyValue struct {
+	Key      string
+	VType    int32
+	VStr     string
+	VBool    bool
+	VInt64   int64
+	VFloat64 float64
+	VBinary  []byte
+}
+
+type apiV2Log struct {
+	Timestamp time.Time
+	Fields    []*apiV2KeyValue
+}
+
+type apiV2SpanRef struct {
+	TraceID []byte
+	SpanID  []byte
+	RefType int32
+}
+
+type apiV2Process struct {
+	ServiceName string
+	Tags        []*apiV2KeyValue
+}
+
+type apiV2Span struct {
+	TraceID       []byte
+	SpanID        []byte
+	OperationName string
+	References    []*apiV2SpanRef
+	Flags         uint32
+	StartTime     time.Time
+	Duration      time.Duration
+	Tags          []*apiV2KeyValue
+	Logs          []*apiV2Log
+	Process       *apiV2Process
+}
+
+type apiV2Batch struct {
+	Spans   []*apiV2Span
+	Process *apiV2Process
+}
+
+// postSpansRequest is the request of CollectorService.PostSpans.
+type postSpansRequest struct {
+	Batch *apiV2Batch
+}
+
+// postSpansResponse is the empty response of CollectorService.PostSpans.
+type postSpansResponse struct{}
+
+type protoField struct {
+	num     protowire.Number
+	typ     protowire.Type
+	varint  uint64
+	fixed64 uint64
+	bytes   []byte
+}
+
+// decodeFields walks all fields of the message.
+func decodeFields(b []byte, fn func(f *protoField) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f := &protoField{num: num, typ: typ}
+		switch typ { //nolint:exhaustive
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			f.fixed64, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *postSpansRequest) Unmarshal(b []byte) error {
+	return decodeFields(b, func(f *protoField) error {
+		if f.num == 1 {
+			m.Batch = &apiV2Batch{}
+			return m.Batch.unmarshal(f.bytes)
+		}
+		return nil
+	})
+}
+
+func (*postSpansResponse) Marshal() ([]byte, error) {
+	return nil, nil
+}
+
+func (m *apiV2Batch) unmarshal(b []byte) error {
+	return decodeFields(b, func(f *protoField) error {
+		switch f.num {
+		case 1:
+			span := &apiV2Span{}
+			if err := span.unmarshal(f.bytes); err != nil {
+				return err
+			}
+			m.Spans = append(m.Spans, span)
+		case 2:
+			m.Process = &apiV2Process{}
+			return m.Process.unmarshal(f.bytes)
+		}
+		return nil
+	})
+}
+
+func (m *apiV2Span) unmarshal(b []byte) error {
+	return decodeFields(b, func(f *protoField) error {
+		switch f.num {
+		case 1:
+			m.TraceID = f.bytes
+		case 2:
+			m.SpanID = f.bytes
+		case 3:
+			m.OperationName = string(f.bytes)
+		case 4:
+			ref := &apiV2SpanRef{}
+			if err := ref.unmarshal(f.bytes); err != nil {
+				return err
+			}
+			m.References = append(m.References, ref)
+		case 5:
+			m.Flags = uint32(f.varint)
+		case 6:
+			sec, nsec, err := unmarshalTimestamp(f.bytes)
+			if err != nil {
+				return err
+			}
+			m.StartTime = time.Unix(sec, nsec)
+		case 7:
+			sec, nsec, err := unmarshalTimestamp(f.bytes)
+			if err != nil {
+				return err
+			}
+			m.Duration = time.Duration(sec)*time.Second + time.Duration(nsec)
+		case 8:
+			kv := &apiV2KeyValue{}
+			if err := kv.unmarshal(f.bytes); err != nil {
+				return err
+			}
+			m.Tags = append(m.Tags, kv)
+		case 9:
+			lg := &apiV2Log{}
+			if err := lg.unmarshal(f.bytes); err != nil {
+				return err
+			}
+			m.Logs = append(m.Logs, lg)
+		case 10:
+			m.Process = &apiV2Process{}
+			return m.Process.unmarshal(f.bytes)
+		}
+		return nil
+	})
+}
+
+func (m *apiV2SpanRef) unmarshal(b []byte) error {
+	return decodeFields(b, func(f *protoField) error {
+		switch f.num {
+		case 1:
+			m.TraceID = f.bytes
+		case 2:
+			m.SpanID = f.bytes
+		case 3:
+			m.RefType = int32(f.varint)
+		}
+		return nil
+	})
+}
+
+func (m *apiV2Process) unmarshal(b []byte) error {
+	return decodeFields(b, func(f *protoField) error {
+		switch f.num {
+		case 1:
+			m.ServiceName = string(f.bytes)
+		case 2:
+			kv := &apiV2KeyValue{}
+			if err := kv.unmarshal(f.bytes); err != nil {
+				return err
+			}
+			m.Tags = append(m.Tags, kv)
+		}
+		return nil
+	})
+}
+
+func (m *apiV2Log) unmarshal(b []byte) error {
+	return decodeFields(b, func(f *protoField) error {
+		switch f.num {
+		case 1:
+			sec, nsec, err := unmarshalTimestamp(f.bytes)
+			if err != nil {
+				return err
+			}
+			m.Timestamp = time.Unix(sec, nsec)
+		case 2:
+			kv := &apiV2KeyValue{}
+			if err := kv.unmarshal(f.bytes); err != nil {
+				return err
+			}
+			m.Fields = append(m.Fields, kv)
+		}
+		return nil
+	})
+}
+
+func (m *apiV2KeyValue) unmarshal(b []byte) error {
+	return decodeFields(b, func(f *protoField) error {
+		switch f.num {
+		case 1:
+			m.Key = string(f.bytes)
+		case 2:
+			m.VType = int32(f.varint)
+		case 3:
+			m.VStr = string(f.bytes)
+		case 4:
+			m.VBool = f.varint != 0
+		case 5:
+			m.VInt64 = int64(f.varint)
+		case 6:
+			m.VFloat64 = math.Float64frombits(f.fixed64)
+		case 7:
+			m.VBinary = f.bytes
+		}
+		return nil
+	})
+}
+
+// unmarshalTimestamp decodes google.protobuf.Timestamp and google.protobuf.Duration.
+func unmarshalTimestamp(b []byte) (sec, nsec int64, err error) {
+	err = decodeFields(b, func(f *protoField) error {
+		switch f.num {
+		case 1:
+			sec = int64(f.varint)
+		case 2:
+			nsec = int64(int32(f.varint))
+		}
+		return nil
+	})
+	return
+}
+
+// toThriftBatches converts the api_v2 batch to thrift batches, so the spans are
+// handled the same as Thrift over HTTP and UDP. Spans with their own process are
+// grouped into batches by service name.
+func (m *apiV2Batch) toThriftBatches() []*jaeger.Batch {
+	var (
+		batches []*jaeger.Batch
+		indexes = map[string]int{}
+	)
+
+	for _, span := range m.Spans {
+		process := span.Process
+		if process == nil {
+			process = m.Process
+		}
+		if process == nil {
+			process = &apiV2Process{}
+		}
+
+		idx, ok := indexes[process.ServiceName]
+		if !ok {
+			idx = len(batches)
+			indexes[process.ServiceName] = idx
+			batches = append(batches, &jaeger.Batch{
+				Process: &jaeger.Process{
+					ServiceName: process.ServiceName,
+					Tags:        toThriftTags(process.Tags),
+				},
+			})
+		}
+
+		batches[idx].Spans = append(batches[idx].Spans, span.toThrift())
+	}
+
+	return batches
+}
+
+func (m *apiV2Span) toThrift() *jaeger.Span {
+	traceIDHigh, traceIDLow := traceIDFromBytes(m.TraceID)
+
+	span := &jaeger.Span{
+		TraceIdLow:    int64(traceIDLow),
+		TraceIdHigh:   int64(traceIDHigh),
+		SpanId:        int64(spanIDFromBytes(m.SpanID)),
+		OperationName: m.OperationName,
+		Flags:         int32(m.Flags),
+		StartTime:     m.StartTime.UnixMicro(),
+		Duration:      m.Duration.Microseconds(),
+		Tags:          toThriftTags(m.Tags),
+	}
+
+	// parent is the first CHILD_OF reference, or the first reference if no CHILD_OF
+	for _, ref := range m.References {
+		if ref.RefType == apiV2RefChildOf {
+			span.ParentSpanId = int64(spanIDFromBytes(ref.SpanID))
+			break
+		}
+	}
+	if span.ParentSpanId == 0 && len(m.References) > 0 {
+		span.ParentSpanId = int64(spanIDFromBytes(m.References[0].SpanID))
+	}
+
+	for _, ref := range m.References {
+		high, low := traceIDFromBytes(ref.TraceID)
+		refType := jaeger.SpanRefType_CHILD_OF
+		if ref.RefType == apiV2RefFollowsFrom {
+			refType = jaeger.SpanRefType_FOLLOWS_FROM
+		}
+
+		span.References = append(span.References, &jaeger.SpanRef{
+			RefType:     refType,
+			TraceIdLow:  int64(low),
+			TraceIdHigh: int64(high),
+			SpanId:      int64(spanIDFromBytes(ref.SpanID)),
+		})
+	}
+
+	for _, lg := range m.Logs {
+		span.Logs = append(span.Logs, &jaeger.Log{
+			Timestamp: lg.Timestamp.UnixMicro(),
+			Fields:    toThriftTags(lg.Fields),
+		})
+	}
+
+	return span
+}
+
+func toThriftTags(kvs []*apiV2KeyValue) []*jaeger.Tag {
+	var tags []*jaeger.Tag
+
+	for _, kv := range kvs {
+		tag := &jaeger.Tag{Key: kv.Key}
+
+		switch kv.VType {
+		case apiV2ValueBool:
+			tag.VType, tag.VBool = jaeger.TagType_BOOL, &kv.VBool
+		case apiV2ValueInt64:
+			tag.VType, tag.VLong = jaeger.TagType_LONG, &kv.VInt64
+		case apiV2ValueFloat64:
+			tag.VType, tag.VDouble = jaeger.TagType_DOUBLE, &kv.VFloat64
+		case apiV2ValueBinary:
+			tag.VType, tag.VBinary = jaeger.TagType_BINARY, kv.VBinary
+		default: // STRING
+			tag.VType, tag.VStr = jaeger.TagType_STRING, &kv.VStr
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// traceIDFromBytes decodes the 16 bytes(or 8 bytes for 64bit trace ID) big-endian trace ID.
+func traceIDFromBytes(b []byte) (high, low uint64) {
+	switch {
+	case len(b) >= 16:
+		return binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:16])
+	case len(b) >= 8:
+		return 0, binary.BigEndian.Uint64(b[:8])
+	default:
+		return 0, 0
+	}
+}
+
+func spanIDFromBytes(b []byte) uint64 {
+	if len(b) < 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b[:8])
+}
@@ -25,11 +25,13 @@ func (ipt *Input) GetENVDoc() []*inputs.ENVInfo {
 	infos := []*inputs.ENVInfo{
 		{FieldName: "Endpoint", ENVName: "HTTP_ENDPOINT", ConfField: "endpoint", Type: doc.String, Example: `/apis/traces`, Desc: "Endpoint for receiving tracing span over HTTP", DescZh: "通过 HTTP 接收 tracing span 的端点"},
 		{FieldName: "Address", ENVName: "UDP_ENDPOINT", ConfField: "address", Type: doc.String, Example: `127.0.0.1:6831`, Desc: "Agent URL for UDP transport", DescZh: "UDP 代理 URL"},
+		{FieldName: "GRPCAddress", ENVName: "GRPC_ENDPOINT", ConfField: "grpc_address", Type: doc.String, Example: `127.0.0.1:14250`, Desc: "Collector address for gRPC transport", DescZh: "gRPC collector 地址"},
 		{FieldName: "IgnoreTags", Type: doc.JSON, Example: "`'[\"block1\",\"block2\"]'`", Desc: "Ignore tags", DescZh: "忽略的标签"},
 		{FieldName: "KeepRareResource", Type: doc.Boolean, Default: `false`, Desc: "Keep rare tracing resources list switch", DescZh: "保持稀有跟踪资源列表"},
 		{FieldName: "DelMessage", Type: doc.Boolean, Default: `false`, Desc: "Delete trace message", DescZh: "删除 trace 消息"},
 		{FieldName: "CloseResource", Type: doc.JSON, Example: "`'{\"service1\":[\"resource1\",\"other\"],\"service2\":[\"resource2\",\"other\"]}'`", Desc: "Ignore tracing resources that service (regular)", DescZh: "忽略指定服务器的 tracing（正则匹配）"},
 		{FieldName: "Sampler", Type: doc.Float, Example: `0.3`, Desc: "Global sampling rate", DescZh: "全局采样率"},
+		{FieldName: "RemoteSampling", Type: doc.JSON, Example: "`'{\"default_type\":\"probabilistic\", \"default_param\":0.5, \"services\":[{\"service\":\"order\", \"type\":\"rate_limiting\", \"param\":10}]}'`", Desc: "Remote sampling strategies for Jaeger SDKs", DescZh: "提供给 Jaeger SDK 的远程采样策略"},
 		{FieldName: "WPConfig", ENVName: "THREADS", Type: doc.JSON, Example: "`'{\"buffer\":1000, \"threads\":100}'`", Desc: "Total number of threads and buffer", DescZh: "线程和缓存的数量"},
 		{FieldName: "LocalCacheConfig", ENVName: "STORAGE", Type: doc.JSON, Example: "`'{\"storage\":\"./jaeger_storage\", \"capacity\": 5120}'`", Desc: "Local cache file path and size (MB) ", DescZh: "本地缓存路径和大小（MB）"},
 		{FieldName: "Tags", Type: doc.JSON, Example: "`'{\"k1\":\"v1\", \"k2\":\"v2\", \"k3\":\"v3\"}'`"},
@@ -41,20 +43,24 @@ func (ipt *Input) GetENVDoc() []*inputs.ENVInfo {
 // ReadEnv load config from environment values
 // ENV_INPUT_JAEGER_HTTP_ENDPOINT : string
 // ENV_INPUT_JAEGER_UDP_ENDPOINT : string
+// ENV_INPUT_JAEGER_GRPC_ENDPOINT : string
 // ENV_INPUT_JAEGER_IGNORE_TAGS : JSON string
 // ENV_INPUT_JAEGER_KEEP_RARE_RESOURCE : bool
 // ENV_INPUT_JAEGER_CLOSE_RESOURCE : JSON string
 // ENV_INPUT_JAEGER_SAMPLER : float
+// ENV_INPUT_JAEGER_REMOTE_SAMPLING : JSON string
 // ENV_INPUT_JAEGER_TAGS : JSON string
 // ENV_INPUT_JAEGER_THREADS : JSON string
 // ENV_INPUT_JAEGER_STORAGE : JSON string
 // below is a complete example for env in shell
 // export ENV_INPUT_JAEGER_HTTP_ENDPOINT="/apis/traces"
 // export ENV_INPUT_JAEGER_UDP_ENDPOINT="127.0.0.1:6831"
+// export ENV_INPUT_JAEGER_GRPC_ENDPOINT="127.0.0.1:14250"
 // export ENV_INPUT_JAEGER_IGNORE_TAGS=`["block1", "block2"]`
 // export ENV_INPUT_JAEGER_KEEP_RARE_RESOURCE=true
 // export ENV_INPUT_JAEGER_CLOSE_RESOURCE=`{"service1":["resource1"], "service2":["resource2"], "service3":["resource3"]}`
 // export ENV_INPUT_JAEGER_SAMPLER=0.3
+// export ENV_INPUT_JAEGER_REMOTE_SAMPLING=`{"default_type":"probabilistic", "default_param":0.5}`
 // export ENV_INPUT_JAEGER_TAGS=`{"k1":"v1", "k2":"v2", "k3":"v3"}`
 // export ENV_INPUT_JAEGER_THREADS=`{"buffer":1000, "threads":100}`
 // export ENV_INPUT_JAEGER_STORAGE=`{"storage":"./jaeger_storage", "capacity": 5120}`.
@@ -65,6 +71,7 @@ func (ipt *Input) ReadEnv(envs map[string]string) {
 		"ENV_INPUT_JAEGER_HTTP_ENDPOINT", "ENV_INPUT_JAEGER_UDP_ENDPOINT", "ENV_INPUT_JAEGER_IGNORE_TAGS",
 		"ENV_INPUT_JAEGER_KEEP_RARE_RESOURCE", "ENV_INPUT_JAEGER_CLOSE_RESOURCE", "ENV_INPUT_JAEGER_SAMPLER",
 		"ENV_INPUT_JAEGER_TAGS", "ENV_INPUT_JAEGER_THREADS", "ENV_INPUT_JAEGER_STORAGE", "ENV_INPUT_JAEGER_DEL_MESSAGE",
+		"ENV_INPUT_JAEGER_GRPC_ENDPOINT", "ENV_INPUT_JAEGER_REMOTE_SAMPLING",
 	} {
 		value, ok := envs[key]
 		if !ok {
@@ -75,6 +82,8 @@ func (ipt *Input) ReadEnv(envs map[string]string) {
 			ipt.Endpoint = value
 		case "ENV_INPUT_JAEGER_UDP_ENDPOINT":
 			ipt.Address = value
+		case "ENV_INPUT_JAEGER_GRPC_ENDPOINT":
+			ipt.GRPCAddress = value
 		case "ENV_INPUT_JAEGER_IGNORE_TAGS":
 			var list []string
 			if err := json.Unmarshal([]byte(value), &list); err != nil {
@@ -104,6 +113,13 @@ func (ipt *Input) ReadEnv(envs map[string]string) {
 				}
 				ipt.Sampler.SamplingRateGlobal = ratio
 			}
+		case "ENV_INPUT_JAEGER_REMOTE_SAMPLING":
+			var rs remoteSampling
+			if err := json.Unmarshal([]byte(value), &rs); err != nil {
+				log.Warnf("parse %s=%s failed: %s", key, value, err.Error())
+			} else {
+				ipt.RemoteSampling = &rs
+			}
 		case "ENV_INPUT_JAEGER_TAGS":
 			var tags map[string]string
 			if err := json.Unmarshal([]byte(value), &tags); err != nil {
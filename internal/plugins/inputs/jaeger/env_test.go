@@ -40,10 +40,12 @@ func TestReadEnv(t *testing.T) {
 			envs: map[string]string{
 				"ENV_INPUT_JAEGER_HTTP_ENDPOINT":      "/apis/traces",
 				"ENV_INPUT_JAEGER_UDP_ENDPOINT":       "127.0.0.1:6831",
+				"ENV_INPUT_JAEGER_GRPC_ENDPOINT":      "127.0.0.1:14250",
 				"ENV_INPUT_JAEGER_IGNORE_TAGS":        `["block1", "block2"]`,
 				"ENV_INPUT_JAEGER_KEEP_RARE_RESOURCE": "true",
 				"ENV_INPUT_JAEGER_CLOSE_RESOURCE":     `{"service1":["resource1"], "service2":["resource2"], "service3":["resource3"]}`,
 				"ENV_INPUT_JAEGER_SAMPLER":            "0.3",
+				"ENV_INPUT_JAEGER_REMOTE_SAMPLING":    `{"default_type":"probabilistic", "default_param":0.5, "services":[{"service":"order", "type":"rate_limiting", "param":10}]}`,
 				"ENV_INPUT_JAEGER_TAGS":               `{"k1":"v1", "k2":"v2", "k3":"v3"}`,
 				"ENV_INPUT_JAEGER_THREADS":            `{"buffer":1000, "threads":100}`,
 				"ENV_INPUT_JAEGER_STORAGE":            `{"storage":"./jaeger_storage", "capacity": 5120}`,
@@ -51,10 +53,16 @@ func TestReadEnv(t *testing.T) {
 			expected: &Input{
 				Endpoint:         "/apis/traces",
 				Address:          "127.0.0.1:6831",
+				GRPCAddress:      "127.0.0.1:14250",
 				IgnoreTags:       []string{"block1", "block2"},
 				KeepRareResource: true,
 				CloseResource:    map[string][]string{"service1": {"resource1"}, "service2": {"resource2"}, "service3": {"resource3"}},
 				Sampler:          &trace.Sampler{SamplingRateGlobal: 0.3},
+				RemoteSampling: &remoteSampling{
+					DefaultType:  samplingProbabilistic,
+					DefaultParam: 0.5,
+					Services:     []*serviceSamplingStrategy{{Service: "order", Type: samplingRateLimiting, Param: 10}},
+				},
 				Tags:             map[string]string{"k1": "v1", "k2": "v2", "k3": "v3"},
 				WPConfig:         &workerpool.WorkerPoolConfig{Buffer: 1000, Threads: 100},
 				LocalCacheConfig: &storage.StorageConfig{Path: "./jaeger_storage", Capacity: 5120},
@@ -27,6 +27,7 @@ import (
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/storage"
 	itrace "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/trace"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/workerpool"
+	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -36,8 +37,10 @@ var (
 )
 
 const (
-	inputName    = "jaeger"
-	sampleConfig = `
+	inputName = "jaeger"
+)
+
+var sampleConfig = `
 [[inputs.jaeger]]
   # Jaeger endpoint for receiving tracing span over HTTP.
   # Default value set as below. DO NOT MODIFY THE ENDPOINT if not necessary.
@@ -47,6 +50,9 @@ const (
   # address = "127.0.0.1:6831"
   # binary_address = "127.0.0.1:6832"
 
+  # Jaeger collector host:port address for receiving spans over gRPC(api_v2 CollectorService).
+  # grpc_address = "127.0.0.1:14250"
+
   ## ignore_tags will work as a blacklist to prevent tags send to data center.
   ## Every value in this list is a valid string of regular expression.
   # ignore_tags = ["block1", "block2"]
@@ -70,11 +76,31 @@ const (
     # "*" = ["close_resource_under_all_services"]
     # ...
 
+  ## Remote sampling strategies for Jaeger SDKs, which poll GET <endpoint>?service=<service>.
+  ## Strategy type is probabilistic(param is the sampling rate in [0, 1]) or
+  ## rate_limiting(param is the max traces per second). The default strategy applies to
+  ## services not configured. Operations overwrite the sampling rate of probabilistic strategy.
+  # [inputs.jaeger.remote_sampling]
+    # endpoint = "/api/sampling"
+    # default_type = "probabilistic"
+    # default_param = 1.0
+    # [[inputs.jaeger.remote_sampling.services]]
+    #   service = "order"
+    #   type = "rate_limiting"
+    #   param = 10
+    # [[inputs.jaeger.remote_sampling.services]]
+    #   service = "payment"
+    #   type = "probabilistic"
+    #   param = 0.5
+    #   [[inputs.jaeger.remote_sampling.services.operations]]
+    #     operation = "GET /healthz"
+    #     param = 0.0
+
   ## Sampler config uses to set global sampling strategy.
   ## sampling_rate used to set global sampling rate.
   # [inputs.jaeger.sampler]
     # sampling_rate = 1.0
-
+` + itrace.AfterGatherExtensionSample(inputName) + `
   # [inputs.jaeger.tags]
     # key1 = "value1"
     # key2 = "value2"
@@ -95,7 +121,6 @@ const (
     # path = "./jaeger_storage"
     # capacity = 5120
 `
-)
 
 var (
 	log            = logger.DefaultSLogger(inputName)
@@ -108,6 +133,8 @@ var (
 )
 
 type Input struct {
+	itrace.AfterGatherExtension
+
 	Path             string                       `toml:"path"`          // deprecated
 	UDPAgent         string                       `toml:"udp_agent"`     // deprecated
 	Pipelines        map[string]string            `toml:"pipelines"`     // deprecated
@@ -115,11 +142,13 @@ type Input struct {
 	Endpoint         string                       `toml:"endpoint"`
 	Address          string                       `toml:"address"`
 	BinaryAddress    string                       `toml:"binary_address"`
+	GRPCAddress      string                       `toml:"grpc_address"`
 	IgnoreTags       []string                     `toml:"ignore_tags"`
 	DelMessage       bool                         `toml:"del_message"`
 	KeepRareResource bool                         `toml:"keep_rare_resource"`
 	CloseResource    map[string][]string          `toml:"close_resource"`
 	Sampler          *itrace.Sampler              `toml:"sampler"`
+	RemoteSampling   *remoteSampling              `toml:"remote_sampling"`
 	Tags             map[string]string            `toml:"tags"`
 	WPConfig         *workerpool.WorkerPoolConfig `toml:"threads"`
 	LocalCacheConfig *storage.StorageConfig       `toml:"storage"`
@@ -127,6 +156,7 @@ type Input struct {
 	feeder  dkio.Feeder
 	semStop *cliutils.Sem // start stop signal
 	Tagger  datakit.GlobalTagger
+	grpcSvr *grpc.Server
 }
 
 func (*Input) Catalog() string { return inputName }
@@ -136,7 +166,10 @@ func (*Input) AvailableArchs() []string { return datakit.AllOS }
 func (*Input) SampleConfig() string { return sampleConfig }
 
 func (*Input) SampleMeasurement() []inputs.Measurement {
-	return []inputs.Measurement{&itrace.TraceMeasurement{Name: inputName}}
+	return []inputs.Measurement{
+		&itrace.TraceMeasurement{Name: inputName},
+		&itrace.REDMetricMeasurement{Name: "Jaeger"},
+	}
 }
 
 func (ipt *Input) RegHTTPHandler() {
@@ -228,12 +261,25 @@ func (ipt *Input) RegHTTPHandler() {
 		afterGather.AppendFilter(sampler.Sample)
 	}
 
+	// add tail sampler as the last filter, and RED metrics derived from all traces before filters
+	afterGatherRun = ipt.SetupAfterGather(inputName, afterGather, ipt.feeder, ipt.Tagger.HostTags())
+
 	log.Debugf("### register handler for %s of agent %s", ipt.Endpoint, inputName)
 	if ipt.Endpoint != "" {
 		httpapi.RegHTTPHandler("POST", ipt.Endpoint,
 			workerpool.HTTPWrapper(httpStatusRespFunc, wkpool,
 				httpapi.HTTPStorageWrapper(storage.HTTP_KEY, httpStatusRespFunc, localCache, handleJaegerTrace)))
 	}
+
+	if ipt.RemoteSampling != nil {
+		if err := ipt.RemoteSampling.init(); err != nil {
+			log.Errorf("init remote sampling failed: %s, remote sampling disabled", err.Error())
+			ipt.RemoteSampling = nil
+		} else {
+			log.Debugf("register sampling handler for %s of agent %s", ipt.RemoteSampling.Endpoint, inputName)
+			httpapi.RegHTTPHandler(http.MethodGet, ipt.RemoteSampling.Endpoint, ipt.RemoteSampling.handle)
+		}
+	}
 }
 
 func (ipt *Input) Run() {
@@ -266,6 +312,17 @@ func (ipt *Input) Run() {
 			return nil
 		})
 	}
+	if ipt.GRPCAddress != "" {
+		log.Debugf("%s gRPC collector is starting...", inputName)
+		// created before the goroutine, so exit() can always stop it safely
+		svr := newGRPCCollector()
+		ipt.grpcSvr = svr
+		g := goroutine.NewGroup(goroutine.Option{Name: inputName})
+		g.Go(func(ctx context.Context) error {
+			runGRPCCollector(svr, ipt.GRPCAddress)
+			return nil
+		})
+	}
 
 	log.Debugf("### %s agent is running...", inputName)
 
@@ -282,6 +339,11 @@ func (ipt *Input) Run() {
 }
 
 func (ipt *Input) exit() {
+	ipt.CloseAfterGather()
+	if ipt.grpcSvr != nil {
+		ipt.grpcSvr.Stop()
+		log.Debug("gRPC collector closed")
+	}
 	if wkpool != nil {
 		wkpool.Shutdown()
 		log.Debug("### workerpool closed")
@@ -302,6 +364,10 @@ func (ipt *Input) Terminate() {
 	if ipt.Endpoint != "" {
 		httpapi.RemoveHTTPRoute("POST", ipt.Endpoint)
 	}
+
+	if ipt.RemoteSampling != nil {
+		httpapi.RemoveHTTPRoute(http.MethodGet, ipt.RemoteSampling.Endpoint)
+	}
 }
 
 func defaultInput() *Input {
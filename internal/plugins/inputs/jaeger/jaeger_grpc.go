@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package jaeger
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	itrace "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/trace"
+	"google.golang.org/grpc"
+)
+
+const postSpansMethod = "/jaeger.api_v2.CollectorService/PostSpans"
+
+// collectorServer is the api_v2 CollectorService.
+type collectorServer interface {
+	PostSpans(context.Context, *postSpansRequest) (*postSpansResponse, error)
+}
+
+var collectorServiceDesc = grpc.ServiceDesc{
+	ServiceName: "jaeger.api_v2.CollectorService",
+	HandlerType: (*collectorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "PostSpans", Handler: postSpansHandler},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "collector.proto",
+}
+
+func postSpansHandler(srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := &postSpansRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	if interceptor == nil {
+		return srv.(collectorServer).PostSpans(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: postSpansMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(collectorServer).PostSpans(ctx, req.(*postSpansRequest))
+	}
+
+	return interceptor(ctx, in, info, handler)
+}
+
+// grpcCodec (un)marshals the api_v2 messages by their own methods, it's forced
+// on the collector gRPC server for there are no generated protobuf messages.
+type grpcCodec struct{}
+
+func (grpcCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(interface{ Marshal() ([]byte, error) }); ok {
+		return m.Marshal()
+	}
+	return nil, fmt.Errorf("unexpected message type %T", v)
+}
+
+func (grpcCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(interface{ Unmarshal([]byte) error }); ok {
+		return m.Unmarshal(data)
+	}
+	return fmt.Errorf("unexpected message type %T", v)
+}
+
+func (grpcCodec) Name() string { return "proto" }
+
+type collectorService struct{}
+
+func (*collectorService) PostSpans(ctx context.Context, req *postSpansRequest) (*postSpansResponse, error) {
+	if req.Batch == nil || afterGatherRun == nil {
+		return &postSpansResponse{}, nil
+	}
+
+	var dktraces itrace.DatakitTraces
+	for _, batch := range req.Batch.toThriftBatches() {
+		if dktrace := batchToDkTrace(batch); len(dktrace) != 0 {
+			dktraces = append(dktraces, dktrace)
+		}
+	}
+
+	if len(dktraces) != 0 {
+		afterGatherRun.Run(inputName, dktraces)
+	}
+
+	return &postSpansResponse{}, nil
+}
+
+// newGRPCCollector create the gRPC server of the api_v2 CollectorService.
+func newGRPCCollector() *grpc.Server {
+	opts := append([]grpc.ServerOption{grpc.ForceServerCodec(grpcCodec{})}, itrace.DefaultGRPCServerOpts...)
+	svr := grpc.NewServer(opts...)
+	svr.RegisterService(&collectorServiceDesc, &collectorService{})
+
+	return svr
+}
+
+func runGRPCCollector(svr *grpc.Server, addr string) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Errorf("jaeger gRPC collector listening on %s failed: %s", addr, err.Error())
+
+		return
+	}
+	log.Debugf("jaeger gRPC collector listening on: %s", addr)
+
+	if err = svr.Serve(listener); err != nil {
+		log.Error(err.Error())
+	}
+	log.Debug("jaeger gRPC collector exits")
+}
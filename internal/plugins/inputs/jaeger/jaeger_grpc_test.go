@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package jaeger
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go/thrift-gen/jaeger"
+	itrace "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// rawMessage is the encoded protobuf message used by the test client.
+type rawMessage []byte
+
+func (m rawMessage) Marshal() ([]byte, error) { return m, nil }
+
+func (m *rawMessage) Unmarshal(b []byte) error { *m = b; return nil }
+
+func appendBytesField(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func encodeKeyValue(key string, vtype uint64, value interface{}) []byte {
+	b := appendBytesField(nil, 1, []byte(key))
+	b = appendVarintField(b, 2, vtype)
+
+	switch v := value.(type) {
+	case string:
+		b = appendBytesField(b, 3, []byte(v))
+	case bool:
+		b = appendVarintField(b, 4, protowire.EncodeBool(v))
+	case int64:
+		b = appendVarintField(b, 5, uint64(v))
+	case float64:
+		b = protowire.AppendTag(b, 6, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v))
+	}
+
+	return b
+}
+
+func encodeTimestamp(sec, nsec int64) []byte {
+	return appendVarintField(appendVarintField(nil, 1, uint64(sec)), 2, uint64(nsec))
+}
+
+func encodeID(ids ...uint64) []byte {
+	var b []byte
+	for _, id := range ids {
+		b = binary.BigEndian.AppendUint64(b, id)
+	}
+	return b
+}
+
+func encodeProcess(service string, tags ...[]byte) []byte {
+	b := appendBytesField(nil, 1, []byte(service))
+	for _, tag := range tags {
+		b = appendBytesField(b, 2, tag)
+	}
+	return b
+}
+
+func testPostSpansRequest(start time.Time) []byte {
+	// root span
+	root := appendBytesField(nil, 1, encodeID(0x1, 0x2))
+	root = appendBytesField(root, 2, encodeID(0xa))
+	root = appendBytesField(root, 3, []byte("GET /orders"))
+	root = appendBytesField(root, 6, encodeTimestamp(start.Unix(), int64(start.Nanosecond())))
+	root = appendBytesField(root, 7, encodeTimestamp(1, 500000000))
+	root = appendBytesField(root, 8, encodeKeyValue("http.status_code", apiV2ValueInt64, int64(500)))
+	root = appendBytesField(root, 8, encodeKeyValue("error", apiV2ValueBool, true))
+	root = appendBytesField(root, 99, []byte("unknown field"))
+
+	// child span
+	ref := appendBytesField(nil, 1, encodeID(0x1, 0x2))
+	ref = appendBytesField(ref, 2, encodeID(0xa))
+	ref = appendVarintField(ref, 3, apiV2RefChildOf)
+
+	child := appendBytesField(nil, 1, encodeID(0x1, 0x2))
+	child = appendBytesField(child, 2, encodeID(0xb))
+	child = appendBytesField(child, 3, []byte("SELECT"))
+	child = appendBytesField(child, 4, ref)
+	child = appendBytesField(child, 6, encodeTimestamp(start.Unix(), int64(start.Nanosecond())))
+	child = appendBytesField(child, 7, encodeTimestamp(0, 2000000))
+	child = appendBytesField(child, 8, encodeKeyValue("db.rows", apiV2ValueFloat64, 1.5))
+
+	// span with its own process
+	other := appendBytesField(nil, 1, encodeID(0x1, 0x2))
+	other = appendBytesField(other, 2, encodeID(0xc))
+	other = appendBytesField(other, 3, []byte("charge"))
+	other = appendBytesField(other, 10, encodeProcess("payment"))
+
+	batch := appendBytesField(nil, 1, root)
+	batch = appendBytesField(batch, 1, child)
+	batch = appendBytesField(batch, 1, other)
+	batch = appendBytesField(batch, 2, encodeProcess("order", encodeKeyValue("env", 0, "prod")))
+
+	return appendBytesField(nil, 1, batch)
+}
+
+func TestPostSpansRequestUnmarshal(t *testing.T) {
+	start := time.Unix(1700000000, 123456000)
+
+	req := &postSpansRequest{}
+	require.NoError(t, req.Unmarshal(testPostSpansRequest(start)))
+	require.NotNil(t, req.Batch)
+	require.Len(t, req.Batch.Spans, 3)
+
+	batches := req.Batch.toThriftBatches()
+	require.Len(t, batches, 2)
+
+	order := batches[0]
+	assert.Equal(t, "order", order.Process.ServiceName)
+	require.Len(t, order.Process.Tags, 1)
+	assert.Equal(t, "prod", order.Process.Tags[0].GetVStr())
+	require.Len(t, order.Spans, 2)
+
+	root := order.Spans[0]
+	assert.Equal(t, int64(0x1), root.TraceIdHigh)
+	assert.Equal(t, int64(0x2), root.TraceIdLow)
+	assert.Equal(t, int64(0xa), root.SpanId)
+	assert.Equal(t, int64(0), root.ParentSpanId)
+	assert.Equal(t, "GET /orders", root.OperationName)
+	assert.Equal(t, start.UnixMicro(), root.StartTime)
+	assert.Equal(t, int64(1500000), root.Duration)
+	require.Len(t, root.Tags, 2)
+	assert.Equal(t, jaeger.TagType_LONG, root.Tags[0].VType)
+	assert.Equal(t, int64(500), root.Tags[0].GetVLong())
+	assert.Equal(t, jaeger.TagType_BOOL, root.Tags[1].VType)
+	assert.True(t, root.Tags[1].GetVBool())
+
+	child := order.Spans[1]
+	assert.Equal(t, int64(0xa), child.ParentSpanId)
+	assert.Equal(t, int64(2000), child.Duration)
+	require.Len(t, child.References, 1)
+	assert.Equal(t, jaeger.SpanRefType_CHILD_OF, child.References[0].RefType)
+	assert.Equal(t, 1.5, child.Tags[0].GetVDouble())
+
+	payment := batches[1]
+	assert.Equal(t, "payment", payment.Process.ServiceName)
+	require.Len(t, payment.Spans, 1)
+	assert.Equal(t, int64(0xc), payment.Spans[0].SpanId)
+
+	assert.Error(t, (&postSpansRequest{}).Unmarshal([]byte{0x0a, 0xff}))
+}
+
+func TestGRPCCollector(t *testing.T) {
+	var (
+		ch    = make(chan itrace.DatakitTraces, 1)
+		start = time.Now()
+	)
+
+	afterGatherRun = itrace.AfterGatherFunc(func(inputName string, dktraces itrace.DatakitTraces) {
+		ch <- dktraces
+	})
+	defer func() { afterGatherRun = nil }()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	svr := newGRPCCollector()
+	go svr.Serve(listener) //nolint:errcheck
+	defer svr.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(grpcCodec{})))
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var resp rawMessage
+	require.NoError(t, conn.Invoke(ctx, postSpansMethod, rawMessage(testPostSpansRequest(start)), &resp))
+
+	select {
+	case dktraces := <-ch:
+		require.Len(t, dktraces, 2)
+		require.Len(t, dktraces[0], 2)
+
+		root := dktraces[0][0]
+		assert.Equal(t, "order", root.GetTag(itrace.TagService))
+		assert.Equal(t, itrace.StatusErr, root.GetTag(itrace.TagSpanStatus))
+		assert.Equal(t, "prod", root.GetTag(itrace.TagEnv))
+		assert.Equal(t, "00000000000000010000000000000002", root.Get(itrace.FieldTraceID))
+		assert.Equal(t, "GET /orders", root.Get(itrace.FieldResource))
+
+		assert.Equal(t, "payment", dktraces[1][0].GetTag(itrace.TagService))
+	case <-ctx.Done():
+		t.Fatal("wait traces timeout")
+	}
+}
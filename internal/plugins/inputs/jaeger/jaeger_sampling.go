@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package jaeger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/uber/jaeger-client-go/thrift-gen/sampling"
+)
+
+const (
+	defaultSamplingEndpoint = "/api/sampling"
+
+	samplingProbabilistic = "probabilistic"
+	samplingRateLimiting  = "rate_limiting"
+)
+
+// remoteSampling is the sampling strategies served to Jaeger SDKs which poll
+// `GET <endpoint>?service=<service>`, the same as the Jaeger collector.
+type remoteSampling struct {
+	Endpoint     string                     `toml:"endpoint" json:"endpoint"`
+	DefaultType  string                     `toml:"default_type" json:"default_type"`
+	DefaultParam float64                    `toml:"default_param" json:"default_param"`
+	Services     []*serviceSamplingStrategy `toml:"services" json:"services"`
+
+	defaultStrategy *sampling.SamplingStrategyResponse
+	strategies      map[string]*sampling.SamplingStrategyResponse
+}
+
+type serviceSamplingStrategy struct {
+	Service string  `toml:"service" json:"service"`
+	Type    string  `toml:"type" json:"type"`
+	Param   float64 `toml:"param" json:"param"`
+
+	// Operations overwrite the probability of specified operations, only for
+	// probabilistic strategy.
+	Operations []*operationSamplingStrategy `toml:"operations" json:"operations"`
+}
+
+type operationSamplingStrategy struct {
+	Operation string  `toml:"operation" json:"operation"`
+	Param     float64 `toml:"param" json:"param"`
+}
+
+func (rs *remoteSampling) init() error {
+	if rs.Endpoint == "" {
+		rs.Endpoint = defaultSamplingEndpoint
+	}
+	if rs.DefaultType == "" {
+		rs.DefaultType, rs.DefaultParam = samplingProbabilistic, 1
+	}
+
+	var err error
+	if rs.defaultStrategy, err = newSamplingStrategy(rs.DefaultType, rs.DefaultParam, nil); err != nil {
+		return fmt.Errorf("default strategy: %w", err)
+	}
+
+	rs.strategies = make(map[string]*sampling.SamplingStrategyResponse)
+	for _, s := range rs.Services {
+		if s.Service == "" {
+			return fmt.Errorf("service name of sampling strategy not set")
+		}
+
+		strategy, err := newSamplingStrategy(s.Type, s.Param, s.Operations)
+		if err != nil {
+			return fmt.Errorf("strategy of service %s: %w", s.Service, err)
+		}
+		rs.strategies[s.Service] = strategy
+	}
+
+	return nil
+}
+
+func newSamplingStrategy(typ string, param float64,
+	operations []*operationSamplingStrategy,
+) (*sampling.SamplingStrategyResponse, error) {
+	switch typ {
+	case samplingProbabilistic:
+		if param < 0 || param > 1 {
+			return nil, fmt.Errorf("invalid probabilistic param %v, should be in [0, 1]", param)
+		}
+
+		strategy := &sampling.SamplingStrategyResponse{
+			StrategyType:          sampling.SamplingStrategyType_PROBABILISTIC,
+			ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: param},
+		}
+
+		if len(operations) > 0 {
+			perOperation := &sampling.PerOperationSamplingStrategies{
+				DefaultSamplingProbability: param,
+				PerOperationStrategies:     []*sampling.OperationSamplingStrategy{},
+			}
+
+			for _, op := range operations {
+				if op.Param < 0 || op.Param > 1 {
+					return nil, fmt.Errorf("invalid probabilistic param %v of operation %s, should be in [0, 1]", op.Param, op.Operation)
+				}
+
+				perOperation.PerOperationStrategies = append(perOperation.PerOperationStrategies,
+					&sampling.OperationSamplingStrategy{
+						Operation:             op.Operation,
+						ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: op.Param},
+					})
+			}
+
+			strategy.OperationSampling = perOperation
+		}
+
+		return strategy, nil
+
+	case samplingRateLimiting:
+		if param < 0 || param > math.MaxInt16 {
+			return nil, fmt.Errorf("invalid rate_limiting param %v, should be in [0, %d]", param, math.MaxInt16)
+		}
+
+		if len(operations) > 0 {
+			return nil, fmt.Errorf("operations only available for probabilistic strategy")
+		}
+
+		return &sampling.SamplingStrategyResponse{
+			StrategyType:         sampling.SamplingStrategyType_RATE_LIMITING,
+			RateLimitingSampling: &sampling.RateLimitingSamplingStrategy{MaxTracesPerSecond: int16(param)},
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown strategy type %q, should be %s or %s", typ, samplingProbabilistic, samplingRateLimiting)
+	}
+}
+
+func (rs *remoteSampling) strategyOf(service string) *sampling.SamplingStrategyResponse {
+	if strategy, ok := rs.strategies[service]; ok {
+		return strategy
+	}
+	return rs.defaultStrategy
+}
+
+func (rs *remoteSampling) handle(resp http.ResponseWriter, req *http.Request) {
+	service := req.URL.Query().Get("service")
+	if service == "" {
+		http.Error(resp, "'service' parameter must be provided", http.StatusBadRequest)
+
+		return
+	}
+
+	buf, err := json.Marshal(rs.strategyOf(service))
+	if err != nil {
+		log.Errorf("marshal sampling strategy failed: %s", err.Error())
+		resp.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if _, err := resp.Write(buf); err != nil {
+		log.Debugf("write sampling strategy failed: %s", err.Error())
+	}
+}
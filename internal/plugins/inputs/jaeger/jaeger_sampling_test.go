@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package jaeger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteSampling(t *testing.T) {
+	rs := &remoteSampling{
+		DefaultType:  samplingProbabilistic,
+		DefaultParam: 0.5,
+		Services: []*serviceSamplingStrategy{
+			{Service: "order", Type: samplingRateLimiting, Param: 10},
+			{
+				Service: "payment",
+				Type:    samplingProbabilistic,
+				Param:   0.8,
+				Operations: []*operationSamplingStrategy{
+					{Operation: "GET /healthz", Param: 0},
+				},
+			},
+		},
+	}
+	require.NoError(t, rs.init())
+	assert.Equal(t, defaultSamplingEndpoint, rs.Endpoint)
+
+	get := func(query string) (int, string) {
+		w := httptest.NewRecorder()
+		rs.handle(w, httptest.NewRequest(http.MethodGet, rs.Endpoint+query, nil))
+		return w.Code, w.Body.String()
+	}
+
+	code, body := get("?service=order")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"strategyType":"RATE_LIMITING","rateLimitingSampling":{"maxTracesPerSecond":10}}`, body)
+
+	code, body = get("?service=payment")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{
+		"strategyType":"PROBABILISTIC",
+		"probabilisticSampling":{"samplingRate":0.8},
+		"operationSampling":{
+			"defaultSamplingProbability":0.8,
+			"defaultLowerBoundTracesPerSecond":0,
+			"perOperationStrategies":[{"operation":"GET /healthz","probabilisticSampling":{"samplingRate":0}}]
+		}
+	}`, body)
+
+	code, body = get("?service=unknown")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":0.5}}`, body)
+
+	code, _ = get("")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestRemoteSamplingInit(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		rs := &remoteSampling{}
+		require.NoError(t, rs.init())
+		assert.Equal(t, 1.0, rs.strategyOf("any").ProbabilisticSampling.SamplingRate)
+	})
+
+	for name, rs := range map[string]*remoteSampling{
+		"invalid-type":        {DefaultType: "adaptive"},
+		"invalid-probability": {DefaultType: samplingProbabilistic, DefaultParam: 1.5},
+		"invalid-rate":        {DefaultType: samplingRateLimiting, DefaultParam: -1},
+		"no-service-name":     {Services: []*serviceSamplingStrategy{{Type: samplingProbabilistic, Param: 1}}},
+		"operations-of-rate-limiting": {Services: []*serviceSamplingStrategy{{
+			Service:    "order",
+			Type:       samplingRateLimiting,
+			Param:      1,
+			Operations: []*operationSamplingStrategy{{Operation: "op", Param: 0.1}},
+		}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, rs.init())
+		})
+	}
+}
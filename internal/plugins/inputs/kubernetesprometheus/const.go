@@ -76,6 +76,10 @@ const (
   #  scheme = "https"
   #  port = "__kubernetes_node_kubelet_endpoint_port"
   #  path = "/metrics"
+  #  # Negotiate the protobuf format, required to collect native histograms.
+  #  prefer_protobuf = false
+  #  # Collect exemplars as "<field>_exemplar" with exemplar labels(such as trace_id) as fields.
+  #  collect_exemplars = false
   #
   #  [inputs.kubernetesprometheus.instances.http_headers]
   #    # Authorization = "Bearer your-token-here"
@@ -86,6 +90,18 @@ const (
   #    [inputs.kubernetesprometheus.instances.custom.tags]
   #      node_name = "__kubernetes_node_name"
   #
+  #  # Relabel the discovered targets before scraping, all the __kubernetes_* keys are available.
+  #  [[inputs.kubernetesprometheus.instances.relabel_configs]]
+  #    source_labels = ["__kubernetes_node_label_kubernetes.io/os"]
+  #    regex         = "linux"
+  #    action        = "keep"
+  #
+  #  # Relabel the scraped samples, the metric name is in label "__name__".
+  #  [[inputs.kubernetesprometheus.instances.metric_relabel_configs]]
+  #    source_labels = ["__name__"]
+  #    regex         = "go_.*"
+  #    action        = "drop"
+  #
   #  [inputs.kubernetesprometheus.instances.auth]
   #    bearer_token_file = "/var/run/secrets/kubernetes.io/serviceaccount/token"
   #    [inputs.kubernetesprometheus.instances.auth.tls_config]
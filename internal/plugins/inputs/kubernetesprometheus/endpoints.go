@@ -166,7 +166,10 @@ func (e *Endpoints) startScrape(ctx context.Context, key, traits string, item *c
 				promscrape.WithMeasurement(cfg.measurement),
 				promscrape.KeepExistMetricName(cfg.keepExistMetricName),
 				promscrape.HonorTimestamps(cfg.honorTimestamps),
-				promscrape.WithExtraTags(cfg.tags))
+				promscrape.WithExtraTags(cfg.tags),
+				promscrape.WithMetricRelabelConfigs(ins.MetricRelabelConfigs),
+				promscrape.PreferProtobuf(ins.PreferProtobuf),
+				promscrape.WithExemplars(ins.CollectExemplars))
 
 			checkPausedFunc := func() bool {
 				return checkPaused(ctx, cfg.nodeName == "")
@@ -249,7 +252,10 @@ func tryCreateScrapeForEndpoints(
 			promscrape.WithMeasurement(cfg.measurement),
 			promscrape.KeepExistMetricName(cfg.keepExistMetricName),
 			promscrape.HonorTimestamps(cfg.honorTimestamps),
-			promscrape.WithExtraTags(cfg.tags))
+			promscrape.WithExtraTags(cfg.tags),
+			promscrape.WithMetricRelabelConfigs(endpointsInstance.MetricRelabelConfigs),
+			promscrape.PreferProtobuf(endpointsInstance.PreferProtobuf),
+			promscrape.WithExemplars(endpointsInstance.CollectExemplars))
 
 		checkPausedFunc := func() bool {
 			return checkPaused(ctx, cfg.nodeName == "")
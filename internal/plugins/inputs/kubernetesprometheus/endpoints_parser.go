@@ -236,7 +236,7 @@ func (p *endpointsParser) parsePromConfig(ins *Instance) ([]*basePromConfig, err
 				nodeName = *address.NodeName
 			}
 
-			configs = append(configs, &basePromConfig{
+			cfg := &basePromConfig{
 				urlstr:              u.String(),
 				headers:             ins.Headers,
 				measurement:         measurement,
@@ -244,13 +244,52 @@ func (p *endpointsParser) parsePromConfig(ins *Instance) ([]*basePromConfig, err
 				honorTimestamps:     ins.honorTimestamps,
 				tags:                tags,
 				nodeName:            nodeName,
-			})
+				metaLabels:          p.metaLabels(&set.Addresses[addressIdx], set.Ports),
+			}
+			if !cfg.relabel(ins.RelabelConfigs) {
+				continue // dropped by relabel_configs
+			}
+
+			configs = append(configs, cfg)
 		}
 	}
 
 	return configs, nil
 }
 
+func (p *endpointsParser) metaLabels(address *corev1.EndpointAddress, ports []corev1.EndpointPort) map[string]string {
+	keys := []string{
+		"__kubernetes_endpoints_name",
+		"__kubernetes_endpoints_namespace",
+	}
+	for k := range p.item.Labels {
+		keys = append(keys, "__kubernetes_endpoints_label_"+k)
+	}
+	for k := range p.item.Annotations {
+		keys = append(keys, "__kubernetes_endpoints_annotation_"+k)
+	}
+	res := resolveKeys(keys, p.matchEndpoints)
+
+	addressKeys := []string{
+		"__kubernetes_endpoints_address_node_name",
+		"__kubernetes_endpoints_address_ip",
+		"__kubernetes_endpoints_address_target_kind",
+		"__kubernetes_endpoints_address_target_name",
+		"__kubernetes_endpoints_address_target_namespace",
+	}
+	matchAddress := func(key string) (bool, string) { return p.matchAddress(address, key) }
+	for k, v := range resolveKeys(addressKeys, matchAddress) {
+		res[k] = v
+	}
+
+	for _, port := range ports {
+		if port.Name != "" {
+			res["__kubernetes_endpoints_port_"+port.Name+"_number"] = strconv.Itoa(int(port.Port))
+		}
+	}
+	return res
+}
+
 func (p *endpointsParser) matchEndpoints(key string) (matched bool, res string) {
 	for _, v := range EndpointsValueFroms {
 		matched, args := v.key.matches(key)
@@ -7,12 +7,15 @@ package kubernetesprometheus
 
 import (
 	"context"
+	"net/url"
 	"regexp"
+	"strings"
 	"sync/atomic"
 
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
 	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
 	dknet "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/net"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 )
@@ -49,6 +52,11 @@ type (
 		Custom  `toml:"custom"`
 		Auth    `toml:"auth"`
 
+		PreferProtobuf       bool            `toml:"prefer_protobuf"`
+		CollectExemplars     bool            `toml:"collect_exemplars"`
+		RelabelConfigs       relabel.Configs `toml:"relabel_configs"`
+		MetricRelabelConfigs relabel.Configs `toml:"metric_relabel_configs"`
+
 		validator *resourceValidator
 	}
 
@@ -83,9 +91,68 @@ type (
 		honorTimestamps     bool
 		// Only used on Endpoints.
 		nodeName string
+		// The __kubernetes_* labels of the target, only used for relabeling.
+		metaLabels map[string]string
 	}
 )
 
+// relabel applies relabel_configs to the target, the URL and tags are rebuilt from
+// the relabeled labels. It returns false if the target should be dropped.
+func (cfg *basePromConfig) relabel(configs relabel.Configs) bool {
+	if len(configs) == 0 {
+		return true
+	}
+
+	u, err := url.Parse(cfg.urlstr)
+	if err != nil {
+		return false
+	}
+
+	labels := make(map[string]string, len(cfg.metaLabels)+len(cfg.tags)+3)
+	for k, v := range cfg.metaLabels {
+		labels[k] = v
+	}
+	for k, v := range cfg.tags {
+		labels[k] = v
+	}
+	labels[relabel.AddressLabel] = u.Host
+	labels[relabel.SchemeLabel] = u.Scheme
+	labels[relabel.MetricsPathLabel] = u.Path
+	for k, arr := range u.Query() {
+		if len(arr) > 0 {
+			labels[relabel.ParamLabelPrefix+k] = arr[0]
+		}
+	}
+
+	labels, keep := configs.Process(labels)
+	if !keep || labels[relabel.AddressLabel] == "" {
+		return false
+	}
+
+	query := url.Values{}
+	for k, v := range labels {
+		if strings.HasPrefix(k, relabel.ParamLabelPrefix) {
+			query.Set(strings.TrimPrefix(k, relabel.ParamLabelPrefix), v)
+		}
+	}
+
+	scheme := labels[relabel.SchemeLabel]
+	if scheme == "" {
+		scheme = u.Scheme
+	}
+
+	u = &url.URL{
+		Scheme:   scheme,
+		Host:     labels[relabel.AddressLabel],
+		Path:     labels[relabel.MetricsPathLabel],
+		RawQuery: query.Encode(),
+	}
+
+	cfg.urlstr = u.String()
+	cfg.tags = relabel.PublicLabels(labels)
+	return true
+}
+
 func (ins *Instance) setDefault(ipt *Input) {
 	switch ins.Role {
 	case string(RoleNode):
@@ -147,6 +214,15 @@ func (im InstanceManager) Run(
 			continue
 		}
 
+		if err := ins.RelabelConfigs.Setup(); err != nil {
+			klog.Warnf("invalid relabel_configs for role %s, err %s", role, err)
+			continue
+		}
+		if err := ins.MetricRelabelConfigs.Setup(); err != nil {
+			klog.Warnf("invalid metric_relabel_configs for role %s, err %s", role, err)
+			continue
+		}
+
 		v, err := newResourceValidator(ins.Namespaces, ins.Selector)
 		if err != nil {
 			klog.Warnf("cannot parse selector %s err %s", ins.Selector, err)
@@ -153,6 +153,9 @@ func (n *Node) startScrape(ctx context.Context, key, traits string, item *corev1
 			klog.Warnf("node %s has unexpected url, err %s", key, err)
 			continue
 		}
+		if cfg == nil {
+			continue
+		}
 
 		opts := buildPromOptions(
 			n.role, key,
@@ -162,7 +165,10 @@ func (n *Node) startScrape(ctx context.Context, key, traits string, item *corev1
 			promscrape.WithMeasurement(cfg.measurement),
 			promscrape.KeepExistMetricName(cfg.keepExistMetricName),
 			promscrape.HonorTimestamps(cfg.honorTimestamps),
-			promscrape.WithExtraTags(cfg.tags))
+			promscrape.WithExtraTags(cfg.tags),
+			promscrape.WithMetricRelabelConfigs(ins.MetricRelabelConfigs),
+			promscrape.PreferProtobuf(ins.PreferProtobuf),
+			promscrape.WithExemplars(ins.CollectExemplars))
 
 		prom, err := newPromScraper(RoleNode, key, cfg.urlstr, cfg.measurement, n.feeder, checkPausedFunc, opts)
 		if err != nil {
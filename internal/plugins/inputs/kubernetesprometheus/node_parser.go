@@ -143,13 +143,35 @@ func (p *nodeParser) parsePromConfig(ins *Instance) (*basePromConfig, error) {
 		measurement = res
 	}
 
-	return &basePromConfig{
+	cfg := &basePromConfig{
 		urlstr:              u.String(),
 		measurement:         measurement,
 		keepExistMetricName: ins.keepExistMetricName,
 		honorTimestamps:     ins.honorTimestamps,
 		tags:                tags,
-	}, nil
+		metaLabels:          p.metaLabels(),
+	}
+	if !cfg.relabel(ins.RelabelConfigs) {
+		return nil, nil // dropped by relabel_configs
+	}
+	return cfg, nil
+}
+
+func (p *nodeParser) metaLabels() map[string]string {
+	keys := []string{
+		"__kubernetes_node_name",
+		"__kubernetes_node_address_Hostname",
+		"__kubernetes_node_address_InternalIP",
+		"__kubernetes_node_address_ExternalIP",
+		"__kubernetes_node_kubelet_endpoint_port",
+	}
+	for k := range p.item.Labels {
+		keys = append(keys, "__kubernetes_node_label_"+k)
+	}
+	for k := range p.item.Annotations {
+		keys = append(keys, "__kubernetes_node_annotation_"+k)
+	}
+	return resolveKeys(keys, p.matches)
 }
 
 func (p *nodeParser) matches(key string) (matched bool, res string) {
@@ -153,6 +153,9 @@ func (p *Pod) startScrape(ctx context.Context, key, traits string, item *corev1.
 			klog.Warnf("pod %s has unexpected url, err %s", key, err)
 			continue
 		}
+		if cfg == nil {
+			continue
+		}
 
 		opts := buildPromOptions(
 			p.role, key,
@@ -162,7 +165,10 @@ func (p *Pod) startScrape(ctx context.Context, key, traits string, item *corev1.
 			promscrape.WithMeasurement(cfg.measurement),
 			promscrape.KeepExistMetricName(cfg.keepExistMetricName),
 			promscrape.HonorTimestamps(cfg.honorTimestamps),
-			promscrape.WithExtraTags(cfg.tags))
+			promscrape.WithExtraTags(cfg.tags),
+			promscrape.WithMetricRelabelConfigs(ins.MetricRelabelConfigs),
+			promscrape.PreferProtobuf(ins.PreferProtobuf),
+			promscrape.WithExemplars(ins.CollectExemplars))
 
 		prom, err := newPromScraper(p.role, key, cfg.urlstr, cfg.measurement, p.feeder, checkPausedFunc, opts)
 		if err != nil {
@@ -170,13 +170,42 @@ func (p *podParser) parsePromConfig(ins *Instance) (*basePromConfig, error) {
 		measurement = ""
 	}
 
-	return &basePromConfig{
+	cfg := &basePromConfig{
 		urlstr:              u.String(),
 		measurement:         measurement,
 		keepExistMetricName: ins.keepExistMetricName,
 		honorTimestamps:     ins.honorTimestamps,
 		tags:                tags,
-	}, nil
+		metaLabels:          p.metaLabels(),
+	}
+	if !cfg.relabel(ins.RelabelConfigs) {
+		return nil, nil // dropped by relabel_configs
+	}
+	return cfg, nil
+}
+
+func (p *podParser) metaLabels() map[string]string {
+	keys := []string{
+		"__kubernetes_pod_name",
+		"__kubernetes_pod_namespace",
+		"__kubernetes_pod_node_name",
+		"__kubernetes_pod_ip",
+		"__kubernetes_pod_host_ip",
+	}
+	for k := range p.item.Labels {
+		keys = append(keys, "__kubernetes_pod_label_"+k)
+	}
+	for k := range p.item.Annotations {
+		keys = append(keys, "__kubernetes_pod_annotation_"+k)
+	}
+	for _, container := range p.item.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name != "" {
+				keys = append(keys, "__kubernetes_pod_container_"+container.Name+"_port_"+port.Name+"_number")
+			}
+		}
+	}
+	return resolveKeys(keys, p.matches)
 }
 
 func (p *podParser) matches(key string) (matched bool, res string) {
@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -238,3 +240,61 @@ func TestParsePod(t *testing.T) {
 		assert.Equal(t, out.tags[k], res.tags[k])
 	}
 }
+
+func TestParsePodWithRelabel(t *testing.T) {
+	ins := &Instance{
+		Target: Target{
+			Scheme:  "http",
+			Address: "__kubernetes_pod_ip",
+			Port:    "__kubernetes_pod_container_nginx_port_metrics_number",
+			Path:    "/metrics",
+		},
+		Custom: Custom{
+			Tags: map[string]string{
+				"pod_name": "__kubernetes_pod_name",
+			},
+		},
+		RelabelConfigs: relabel.Configs{
+			{SourceLabels: []string{"__kubernetes_pod_label_scrape"}, Regex: "true", Action: relabel.Keep},
+			{Regex: "__kubernetes_pod_label_(.+)", Action: relabel.LabelMap},
+			{SourceLabels: []string{"__kubernetes_pod_annotation_metrics_path"}, Regex: "(.+)", TargetLabel: "__metrics_path__"},
+			{TargetLabel: "__param_format", Replacement: strPtr("prometheus")},
+			{Regex: "scrape", Action: relabel.LabelDrop},
+		},
+	}
+	require.NoError(t, ins.RelabelConfigs.Setup())
+
+	newPod := func(scrape string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "nginx-123",
+				Labels:      map[string]string{"app": "nginx", "scrape": scrape},
+				Annotations: map[string]string{"metrics_path": "/stats/prometheus"},
+			},
+			Status: corev1.PodStatus{PodIP: "172.16.10.10"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  "nginx",
+						Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9090}},
+					},
+				},
+			},
+		}
+	}
+
+	res, err := newPodParser(newPod("true")).parsePromConfig(ins)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+
+	assert.Equal(t, "http://172.16.10.10:9090/stats/prometheus?format=prometheus", res.urlstr)
+	assert.Equal(t, map[string]string{"pod_name": "nginx-123", "app": "nginx"}, res.tags)
+
+	res, err = newPodParser(newPod("false")).parsePromConfig(ins)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
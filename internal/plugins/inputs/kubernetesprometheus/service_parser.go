@@ -163,6 +163,10 @@ func (s *serviceParser) transToEndpointsInstance(ins *Instance) *Instance {
 			BearerTokenFile: ins.Auth.BearerTokenFile,
 			TLSConfig:       deepCopyTLSConfig(ins.Auth.TLSConfig),
 		},
+		PreferProtobuf:       ins.PreferProtobuf,
+		CollectExemplars:     ins.CollectExemplars,
+		RelabelConfigs:       ins.RelabelConfigs,
+		MetricRelabelConfigs: ins.MetricRelabelConfigs,
 	}
 
 	if _, res := s.matches(ins.Scrape); res != "" {
@@ -89,3 +89,15 @@ func getLocalNodeName() (string, error) {
 	}
 	return "", fmt.Errorf("invalid ENV_K8S_NODE_NAME environment, cannot be empty")
 }
+
+// resolveKeys resolves the value of every key with the matches func, keys without
+// value are ignored.
+func resolveKeys(keys []string, matches func(string) (bool, string)) map[string]string {
+	res := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if matched, val := matches(key); matched && val != "" {
+			res[key] = val
+		}
+	}
+	return res
+}
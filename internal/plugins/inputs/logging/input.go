@@ -20,6 +20,7 @@ import (
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/goroutine"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/logtail/multiline"
+	dknet "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/net"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/tailer"
 	timex "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/time"
@@ -38,6 +39,9 @@ type Input struct {
 	MaxOpenFiles  int      `toml:"max_open_files"`
 	IgnoreDeadLog string   `toml:"ignore_dead_log"`
 
+	SocketFormat string                 `toml:"socket_format"`
+	SocketTLS    *dknet.TLSClientConfig `toml:"socket_tls"`
+
 	Source                string   `toml:"source"`
 	Service               string   `toml:"service"`
 	Pipeline              string   `toml:"pipeline"`
@@ -198,6 +202,8 @@ func (ipt *Input) buildTailerOptions(fieldWhitelist []string, ignoreDuration tim
 		tailer.WithPipeline(ipt.Pipeline),
 		tailer.EnableDebugFields(config.Cfg.EnableDebugFields),
 		tailer.WithSockets(ipt.Sockets),
+		tailer.WithSocketFormat(ipt.SocketFormat),
+		tailer.WithSocketTLS(ipt.SocketTLS),
 		tailer.WithIgnoredStatuses(ipt.IgnoreStatus),
 		tailer.WithMaxOpenFiles(ipt.MaxOpenFiles),
 		tailer.WithFromBeginning(ipt.FromBeginning),
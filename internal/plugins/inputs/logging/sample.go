@@ -12,6 +12,7 @@ var sampleCfg = `
   # ========== File Configuration ==========
   # List of log file paths, supports glob patterns for batch specification
   # Recommended to use absolute paths with file extensions to avoid collecting unexpected files
+  # Matched gzip/zstd compressed files(such as rotated *.log.1.gz) are read once to the end
   logfiles = [
     # Linux/Unix examples:
     # "/var/log/*.log",     # All .log files in directory
@@ -25,13 +26,17 @@ var sampleCfg = `
     # '''C:\\Program Files\\App\\logs\\*.log''', # Use triple quotes for paths with spaces
   ]
 
-  # Socket log reception, supports tcp/udp protocols
+  # Socket log reception, supports tcp/udp/tls protocols
   # Recommended to use internal network ports for security
   sockets = [
     # "tcp://0.0.0.0:9540",  # TCP listening port
     # "udp://0.0.0.0:9541",  # UDP listening port
+    # "tls://0.0.0.0:6514",  # TLS listening port, socket_tls required
   ]
 
+  # Socket data format: raw(newline-delimited text, the default) or syslog(RFC 3164/5424)
+  # socket_format = "syslog"
+
   # File path filtering, files matching these patterns will be ignored
   ignore = [
     # "*.tmp",
@@ -85,6 +90,13 @@ var sampleCfg = `
   # Whether to read from the beginning of log files
   from_beginning = false
 
+  # ========== Socket TLS Configuration ==========
+  # TLS config for tls:// sockets
+  # [inputs.logging.socket_tls]
+  #   cert     = "/path/to/server.crt"
+  #   cert_key = "/path/to/server.key"
+  #   ca_certs = ["/path/to/ca.crt"] # if set, client certificates are required and verified
+
   # ========== Custom Tags ==========
   [inputs.logging.tags]
   # environment = "production"
@@ -38,8 +38,10 @@ var (
 )
 
 const (
-	inputName    = "opentelemetry"
-	sampleConfig = `
+	inputName = "opentelemetry"
+)
+
+var sampleConfig = `
 [[inputs.opentelemetry]]
   ## customer_tags will work as a whitelist to prevent tags send to data center.
   ## All . will replace to _ ,like this :
@@ -109,7 +111,7 @@ const (
   ## sampling_rate used to set global sampling rate.
   # [inputs.opentelemetry.sampler]
     # sampling_rate = 1.0
-
+` + itrace.AfterGatherExtensionSample(inputName) + `
   # [inputs.opentelemetry.tags]
     # key1 = "value1"
     # key2 = "value2"
@@ -158,9 +160,10 @@ const (
   # ex_name = "env_resource_name"
   # ...
 `
-)
 
 type Input struct {
+	itrace.AfterGatherExtension
+
 	Pipelines           map[string]string `toml:"pipelines"`             // deprecated
 	IgnoreAttributeKeys []string          `toml:"ignore_attribute_keys"` // deprecated
 	CustomerTags        []string          `toml:"customer_tags"`
@@ -219,6 +222,7 @@ func (*Input) SampleMeasurement() []inputs.Measurement {
 		&JVMMeasurement{},
 		&itrace.TraceMeasurement{Name: inputName},
 		&itrace.TracingMetricMeasurement{Source: "opentelemetry", Name: "OpenTelemetry"},
+		&itrace.REDMetricMeasurement{Name: "OpenTelemetry"},
 	}
 }
 
@@ -351,19 +355,22 @@ func (ipt *Input) RegHTTPHandler() {
 		afterGather.AppendFilter(sampler.Sample)
 	}
 
+	// add tail sampler as the last filter, and RED metrics derived from all traces before filters
+	afterGatherRun := ipt.SetupAfterGather(inputName, afterGather, ipt.feeder, ipt.Tagger.HostTags())
+
 	expectedHeaders := map[string][]string{"Content-Type": {"application/x-protobuf", "application/json"}}
 	for k, v := range ipt.ExpectedHeaders {
 		expectedHeaders[k] = append(expectedHeaders[k], v)
 	}
 
 	if ipt.GRPCConfig != nil {
-		ipt.GRPCConfig.afterGatherRun = afterGather
+		ipt.GRPCConfig.afterGatherRun = afterGatherRun
 		ipt.GRPCConfig.feeder = ipt.feeder
 	}
 
 	if ipt.HTTPConfig != nil {
 		ipt.HTTPConfig.input = ipt
-		ipt.HTTPConfig.initConfig(afterGather)
+		ipt.HTTPConfig.initConfig(afterGatherRun)
 
 		httpapi.RegHTTPHandler("POST", ipt.HTTPConfig.TraceAPI,
 			httpapi.CheckExpectedHeaders(
@@ -409,6 +416,7 @@ func (ipt *Input) Run() {
 }
 
 func (ipt *Input) exit() {
+	ipt.CloseAfterGather()
 	if ipt.workerPool != nil {
 		ipt.workerPool.Shutdown()
 		log.Info("workerpool closed")
@@ -29,7 +29,7 @@ type httpConfig struct {
 	input          *Input
 }
 
-func (h *httpConfig) initConfig(agterGather *itrace.AfterGather) {
+func (h *httpConfig) initConfig(agterGather itrace.AfterGatherHandler) {
 	// 路由可能为空，为版本兼容设置默认值。
 	if h.TraceAPI == "" {
 		h.TraceAPI = defaultTraceAPI
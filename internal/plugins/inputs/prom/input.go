@@ -24,6 +24,7 @@ import (
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/ntp"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
 	iprom "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/prom"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 )
 
 var _ inputs.ElectionInput = (*Input)(nil)
@@ -58,6 +59,8 @@ type Input struct {
 	Measurements           []iprom.Rule `toml:"measurements"`
 	KeepExistMetricName    bool         `toml:"keep_exist_metric_name"`
 	HonorTimestamps        bool         `toml:"honor_timestamps"`
+	PreferProtobuf         bool         `toml:"prefer_protobuf"`
+	CollectExemplars       bool         `toml:"collect_exemplars"`
 	Output                 string       `toml:"output"`
 	MaxFileSize            int64        `toml:"max_file_size"`
 
@@ -74,6 +77,8 @@ type Input struct {
 	IgnoreTagKV map[string][]string `toml:"ignore_tag_kv_match"`
 	HTTPHeaders map[string]string   `toml:"http_headers"`
 
+	MetricRelabelConfigs relabel.Configs `toml:"metric_relabel_configs"`
+
 	Tags               map[string]string `toml:"tags"`
 	DisableHostTag     bool              `toml:"disable_host_tag"`
 	DisableInstanceTag bool              `toml:"disable_instance_tag"`
@@ -456,6 +461,10 @@ func (i *Input) Init() error {
 		i.urlTags[u] = tempTags
 	}
 
+	if err := i.MetricRelabelConfigs.Setup(); err != nil {
+		return fmt.Errorf("invalid metric_relabel_configs: %w", err)
+	}
+
 	if i.StreamSize > 0 && i.callbackFunc == nil { // set callback on streamming-mode
 		i.callbackFunc = i.defaultHandleCallback()
 	}
@@ -474,6 +483,8 @@ func (i *Input) Init() error {
 		iprom.WithMeasurements(i.Measurements),
 		iprom.KeepExistMetricName(i.KeepExistMetricName),
 		iprom.HonorTimestamps(i.HonorTimestamps),
+		iprom.WithPreferProtobuf(i.PreferProtobuf),
+		iprom.WithExemplars(i.CollectExemplars),
 		iprom.WithOutput(i.Output),
 		iprom.WithMaxFileSize(i.MaxFileSize),
 		iprom.WithTLSOpen(i.TLSOpen),
@@ -487,6 +498,7 @@ func (i *Input) Init() error {
 		iprom.WithAsLogging(i.AsLogging),
 		iprom.WithIgnoreTagKV(i.IgnoreTagKV),
 		iprom.WithHTTPHeaders(i.HTTPHeaders),
+		iprom.WithMetricRelabelConfigs(i.MetricRelabelConfigs),
 		iprom.WithDisableInfoTag(i.DisableInfoTag),
 		iprom.WithMaxBatchCallback(i.StreamSize, i.callbackFunc),
 		iprom.WithAuth(i.Auth),
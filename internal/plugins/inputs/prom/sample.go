@@ -75,6 +75,13 @@ const sampleCfg = `
   ## Use the timestamps provided by the target. Set to 'false' to use the scrape time.
   honor_timestamps = true
 
+  ## Negotiate the protobuf exposition format with the target, it's required to
+  ## collect native histograms, which are converted to buckets with tag 'le'.
+  # prefer_protobuf = false
+
+  ## Collect exemplars as '<field>_exemplar' with exemplar labels(such as trace_id) as fields.
+  # collect_exemplars = false
+
   ## TLS config
   # insecure_skip_verify = true
   ## Following ca_certs/cert/cert_key are optional, if insecure_skip_verify = true.
@@ -126,6 +133,13 @@ const sampleCfg = `
     # key1 = [ "val1.*", "val2.*"]
     # key2 = [ "val1.*", "val2.*"]
 
+  ## Prometheus-style relabeling on scraped samples, the metric name is available as label __name__.
+  ## Supported actions: replace/keep/drop/hashmod/labelmap/labeldrop/labelkeep/lowercase/uppercase.
+  # [[inputs.prom.metric_relabel_configs]]
+    # source_labels = ["__name__"]
+    # regex         = "go_.*"
+    # action        = "drop"
+
   ## Add HTTP headers to data pulling (Example basic authentication).
   # [inputs.prom.http_headers]
     # Authorization = "Basic bXl0b21jYXQ="
@@ -93,6 +93,19 @@ const (
   #     # cert     = "/opt/tls/client.crt"
   #     # cert_key = "/opt/tls/client.key"
 
+  ## DNS Service Discovery
+  # [inputs.promsd.dns_sd_config]
+  #   ## DNS names to query
+  #   names = ["_prometheus._tcp.example.com"]
+  #   ## Record type: SRV, A or AAAA (default SRV)
+  #   type = "SRV"
+  #   ## Port of the targets, required for A and AAAA records
+  #   # port = 9100
+  #   ## Optional DNS server (format: host:port), use the system resolver if empty
+  #   # server = "10.0.0.2:53"
+  #   ## Refresh interval for re-querying DNS
+  #   refresh_interval = "30s"
+
   # ============================================================================
   # Scrape Configuration
   # ============================================================================
@@ -111,6 +124,13 @@ const (
     ## Scraping interval
     interval = "30s"
 
+    ## Negotiate the protobuf exposition format with the targets, it's required to
+    ## collect native histograms, which are converted to buckets with tag "le".
+    # prefer_protobuf = false
+
+    ## Collect exemplars as "<field>_exemplar" with exemplar labels(such as trace_id) as fields.
+    # collect_exemplars = false
+
     ## Optional: Custom HTTP headers
     [inputs.promsd.scrape.http_headers]
       # Authorization = "Bearer <token>"
@@ -127,6 +147,26 @@ const (
       # cert     = "/opt/tls/client.crt"
       # cert_key = "/opt/tls/client.key"
 
+    ## Optional: Prometheus-style relabeling on discovered targets before scraping.
+    ## Labels __address__/__scheme__/__metrics_path__/__param_<name> and the discovered
+    ## labels(such as __meta_consul_service) are available, labels with prefix "__" are
+    ## removed after relabeling.
+    ## Supported actions: replace/keep/drop/hashmod/labelmap/labeldrop/labelkeep/lowercase/uppercase.
+    # [[inputs.promsd.scrape.relabel_configs]]
+    #   source_labels = ["__meta_consul_service"]
+    #   target_label  = "service"
+    #
+    # [[inputs.promsd.scrape.relabel_configs]]
+    #   source_labels = ["__meta_consul_tags"]
+    #   regex         = ".*,metrics,.*"
+    #   action        = "keep"
+
+    ## Optional: Prometheus-style relabeling on scraped samples, the metric name is available as label __name__.
+    # [[inputs.promsd.scrape.metric_relabel_configs]]
+    #   source_labels = ["__name__"]
+    #   regex         = "go_.*"
+    #   action        = "drop"
+
   # ============================================================================
   # Additional Tags
   # ============================================================================
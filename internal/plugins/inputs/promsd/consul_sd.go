@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/GuanceCloud/cliutils/logger"
@@ -104,21 +106,18 @@ func (sd *ConsulSD) produceScrapers(ctx context.Context, cfg *ScrapeConfig, opts
 func (sd *ConsulSD) convertTargetsToScraper(cfg *ScrapeConfig, opts []promscrape.Option, newTargets []consulSDTarget) ([]scraper, error) {
 	var scrapers []scraper
 
-	for _, target := range newTargets {
-		u := &url.URL{
-			Scheme: cfg.Scheme,
-			Host:   target.Address,
-			Path:   cfg.MetricsPath,
-		}
+	if _, err := url.ParseQuery(cfg.Params); err != nil {
+		sd.logger.Warnf("consul_sd: unexpected scrape params: %s", cfg.Params)
+	}
 
-		paramValues, err := url.ParseQuery(cfg.Params)
-		if err != nil {
-			sd.logger.Warnf("consul_sd: unexpected scrape params: %s", cfg.Params)
-		} else {
-			u.RawQuery = paramValues.Encode()
+	for _, target := range newTargets {
+		urlstr, tags, keep := buildTarget(cfg, target.Address, target.Labels)
+		if !keep {
+			sd.logger.Debugf("consul_sd: target %s dropped by relabel_configs", target.Address)
+			continue
 		}
 
-		scraper, err := newPromScraper(u.String(), opts)
+		scraper, err := newPromScraper(urlstr, tags, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -179,7 +178,7 @@ func (sd *ConsulSD) getServiceTargets(client *api.Client, service string) ([]con
 		if entry.ServiceAddress == "" {
 			address = fmt.Sprintf("%s:%d", entry.Address, entry.ServicePort)
 		}
-		targets = append(targets, consulSDTarget{Address: address})
+		targets = append(targets, consulSDTarget{Address: address, Labels: consulMetaLabels(entry)})
 	}
 	return targets, nil
 }
@@ -224,4 +223,39 @@ func (sd *ConsulSD) terminatedTasks() {
 
 type consulSDTarget struct {
 	Address string
+	Labels  map[string]string // meta labels for relabeling
+}
+
+// consulMetaLabels returns the meta labels of the service, same as Prometheus consul_sd_config.
+func consulMetaLabels(entry *api.CatalogService) map[string]string {
+	labels := map[string]string{
+		"__meta_consul_address":         entry.Address,
+		"__meta_consul_dc":              entry.Datacenter,
+		"__meta_consul_node":            entry.Node,
+		"__meta_consul_service":         entry.ServiceName,
+		"__meta_consul_service_id":      entry.ServiceID,
+		"__meta_consul_service_address": entry.ServiceAddress,
+		"__meta_consul_service_port":    strconv.Itoa(entry.ServicePort),
+		"__meta_consul_namespace":       entry.Namespace,
+		"__meta_consul_partition":       entry.Partition,
+	}
+
+	// tags are joined with the leading and trailing separator, so it can be matched by regex `.*,tag,.*'
+	if len(entry.ServiceTags) > 0 {
+		labels["__meta_consul_tags"] = "," + strings.Join(entry.ServiceTags, ",") + ","
+	}
+
+	for k, v := range entry.NodeMeta {
+		labels["__meta_consul_metadata_"+k] = v
+	}
+
+	for k, v := range entry.ServiceMeta {
+		labels["__meta_consul_service_metadata_"+k] = v
+	}
+
+	for k, v := range entry.TaggedAddresses {
+		labels["__meta_consul_tagged_address_"+k] = v
+	}
+
+	return labels
 }
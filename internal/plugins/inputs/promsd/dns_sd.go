@@ -0,0 +1,255 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package promsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/logger"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/promscrape"
+)
+
+const (
+	dnsTypeSRV  = "SRV"
+	dnsTypeA    = "A"
+	dnsTypeAAAA = "AAAA"
+
+	dnsNameLabel            = "__meta_dns_name"
+	dnsSRVRecordTargetLabel = "__meta_dns_srv_record_target"
+	dnsSRVRecordPortLabel   = "__meta_dns_srv_record_port"
+
+	defaultDNSLookupTimeout = time.Second * 10
+)
+
+type DNSSD struct {
+	Names           []string      `toml:"names"`
+	Type            string        `toml:"type"`
+	Port            int           `toml:"port"`
+	Server          string        `toml:"server"`
+	RefreshInterval time.Duration `toml:"refresh_interval"`
+
+	targetGroups TargetGroups
+	tasks        []scraper
+
+	resolver dnsResolver
+	logger   *logger.Logger
+}
+
+// dnsResolver is the subset of net.Resolver used by DNSSD.
+type dnsResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+func (sd *DNSSD) SetLogger(logger *logger.Logger) { sd.logger = logger }
+
+func (sd *DNSSD) StartScraperProducer(ctx context.Context, cfg *ScrapeConfig, opts []promscrape.Option, out chan<- scraper) {
+	sd.logger.Infof("dns_sd: starting service discovery for %v", sd.Names)
+
+	if err := sd.setup(); err != nil {
+		sd.logger.Errorf("dns_sd: invalid config: %s", err)
+		return
+	}
+
+	ticker := time.NewTicker(sd.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := sd.produceScrapers(ctx, cfg, opts, out); err != nil {
+			sd.logger.Warnf("dns_sd: failed to produce scrapers: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			sd.terminateTasks()
+			sd.logger.Info("dns_sd: terminating all tasks and exiting")
+			return
+
+		case <-ticker.C:
+			// next
+		}
+	}
+}
+
+func (sd *DNSSD) setup() error {
+	if len(sd.Names) == 0 {
+		return fmt.Errorf("names is required")
+	}
+
+	sd.Type = strings.ToUpper(sd.Type)
+	if sd.Type == "" {
+		sd.Type = dnsTypeSRV
+	}
+
+	switch sd.Type {
+	case dnsTypeSRV:
+		// port comes from the SRV records
+	case dnsTypeA, dnsTypeAAAA:
+		if sd.Port <= 0 || sd.Port > 65535 {
+			return fmt.Errorf("invalid port %d, a valid port is required for type %s", sd.Port, sd.Type)
+		}
+	default:
+		return fmt.Errorf("unsupported type %q, only SRV/A/AAAA supported", sd.Type)
+	}
+
+	if sd.resolver != nil {
+		return nil
+	}
+
+	if sd.Server == "" {
+		sd.resolver = net.DefaultResolver
+		return nil
+	}
+
+	server := sd.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	sd.resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{}
+			return d.DialContext(ctx, network, server)
+		},
+	}
+	return nil
+}
+
+func (sd *DNSSD) produceScrapers(ctx context.Context, cfg *ScrapeConfig, opts []promscrape.Option, out chan<- scraper) error {
+	newTargetGroups, err := sd.discoverTargetGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !sd.targetGroupsChanged(newTargetGroups) {
+		sd.logger.Debugf("dns_sd: target groups unchanged")
+		return nil
+	}
+
+	scrapers, err := convertTargetGroupsToScraper(cfg, opts, newTargetGroups)
+	if err != nil {
+		return err
+	}
+
+	for _, scraper := range scrapers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		select {
+		case out <- scraper:
+			// next
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	sd.terminateTasks()
+	sd.targetGroups = newTargetGroups
+	sd.tasks = scrapers
+	sd.logger.Infof("dns_sd: updated target groups, found %d new scrapers", len(scrapers))
+	return nil
+}
+
+// discoverTargetGroups looks up all the names, every record is converted to a
+// target group with the __meta_dns_* labels. If any lookup failed, the previous
+// target groups are kept.
+func (sd *DNSSD) discoverTargetGroups(ctx context.Context) (TargetGroups, error) {
+	var res TargetGroups
+
+	for _, name := range sd.Names {
+		groups, err := sd.lookup(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("lookup %s %s: %w", sd.Type, name, err)
+		}
+		res = append(res, groups...)
+	}
+
+	return res, nil
+}
+
+func (sd *DNSSD) lookup(ctx context.Context, name string) (TargetGroups, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultDNSLookupTimeout)
+	defer cancel()
+
+	var res TargetGroups
+
+	switch sd.Type {
+	case dnsTypeSRV:
+		_, records, err := sd.resolver.LookupSRV(ctx, "", "", name)
+		if err != nil {
+			if isDNSNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		for _, record := range records {
+			target := strings.TrimSuffix(record.Target, ".")
+			port := strconv.Itoa(int(record.Port))
+
+			res = append(res, TargetGroup{
+				Targets: []string{net.JoinHostPort(target, port)},
+				Labels: map[string]string{
+					dnsNameLabel:            name,
+					dnsSRVRecordTargetLabel: record.Target,
+					dnsSRVRecordPortLabel:   port,
+				},
+			})
+		}
+
+	case dnsTypeA, dnsTypeAAAA:
+		network := "ip4"
+		if sd.Type == dnsTypeAAAA {
+			network = "ip6"
+		}
+
+		ips, err := sd.resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			if isDNSNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		for _, ip := range ips {
+			res = append(res, TargetGroup{
+				Targets: []string{net.JoinHostPort(ip.String(), strconv.Itoa(sd.Port))},
+				Labels: map[string]string{
+					dnsNameLabel: name,
+				},
+			})
+		}
+	}
+
+	// the order of the records is random, sort them to detect changes
+	sort.Slice(res, func(i, j int) bool { return res[i].Targets[0] < res[j].Targets[0] })
+	return res, nil
+}
+
+func isDNSNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+func (sd *DNSSD) targetGroupsChanged(newTargetGroups TargetGroups) bool {
+	return !reflect.DeepEqual(sd.targetGroups, newTargetGroups)
+}
+
+func (sd *DNSSD) terminateTasks() {
+	for _, task := range sd.tasks {
+		task.markAsTerminated()
+	}
+}
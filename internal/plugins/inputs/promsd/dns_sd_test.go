@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package promsd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/GuanceCloud/cliutils/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockResolver struct {
+	srv map[string][]*net.SRV
+	ips map[string][]net.IP
+	err error
+}
+
+func (r *mockResolver) LookupSRV(_ context.Context, _, _, name string) (string, []*net.SRV, error) {
+	if r.err != nil {
+		return "", nil, r.err
+	}
+	records, ok := r.srv[name]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return name, records, nil
+}
+
+func (r *mockResolver) LookupIP(_ context.Context, network, host string) ([]net.IP, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	ips, ok := r.ips[network+"/"+host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return ips, nil
+}
+
+func TestDNSSDSetup(t *testing.T) {
+	cases := []struct {
+		name string
+		sd   *DNSSD
+		fail bool
+	}{
+		{"default-srv", &DNSSD{Names: []string{"_x._tcp.example.com"}}, false},
+		{"a-with-port", &DNSSD{Names: []string{"example.com"}, Type: "a", Port: 9100}, false},
+		{"custom-server", &DNSSD{Names: []string{"example.com"}, Server: "10.0.0.2"}, false},
+		{"no-names", &DNSSD{}, true},
+		{"a-without-port", &DNSSD{Names: []string{"example.com"}, Type: "A"}, true},
+		{"unknown-type", &DNSSD{Names: []string{"example.com"}, Type: "MX"}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.sd.setup()
+			if tc.fail {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, tc.sd.resolver)
+		})
+	}
+}
+
+func TestDNSSDDiscoverTargetGroups(t *testing.T) {
+	resolver := &mockResolver{
+		srv: map[string][]*net.SRV{
+			"_node._tcp.example.com": {
+				{Target: "node-2.example.com.", Port: 9100},
+				{Target: "node-1.example.com.", Port: 9100},
+			},
+		},
+		ips: map[string][]net.IP{
+			"ip4/node.example.com": {net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.1")},
+			"ip6/node.example.com": {net.ParseIP("fd00::1")},
+		},
+	}
+
+	t.Run("srv", func(t *testing.T) {
+		sd := &DNSSD{Names: []string{"_node._tcp.example.com", "_missing._tcp.example.com"}, resolver: resolver}
+		require.NoError(t, sd.setup())
+
+		groups, err := sd.discoverTargetGroups(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, TargetGroups{
+			{
+				Targets: []string{"node-1.example.com:9100"},
+				Labels: map[string]string{
+					dnsNameLabel:            "_node._tcp.example.com",
+					dnsSRVRecordTargetLabel: "node-1.example.com.",
+					dnsSRVRecordPortLabel:   "9100",
+				},
+			},
+			{
+				Targets: []string{"node-2.example.com:9100"},
+				Labels: map[string]string{
+					dnsNameLabel:            "_node._tcp.example.com",
+					dnsSRVRecordTargetLabel: "node-2.example.com.",
+					dnsSRVRecordPortLabel:   "9100",
+				},
+			},
+		}, groups)
+	})
+
+	t.Run("a", func(t *testing.T) {
+		sd := &DNSSD{Names: []string{"node.example.com"}, Type: "A", Port: 9100, resolver: resolver}
+		require.NoError(t, sd.setup())
+
+		groups, err := sd.discoverTargetGroups(context.Background())
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, []string{"10.0.0.1:9100"}, groups[0].Targets)
+		assert.Equal(t, []string{"10.0.0.2:9100"}, groups[1].Targets)
+		assert.Equal(t, "node.example.com", groups[0].Labels[dnsNameLabel])
+	})
+
+	t.Run("aaaa", func(t *testing.T) {
+		sd := &DNSSD{Names: []string{"node.example.com"}, Type: "AAAA", Port: 9100, resolver: resolver}
+		require.NoError(t, sd.setup())
+
+		groups, err := sd.discoverTargetGroups(context.Background())
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, []string{"[fd00::1]:9100"}, groups[0].Targets)
+	})
+
+	t.Run("lookup-error", func(t *testing.T) {
+		sd := &DNSSD{Names: []string{"_node._tcp.example.com"}, resolver: &mockResolver{err: errors.New("i/o timeout")}}
+		require.NoError(t, sd.setup())
+
+		_, err := sd.discoverTargetGroups(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestDNSSDProduceScrapers(t *testing.T) {
+	resolver := &mockResolver{
+		srv: map[string][]*net.SRV{
+			"_node._tcp.example.com": {{Target: "node-1.example.com.", Port: 9100}},
+		},
+	}
+
+	sd := &DNSSD{Names: []string{"_node._tcp.example.com"}, resolver: resolver, logger: logger.DefaultSLogger("test")}
+	require.NoError(t, sd.setup())
+
+	cfg := &ScrapeConfig{Scheme: "http", MetricsPath: "/metrics"}
+	out := make(chan scraper, 10)
+
+	require.NoError(t, sd.produceScrapers(context.Background(), cfg, nil, out))
+	require.Len(t, out, 1)
+	first := <-out
+	assert.Equal(t, "http://node-1.example.com:9100/metrics", first.targetURL())
+
+	// unchanged, no new scrapers
+	require.NoError(t, sd.produceScrapers(context.Background(), cfg, nil, out))
+	assert.Len(t, out, 0)
+	assert.False(t, first.isTerminated())
+
+	// records changed, the old scrapers are terminated
+	resolver.srv["_node._tcp.example.com"] = append(resolver.srv["_node._tcp.example.com"],
+		&net.SRV{Target: "node-2.example.com.", Port: 9100})
+
+	require.NoError(t, sd.produceScrapers(context.Background(), cfg, nil, out))
+	assert.Len(t, out, 2)
+	assert.True(t, first.isTerminated())
+}
@@ -34,6 +34,7 @@ type Input struct {
 	HTTPSD   *HTTPSD       `toml:"http_sd_config"`
 	ConsulSD *ConsulSD     `toml:"consul_sd_config"`
 	FileSD   *FileSD       `toml:"file_sd_config"`
+	DNSSD    *DNSSD        `toml:"dns_sd_config"`
 
 	Tags map[string]string `toml:"tags"`
 
@@ -115,6 +116,10 @@ func (ipt *Input) setup() {
 		ipt.FileSD.RefreshInterval = config.ProtectedInterval(minRefreshInterval, maxRefreshInterval, ipt.FileSD.RefreshInterval)
 		ipt.FileSD.SetLogger(ipt.logger)
 	}
+	if ipt.DNSSD != nil {
+		ipt.DNSSD.RefreshInterval = config.ProtectedInterval(minRefreshInterval, maxRefreshInterval, ipt.DNSSD.RefreshInterval)
+		ipt.DNSSD.SetLogger(ipt.logger)
+	}
 }
 
 func (ipt *Input) startWorker(ctx context.Context, scraperChan chan scraper) {
@@ -163,6 +168,13 @@ func (ipt *Input) startServiceDiscovery(ctx context.Context, g *goroutine.Group,
 			return nil
 		})
 	}
+
+	if ipt.DNSSD != nil {
+		g.Go(func(_ context.Context) error {
+			ipt.DNSSD.StartScraperProducer(ctx, ipt.Scrape, promOptions, scraperChan)
+			return nil
+		})
+	}
 }
 
 func (ipt *Input) buildPromOptions() ([]promscrape.Option, error) {
@@ -170,6 +182,14 @@ func (ipt *Input) buildPromOptions() ([]promscrape.Option, error) {
 		return nil, fmt.Errorf("scrape config is required")
 	}
 
+	if err := ipt.Scrape.RelabelConfigs.Setup(); err != nil {
+		return nil, fmt.Errorf("invalid relabel_configs: %w", err)
+	}
+
+	if err := ipt.Scrape.MetricRelabelConfigs.Setup(); err != nil {
+		return nil, fmt.Errorf("invalid metric_relabel_configs: %w", err)
+	}
+
 	var globalTags map[string]string
 	if ipt.Election {
 		globalTags = ipt.tagger.ElectionTags()
@@ -186,6 +206,9 @@ func (ipt *Input) buildPromOptions() ([]promscrape.Option, error) {
 		promscrape.WithExtraTags(mergedTags),
 		promscrape.WithHTTPHeader(ipt.Scrape.HTTPHeaders),
 		promscrape.WithCallback(ipt.callbackFn),
+		promscrape.WithMetricRelabelConfigs(ipt.Scrape.MetricRelabelConfigs),
+		promscrape.PreferProtobuf(ipt.Scrape.PreferProtobuf),
+		promscrape.WithExemplars(ipt.Scrape.CollectExemplars),
 	}
 
 	if ipt.Scrape.Auth != nil {
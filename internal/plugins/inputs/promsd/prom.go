@@ -28,7 +28,8 @@ type promScraper struct {
 	terminated atomic.Bool
 }
 
-func newPromScraper(urlstr string, opts []promscrape.Option) (*promScraper, error) {
+// newPromScraper create scraper of the URL, targetTags take precedence over the default host/instance tags.
+func newPromScraper(urlstr string, targetTags map[string]string, opts []promscrape.Option) (*promScraper, error) {
 	u, err := url.Parse(urlstr)
 	if err != nil {
 		return nil, err
@@ -36,8 +37,11 @@ func newPromScraper(urlstr string, opts []promscrape.Option) (*promScraper, erro
 
 	p := promScraper{urlstr: u.String()}
 	tags := buildScraperTags(u.Host)
+	for k, v := range targetTags {
+		tags[k] = v
+	}
 
-	p.pm, err = promscrape.NewPromScraper(append(opts, promscrape.WithExtraTags(tags))...)
+	p.pm, err = promscrape.NewPromScraper(append(opts[:len(opts):len(opts)], promscrape.WithExtraTags(tags))...)
 	if err != nil {
 		return nil, err
 	}
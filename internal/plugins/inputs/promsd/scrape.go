@@ -14,6 +14,7 @@ import (
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/ntp"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/plugins/inputs"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/promscrape"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 )
 
 type SD interface {
@@ -32,8 +33,15 @@ type ScrapeConfig struct {
 	Params              string            `toml:"params"`
 	Interval            time.Duration     `toml:"interval"`
 	KeepExistMetricName bool              `toml:"keep_exist_metric_name"`
+	PreferProtobuf      bool              `toml:"prefer_protobuf"`
+	CollectExemplars    bool              `toml:"collect_exemplars"`
 	HTTPHeaders         map[string]string `toml:"http_headers"`
 	Auth                *Auth             `toml:"auth"`
+
+	// RelabelConfigs relabel the discovered targets before scraping.
+	RelabelConfigs relabel.Configs `toml:"relabel_configs"`
+	// MetricRelabelConfigs relabel the scraped samples.
+	MetricRelabelConfigs relabel.Configs `toml:"metric_relabel_configs"`
 }
 
 func startScraperConsumer(ctx context.Context, logger *logger.Logger, workerName string, scrapeInterval time.Duration, in <-chan scraper) {
@@ -10,8 +10,13 @@ import (
 	"strings"
 
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/promscrape"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 )
 
+// metaLabelPrefix is the prefix of labels generated by service discovery, such as
+// __meta_consul_service. These labels are only used for relabeling.
+const metaLabelPrefix = "__meta_"
+
 type TargetGroups []TargetGroup
 
 type TargetGroup struct {
@@ -36,13 +41,13 @@ func convertTargetGroupsToScraper(cfg *ScrapeConfig, opts []promscrape.Option, n
 func buildScrapersFromGroup(cfg *ScrapeConfig, opts []promscrape.Option, group TargetGroup) ([]scraper, error) {
 	var scrapers []scraper
 
-	scheme := extractSchemeFromLabels(group.Labels, cfg.Scheme)
-	path := extractMetricsPathFromLabels(group.Labels, cfg.MetricsPath)
-	params := mergeParamsFromLabelsAndConfig(group.Labels, cfg.Params)
-
 	for _, target := range group.Targets {
-		url := buildScrapeURL(scheme, target, path, params)
-		scraper, err := newPromScraper(url, append(opts, promscrape.WithExtraTags(group.Labels)))
+		url, tags, keep := buildTarget(cfg, target, group.Labels)
+		if !keep {
+			continue
+		}
+
+		scraper, err := newPromScraper(url, tags, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -52,6 +57,52 @@ func buildScrapersFromGroup(cfg *ScrapeConfig, opts []promscrape.Option, group T
 	return scrapers, nil
 }
 
+// buildTarget build the scrape URL and the tags of the target. If relabel_configs
+// configured, the target labels(__address__/__scheme__/__metrics_path__/__param_<name>
+// and the discovered labels) are relabeled, the target is dropped if keep is false.
+func buildTarget(cfg *ScrapeConfig, address string, labels map[string]string) (urlstr string, tags map[string]string, keep bool) {
+	if len(cfg.RelabelConfigs) == 0 {
+		scheme := extractSchemeFromLabels(labels, cfg.Scheme)
+		path := extractMetricsPathFromLabels(labels, cfg.MetricsPath)
+		params := mergeParamsFromLabelsAndConfig(labels, cfg.Params)
+
+		tags = make(map[string]string, len(labels))
+		for k, v := range labels {
+			if !strings.HasPrefix(k, metaLabelPrefix) {
+				tags[k] = v
+			}
+		}
+		return buildScrapeURL(scheme, address, path, params), tags, true
+	}
+
+	lbs := map[string]string{
+		relabel.AddressLabel:     address,
+		relabel.SchemeLabel:      cfg.Scheme,
+		relabel.MetricsPathLabel: cfg.MetricsPath,
+	}
+	if values, err := url.ParseQuery(cfg.Params); err == nil {
+		for k, arr := range values {
+			if len(arr) > 0 {
+				lbs[relabel.ParamLabelPrefix+k] = arr[0]
+			}
+		}
+	}
+	for k, v := range labels {
+		lbs[k] = v
+	}
+
+	lbs, keep = cfg.RelabelConfigs.Process(lbs)
+	if !keep || lbs[relabel.AddressLabel] == "" {
+		return "", nil, false
+	}
+
+	scheme := extractSchemeFromLabels(lbs, cfg.Scheme)
+	path := extractMetricsPathFromLabels(lbs, cfg.MetricsPath)
+	params := url.Values(extractParamsFromLabels(lbs))
+
+	return buildScrapeURL(scheme, lbs[relabel.AddressLabel], path, params), relabel.PublicLabels(lbs), true
+}
+
 func buildScrapeURL(scheme, target, path string, params url.Values) string {
 	u := &url.URL{
 		Scheme:   scheme,
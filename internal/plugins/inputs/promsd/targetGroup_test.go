@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package promsd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
+)
+
+func TestBuildTarget(t *testing.T) {
+	t.Run("no-relabel", func(t *testing.T) {
+		cfg := &ScrapeConfig{Scheme: "http", MetricsPath: "/metrics", Params: "a=1"}
+
+		urlstr, tags, keep := buildTarget(cfg, "10.0.0.1:9100", map[string]string{
+			"__meta_consul_service": "node",
+			"__metrics_path__":      "/probe",
+			"env":                   "prod",
+		})
+
+		assert.True(t, keep)
+		assert.Equal(t, "http://10.0.0.1:9100/probe?a=1", urlstr)
+		assert.Equal(t, map[string]string{"__metrics_path__": "/probe", "env": "prod"}, tags)
+	})
+
+	t.Run("relabel", func(t *testing.T) {
+		cfg := &ScrapeConfig{
+			Scheme:      "http",
+			MetricsPath: "/metrics",
+			Params:      "module=http_2xx",
+			RelabelConfigs: relabel.Configs{
+				{SourceLabels: []string{"__meta_consul_tags"}, Regex: ".*,metrics,.*", Action: relabel.Keep},
+				{SourceLabels: []string{"__address__"}, TargetLabel: "__param_target"},
+				{SourceLabels: []string{"__param_target"}, TargetLabel: "instance"},
+				{TargetLabel: "__address__", Replacement: strPtr("blackbox:9115")},
+				{TargetLabel: "__metrics_path__", Replacement: strPtr("/probe")},
+				{SourceLabels: []string{"__meta_consul_service"}, TargetLabel: "service"},
+			},
+		}
+		require.NoError(t, cfg.RelabelConfigs.Setup())
+
+		urlstr, tags, keep := buildTarget(cfg, "10.0.0.1:80", map[string]string{
+			"__meta_consul_service": "web",
+			"__meta_consul_tags":    ",http,metrics,",
+		})
+
+		assert.True(t, keep)
+		assert.Equal(t, "http://blackbox:9115/probe?module=http_2xx&target=10.0.0.1%3A80", urlstr)
+		assert.Equal(t, map[string]string{"instance": "10.0.0.1:80", "service": "web"}, tags)
+
+		_, _, keep = buildTarget(cfg, "10.0.0.2:80", map[string]string{
+			"__meta_consul_service": "db",
+			"__meta_consul_tags":    ",db,",
+		})
+		assert.False(t, keep)
+	})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -39,7 +39,9 @@ var (
 const (
 	inputName     = "skywalking"
 	jvmMetricName = "skywalking_jvm"
-	sampleConfig  = `
+)
+
+var sampleConfig = `
 [[inputs.skywalking]]
   ## Skywalking HTTP endpoints for tracing, metric, logging and profiling.
   ## NOTE: DO NOT EDIT.
@@ -80,7 +82,7 @@ const (
   ## sampling_rate used to set global sampling rate.
   # [inputs.skywalking.sampler]
     # sampling_rate = 1.0
-
+` + itrace.AfterGatherExtensionSample(inputName) + `
   # [inputs.skywalking.tags]
     # key1 = "value1"
     # key2 = "value2"
@@ -100,7 +102,6 @@ const (
     # path = "./skywalking_storage"
     # capacity = 5120
 `
-)
 
 var (
 	log                                       = logger.DefaultSLogger(inputName)
@@ -118,6 +119,8 @@ var (
 )
 
 type Input struct {
+	itrace.AfterGatherExtension
+
 	V2               interface{}                  `toml:"V2"`            // deprecated *skywalkingConfig
 	V3               interface{}                  `toml:"V3"`            // deprecated *skywalkingConfig
 	Pipelines        map[string]string            `toml:"pipelines"`     // deprecated
@@ -146,7 +149,10 @@ func (*Input) AvailableArchs() []string { return datakit.AllOS }
 func (*Input) SampleConfig() string { return sampleConfig }
 
 func (ipt *Input) SampleMeasurement() []inputs.Measurement {
-	return []inputs.Measurement{&MetricMeasurement{}, &itrace.TraceMeasurement{Name: "skywalking"}}
+	return []inputs.Measurement{
+		&MetricMeasurement{}, &itrace.TraceMeasurement{Name: "skywalking"},
+		&itrace.REDMetricMeasurement{Name: "SkyWalking"},
+	}
 }
 
 func (ipt *Input) RegHTTPHandler() {
@@ -254,6 +260,9 @@ func (ipt *Input) RegHTTPHandler() {
 		afterGather.AppendFilter(sampler.Sample)
 	}
 
+	// add tail sampler as the last filter, and RED metrics derived from all traces before filters
+	afterGatherRun = ipt.SetupAfterGather(inputName, afterGather, ipt.feeder, ipt.Tagger.HostTags())
+
 	for _, v := range ipt.Endpoints {
 		log.Debugf("### register skywalking http v3: %s", v)
 		switch v {
@@ -309,6 +318,7 @@ func (ipt *Input) Run() {
 }
 
 func (ipt *Input) exit() {
+	ipt.CloseAfterGather()
 	if skySvr != nil {
 		skySvr.Stop()
 	}
@@ -99,6 +99,9 @@ type Input struct {
 	// Max duration for each metric to stay cached without being updated.
 	MaxTTL time.Duration `toml:"max_ttl"`
 
+	// Tag the data received over unix socket with the container of the sender.
+	OriginDetection bool `toml:"origin_detection"`
+
 	// Protocol listeners
 	UDPlistener *net.UDPConn
 	TCPlistener *net.TCPListener
@@ -170,6 +173,7 @@ func (ipt *Input) setup() error {
 		istatsd.WithMaxTCPConnections(ipt.MaxTCPConnections),
 		istatsd.WithTCPKeepAlive(ipt.TCPKeepAlive),
 		istatsd.WithMaxTTL(ipt.MaxTTL),
+		istatsd.WithOriginDetection(ipt.OriginDetection),
 	}
 
 	col, err := istatsd.NewCollector(ipt.UDPlistener, ipt.TCPlistener, opts...)
@@ -198,9 +202,34 @@ func (ipt *Input) Collect() error {
 		ipt.l.Debug("GetPoints 0 pts")
 	}
 
+	events, objects := ipt.Col.GetServiceCheckPoints()
+	ipt.feedServiceChecks(point.KeyEvent, events)
+	ipt.feedServiceChecks(point.Object, objects)
+
 	return nil
 }
 
+func (ipt *Input) feedServiceChecks(cat point.Category, pts []*point.Point) {
+	if len(pts) == 0 {
+		return
+	}
+
+	for _, pt := range pts {
+		for k, v := range ipt.taggerTags {
+			pt.AddTag(k, v)
+		}
+	}
+
+	if err := ipt.Feeder.Feed(cat, pts, dkio.WithSource(ipt.Source)); err != nil {
+		ipt.Feeder.FeedLastError(err.Error(),
+			metrics.WithLastErrorInput(inputName),
+			metrics.WithLastErrorSource(ipt.Source),
+			metrics.WithLastErrorCategory(cat),
+		)
+		ipt.l.Errorf("feed %s: %s", cat, err)
+	}
+}
+
 func (ipt *Input) feedBatch(points []*point.Point) {
 	pts := []*point.Point{}
 	for i, v := range points {
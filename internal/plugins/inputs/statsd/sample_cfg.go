@@ -18,6 +18,10 @@ const sampleConfig = `
   ## Address to host unix listener on, linux only
   service_unix_address = "/var/run/datakit/statsd.sock"
 
+  ## Tag the data received over unix socket with the sender's container_id/pod_uid,
+  ## the sender is detected by the socket credentials, linux only
+  # origin_detection = false
+
   ## Address and port to host UDP listener on
   service_address = ":8125"
 
@@ -47,7 +51,7 @@ const sampleConfig = `
   ## http://docs.datadoghq.com/guides/dogstatsd/
   parse_data_dog_tags = true
 
-  ## Parses datadog extensions to the statsd format
+  ## Parses datadog extensions to the statsd format, such as events and service checks
   datadog_extensions = true
 
   ## Parses distributions metric as specified in the datadog statsd format
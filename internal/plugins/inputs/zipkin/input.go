@@ -33,8 +33,10 @@ var (
 )
 
 const (
-	inputName    = "zipkin"
-	sampleConfig = `
+	inputName = "zipkin"
+)
+
+var sampleConfig = `
 [[inputs.zipkin]]
   pathV1 = "/api/v1/spans"
   pathV2 = "/api/v2/spans"
@@ -66,7 +68,7 @@ const (
   ## sampling_rate used to set global sampling rate.
   # [inputs.zipkin.sampler]
     # sampling_rate = 1.0
-
+` + itrace.AfterGatherExtensionSample(inputName) + `
   # [inputs.zipkin.tags]
     # key1 = "value1"
     # key2 = "value2"
@@ -86,7 +88,6 @@ const (
     # path = "./zipkin_storage"
     # capacity = 5120
 `
-)
 
 var (
 	log            = logger.DefaultSLogger(inputName)
@@ -101,6 +102,8 @@ var (
 )
 
 type Input struct {
+	itrace.AfterGatherExtension
+
 	Pipelines        map[string]string            `toml:"pipelines"`     // deprecated
 	CustomerTags     []string                     `toml:"customer_tags"` // deprecated
 	PathV1           string                       `toml:"pathV1"`
@@ -126,7 +129,10 @@ func (*Input) AvailableArchs() []string { return datakit.AllOS }
 func (*Input) SampleConfig() string { return sampleConfig }
 
 func (*Input) SampleMeasurement() []inputs.Measurement {
-	return []inputs.Measurement{&itrace.TraceMeasurement{Name: inputName}}
+	return []inputs.Measurement{
+		&itrace.TraceMeasurement{Name: inputName},
+		&itrace.REDMetricMeasurement{Name: "Zipkin"},
+	}
 }
 
 func (ipt *Input) RegHTTPHandler() {
@@ -250,6 +256,9 @@ func (ipt *Input) RegHTTPHandler() {
 		afterGather.AppendFilter(sampler.Sample)
 	}
 
+	// add tail sampler as the last filter, and RED metrics derived from all traces before filters
+	afterGatherRun = ipt.SetupAfterGather(inputName, afterGather, ipt.feeder, ipt.Tagger.HostTags())
+
 	if ipt.PathV1 == "" {
 		ipt.PathV1 = apiv1Path
 	}
@@ -294,6 +303,7 @@ func (ipt *Input) Run() {
 }
 
 func (ipt *Input) exit() {
+	ipt.CloseAfterGather()
 	if wkpool != nil {
 		wkpool.Shutdown()
 		log.Debug("### workerpool closed")
@@ -12,6 +12,7 @@ import (
 	"github.com/GuanceCloud/cliutils/logger"
 	"github.com/GuanceCloud/cliutils/point"
 	dknet "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/net"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 )
 
 type option struct {
@@ -45,6 +46,10 @@ type option struct {
 	ignoreTagKV map[string][]*regexp.Regexp // drop scraped prom data if tag key's value matched
 	httpHeaders map[string]string
 
+	metricRelabelConfigs relabel.Configs // relabel scraped samples
+	preferProtobuf       bool            // negotiate the protobuf format, required by native histograms
+	exemplars            bool            // collect exemplars as <field>_exemplar points
+
 	tags           map[string]string
 	disableInfoTag bool
 
@@ -201,6 +206,12 @@ func WithIgnoreTagKV(tagKVs map[string][]string) PromOption {
 	}
 }
 
+// WithMetricRelabelConfigs set the relabel rules applied to each scraped sample, the rules
+// should already be Setup.
+func WithMetricRelabelConfigs(cs relabel.Configs) PromOption {
+	return func(opt *option) { opt.metricRelabelConfigs = cs }
+}
+
 func WithHTTPHeaders(m map[string]string) PromOption {
 	return func(opt *option) { opt.httpHeaders = m }
 }
@@ -215,4 +226,7 @@ func WithMaxBatchCallback(i int, f func([]*point.Point) error) PromOption {
 		}
 	}
 }
+func WithPreferProtobuf(b bool) PromOption { return func(opt *option) { opt.preferProtobuf = b } }
+func WithExemplars(b bool) PromOption      { return func(opt *option) { opt.exemplars = b } }
+
 func WithLogger(l *logger.Logger) PromOption { return func(opt *option) { opt.l = l } }
@@ -28,6 +28,7 @@ import (
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/httpcli"
 	dknet "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/net"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/promscrape"
 )
 
 type Rule struct {
@@ -134,6 +135,9 @@ func (p *Prom) GetReq(url string) (*http.Request, error) {
 	} else {
 		req, err = http.NewRequest("GET", url, nil)
 	}
+	if err == nil && p.opt.preferProtobuf {
+		req.Header.Set("Accept", promscrape.AcceptHeaderProtobuf)
+	}
 	for k, v := range p.opt.httpHeaders {
 		req.Header.Set(k, v)
 	}
@@ -181,7 +185,12 @@ func (p *Prom) CollectFromHTTPV2(u string, opts ...PromOption) ([]*point.Point,
 
 	// A agent used to count bytes.
 	wCounter := &writeCounter{}
-	pts, err := p.ProcessMetrics(io.TeeReader(resp.Body, wCounter), u)
+	var pts []*point.Point
+	if expfmt.ResponseFormat(resp.Header) == expfmt.FmtProtoDelim {
+		pts, err = p.protobuf2Metrics(io.TeeReader(resp.Body, wCounter), u)
+	} else {
+		pts, err = p.ProcessMetrics(io.TeeReader(resp.Body, wCounter), u)
+	}
 	if err != nil {
 		return nil, err
 	}
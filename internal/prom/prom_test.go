@@ -10,6 +10,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"sort"
@@ -23,8 +24,11 @@ import (
 	"github.com/prometheus/common/expfmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	dknet "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/net"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 )
 
 const promURL = "http://127.0.0.1:9100/metrics"
@@ -3965,3 +3969,246 @@ up 1
 		require.NoError(t, err)
 	})
 }
+
+func TestMetricRelabel(t *testing.T) {
+	mockBody := `
+# TYPE promhttp_metric_handler_requests_total counter
+promhttp_metric_handler_requests_total{code="200"} 15143
+promhttp_metric_handler_requests_total{code="500"} 0
+# TYPE go_gc_duration_seconds summary
+go_gc_duration_seconds{quantile="0"} 0
+go_gc_duration_seconds_sum 0.1
+go_gc_duration_seconds_count 2
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="0.003",status_code="404",method="GET",tmp_id="1"} 1
+http_request_duration_seconds_bucket{le="+Inf",status_code="404",method="GET",tmp_id="1"} 1
+http_request_duration_seconds_sum{status_code="404",method="GET",tmp_id="1"} 0.002451013
+http_request_duration_seconds_count{status_code="404",method="GET",tmp_id="1"} 1
+`
+
+	configs := relabel.Configs{
+		// drop go_ metrics
+		{SourceLabels: []string{"__name__"}, Regex: "go_.*", Action: relabel.Drop},
+		// drop the 500 series
+		{SourceLabels: []string{"__name__", "code"}, Regex: "promhttp_.*;5..", Action: relabel.Drop},
+		// rename the metric
+		{SourceLabels: []string{"__name__"}, Regex: "http_(.*)", TargetLabel: "__name__", Replacement: strPtr("web_${1}")},
+		{SourceLabels: []string{"status_code"}, TargetLabel: "status"},
+		{Regex: "tmp_.*|status_code", Action: relabel.LabelDrop},
+	}
+	require.NoError(t, configs.Setup())
+
+	p, err := NewProm(WithMetricRelabelConfigs(configs))
+	require.NoError(t, err)
+
+	p.SetClient(&http.Client{Transport: newTransportMock(mockBody)})
+	pts, err := p.CollectFromHTTPV2(promURL)
+	require.NoError(t, err)
+
+	var names []string
+	for _, pt := range pts {
+		names = append(names, pt.Name())
+
+		switch pt.Name() {
+		case "promhttp":
+			assert.Equal(t, "200", pt.Get("code"))
+		case "web":
+			assert.Equal(t, "404", pt.Get("status"))
+			assert.Nil(t, pt.Get("status_code"))
+			assert.Nil(t, pt.Get("tmp_id"))
+			assert.Equal(t, "GET", pt.Get("method"))
+		}
+
+		t.Logf("%s", pt.Pretty())
+	}
+
+	sort.Strings(names)
+	assert.Equal(t, []string{"promhttp", "web", "web", "web"}, names)
+}
+
+func TestMetricRelabelSeries(t *testing.T) {
+	mockBody := `
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.1
+rpc_duration_seconds{quantile="0.9"} 0.2
+rpc_duration_seconds_sum 0.3
+rpc_duration_seconds_count 2
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="0.003",method="GET"} 1
+http_request_duration_seconds_bucket{le="+Inf",method="GET"} 1
+http_request_duration_seconds_sum{method="GET"} 0.002451013
+http_request_duration_seconds_count{method="GET"} 1
+`
+
+	collect := func(t *testing.T, configs relabel.Configs) []*point.Point {
+		t.Helper()
+		require.NoError(t, configs.Setup())
+
+		p, err := NewProm(WithMetricRelabelConfigs(configs))
+		require.NoError(t, err)
+
+		p.SetClient(&http.Client{Transport: newTransportMock(mockBody)})
+		pts, err := p.CollectFromHTTPV2(promURL)
+		require.NoError(t, err)
+
+		for _, pt := range pts {
+			t.Logf("%s", pt.Pretty())
+		}
+		return pts
+	}
+
+	t.Run("drop-bucket", func(t *testing.T) {
+		pts := collect(t, relabel.Configs{
+			{SourceLabels: []string{"__name__"}, Regex: ".*_bucket", Action: relabel.Drop},
+		})
+
+		var fields []string
+		for _, pt := range pts {
+			if pt.Name() != "http" {
+				continue
+			}
+			for _, kv := range pt.Fields() {
+				fields = append(fields, kv.Key)
+			}
+			assert.Nil(t, pt.Get("le"))
+		}
+
+		sort.Strings(fields)
+		assert.Equal(t, []string{"request_duration_seconds_count", "request_duration_seconds_sum"}, fields)
+	})
+
+	t.Run("drop-by-le-and-quantile", func(t *testing.T) {
+		pts := collect(t, relabel.Configs{
+			{SourceLabels: []string{"le"}, Regex: `\+Inf`, Action: relabel.Drop},
+			{SourceLabels: []string{"quantile"}, Regex: "0.9", Action: relabel.Drop},
+		})
+
+		var les, quantiles []string
+		for _, pt := range pts {
+			if v, ok := pt.Get("le").(string); ok {
+				les = append(les, v)
+			}
+			if v, ok := pt.Get("quantile").(string); ok {
+				quantiles = append(quantiles, v)
+			}
+		}
+
+		assert.Equal(t, []string{"0.003"}, les)
+		assert.Equal(t, []string{"0.5"}, quantiles)
+		assert.Len(t, pts, 4) // count/sum point of both metrics, one bucket and one quantile
+	})
+
+	t.Run("rename-count", func(t *testing.T) {
+		pts := collect(t, relabel.Configs{
+			{
+				SourceLabels: []string{"__name__"}, Regex: "rpc_duration_seconds_count",
+				TargetLabel: "__name__", Replacement: strPtr("rpc_calls_total"),
+			},
+		})
+
+		var found bool
+		for _, pt := range pts {
+			if pt.Name() != "rpc" {
+				continue
+			}
+
+			if pt.Get("calls_total") != nil {
+				found = true
+				assert.Nil(t, pt.Get("duration_seconds_count"))
+				assert.Equal(t, 0.3, pt.Get("duration_seconds_sum")) // same measurement and tags, still within one point
+			}
+		}
+		assert.True(t, found)
+	})
+}
+
+func TestProtobufNativeHistogram(t *testing.T) {
+	exemplarTime := time.Unix(1700000000, 0)
+
+	families := []*dto.MetricFamily{
+		{
+			Name: proto.String("http_requests_total"),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Label: []*dto.LabelPair{{Name: proto.String("method"), Value: proto.String("GET")}},
+					Counter: &dto.Counter{
+						Value: proto.Float64(10),
+						Exemplar: &dto.Exemplar{
+							Label:     []*dto.LabelPair{{Name: proto.String("trace_id"), Value: proto.String("abc")}},
+							Value:     proto.Float64(1),
+							Timestamp: timestamppb.New(exemplarTime),
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: proto.String("http_duration_seconds"),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Histogram: &dto.Histogram{
+						SampleCount:   proto.Uint64(6),
+						SampleSum:     proto.Float64(7.5),
+						Schema:        proto.Int32(0),
+						ZeroThreshold: proto.Float64(0.001),
+						ZeroCount:     proto.Uint64(1),
+						PositiveSpan:  []*dto.BucketSpan{{Offset: proto.Int32(0), Length: proto.Uint32(2)}},
+						PositiveDelta: []int64{2, 1},
+					},
+				},
+			},
+		},
+	}
+
+	var accept string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+
+		w.Header().Set("Content-Type", string(expfmt.FmtProtoDelim))
+		enc := expfmt.NewEncoder(w, expfmt.FmtProtoDelim)
+		for _, mf := range families {
+			require.NoError(t, enc.Encode(mf))
+		}
+	}))
+	defer ts.Close()
+
+	p, err := NewProm(WithPreferProtobuf(true), WithExemplars(true))
+	require.NoError(t, err)
+
+	pts, err := p.CollectFromHTTPV2(ts.URL)
+	require.NoError(t, err)
+	assert.Contains(t, accept, "application/vnd.google.protobuf")
+
+	buckets := map[string]float64{}
+	var exemplars []*point.Point
+	for _, pt := range pts {
+		if v := pt.Get("duration_seconds_bucket"); v != nil {
+			buckets[pt.GetTag("le")] = v.(float64)
+		}
+		if pt.Get("requests_total_exemplar") != nil {
+			exemplars = append(exemplars, pt)
+		}
+
+		t.Logf("%s", pt.Pretty())
+	}
+
+	assert.Equal(t, map[string]float64{
+		"0.001": 1,
+		"1":     3,
+		"2":     6,
+		"+Inf":  6,
+	}, buckets)
+
+	require.Len(t, exemplars, 1)
+	assert.Equal(t, "abc", exemplars[0].Get("trace_id"))
+	assert.Empty(t, exemplars[0].GetTag("trace_id"))
+	assert.Equal(t, "GET", exemplars[0].GetTag("method"))
+	assert.Equal(t, 1.0, exemplars[0].Get("requests_total_exemplar"))
+	assert.Equal(t, exemplarTime.UnixNano(), exemplars[0].Time().UnixNano())
+}
+
+func strPtr(s string) *string {
+	return &s
+}
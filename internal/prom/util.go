@@ -6,15 +6,20 @@
 package prom
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/GuanceCloud/cliutils/point"
 	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/ntp"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/promscrape"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 )
 
 const statusInfo = "INFO"
@@ -205,7 +210,7 @@ func (p *Prom) getTags(labels []*dto.LabelPair, measurementName string, u string
 	return kvs
 }
 
-func (p *Prom) getTagsWithLE(labels []*dto.LabelPair, measurementName string, b *dto.Bucket) point.KVs {
+func (p *Prom) getTagsWithLE(labels []*dto.LabelPair, measurementName string, le string) point.KVs {
 	var kvs point.KVs
 
 	// Add custom tags.
@@ -218,7 +223,9 @@ func (p *Prom) getTagsWithLE(labels []*dto.LabelPair, measurementName string, b
 		kvs = kvs.AddTag(lab.GetName(), lab.GetValue())
 	}
 
-	kvs = kvs.AddTag("le", fmt.Sprint(b.GetUpperBound()))
+	if le != "" {
+		kvs = kvs.AddTag("le", le)
+	}
 	kvs = p.removeIgnoredTags(kvs)
 	kvs = p.renameTags(kvs)
 
@@ -314,6 +321,37 @@ func (p *Prom) text2MetricsNoBatch(in io.Reader, u string) (pts []*point.Point,
 	return p.MetricFamilies2points(metricFamilies, u)
 }
 
+// protobuf2Metrics converts the delimited protobuf exposition format to points.
+func (p *Prom) protobuf2Metrics(in io.Reader, u string) (pts []*point.Point, lastErr error) {
+	p.ptCount = 0
+	for k := range p.InfoTags {
+		delete(p.InfoTags, k)
+	}
+
+	metricFamilies := map[string]*dto.MetricFamily{}
+	decoder := expfmt.NewDecoder(in, expfmt.FmtProtoDelim)
+	for {
+		mf := &dto.MetricFamily{}
+		if err := decoder.Decode(mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		metricFamilies[mf.GetName()] = mf
+	}
+
+	pts, err := p.MetricFamilies2points(metricFamilies, u)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opt.batchCallback != nil {
+		return nil, p.opt.batchCallback(pts)
+	}
+	return pts, nil
+}
+
 func (p *Prom) MetricFamilies2points(metricFamilies map[string]*dto.MetricFamily, u string) (pts []*point.Point, lastErr error) {
 	filteredMetricFamilies := p.filterMetricFamilies(metricFamilies)
 	p.swapTypeInfoToFront(filteredMetricFamilies)
@@ -326,7 +364,6 @@ func (p *Prom) MetricFamilies2points(metricFamilies map[string]*dto.MetricFamily
 	opts := append(point.DefaultMetricOptions(), point.WithTimestamp(ptts))
 	for _, nf := range filteredMetricFamilies {
 		name, value := nf.metricName, nf.metricFamily
-		measurementName, fieldName := p.getNames(name)
 
 		switch value.GetType() {
 		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED, dto.MetricType_COUNTER:
@@ -337,9 +374,18 @@ func (p *Prom) MetricFamilies2points(metricFamilies map[string]*dto.MetricFamily
 					continue
 				}
 
-				kvs := p.getTags(m.GetLabel(), measurementName, u)
+				metricName, labels, ok := p.relabelMetric(name, m)
+				if !ok {
+					continue
+				}
+				measurementName, fieldName := p.getNames(metricName)
+
+				kvs := p.getTags(labels, measurementName, u)
 
 				kvs = p.filterIgnoreTagKV(kvs)
+				if e := m.GetCounter().GetExemplar(); e != nil && p.opt.exemplars {
+					pts = append(pts, p.exemplarPoint(measurementName, fieldName, kvs, e, opts))
+				}
 				kvs = kvs.Add(fieldName, v)
 
 				if p.opt.asLogging != nil && p.opt.asLogging.Enable {
@@ -358,14 +404,8 @@ func (p *Prom) MetricFamilies2points(metricFamilies map[string]*dto.MetricFamily
 
 		case dto.MetricType_SUMMARY:
 			for _, m := range value.GetMetric() {
-				kvs := p.filterIgnoreTagKV(p.getTags(m.GetLabel(), measurementName, u))
-				kvs = kvs.Add(fieldName+"_count", float64(m.GetSummary().GetSampleCount()))
-				kvs = kvs.Add(fieldName+"_sum", m.GetSummary().GetSampleSum())
-				if p.opt.asLogging != nil && p.opt.asLogging.Enable {
-					kvs = kvs.Add("status", statusInfo)
-				}
-
-				pts = append(pts, point.NewPoint(measurementName, kvs, opts...))
+				pts = append(pts, p.countSumPoints(name, m.GetLabel(), u, opts,
+					float64(m.GetSummary().GetSampleCount()), m.GetSummary().GetSampleSum())...)
 
 				for _, q := range m.GetSummary().Quantile {
 					v := q.GetValue()
@@ -373,8 +413,17 @@ func (p *Prom) MetricFamilies2points(metricFamilies map[string]*dto.MetricFamily
 						continue
 					}
 
-					kvs := p.filterIgnoreTagKV(p.getTags(m.GetLabel(), measurementName, u))
-					kvs = kvs.AddTag("quantile", fmt.Sprint(q.GetQuantile()))
+					// relabel each quantile series with label quantile, same as Prometheus
+					seriesName, labels, quantile, ok := p.relabelSeries(name, m.GetLabel(), "quantile", fmt.Sprint(q.GetQuantile()))
+					if !ok {
+						continue
+					}
+					measurementName, fieldName := p.seriesNames(name, "", seriesName)
+
+					kvs := p.filterIgnoreTagKV(p.getTags(labels, measurementName, u))
+					if quantile != "" {
+						kvs = kvs.AddTag("quantile", quantile)
+					}
 					if p.opt.asLogging != nil && p.opt.asLogging.Enable {
 						kvs = kvs.Add("status", statusInfo)
 					}
@@ -392,19 +441,23 @@ func (p *Prom) MetricFamilies2points(metricFamilies map[string]*dto.MetricFamily
 
 		case dto.MetricType_HISTOGRAM:
 			for _, m := range value.GetMetric() {
-				kvs := p.filterIgnoreTagKV(p.getTags(m.GetLabel(), measurementName, u))
-				kvs = kvs.Add(fieldName+"_count", float64(m.GetHistogram().GetSampleCount()))
-				kvs = kvs.Add(fieldName+"_sum", m.GetHistogram().GetSampleSum())
-
-				if p.opt.asLogging != nil && p.opt.asLogging.Enable {
-					kvs = kvs.Add("status", statusInfo)
-				}
-
-				pts = append(pts, point.NewPoint(measurementName, kvs, opts...))
+				pts = append(pts, p.countSumPoints(name, m.GetLabel(), u, opts,
+					promscrape.HistogramCount(m.GetHistogram()), m.GetHistogram().GetSampleSum())...)
+
+				// native histograms are converted to classic buckets
+				for _, b := range promscrape.HistogramBuckets(m.GetHistogram()) {
+					// relabel each bucket series with label le, same as Prometheus
+					seriesName, labels, le, ok := p.relabelSeries(name+"_bucket", m.GetLabel(), "le", fmt.Sprint(b.GetUpperBound()))
+					if !ok {
+						continue
+					}
+					measurementName, fieldName := p.seriesNames(name, "_bucket", seriesName)
 
-				for _, b := range m.GetHistogram().GetBucket() {
-					kvs := p.filterIgnoreTagKV(p.getTagsWithLE(m.GetLabel(), measurementName, b))
-					kvs = kvs.Add(fieldName+"_bucket", float64(b.GetCumulativeCount()))
+					kvs := p.filterIgnoreTagKV(p.getTagsWithLE(labels, measurementName, le))
+					if e := b.GetExemplar(); e != nil && p.opt.exemplars {
+						pts = append(pts, p.exemplarPoint(measurementName, fieldName, kvs, e, opts))
+					}
+					kvs = kvs.Add(fieldName, promscrape.BucketCount(b))
 					if p.opt.asLogging != nil && p.opt.asLogging.Enable {
 						kvs = kvs.Add("status", statusInfo)
 					}
@@ -426,7 +479,12 @@ func (p *Prom) MetricFamilies2points(metricFamilies map[string]*dto.MetricFamily
 			// Info may be used to encode ENUMs whose values do not change over time, such as the type of a network interface.
 			// https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md#info
 			for _, m := range value.GetMetric() {
-				for _, l := range m.GetLabel() {
+				_, labels, ok := p.relabelMetric(name, m)
+				if !ok {
+					continue
+				}
+
+				for _, l := range labels {
 					p.InfoTags[l.GetName()] = l.GetValue()
 				}
 			}
@@ -440,6 +498,153 @@ func (p *Prom) MetricFamilies2points(metricFamilies map[string]*dto.MetricFamily
 	return pts, nil
 }
 
+// exemplarPoint build the point of the exemplar with field <fieldName>_exemplar,
+// the exemplar labels(such as trace_id) are added as string fields, so they do
+// not create new time series.
+func (p *Prom) exemplarPoint(measurementName, fieldName string, tags point.KVs, e *dto.Exemplar, opts []point.Option) *point.Point {
+	kvs := make(point.KVs, 0, len(tags)+len(e.GetLabel())+1)
+	kvs = append(kvs, tags...)
+	for _, l := range e.GetLabel() {
+		kvs = kvs.Add(l.GetName(), l.GetValue())
+	}
+	kvs = kvs.Add(fieldName+"_exemplar", e.GetValue())
+
+	// string fields are dropped by default on metric
+	pt := point.NewPoint(measurementName, kvs, append(append([]point.Option{}, opts...), point.WithStrField(true))...)
+	if ts := e.GetTimestamp(); ts != nil {
+		pt.SetTime(ts.AsTime())
+	}
+	return pt
+}
+
+// relabelMetric apply the metric relabel rules on the metric, the metric name is
+// available as label __name__. It returns the relabeled metric name and labels,
+// or false if the metric is dropped.
+func (p *Prom) relabelMetric(name string, m *dto.Metric) (string, []*dto.LabelPair, bool) {
+	newName, labels, _, ok := p.relabelSeries(name, m.GetLabel(), "", "")
+	return newName, labels, ok
+}
+
+// relabelSeries apply the metric relabel rules on a single series, such as
+// <name>_bucket of histogram. The extra label(such as le/quantile) is available
+// to the rules, and returned separately with its relabeled value. It returns
+// false if the series is dropped.
+func (p *Prom) relabelSeries(name string,
+	labels []*dto.LabelPair,
+	extraKey, extraVal string,
+) (string, []*dto.LabelPair, string, bool) {
+	if len(p.opt.metricRelabelConfigs) == 0 {
+		return name, labels, extraVal, true
+	}
+
+	lbs := make(map[string]string, len(labels)+2)
+	for _, l := range labels {
+		lbs[l.GetName()] = l.GetValue()
+	}
+	if extraKey != "" {
+		lbs[extraKey] = extraVal
+	}
+	lbs[relabel.MetricNameLabel] = name
+
+	lbs, keep := p.opt.metricRelabelConfigs.Process(lbs)
+	if !keep {
+		return "", nil, "", false
+	}
+
+	newName := lbs[relabel.MetricNameLabel]
+	if newName == "" {
+		return "", nil, "", false
+	}
+	delete(lbs, relabel.MetricNameLabel)
+
+	if extraKey != "" {
+		extraVal = lbs[extraKey]
+		delete(lbs, extraKey)
+	}
+
+	res := make([]*dto.LabelPair, 0, len(lbs))
+	for k, v := range lbs {
+		k, v := k, v
+		res = append(res, &dto.LabelPair{Name: &k, Value: &v})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].GetName() < res[j].GetName() })
+
+	return newName, res, extraVal, true
+}
+
+// seriesNames returns measurement and field name of the series <name><suffix>.
+// If the series not renamed by relabeling, the names are derived from the
+// metric name, so the field is always <field><suffix>.
+func (p *Prom) seriesNames(name, suffix, seriesName string) (measurementName, fieldName string) {
+	if seriesName == name+suffix {
+		measurementName, fieldName = p.getNames(name)
+		return measurementName, fieldName + suffix
+	}
+
+	return p.getNames(seriesName)
+}
+
+// countSumPoints build points of the <name>_count and <name>_sum series of
+// summary/histogram. Both series are relabeled separately, and they are in
+// the same point if they got the same measurement and labels.
+func (p *Prom) countSumPoints(name string,
+	labels []*dto.LabelPair,
+	u string,
+	opts []point.Option,
+	count, sum float64,
+) (pts []*point.Point) {
+	type series struct {
+		measurementName,
+		fieldName string
+		labels []*dto.LabelPair
+		value  float64
+	}
+
+	var all []*series
+	for _, x := range []struct {
+		suffix string
+		value  float64
+	}{
+		{"_count", count},
+		{"_sum", sum},
+	} {
+		seriesName, lbs, _, ok := p.relabelSeries(name+x.suffix, labels, "", "")
+		if !ok {
+			continue
+		}
+
+		measurementName, fieldName := p.seriesNames(name, x.suffix, seriesName)
+		all = append(all, &series{
+			measurementName: measurementName,
+			fieldName:       fieldName,
+			labels:          lbs,
+			value:           x.value,
+		})
+	}
+
+	for i := 0; i < len(all); i++ {
+		x := all[i]
+		kvs := p.filterIgnoreTagKV(p.getTags(x.labels, x.measurementName, u))
+		kvs = kvs.Add(x.fieldName, x.value)
+
+		// merge _count/_sum into the same point as before relabeling
+		if i+1 < len(all) &&
+			all[i+1].measurementName == x.measurementName &&
+			sameLabels(all[i+1].labels, x.labels) {
+			kvs = kvs.Add(all[i+1].fieldName, all[i+1].value)
+			i++
+		}
+
+		if p.opt.asLogging != nil && p.opt.asLogging.Enable {
+			kvs = kvs.Add("status", statusInfo)
+		}
+
+		pts = append(pts, point.NewPoint(x.measurementName, kvs, opts...))
+	}
+
+	return pts
+}
+
 func (p *Prom) getMode() string {
 	if p.opt.streamSize > 0 {
 		return "stream"
@@ -468,3 +673,17 @@ func getTimestampS(m *dto.Metric, startTime time.Time) time.Time {
 	}
 	return startTime
 }
+
+func sameLabels(a, b []*dto.LabelPair) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].GetName() != b[i].GetName() || a[i].GetValue() != b[i].GetValue() {
+			return false
+		}
+	}
+
+	return true
+}
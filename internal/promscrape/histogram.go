@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package promscrape
+
+import (
+	"math"
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// IsNativeHistogram reports whether h contains native(sparse) histogram buckets.
+func IsNativeHistogram(h *dto.Histogram) bool {
+	return h.GetZeroThreshold() > 0 ||
+		h.GetZeroCount() > 0 ||
+		h.GetZeroCountFloat() > 0 ||
+		len(h.GetPositiveSpan()) > 0 ||
+		len(h.GetNegativeSpan()) > 0
+}
+
+// HistogramCount returns the sample count of h, the float count takes precedence.
+func HistogramCount(h *dto.Histogram) float64 {
+	if c := h.GetSampleCountFloat(); c > 0 {
+		return c
+	}
+	return float64(h.GetSampleCount())
+}
+
+// BucketCount returns the cumulative count of b, the float count takes precedence.
+func BucketCount(b *dto.Bucket) float64 {
+	if c := b.GetCumulativeCountFloat(); c > 0 {
+		return c
+	}
+	return float64(b.GetCumulativeCount())
+}
+
+// HistogramBuckets returns the cumulative buckets of h. The classic buckets are
+// returned if exist, otherwise the native buckets are converted to classic buckets
+// with their upper bounds, so they can be stored as the `le' tagged bucket points
+// and queried in the same way.
+func HistogramBuckets(h *dto.Histogram) []*dto.Bucket {
+	if len(h.GetBucket()) > 0 || !IsNativeHistogram(h) {
+		return h.GetBucket()
+	}
+
+	negative := nativeBuckets(h.GetNegativeSpan(), h.GetNegativeDelta(), h.GetNegativeCount())
+	positive := nativeBuckets(h.GetPositiveSpan(), h.GetPositiveDelta(), h.GetPositiveCount())
+
+	// from the most negative to the most positive
+	sort.Slice(negative, func(i, j int) bool { return negative[i].index > negative[j].index })
+	sort.Slice(positive, func(i, j int) bool { return positive[i].index < positive[j].index })
+
+	var (
+		res        = make([]*dto.Bucket, 0, len(negative)+len(positive)+2)
+		cumulative float64
+		schema     = h.GetSchema()
+	)
+
+	appendBucket := func(upperBound, count float64) {
+		cumulative += count
+		ub, c := upperBound, cumulative
+		res = append(res, &dto.Bucket{UpperBound: &ub, CumulativeCountFloat: &c})
+	}
+
+	// The negative bucket with index i is (-base^i, -base^(i-1)].
+	for _, b := range negative {
+		appendBucket(-nativeBucketBound(schema, b.index-1), b.count)
+	}
+
+	zeroCount := h.GetZeroCountFloat()
+	if zeroCount <= 0 {
+		zeroCount = float64(h.GetZeroCount())
+	}
+	if zeroCount > 0 || h.GetZeroThreshold() > 0 {
+		appendBucket(h.GetZeroThreshold(), zeroCount)
+	}
+
+	// The positive bucket with index i is (base^(i-1), base^i].
+	for _, b := range positive {
+		appendBucket(nativeBucketBound(schema, b.index), b.count)
+	}
+
+	total := HistogramCount(h)
+	if total < cumulative {
+		total = cumulative
+	}
+	inf := math.Inf(1)
+	res = append(res, &dto.Bucket{UpperBound: &inf, CumulativeCountFloat: &total})
+
+	return res
+}
+
+type nativeBucket struct {
+	index int32
+	count float64
+}
+
+// nativeBuckets expands the spans to buckets with absolute counts. The counts
+// are delta encoded for integer histograms and absolute for float histograms.
+func nativeBuckets(spans []*dto.BucketSpan, deltas []int64, counts []float64) []nativeBucket {
+	var (
+		res   []nativeBucket
+		index int32
+		pos   int
+		count int64
+	)
+
+	for _, span := range spans {
+		index += span.GetOffset()
+		for j := uint32(0); j < span.GetLength(); j++ {
+			var c float64
+			switch {
+			case pos < len(deltas):
+				count += deltas[pos]
+				c = float64(count)
+			case pos < len(counts):
+				c = counts[pos]
+			default:
+				return res
+			}
+
+			res = append(res, nativeBucket{index: index, count: c})
+			index++
+			pos++
+		}
+	}
+
+	return res
+}
+
+// nativeBucketBound returns base^index, base is 2^(2^-schema).
+func nativeBucketBound(schema, index int32) float64 {
+	return math.Exp2(float64(index) * math.Exp2(-float64(schema)))
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package promscrape
+
+import (
+	"math"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func span(offset int32, length uint32) *dto.BucketSpan {
+	return &dto.BucketSpan{Offset: proto.Int32(offset), Length: proto.Uint32(length)}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	t.Run("native-integer", func(t *testing.T) {
+		h := &dto.Histogram{
+			SampleCount:   proto.Uint64(7),
+			SampleSum:     proto.Float64(12.5),
+			Schema:        proto.Int32(0),
+			ZeroThreshold: proto.Float64(0.001),
+			ZeroCount:     proto.Uint64(1),
+			NegativeSpan:  []*dto.BucketSpan{span(0, 1)},
+			NegativeDelta: []int64{2},
+			PositiveSpan:  []*dto.BucketSpan{span(0, 2), span(1, 1)},
+			PositiveDelta: []int64{1, 1, -1},
+		}
+		assert.True(t, IsNativeHistogram(h))
+
+		type bucket struct{ le, count float64 }
+		var got []bucket
+		for _, b := range HistogramBuckets(h) {
+			got = append(got, bucket{b.GetUpperBound(), BucketCount(b)})
+		}
+
+		assert.Equal(t, []bucket{
+			{-0.5, 2},
+			{0.001, 3},
+			{1, 4},
+			{2, 6},
+			{8, 7},
+			{math.Inf(1), 7},
+		}, got)
+	})
+
+	t.Run("native-float-schema-3", func(t *testing.T) {
+		h := &dto.Histogram{
+			SampleCountFloat: proto.Float64(3.5),
+			Schema:           proto.Int32(3),
+			PositiveSpan:     []*dto.BucketSpan{span(8, 1)},
+			PositiveCount:    []float64{3.5},
+		}
+		assert.Equal(t, 3.5, HistogramCount(h))
+
+		buckets := HistogramBuckets(h)
+		assert.Len(t, buckets, 2)
+		assert.InDelta(t, 2.0, buckets[0].GetUpperBound(), 1e-9)
+		assert.Equal(t, 3.5, BucketCount(buckets[0]))
+		assert.True(t, math.IsInf(buckets[1].GetUpperBound(), 1))
+	})
+
+	t.Run("classic", func(t *testing.T) {
+		h := &dto.Histogram{
+			SampleCount: proto.Uint64(2),
+			Bucket: []*dto.Bucket{
+				{UpperBound: proto.Float64(0.1), CumulativeCount: proto.Uint64(1)},
+				{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(2)},
+			},
+		}
+		assert.False(t, IsNativeHistogram(h))
+		assert.Equal(t, h.Bucket, HistogramBuckets(h))
+		assert.Equal(t, float64(2), BucketCount(h.Bucket[1]))
+	})
+}
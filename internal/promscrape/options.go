@@ -11,6 +11,7 @@ import (
 
 	"github.com/GuanceCloud/cliutils/point"
 	dknet "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/net"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 )
 
 type option struct {
@@ -23,6 +24,11 @@ type option struct {
 
 	extraTags map[string]string
 	callback  func([]*point.Point) error
+
+	metricRelabelConfigs relabel.Configs
+
+	preferProtobuf bool
+	exemplars      bool
 }
 
 type optionClientConn struct {
@@ -129,3 +135,21 @@ func WithCallback(fn func([]*point.Point) error) Option {
 		}
 	}
 }
+
+// WithMetricRelabelConfigs set the relabel rules applied to each scraped sample(with the
+// extra tags), the rules should already be Setup.
+func WithMetricRelabelConfigs(cs relabel.Configs) Option {
+	return func(opt *option) { opt.metricRelabelConfigs = cs }
+}
+
+// PreferProtobuf negotiate the delimited protobuf format with the target, it's
+// required to scrape native histograms.
+func PreferProtobuf(b bool) Option {
+	return func(opt *option) { opt.preferProtobuf = b }
+}
+
+// WithExemplars enable collecting exemplars, every exemplar is a point with field
+// <metric>_exemplar and the exemplar labels(such as trace_id) as fields.
+func WithExemplars(b bool) Option {
+	return func(opt *option) { opt.exemplars = b }
+}
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package promscrape
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	acceptHeaderText = "text/plain;version=0.0.4;q=1,*/*;q=0.1"
+
+	// AcceptHeaderProtobuf prefer the delimited protobuf format, which is required
+	// for native histograms, and fallback to the text format.
+	AcceptHeaderProtobuf = "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7," +
+		"text/plain;version=0.0.4;q=0.3,*/*;q=0.1"
+)
+
+// ParseProtobufStream parse the delimited protobuf exposition format from r,
+// callback is called with the rows of each metric family.
+func ParseProtobufStream(r io.Reader, callback func(rows []Row) error) error {
+	decoder := expfmt.NewDecoder(r, expfmt.FmtProtoDelim)
+
+	for {
+		mf := &dto.MetricFamily{}
+		if err := decoder.Decode(mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("cannot decode Prometheus protobuf data: %w", err)
+		}
+
+		if rows := MetricFamilyToRows(nil, mf); len(rows) > 0 {
+			if err := callback(rows); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// MetricFamilyToRows converts mf to rows in the same way as the text format,
+// histograms and summaries are expanded to _count/_sum/_bucket/quantile rows.
+// Native histograms are converted to classic buckets, see HistogramBuckets.
+func MetricFamilyToRows(dst []Row, mf *dto.MetricFamily) []Row {
+	name := mf.GetName()
+
+	for _, m := range mf.GetMetric() {
+		tags := make([]Tag, 0, len(m.GetLabel()))
+		for _, l := range m.GetLabel() {
+			tags = append(tags, Tag{Key: l.GetName(), Value: l.GetValue()})
+		}
+		ts := m.GetTimestampMs()
+
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			dst = append(dst, Row{
+				Metric:    name,
+				Tags:      tags,
+				Value:     m.GetCounter().GetValue(),
+				Timestamp: ts,
+				Exemplar:  toExemplar(m.GetCounter().GetExemplar()),
+			})
+
+		case dto.MetricType_GAUGE:
+			dst = append(dst, Row{Metric: name, Tags: tags, Value: m.GetGauge().GetValue(), Timestamp: ts})
+
+		case dto.MetricType_UNTYPED:
+			dst = append(dst, Row{Metric: name, Tags: tags, Value: m.GetUntyped().GetValue(), Timestamp: ts})
+
+		case dto.MetricType_SUMMARY:
+			s := m.GetSummary()
+			dst = append(dst,
+				Row{Metric: name + "_count", Tags: tags, Value: float64(s.GetSampleCount()), Timestamp: ts},
+				Row{Metric: name + "_sum", Tags: tags, Value: s.GetSampleSum(), Timestamp: ts},
+			)
+			for _, q := range s.GetQuantile() {
+				dst = append(dst, Row{
+					Metric:    name,
+					Tags:      appendTag(tags, "quantile", strconv.FormatFloat(q.GetQuantile(), 'g', -1, 64)),
+					Value:     q.GetValue(),
+					Timestamp: ts,
+				})
+			}
+
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			h := m.GetHistogram()
+			dst = append(dst,
+				Row{Metric: name + "_count", Tags: tags, Value: HistogramCount(h), Timestamp: ts},
+				Row{Metric: name + "_sum", Tags: tags, Value: h.GetSampleSum(), Timestamp: ts},
+			)
+			for _, b := range HistogramBuckets(h) {
+				dst = append(dst, Row{
+					Metric:    name + "_bucket",
+					Tags:      appendTag(tags, "le", strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)),
+					Value:     BucketCount(b),
+					Timestamp: ts,
+					Exemplar:  toExemplar(b.GetExemplar()),
+				})
+			}
+
+		case dto.MetricType_INFO:
+			// not supported in the text format either
+		}
+	}
+
+	return dst
+}
+
+func appendTag(tags []Tag, key, value string) []Tag {
+	res := make([]Tag, 0, len(tags)+1)
+	res = append(res, tags...)
+	return append(res, Tag{Key: key, Value: value})
+}
+
+func toExemplar(e *dto.Exemplar) Exemplar {
+	if e == nil || len(e.GetLabel()) == 0 {
+		return Exemplar{}
+	}
+
+	res := Exemplar{
+		Tags:  make([]Tag, 0, len(e.GetLabel())),
+		Value: e.GetValue(),
+	}
+	for _, l := range e.GetLabel() {
+		res.Tags = append(res.Tags, Tag{Key: l.GetName(), Value: l.GetValue()})
+	}
+	if e.GetTimestamp() != nil {
+		res.Timestamp = e.GetTimestamp().AsTime().UnixMilli()
+	}
+	return res
+}
@@ -0,0 +1,189 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package promscrape
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestUnmarshalExemplar(t *testing.T) {
+	var rs Rows
+	require.NoError(t, rs.Unmarshal(`
+http_requests_total{code="200"} 10 # {trace_id="abc",span_id="def"} 1.5 1700000000.5
+http_requests_total{code="500"} 2 # not an exemplar
+http_request_duration_seconds_bucket{le="0.1"} 3 1700000000000 # {trace_id="xyz"} 0.05
+`))
+	require.Len(t, rs.Rows, 3)
+
+	r := rs.Rows[0]
+	assert.Equal(t, float64(10), r.Value)
+	assert.Equal(t, []Tag{{Key: "trace_id", Value: "abc"}, {Key: "span_id", Value: "def"}}, r.Exemplar.Tags)
+	assert.Equal(t, 1.5, r.Exemplar.Value)
+	assert.Equal(t, int64(1700000000500), r.Exemplar.Timestamp)
+
+	assert.Equal(t, float64(2), rs.Rows[1].Value)
+	assert.Empty(t, rs.Rows[1].Exemplar.Tags)
+
+	r = rs.Rows[2]
+	assert.Equal(t, []Tag{{Key: "le", Value: "0.1"}}, r.Tags)
+	assert.Equal(t, int64(1700000000000), r.Timestamp)
+	assert.Equal(t, []Tag{{Key: "trace_id", Value: "xyz"}}, r.Exemplar.Tags)
+	assert.Equal(t, int64(0), r.Exemplar.Timestamp)
+}
+
+func TestScrapeProtobuf(t *testing.T) {
+	families := []*dto.MetricFamily{
+		{
+			Name: proto.String("http_requests_total"),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{{
+				Label: []*dto.LabelPair{{Name: proto.String("code"), Value: proto.String("200")}},
+				Counter: &dto.Counter{
+					Value: proto.Float64(10),
+					Exemplar: &dto.Exemplar{
+						Label:     []*dto.LabelPair{{Name: proto.String("trace_id"), Value: proto.String("abc")}},
+						Value:     proto.Float64(1),
+						Timestamp: timestamppb.New(time.UnixMilli(1700000000000)),
+					},
+				},
+			}},
+		},
+		{
+			Name: proto.String("rpc_duration_seconds"),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{{
+				Histogram: &dto.Histogram{
+					SampleCount:   proto.Uint64(3),
+					SampleSum:     proto.Float64(2.5),
+					Schema:        proto.Int32(0),
+					PositiveSpan:  []*dto.BucketSpan{span(0, 2)},
+					PositiveDelta: []int64{1, 1},
+				},
+			}},
+		},
+	}
+
+	var accept string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", string(expfmt.FmtProtoDelim))
+		enc := expfmt.NewEncoder(w, expfmt.FmtProtoDelim)
+		for _, mf := range families {
+			require.NoError(t, enc.Encode(mf))
+		}
+	}))
+	defer ts.Close()
+
+	var pts []*point.Point
+	p, err := NewPromScraper(
+		PreferProtobuf(true),
+		WithExemplars(true),
+		KeepExistMetricName(true),
+		WithCallback(func(res []*point.Point) error {
+			pts = append(pts, res...)
+			return nil
+		}))
+	require.NoError(t, err)
+
+	require.NoError(t, p.ScrapeURL(ts.URL))
+	assert.True(t, strings.HasPrefix(accept, "application/vnd.google.protobuf"))
+
+	got := map[string]*point.Point{}
+	for _, pt := range pts {
+		key := pt.Name()
+		for _, kv := range pt.Fields() {
+			key += "/" + kv.Key
+		}
+		if le := pt.Get("le"); le != nil {
+			key += "/" + le.(string)
+		}
+		got[key] = pt
+	}
+
+	pt := got["http/http_requests_total"]
+	require.NotNil(t, pt)
+	assert.Equal(t, float64(10), pt.Get("http_requests_total"))
+	assert.Nil(t, pt.Get("trace_id"))
+
+	pt = got["http/trace_id/http_requests_total_exemplar"]
+	require.NotNil(t, pt)
+	assert.Equal(t, float64(1), pt.Get("http_requests_total_exemplar"))
+	assert.Equal(t, "abc", pt.Get("trace_id"))
+	assert.Empty(t, pt.GetTag("trace_id"))
+	assert.Equal(t, "200", pt.Get("code"))
+	assert.Equal(t, int64(1700000000000)*int64(time.Millisecond), pt.Time().UnixNano())
+
+	assert.Equal(t, float64(3), got["rpc/rpc_duration_seconds_count"].Get("rpc_duration_seconds_count"))
+	assert.Equal(t, float64(1), got["rpc/rpc_duration_seconds_bucket/1"].Get("rpc_duration_seconds_bucket"))
+	assert.Equal(t, float64(3), got["rpc/rpc_duration_seconds_bucket/2"].Get("rpc_duration_seconds_bucket"))
+	assert.Equal(t, float64(3), got["rpc/rpc_duration_seconds_bucket/+Inf"].Get("rpc_duration_seconds_bucket"))
+}
+
+func TestScrapeOpenMetricsExemplar(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		_, _ = w.Write([]byte(`# TYPE http_requests counter
+http_requests_total{code="200"} 10 # {trace_id="abc",span_id="def"} 1.5 1700000000.5
+# TYPE rpc_duration_seconds histogram
+rpc_duration_seconds_bucket{le="0.1"} 3 # {trace_id="xyz"} 0.05
+rpc_duration_seconds_bucket{le="+Inf"} 5
+# EOF
+`))
+	}))
+	defer ts.Close()
+
+	var pts []*point.Point
+	p, err := NewPromScraper(
+		WithExemplars(true),
+		KeepExistMetricName(true),
+		WithCallback(func(res []*point.Point) error {
+			pts = append(pts, res...)
+			return nil
+		}))
+	require.NoError(t, err)
+
+	require.NoError(t, p.ScrapeURL(ts.URL))
+
+	var exemplars []*point.Point
+	for _, pt := range pts {
+		for _, kv := range pt.Fields() {
+			if strings.HasSuffix(kv.Key, "_exemplar") {
+				exemplars = append(exemplars, pt)
+				break
+			}
+		}
+	}
+	require.Len(t, exemplars, 2)
+
+	pt := exemplars[0]
+	assert.Equal(t, 1.5, pt.Get("http_requests_total_exemplar"))
+	assert.Equal(t, "200", pt.GetTag("code"))
+	assert.Equal(t, int64(1700000000500)*int64(time.Millisecond), pt.Time().UnixNano())
+
+	// exemplar labels are fields, not tags
+	assert.Equal(t, "abc", pt.Get("trace_id"))
+	assert.Equal(t, "def", pt.Get("span_id"))
+	assert.Empty(t, pt.GetTag("trace_id"))
+	assert.Empty(t, pt.GetTag("span_id"))
+
+	pt = exemplars[1]
+	assert.Equal(t, 0.05, pt.Get("rpc_duration_seconds_bucket_exemplar"))
+	assert.Equal(t, "0.1", pt.GetTag("le"))
+	assert.Equal(t, "xyz", pt.Get("trace_id"))
+	assert.Empty(t, pt.GetTag("trace_id"))
+}
@@ -68,6 +68,17 @@ type Row struct {
 	Tags      []Tag
 	Value     float64
 	Timestamp int64
+
+	// Exemplar is the OpenMetrics exemplar of the row, it's empty if no Tags.
+	Exemplar Exemplar
+}
+
+// Exemplar is an OpenMetrics exemplar, such as `# {trace_id="abc"} 1.0 1520879607.789'.
+// See https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md#exemplars
+type Exemplar struct {
+	Tags      []Tag
+	Value     float64
+	Timestamp int64 // unit milliseconds, 0 if not set
 }
 
 func (r *Row) reset() {
@@ -75,14 +86,16 @@ func (r *Row) reset() {
 	r.Tags = nil
 	r.Value = 0
 	r.Timestamp = 0
+	r.Exemplar = Exemplar{}
 }
 
-func skipTrailingComment(s string) string {
+// splitTrailingComment split s to the sample and the trailing comment(without '#').
+func splitTrailingComment(s string) (string, string) {
 	n := strings.IndexByte(s, '#')
 	if n < 0 {
-		return s
+		return s, ""
 	}
-	return s[:n]
+	return s[:n], s[n+1:]
 }
 
 func skipLeadingWhitespace(s string) string {
@@ -148,7 +161,10 @@ func (r *Row) unmarshal(s string, tagsPool []Tag, noEscapes bool) ([]Tag, error)
 		return tagsPool, fmt.Errorf("metric cannot be empty")
 	}
 	s = skipLeadingWhitespace(s)
-	s = skipTrailingComment(s)
+	s, comment := splitTrailingComment(s)
+	if comment != "" {
+		tagsPool = r.unmarshalExemplar(comment, tagsPool, noEscapes)
+	}
 	if len(s) == 0 {
 		return tagsPool, fmt.Errorf("value cannot be empty")
 	}
@@ -190,6 +206,48 @@ func (r *Row) unmarshal(s string, tagsPool []Tag, noEscapes bool) ([]Tag, error)
 	return tagsPool, nil
 }
 
+// unmarshalExemplar parse the exemplar from the trailing comment of the row, the
+// comment is ignored if it's not a valid exemplar.
+func (r *Row) unmarshalExemplar(s string, tagsPool []Tag, noEscapes bool) []Tag {
+	s = skipLeadingWhitespace(s)
+	if len(s) == 0 || s[0] != '{' {
+		return tagsPool
+	}
+
+	tagsStart := len(tagsPool)
+	s, tagsPool, err := unmarshalTags(tagsPool, s[1:], noEscapes)
+	if err != nil || len(tagsPool) == tagsStart {
+		return tagsPool[:tagsStart]
+	}
+
+	s = skipTrailingWhitespace(skipLeadingWhitespace(s))
+	valueStr, tsStr := s, ""
+	if n := nextWhitespace(s); n >= 0 {
+		valueStr, tsStr = s[:n], skipLeadingWhitespace(s[n+1:])
+	}
+
+	v, err := fastfloat.Parse(valueStr)
+	if err != nil {
+		return tagsPool[:tagsStart]
+	}
+
+	var ts float64
+	if tsStr != "" {
+		// The exemplar timestamp is always in Unix seconds.
+		if ts, err = fastfloat.Parse(tsStr); err != nil {
+			return tagsPool[:tagsStart]
+		}
+	}
+
+	tags := tagsPool[tagsStart:]
+	r.Exemplar = Exemplar{
+		Tags:      tags[:len(tags):len(tags)],
+		Value:     v,
+		Timestamp: int64(ts * 1000),
+	}
+	return tagsPool
+}
+
 // var rowsReadScrape = metrics.NewCounter(`vm_protoparser_rows_read_total{type="promscrape"}`)
 
 func unmarshalRows(dst []Row, s string, tagsPool []Tag, noEscapes bool) ([]Row, []Tag, error) {
@@ -11,13 +11,16 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptrace"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/GuanceCloud/cliutils/point"
+	"github.com/prometheus/common/expfmt"
 
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/httpcli"
 	dknet "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/net"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 )
 
 type PromScraper struct {
@@ -88,6 +91,10 @@ func (p *PromScraper) ScrapeURL(u string) error {
 		return fmt.Errorf("unexpected status code returned when scraping %q: %d", u, resp.StatusCode)
 	}
 
+	if expfmt.ResponseFormat(resp.Header) == expfmt.FmtProtoDelim {
+		return ParseProtobufStream(resp.Body, p.callbackForRow)
+	}
+
 	return p.ParserStream(resp.Body)
 }
 
@@ -103,23 +110,36 @@ func (p *PromScraper) callbackForRow(rows []Row) error {
 	opts := point.DefaultMetricOptions()
 
 	for _, row := range rows {
-		measurementName, metricName := p.splitMetricName(row.Metric)
 		var kvs point.KVs
-		kvs = kvs.Set(metricName, row.Value)
-
-		for _, tag := range row.Tags {
-			kvs = kvs.AddTag(tag.Key, tag.Value)
-		}
-		for key, value := range p.opt.extraTags {
-			kvs = kvs.AddTag(key, value)
+		name := row.Metric
+
+		if len(p.opt.metricRelabelConfigs) > 0 {
+			var ok bool
+			if name, kvs, ok = p.relabelRow(&row); !ok {
+				continue
+			}
+		} else {
+			for _, tag := range row.Tags {
+				kvs = kvs.AddTag(tag.Key, tag.Value)
+			}
+			for key, value := range p.opt.extraTags {
+				kvs = kvs.AddTag(key, value)
+			}
 		}
 
+		measurementName, metricName := p.splitMetricName(name)
+
 		timeNs := p.timestamp
 		if p.opt.honorTimestamps && row.Timestamp > 0 {
 			// Convert it to nanoseconds.
 			timeNs = row.Timestamp * int64(time.Millisecond)
 		}
 
+		if p.opt.exemplars && len(row.Exemplar.Tags) > 0 {
+			pts = append(pts, p.exemplarPoint(measurementName, metricName, kvs, &row.Exemplar, timeNs))
+		}
+
+		kvs = kvs.Set(metricName, row.Value)
 		pt := point.NewPoint(measurementName, kvs, append(opts, point.WithTimestamp(timeNs))...)
 		pts = append(pts, pt)
 	}
@@ -127,9 +147,75 @@ func (p *PromScraper) callbackForRow(rows []Row) error {
 	return p.opt.callback(pts)
 }
 
+// relabelRow apply the metric relabel rules on the row, the scraped labels take
+// precedence over the extra tags. It returns the relabeled metric name and tags,
+// or false if the row is dropped.
+func (p *PromScraper) relabelRow(row *Row) (string, point.KVs, bool) {
+	lbs := make(map[string]string, len(row.Tags)+len(p.opt.extraTags)+1)
+	for key, value := range p.opt.extraTags {
+		lbs[key] = value
+	}
+	for _, tag := range row.Tags {
+		lbs[tag.Key] = tag.Value
+	}
+	lbs[relabel.MetricNameLabel] = row.Metric
+
+	lbs, keep := p.opt.metricRelabelConfigs.Process(lbs)
+	if !keep {
+		return "", nil, false
+	}
+
+	name := lbs[relabel.MetricNameLabel]
+	if name == "" {
+		return "", nil, false
+	}
+	delete(lbs, relabel.MetricNameLabel)
+
+	keys := make([]string, 0, len(lbs))
+	for key := range lbs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var kvs point.KVs
+	for _, key := range keys {
+		kvs = kvs.AddTag(key, lbs[key])
+	}
+
+	return name, kvs, true
+}
+
+// exemplarPoint build the point of the exemplar, the exemplar labels are added
+// as string fields so they do not create new time series, the exemplar
+// timestamp takes precedence.
+func (p *PromScraper) exemplarPoint(measurementName, metricName string, tags point.KVs, e *Exemplar, timeNs int64) *point.Point {
+	kvs := make(point.KVs, 0, len(tags)+len(e.Tags)+1)
+	kvs = append(kvs, tags...)
+	for _, tag := range e.Tags {
+		kvs = kvs.Add(tag.Key, tag.Value)
+	}
+	kvs = kvs.Set(metricName+"_exemplar", e.Value)
+
+	if e.Timestamp > 0 {
+		timeNs = e.Timestamp * int64(time.Millisecond)
+	}
+
+	// string fields are dropped by default on metric
+	return point.NewPoint(measurementName, kvs,
+		append(point.DefaultMetricOptions(), point.WithStrField(true), point.WithTimestamp(timeNs))...)
+}
+
 func (p *PromScraper) newRequest(u string) (*http.Request, error) {
 	req, err := http.NewRequest("GET", u, nil)
-	req.Header.Set("Accept", "text/plain;version=0.0.4;q=1,*/*;q=0.1")
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opt.preferProtobuf {
+		req.Header.Set("Accept", AcceptHeaderProtobuf)
+	} else {
+		req.Header.Set("Accept", acceptHeaderText)
+	}
 	for k, v := range p.opt.httpHeaders {
 		req.Header.Set(k, v)
 	}
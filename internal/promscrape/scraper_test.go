@@ -12,6 +12,9 @@ import (
 
 	"github.com/GuanceCloud/cliutils/point"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/relabel"
 )
 
 const (
@@ -58,6 +61,45 @@ func TestParseStream(t *testing.T) {
 	t.Logf("count: %d\n", count)
 }
 
+func TestMetricRelabel(t *testing.T) {
+	configs := relabel.Configs{
+		{SourceLabels: []string{"status"}, Regex: "[0-2]", Action: relabel.Keep},
+		{SourceLabels: []string{"__name__"}, Regex: "datakit_(.*)", TargetLabel: "__name__", Replacement: strPtr("dk_${1}")},
+		{SourceLabels: []string{"key-01", "status"}, TargetLabel: "id", Replacement: strPtr("${1}-${2}"), Regex: "(.*);(.*)"},
+		{Regex: "domain|key-01", Action: relabel.LabelDrop},
+	}
+	require.NoError(t, configs.Setup())
+
+	var buf bytes.Buffer
+	buf.WriteString(mockHeader)
+	for i := 0; i < 5; i++ {
+		buf.WriteString(fmt.Sprintf(mockBody, i, i, i, i, i))
+	}
+
+	var pts []*point.Point
+	p := &PromScraper{
+		opt: &option{
+			extraTags:            map[string]string{"key-01": "value-01", "category": "extra"},
+			metricRelabelConfigs: configs,
+			callback: func(x []*point.Point) error {
+				pts = append(pts, x...)
+				return nil
+			},
+		},
+	}
+	require.NoError(t, p.ParserStream(&buf))
+	require.Len(t, pts, 15)
+
+	for _, pt := range pts {
+		assert.Equal(t, "dk", pt.Name())
+		assert.NotNil(t, pt.Get("http_worker_number"))
+		assert.Equal(t, "metric", pt.Get("category")) // scraped label takes precedence
+		assert.Nil(t, pt.Get("domain"))
+		assert.Nil(t, pt.Get("key-01"))
+		assert.Contains(t, []string{"value-01-0", "value-01-1", "value-01-2"}, pt.Get("id"))
+	}
+}
+
 func BenchmarkParseStream(b *testing.B) {
 	var buf bytes.Buffer
 	buf.WriteString(mockHeader)
@@ -158,3 +200,7 @@ func TestSplitMetricName(t *testing.T) {
 		assert.Equal(t, tc.outFieldName, fieldName)
 	}
 }
+
+func strPtr(s string) *string {
+	return &s
+}
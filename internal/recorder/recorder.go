@@ -45,6 +45,7 @@ type Recorder struct {
 	Duration   time.Duration `toml:"duration"`
 	Inputs     []string      `toml:"inputs"`
 	Categories []string      `toml:"categories"`
+	Replay     *Replay       `toml:"replay"`
 
 	totalRecordedPoints atomic.Int64
 	started             time.Time
@@ -80,17 +81,7 @@ func SetupRecorder(r *Recorder) (*Recorder, error) {
 }
 
 func (r *Recorder) categoryOK(c point.Category) bool {
-	if len(r.Categories) == 0 {
-		return true
-	}
-
-	for _, x := range r.Categories {
-		if x == c.String() {
-			return true
-		}
-	}
-
-	return false
+	return categoryIn(r.Categories, c)
 }
 
 func (r *Recorder) inputOK(i string) bool {
@@ -149,7 +140,7 @@ func (r *Recorder) Record(pts []*point.Point, cat point.Category, input string)
 
 		ext = ExtPBJson
 
-		if dataBytes, err = pts2pbjson(pts); err != nil {
+		if dataBytes, err = Pts2PBJson(pts); err != nil {
 			return err
 		}
 
@@ -175,7 +166,8 @@ func (r *Recorder) Record(pts []*point.Point, cat point.Category, input string)
 	return nil
 }
 
-func pts2pbjson(pts []*point.Point) ([]byte, error) {
+// Pts2PBJson marshal points into protobuf-json.
+func Pts2PBJson(pts []*point.Point) ([]byte, error) {
 	pbpts := &point.PBPoints{
 		Arr: make([]*point.PBPoint, 0, len(pts)),
 	}
@@ -0,0 +1,333 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/logger"
+	"github.com/GuanceCloud/cliutils/point"
+	"golang.org/x/time/rate"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+)
+
+var l = logger.DefaultSLogger("recorder")
+
+// FeedFunc used to feed replayed points as if they come from input.
+type FeedFunc func(cat point.Category, pts []*point.Point, input string) error
+
+// Replay configure replaying of recorded data in datakit.conf.
+//
+// Recorded points are fed back to IO as if they come from the input, so pipelines,
+// filters and global tags will apply on them again.
+type Replay struct {
+	Enabled    bool     `toml:"enabled"`
+	Path       string   `toml:"path"`
+	Input      string   `toml:"input"` // if empty, use the input recorded in data file name
+	Categories []string `toml:"categories"`
+
+	// Speed is the multiple of the recorded pace, 0 means replay as fast as possible.
+	Speed float64 `toml:"speed"`
+	// Rate limit the points fed per second, 0 means no limit.
+	Rate       int  `toml:"rate"`
+	Loop       bool `toml:"loop"`
+	RebaseTime bool `toml:"rebase_time"`
+
+	totalReplayedPoints atomic.Int64
+}
+
+type replayFile struct {
+	path     string
+	cat      point.Category
+	input    string
+	recorded time.Time // time of the file recorded, zero if unknown
+}
+
+// StartReplay start replaying recorded data in background, it stops on datakit exit.
+func StartReplay(r *Recorder, feed FeedFunc) error {
+	if r == nil || r.Replay == nil || !r.Replay.Enabled {
+		return nil
+	}
+
+	l = logger.SLogger("recorder")
+
+	rp := r.Replay
+	if rp.Path == "" {
+		rp.Path = datakit.RecorderDir
+	}
+
+	if r.Enabled && filepath.Clean(r.Path) == filepath.Clean(rp.Path) {
+		return fmt.Errorf("recording and replaying on the same path %q not allowed", rp.Path)
+	}
+
+	if rp.Speed < 0 || rp.Rate < 0 {
+		return fmt.Errorf("invalid replay speed %f or rate %d", rp.Speed, rp.Rate)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	g := datakit.G("recorder/replay")
+	g.Go(func(_ context.Context) error {
+		select {
+		case <-datakit.Exit.Wait():
+			cancel()
+		case <-ctx.Done():
+		}
+		return nil
+	})
+
+	g.Go(func(_ context.Context) error {
+		defer cancel()
+
+		if err := rp.Run(ctx, feed); err != nil {
+			l.Errorf("replay on %q: %s", rp.Path, err)
+		}
+		return nil
+	})
+
+	return nil
+}
+
+// Run replay all recorded data until done or ctx canceled.
+func (rp *Replay) Run(ctx context.Context, feed FeedFunc) error {
+	files, err := rp.files()
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no recorded data found")
+	}
+
+	var limiter *rate.Limiter
+	if rp.Rate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(rp.Rate), rp.Rate)
+	}
+
+	l.Infof("start replaying %d files under %q, speed: %f, rate: %d, loop: %v",
+		len(files), rp.Path, rp.Speed, rp.Rate, rp.Loop)
+
+	for round := 1; ; round++ {
+		if err := rp.replayRound(ctx, files, feed, limiter); err != nil {
+			if ctx.Err() != nil { // canceled
+				return nil
+			}
+			return err
+		}
+
+		l.Infof("replay round %d done, total %d points replayed", round, rp.totalReplayedPoints.Load())
+
+		if !rp.Loop {
+			return nil
+		}
+	}
+}
+
+func (rp *Replay) replayRound(ctx context.Context, files []*replayFile, feed FeedFunc, limiter *rate.Limiter) error {
+	var (
+		start = time.Now()
+		first time.Time
+	)
+
+	for _, f := range files {
+		pts, err := LoadFile(f.path)
+		if err != nil {
+			l.Warnf("load %q: %s, ignored", f.path, err)
+			continue
+		}
+
+		if len(pts) == 0 {
+			continue
+		}
+
+		recorded := f.recorded
+		if recorded.IsZero() {
+			recorded = latestTime(pts)
+		}
+
+		if first.IsZero() {
+			first = recorded
+		}
+
+		// keep the pace of recording
+		if rp.Speed > 0 {
+			due := start.Add(time.Duration(float64(recorded.Sub(first)) / rp.Speed))
+			if err := sleepContext(ctx, time.Until(due)); err != nil {
+				return err
+			}
+		}
+
+		if rp.RebaseTime {
+			rebasePointTime(time.Now(), recorded, pts)
+		}
+
+		input := rp.Input
+		if input == "" {
+			input = f.input
+		}
+
+		for len(pts) > 0 {
+			n := len(pts)
+			if limiter != nil {
+				if n > limiter.Burst() {
+					n = limiter.Burst()
+				}
+
+				if err := limiter.WaitN(ctx, n); err != nil {
+					return err
+				}
+			}
+
+			if err := feed(f.cat, pts[:n], input); err != nil {
+				l.Warnf("feed %d points of %q: %s, ignored", n, f.path, err)
+			} else {
+				rp.totalReplayedPoints.Add(int64(n))
+			}
+
+			pts = pts[n:]
+		}
+	}
+
+	return nil
+}
+
+// files get all recorded data files, sorted by the time they recorded.
+func (rp *Replay) files() ([]*replayFile, error) {
+	var arr []*replayFile
+
+	for _, cat := range point.AllCategories() {
+		if !categoryIn(rp.Categories, cat) {
+			continue
+		}
+
+		dir := filepath.Join(rp.Path, cat.String())
+		if err := filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			if info.IsDir() {
+				return nil
+			}
+
+			switch filepath.Ext(path) {
+			case ExtLineProtocol, ExtPBJson:
+				arr = append(arr, parseReplayFile(path, cat))
+			default:
+				l.Debugf("ignore %q", path)
+			}
+
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("walk %q: %w", dir, err)
+		}
+	}
+
+	sort.SliceStable(arr, func(i, j int) bool {
+		return arr[i].recorded.Before(arr[j].recorded)
+	})
+
+	return arr, nil
+}
+
+// parseReplayFile parse input and record time from file name like <input>.<unix-nano><ext>.
+func parseReplayFile(path string, cat point.Category) *replayFile {
+	f := &replayFile{path: path, cat: cat}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	f.input = name
+
+	if idx := strings.LastIndex(name, "."); idx > 0 {
+		if ts, err := strconv.ParseInt(name[idx+1:], 10, 64); err == nil {
+			f.input = name[:idx]
+			f.recorded = time.Unix(0, ts)
+		}
+	}
+
+	return f
+}
+
+// LoadFile load points from recorded data file.
+func LoadFile(path string) ([]*point.Point, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Ext(path) {
+	case ExtLineProtocol:
+		dec := point.GetDecoder(point.WithDecEncoding(point.LineProtocol))
+		defer point.PutDecoder(dec)
+
+		return dec.Decode(data)
+
+	case ExtPBJson:
+		return PBJson2pts(data)
+
+	default:
+		return nil, fmt.Errorf("unknown data file %q", path)
+	}
+}
+
+// rebasePointTime move points' time relative to now, the distance between
+// point's time and the time it recorded kept.
+func rebasePointTime(now, recorded time.Time, pts []*point.Point) {
+	for _, pt := range pts {
+		pt.SetTime(now.Add(pt.Time().Sub(recorded)))
+	}
+}
+
+func latestTime(pts []*point.Point) (t time.Time) {
+	for _, pt := range pts {
+		if pt.Time().After(t) {
+			t = pt.Time()
+		}
+	}
+	return
+}
+
+func categoryIn(arr []string, c point.Category) bool {
+	if len(arr) == 0 {
+		return true
+	}
+
+	for _, x := range arr {
+		if x == c.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sleepContext(ctx context.Context, du time.Duration) error {
+	if du <= 0 {
+		return ctx.Err()
+	}
+
+	tick := time.NewTimer(du)
+	defer tick.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-tick.C:
+		return nil
+	}
+}
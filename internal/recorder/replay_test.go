@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	T "testing"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type replayed struct {
+	cat   point.Category
+	input string
+	pts   []*point.Point
+}
+
+// writeRecorded write points recorded at time ts, points' time are 1s before ts.
+func writeRecorded(t *T.T, dir string, cat point.Category, input string, ts time.Time, npts int) {
+	t.Helper()
+
+	pts := point.NewRander(point.WithRandTime(ts.Add(-time.Second))).Rand(npts)
+
+	data, err := Pts2PBJson(pts)
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, cat.String()), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, cat.String(),
+		fmt.Sprintf("%s.%d%s", input, ts.UnixNano(), ExtPBJson)), data, os.ModePerm))
+}
+
+func TestReplay(t *T.T) {
+	dir := t.TempDir()
+	recorded := time.Unix(1698217783, 0)
+
+	writeRecorded(t, dir, point.Metric, "cpu", recorded.Add(200*time.Millisecond), 3)
+	writeRecorded(t, dir, point.Metric, "cpu", recorded, 2)
+	writeRecorded(t, dir, point.Logging, "logging-some-pod", recorded.Add(100*time.Millisecond), 1)
+
+	t.Run("replay", func(t *T.T) {
+		var res []*replayed
+		feed := func(cat point.Category, pts []*point.Point, input string) error {
+			res = append(res, &replayed{cat: cat, input: input, pts: pts})
+			return nil
+		}
+
+		rp := &Replay{Path: dir, Speed: 1.0, RebaseTime: true}
+
+		start := time.Now()
+		require.NoError(t, rp.Run(context.Background(), feed))
+		assert.True(t, time.Since(start) >= 200*time.Millisecond) // recording pace kept
+
+		require.Len(t, res, 3)
+		assert.Equal(t, int64(6), rp.totalReplayedPoints.Load())
+
+		// replayed in order they recorded
+		assert.Equal(t, point.Metric, res[0].cat)
+		assert.Equal(t, "cpu", res[0].input)
+		assert.Len(t, res[0].pts, 2)
+
+		assert.Equal(t, point.Logging, res[1].cat)
+		assert.Equal(t, "logging-some-pod", res[1].input)
+
+		assert.Len(t, res[2].pts, 3)
+
+		for _, r := range res {
+			for _, pt := range r.pts {
+				assert.WithinDuration(t, start.Add(-time.Second), pt.Time(), time.Second)
+			}
+		}
+	})
+
+	t.Run("input-and-categories", func(t *T.T) {
+		var res []*replayed
+		feed := func(cat point.Category, pts []*point.Point, input string) error {
+			res = append(res, &replayed{cat: cat, input: input, pts: pts})
+			return nil
+		}
+
+		rp := &Replay{Path: dir, Input: "mocked", Categories: []string{point.Metric.String()}}
+		require.NoError(t, rp.Run(context.Background(), feed))
+
+		require.Len(t, res, 2)
+		for _, r := range res {
+			assert.Equal(t, "mocked", r.input)
+			assert.Equal(t, point.Metric, r.cat)
+
+			for _, pt := range r.pts { // time not rebased
+				assert.True(t, pt.Time().Before(recorded.Add(time.Second)))
+			}
+		}
+	})
+
+	t.Run("rate-and-loop", func(t *T.T) {
+		npts := 0
+		feed := func(cat point.Category, pts []*point.Point, input string) error {
+			assert.LessOrEqual(t, len(pts), 2)
+			npts += len(pts)
+			return nil
+		}
+
+		rp := &Replay{Path: dir, Rate: 2, Loop: true}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(3*time.Second, cancel)
+
+		require.NoError(t, rp.Run(ctx, feed))
+
+		// 2 points burst + 2 points/second
+		assert.True(t, npts >= 6 && npts <= 10, "got %d points", npts)
+	})
+
+	t.Run("no-data", func(t *T.T) {
+		rp := &Replay{Path: t.TempDir()}
+		assert.Error(t, rp.Run(context.Background(), nil))
+	})
+}
+
+func TestParseReplayFile(t *T.T) {
+	f := parseReplayFile("/recorder/logging/logging-some.pod.1698217783322857000.pbjson", point.Logging)
+	assert.Equal(t, "logging-some.pod", f.input)
+	assert.Equal(t, int64(1698217783322857000), f.recorded.UnixNano())
+
+	f = parseReplayFile("/recorder/metric/cpu.lp", point.Metric)
+	assert.Equal(t, "cpu", f.input)
+	assert.True(t, f.recorded.IsZero())
+}
+
+func TestStartReplay(t *T.T) {
+	dir := t.TempDir()
+	r := &Recorder{Enabled: true, Path: dir, Replay: &Replay{Enabled: true, Path: dir}}
+	assert.Error(t, StartReplay(r, nil))
+
+	r = &Recorder{Replay: &Replay{Enabled: true, Path: dir, Speed: -1}}
+	assert.Error(t, StartReplay(r, nil))
+}
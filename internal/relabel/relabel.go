@@ -0,0 +1,275 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+// Package relabel implements Prometheus-style relabeling, it's shared by all
+// Prometheus scrapers to relabel discovered targets and scraped samples.
+//
+// See https://prometheus.io/docs/prometheus/latest/configuration/configuration/#relabel_config
+package relabel
+
+import (
+	"crypto/md5" //nolint:gosec
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Action string
+
+const (
+	Replace   Action = "replace"
+	Keep      Action = "keep"
+	Drop      Action = "drop"
+	HashMod   Action = "hashmod"
+	LabelMap  Action = "labelmap"
+	LabelDrop Action = "labeldrop"
+	LabelKeep Action = "labelkeep"
+	Lowercase Action = "lowercase"
+	Uppercase Action = "uppercase"
+)
+
+const (
+	// MetricNameLabel is the label of the metric name within samples.
+	MetricNameLabel = "__name__"
+	// AddressLabel is the label of the target address(host:port).
+	AddressLabel = "__address__"
+	// SchemeLabel is the label of the target scheme(http/https).
+	SchemeLabel = "__scheme__"
+	// MetricsPathLabel is the label of the target metrics path.
+	MetricsPathLabel = "__metrics_path__"
+	// ParamLabelPrefix is the prefix of the target URL params.
+	ParamLabelPrefix = "__param_"
+	// ReservedLabelPrefix is the prefix of internal labels, these labels are
+	// removed after target relabeling.
+	ReservedLabelPrefix = "__"
+
+	defaultSeparator   = ";"
+	defaultRegex       = "(.*)"
+	defaultReplacement = "$1"
+)
+
+// Config is a single relabel rule.
+type Config struct {
+	// SourceLabels are concatenated with Separator and matched against Regex.
+	SourceLabels []string `toml:"source_labels" json:"source_labels"`
+	// Separator between concatenated source label values, default `;'.
+	Separator string `toml:"separator" json:"separator"`
+	// Regex is fully anchored, default `(.*)'.
+	Regex string `toml:"regex" json:"regex"`
+	// Modulus to take of the hash of the source label values, used by hashmod.
+	Modulus uint64 `toml:"modulus" json:"modulus"`
+	// TargetLabel is the label to write for replace/hashmod.
+	TargetLabel string `toml:"target_label" json:"target_label"`
+	// Replacement against which a regex replace is performed, default `$1'.
+	// It's a pointer to tell an explicit empty replacement from unset.
+	Replacement *string `toml:"replacement" json:"replacement"`
+	// Action to perform, default replace.
+	Action Action `toml:"action" json:"action"`
+
+	re          *regexp.Regexp
+	replacement string
+}
+
+// Configs are relabel rules applied in order.
+type Configs []*Config
+
+// Setup fill the defaults and validate all the rules, it must be called before Process.
+func (cs Configs) Setup() error {
+	for idx, c := range cs {
+		if c == nil {
+			return fmt.Errorf("relabel config #%d is empty", idx)
+		}
+
+		if err := c.setup(); err != nil {
+			return fmt.Errorf("relabel config #%d: %w", idx, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) setup() error {
+	if c.Action == "" {
+		c.Action = Replace
+	}
+	c.Action = Action(strings.ToLower(string(c.Action)))
+
+	if c.Separator == "" {
+		c.Separator = defaultSeparator
+	}
+
+	if c.Regex == "" {
+		c.Regex = defaultRegex
+	}
+
+	c.replacement = defaultReplacement
+	if c.Replacement != nil {
+		c.replacement = *c.Replacement
+	}
+
+	re, err := regexp.Compile("^(?:" + c.Regex + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %w", c.Regex, err)
+	}
+	c.re = re
+
+	switch c.Action {
+	case Replace:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("target_label is required for action %s", c.Action)
+		}
+
+	case Lowercase, Uppercase:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("target_label is required for action %s", c.Action)
+		}
+		if len(c.SourceLabels) == 0 {
+			return fmt.Errorf("source_labels is required for action %s", c.Action)
+		}
+
+	case HashMod:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("target_label is required for action %s", c.Action)
+		}
+		if c.Modulus == 0 {
+			return fmt.Errorf("modulus is required for action %s", c.Action)
+		}
+
+	case Keep, Drop:
+		if len(c.SourceLabels) == 0 {
+			return fmt.Errorf("source_labels is required for action %s", c.Action)
+		}
+
+	case LabelMap, LabelDrop, LabelKeep:
+		if len(c.SourceLabels) > 0 {
+			return fmt.Errorf("source_labels is not allowed for action %s", c.Action)
+		}
+
+	default:
+		return fmt.Errorf("unknown action %q", c.Action)
+	}
+
+	return nil
+}
+
+// Process apply the rules to labels in order, labels is modified in place.
+// It returns false if the labels should be dropped. Labels with empty value
+// are removed.
+func (cs Configs) Process(labels map[string]string) (map[string]string, bool) {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	for _, c := range cs {
+		if !c.process(labels) {
+			return nil, false
+		}
+	}
+
+	return labels, true
+}
+
+func (c *Config) process(labels map[string]string) bool {
+	values := make([]string, 0, len(c.SourceLabels))
+	for _, name := range c.SourceLabels {
+		values = append(values, labels[name])
+	}
+	val := strings.Join(values, c.Separator)
+
+	switch c.Action {
+	case Keep:
+		return c.re.MatchString(val)
+
+	case Drop:
+		return !c.re.MatchString(val)
+
+	case Replace:
+		indexes := c.re.FindStringSubmatchIndex(val)
+		if indexes == nil {
+			break
+		}
+
+		target := string(c.re.ExpandString(nil, c.TargetLabel, val, indexes))
+		if target == "" {
+			break
+		}
+
+		setLabel(labels, target, string(c.re.ExpandString(nil, c.replacement, val, indexes)))
+
+	case Lowercase:
+		setLabel(labels, c.TargetLabel, strings.ToLower(val))
+
+	case Uppercase:
+		setLabel(labels, c.TargetLabel, strings.ToUpper(val))
+
+	case HashMod:
+		mod := sum64(md5.Sum([]byte(val))) % c.Modulus //nolint:gosec
+		setLabel(labels, c.TargetLabel, strconv.FormatUint(mod, 10))
+
+	case LabelMap:
+		// sorted so the result is stable if different labels mapped to the same name
+		for _, name := range sortedNames(labels) {
+			if c.re.MatchString(name) {
+				setLabel(labels, c.re.ReplaceAllString(name, c.replacement), labels[name])
+			}
+		}
+
+	case LabelDrop:
+		for name := range labels {
+			if c.re.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+
+	case LabelKeep:
+		for name := range labels {
+			if !c.re.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+	}
+
+	return true
+}
+
+func setLabel(labels map[string]string, name, value string) {
+	if value == "" {
+		delete(labels, name)
+		return
+	}
+	labels[name] = value
+}
+
+func sortedNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sum64 sums the md5 hash to an uint64, same as Prometheus.
+func sum64(hash [md5.Size]byte) uint64 {
+	var s uint64
+	for i, b := range hash {
+		shift := uint64((md5.Size - i - 1) * 8)
+		s |= uint64(b) << shift
+	}
+	return s
+}
+
+// PublicLabels returns the labels without the reserved prefix `__'.
+func PublicLabels(labels map[string]string) map[string]string {
+	res := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if !strings.HasPrefix(k, ReservedLabelPrefix) {
+			res[k] = v
+		}
+	}
+	return res
+}
@@ -0,0 +1,238 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package relabel
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcess(t *testing.T) {
+	cases := []struct {
+		name    string
+		configs Configs
+		in      map[string]string
+		out     map[string]string
+		drop    bool
+	}{
+		{
+			name: "replace",
+			configs: Configs{
+				{SourceLabels: []string{"a", "b"}, Regex: "(.*);(.*)", TargetLabel: "c", Replacement: strPtr("${2}-${1}")},
+			},
+			in:  map[string]string{"a": "foo", "b": "bar"},
+			out: map[string]string{"a": "foo", "b": "bar", "c": "bar-foo"},
+		},
+		{
+			name: "replace-defaults",
+			configs: Configs{
+				{SourceLabels: []string{"a"}, TargetLabel: "b"},
+			},
+			in:  map[string]string{"a": "foo"},
+			out: map[string]string{"a": "foo", "b": "foo"},
+		},
+		{
+			name: "replace-not-match",
+			configs: Configs{
+				{SourceLabels: []string{"a"}, Regex: "x.*", TargetLabel: "b", Replacement: strPtr("y")},
+			},
+			in:  map[string]string{"a": "foo"},
+			out: map[string]string{"a": "foo"},
+		},
+		{
+			name: "replace-anchored",
+			configs: Configs{
+				{SourceLabels: []string{"a"}, Regex: "o+", TargetLabel: "b", Replacement: strPtr("y")},
+			},
+			in:  map[string]string{"a": "foo"},
+			out: map[string]string{"a": "foo"},
+		},
+		{
+			name: "replace-empty-delete",
+			configs: Configs{
+				{SourceLabels: []string{"missing"}, TargetLabel: "a"},
+			},
+			in:  map[string]string{"a": "foo"},
+			out: map[string]string{},
+		},
+		{
+			name: "replace-explicit-empty",
+			configs: Configs{
+				{SourceLabels: []string{"a"}, TargetLabel: "b", Replacement: strPtr("")},
+			},
+			in:  map[string]string{"a": "foo", "b": "bar"},
+			out: map[string]string{"a": "foo"},
+		},
+		{
+			name: "replace-target-from-regex",
+			configs: Configs{
+				{SourceLabels: []string{"a"}, Regex: "(\\w+)=(\\w+)", TargetLabel: "${1}", Replacement: strPtr("${2}")},
+			},
+			in:  map[string]string{"a": "env=prod"},
+			out: map[string]string{"a": "env=prod", "env": "prod"},
+		},
+		{
+			name: "keep",
+			configs: Configs{
+				{SourceLabels: []string{"__name__"}, Regex: "go_.*", Action: Keep},
+			},
+			in:   map[string]string{"__name__": "process_cpu_seconds_total"},
+			drop: true,
+		},
+		{
+			name: "keep-matched",
+			configs: Configs{
+				{SourceLabels: []string{"__name__"}, Regex: "go_.*", Action: Keep},
+			},
+			in:  map[string]string{"__name__": "go_goroutines"},
+			out: map[string]string{"__name__": "go_goroutines"},
+		},
+		{
+			name: "drop",
+			configs: Configs{
+				{SourceLabels: []string{"env", "zone"}, Regex: "dev;.*", Action: Drop},
+			},
+			in:   map[string]string{"env": "dev", "zone": "a"},
+			drop: true,
+		},
+		{
+			name: "hashmod",
+			configs: Configs{
+				{SourceLabels: []string{"__address__"}, Modulus: 8, TargetLabel: "__tmp_hash", Action: HashMod},
+				{SourceLabels: []string{"__tmp_hash"}, Regex: "5", Action: Keep},
+			},
+			in:  map[string]string{"__address__": "10.0.0.1:9100"},
+			out: map[string]string{"__address__": "10.0.0.1:9100", "__tmp_hash": "5"},
+		},
+		{
+			name: "labelmap",
+			configs: Configs{
+				{Regex: "__meta_pod_label_(.+)", Action: LabelMap},
+			},
+			in:  map[string]string{"__meta_pod_label_app": "nginx", "__meta_pod_name": "x"},
+			out: map[string]string{"__meta_pod_label_app": "nginx", "__meta_pod_name": "x", "app": "nginx"},
+		},
+		{
+			name: "labeldrop",
+			configs: Configs{
+				{Regex: "tmp_.*", Action: LabelDrop},
+			},
+			in:  map[string]string{"tmp_a": "1", "tmp_b": "2", "c": "3"},
+			out: map[string]string{"c": "3"},
+		},
+		{
+			name: "labelkeep",
+			configs: Configs{
+				{Regex: "__name__|job", Action: LabelKeep},
+			},
+			in:  map[string]string{"__name__": "up", "job": "node", "pod": "x"},
+			out: map[string]string{"__name__": "up", "job": "node"},
+		},
+		{
+			name: "lowercase",
+			configs: Configs{
+				{SourceLabels: []string{"a"}, TargetLabel: "a", Action: Lowercase},
+			},
+			in:  map[string]string{"a": "FOO"},
+			out: map[string]string{"a": "foo"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, tc.configs.Setup())
+
+			out, keep := tc.configs.Process(tc.in)
+			if tc.drop {
+				assert.False(t, keep)
+				return
+			}
+
+			assert.True(t, keep)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestHashModStable(t *testing.T) {
+	// the result is the same as Prometheus
+	configs := Configs{{SourceLabels: []string{"a"}, Modulus: 1000, TargetLabel: "b", Action: HashMod}}
+	require.NoError(t, configs.Setup())
+
+	out, _ := configs.Process(map[string]string{"a": "foo"})
+	assert.Equal(t, "696", out["b"])
+}
+
+func TestSetup(t *testing.T) {
+	cases := []struct {
+		name   string
+		config *Config
+	}{
+		{"bad-regex", &Config{SourceLabels: []string{"a"}, Regex: "(", TargetLabel: "b"}},
+		{"unknown-action", &Config{Action: "foo"}},
+		{"replace-no-target", &Config{SourceLabels: []string{"a"}}},
+		{"hashmod-no-modulus", &Config{SourceLabels: []string{"a"}, TargetLabel: "b", Action: HashMod}},
+		{"keep-no-source", &Config{Action: Keep}},
+		{"labelmap-with-source", &Config{SourceLabels: []string{"a"}, Action: LabelMap}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Error(t, Configs{tc.config}.Setup())
+		})
+	}
+
+	assert.Error(t, Configs{nil}.Setup())
+}
+
+func TestUnmarshalTOML(t *testing.T) {
+	var x struct {
+		RelabelConfigs Configs `toml:"relabel_configs"`
+	}
+
+	_, err := toml.Decode(`
+[[relabel_configs]]
+  source_labels = ["__meta_consul_service"]
+  regex         = "(.+)"
+  target_label  = "job"
+
+[[relabel_configs]]
+  action = "LabelDrop"
+  regex  = "tmp_.*"
+
+[[relabel_configs]]
+  source_labels = ["__meta_consul_service"]
+  target_label  = "instance"
+  replacement   = ""
+`, &x)
+	require.NoError(t, err)
+	require.NoError(t, x.RelabelConfigs.Setup())
+	require.Len(t, x.RelabelConfigs, 3)
+
+	// replacement not set
+	assert.Nil(t, x.RelabelConfigs[0].Replacement)
+
+	// explicit empty replacement not overwritten with default
+	require.NotNil(t, x.RelabelConfigs[2].Replacement)
+	assert.Equal(t, "", *x.RelabelConfigs[2].Replacement)
+
+	out, keep := x.RelabelConfigs.Process(map[string]string{"__meta_consul_service": "redis", "tmp_a": "1", "instance": "x"})
+	assert.True(t, keep)
+	assert.Equal(t, map[string]string{"__meta_consul_service": "redis", "job": "redis"}, out)
+}
+
+func TestPublicLabels(t *testing.T) {
+	assert.Equal(t,
+		map[string]string{"job": "x"},
+		PublicLabels(map[string]string{"__address__": "a:1", "job": "x", "__param_module": "m"}))
+}
+
+func strPtr(s string) *string {
+	return &s
+}
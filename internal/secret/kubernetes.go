@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	k8sclient "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/kubernetes/client"
+)
+
+//nolint:gosec
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// KubernetesConfig is the configure of Kubernetes Secret provider.
+type KubernetesConfig struct {
+	// Namespace of the Secret if not specified in the reference, default to
+	// the namespace of DataKit Pod.
+	Namespace string `toml:"namespace"`
+}
+
+// secretGetter is the subset of the Secret client used by kubernetesProvider.
+type secretGetter interface {
+	Get(ctx context.Context, namespace, name string) (*corev1.Secret, error)
+}
+
+type secretClient struct {
+	cli corev1client.SecretsGetter
+}
+
+func (c *secretClient) Get(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	return c.cli.Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// kubernetesProvider reads Kubernetes Secret, such as secret://k8s/<namespace>/<name>#<key>
+// or secret://k8s/<name>#<key>.
+type kubernetesProvider struct {
+	cfg *KubernetesConfig
+
+	mu  sync.Mutex
+	cli secretGetter
+}
+
+func newKubernetesProvider(cfg *KubernetesConfig) *kubernetesProvider {
+	if cfg == nil {
+		cfg = &KubernetesConfig{}
+	}
+	return &kubernetesProvider{cfg: cfg}
+}
+
+func (*kubernetesProvider) Name() string { return "k8s" }
+
+func (p *kubernetesProvider) client() (secretGetter, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cli != nil {
+		return p.cli, nil
+	}
+
+	restConfig, err := k8sclient.DefaultConfigInCluster()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes config: %w", err)
+	}
+
+	cli, err := corev1client.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client: %w", err)
+	}
+
+	p.cli = &secretClient{cli: cli}
+	return p.cli, nil
+}
+
+func (p *kubernetesProvider) namespace() string {
+	if p.cfg.Namespace != "" {
+		return p.cfg.Namespace
+	}
+
+	if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil && len(data) > 0 {
+		return strings.TrimSpace(string(data))
+	}
+
+	return "default"
+}
+
+func (p *kubernetesProvider) Get(ctx context.Context, ref *Ref) (string, error) {
+	if ref.Key == "" {
+		return "", fmt.Errorf("should be secret://k8s/[<namespace>/]<name>#<key>")
+	}
+
+	ns, name, ok := strings.Cut(ref.Path, "/")
+	if !ok {
+		ns, name = p.namespace(), ref.Path
+	}
+
+	cli, err := p.client()
+	if err != nil {
+		return "", err
+	}
+
+	s, err := cli.Get(ctx, ns, name)
+	if err != nil {
+		return "", err
+	}
+
+	if v, ok := s.Data[ref.Key]; ok {
+		return string(v), nil
+	}
+
+	if v, ok := s.StringData[ref.Key]; ok {
+		return v, nil
+	}
+
+	return "", fmt.Errorf("key %q not found in secret %s/%s", ref.Key, ns, name)
+}
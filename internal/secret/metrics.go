@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package secret
+
+import (
+	"github.com/GuanceCloud/cliutils/metrics"
+	p8s "github.com/prometheus/client_golang/prometheus"
+)
+
+var resolveVec, rotateVec *p8s.CounterVec
+
+func metricsSetup() {
+	resolveVec = p8s.NewCounterVec(
+		p8s.CounterOpts{
+			Namespace: "datakit",
+			Subsystem: "secret",
+			Name:      "resolve_total",
+			Help:      "Secret resolved count from providers",
+		},
+		[]string{
+			"provider",
+			"status",
+		},
+	)
+
+	rotateVec = p8s.NewCounterVec(
+		p8s.CounterOpts{
+			Namespace: "datakit",
+			Subsystem: "secret",
+			Name:      "rotate_total",
+			Help:      "Secret rotated count",
+		},
+		[]string{
+			"provider",
+		},
+	)
+
+	metrics.MustRegister(
+		resolveVec,
+		rotateVec,
+	)
+}
+
+func init() {
+	metricsSetup()
+}
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// envProvider reads secret from environment, such as secret://env/MYSQL_PASSWORD.
+type envProvider struct{}
+
+func (*envProvider) Name() string { return "env" }
+
+func (*envProvider) Get(_ context.Context, ref *Ref) (string, error) {
+	v, ok := os.LookupEnv(ref.Path)
+	if !ok {
+		return "", fmt.Errorf("environment %s not set", ref.Path)
+	}
+	return v, nil
+}
+
+// fileProvider reads secret from file, such as secret://file/path/to/secret.
+// The path is absolute, and the trailing newline is trimmed.
+type fileProvider struct{}
+
+func (*fileProvider) Name() string { return "file" }
+
+func (*fileProvider) Get(_ context.Context, ref *Ref) (string, error) {
+	p := ref.Path
+	if !filepath.IsAbs(p) {
+		p = string(filepath.Separator) + p
+	}
+
+	data, err := os.ReadFile(filepath.Clean(p))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
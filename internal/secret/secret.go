@@ -0,0 +1,389 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+// Package secret resolves secret references in configures.
+//
+// A secret reference is in the form of secret://<provider>/<path>[#<key>], such as
+//
+//	secret://env/MYSQL_PASSWORD
+//	secret://file/usr/local/datakit/secrets/mysql
+//	secret://vault/secret/datakit/mysql#password
+//	secret://k8s/datakit/mysql-secret#password
+//
+// Resolved values are cached with TTL, and re-resolved once expired. If the
+// value changed(the secret rotated), the configure referenced the secret is
+// reloaded.
+package secret
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/logger"
+
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/datakit"
+)
+
+const (
+	Scheme = "secret://"
+
+	defaultTTL     = 5 * time.Minute
+	minTTL         = 10 * time.Second
+	resolveTimeout = 10 * time.Second
+)
+
+var (
+	l = logger.DefaultSLogger("secret")
+
+	refRe = regexp.MustCompile(`secret://[A-Za-z0-9_-]+/[^\s"']+`)
+
+	// escape the resolved value within TOML basic(and multi-line basic) string.
+	valueEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+	)
+
+	defaultManager = NewManager(nil)
+)
+
+// Config is the configure of secret providers.
+type Config struct {
+	// TTL of the cached secret, the secret is re-resolved once expired.
+	TTL time.Duration `toml:"ttl"`
+
+	Vault      *VaultConfig      `toml:"vault,omitempty"`
+	Kubernetes *KubernetesConfig `toml:"kubernetes,omitempty"`
+}
+
+// Ref is a parsed secret reference.
+type Ref struct {
+	Raw      string // The origin reference, such as secret://vault/secret/mysql#password.
+	Provider string // Provider name, such as vault.
+	Path     string // Path of the secret within the provider, such as secret/mysql.
+	Key      string // Key within the secret, such as password.
+}
+
+// Provider gets secret value from some secret store.
+type Provider interface {
+	Name() string
+	Get(ctx context.Context, ref *Ref) (string, error)
+}
+
+// ReloadFunc is called if any secret referenced by the configure rotated.
+type ReloadFunc func(key, conf string) error
+
+// ParseRef parses secret reference secret://<provider>/<path>[#<key>].
+func ParseRef(s string) (*Ref, error) {
+	if !strings.HasPrefix(s, Scheme) {
+		return nil, fmt.Errorf("invalid secret reference %q: should prefixed with %s", s, Scheme)
+	}
+
+	provider, rest, ok := strings.Cut(strings.TrimPrefix(s, Scheme), "/")
+	if !ok || provider == "" || rest == "" {
+		return nil, fmt.Errorf("invalid secret reference %q: should be %s<provider>/<path>[#<key>]", s, Scheme)
+	}
+
+	ref := &Ref{Raw: s, Provider: provider, Path: rest}
+	if idx := strings.LastIndex(rest, "#"); idx >= 0 {
+		ref.Path, ref.Key = rest[:idx], rest[idx+1:]
+	}
+
+	if ref.Path == "" {
+		return nil, fmt.Errorf("invalid secret reference %q: empty path", s)
+	}
+
+	return ref, nil
+}
+
+// HasRef test if there are any secret reference within data.
+func HasRef(data string) bool {
+	return strings.Contains(data, Scheme) && refRe.MatchString(data)
+}
+
+type entry struct {
+	ref    *Ref
+	value  string
+	expire time.Time
+}
+
+type watcher struct {
+	conf string
+	refs []string
+	cb   ReloadFunc
+}
+
+// Manager resolves secret references with providers, and reloads configures
+// on secret rotation.
+type Manager struct {
+	mu sync.Mutex
+
+	ttl       time.Duration
+	providers map[string]Provider
+	cache     map[string]*entry
+	watchers  map[string]*watcher
+
+	startOnce sync.Once
+}
+
+// NewManager create secret manager with builtin providers.
+func NewManager(cfg *Config) *Manager {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	m := &Manager{
+		ttl:       cfg.TTL,
+		providers: map[string]Provider{},
+		cache:     map[string]*entry{},
+		watchers:  map[string]*watcher{},
+	}
+
+	if m.ttl <= 0 {
+		m.ttl = defaultTTL
+	} else if m.ttl < minTTL {
+		m.ttl = minTTL
+	}
+
+	m.AddProvider(&envProvider{})
+	m.AddProvider(&fileProvider{})
+	m.AddProvider(newVaultProvider(cfg.Vault))
+	m.AddProvider(newKubernetesProvider(cfg.Kubernetes))
+
+	return m
+}
+
+// Setup setup the default manager.
+func Setup(cfg *Config) {
+	l = logger.SLogger("secret")
+	defaultManager = NewManager(cfg)
+}
+
+// Default returns the default manager.
+func Default() *Manager {
+	return defaultManager
+}
+
+// AddProvider add(or replace) a provider.
+func (m *Manager) AddProvider(p Provider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.providers[p.Name()] = p
+}
+
+// Resolve replaces all secret references within data with the secret value.
+// The value is escaped according to the string context of the reference,
+// references within comment are ignored, and unquoted references are rejected.
+func (m *Manager) Resolve(data string) (string, error) {
+	if !HasRef(data) {
+		return data, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var (
+		sb   strings.Builder
+		last int
+	)
+
+	for _, ref := range findRefs(data) {
+		if ref.kind == strComment { // commented reference ignored
+			continue
+		}
+
+		raw := data[ref.start:ref.end]
+		v, err := m.get(raw)
+		if err != nil {
+			return "", err
+		}
+
+		if v, err = escapeValue(ref.kind, raw, v); err != nil {
+			return "", err
+		}
+
+		sb.WriteString(data[last:ref.start])
+		sb.WriteString(v)
+		last = ref.end
+	}
+
+	sb.WriteString(data[last:])
+	return sb.String(), nil
+}
+
+// get returns the secret value from cache, or from the provider if not cached
+// or expired. The caller should hold the lock.
+func (m *Manager) get(raw string) (string, error) {
+	if e, ok := m.cache[raw]; ok && time.Now().Before(e.expire) {
+		return e.value, nil
+	}
+
+	ref, err := ParseRef(raw)
+	if err != nil {
+		return "", err
+	}
+
+	v, err := m.fetch(ref)
+	if err != nil {
+		return "", err
+	}
+
+	m.cache[raw] = &entry{ref: ref, value: v, expire: time.Now().Add(m.ttl)}
+	return v, nil
+}
+
+func (m *Manager) fetch(ref *Ref) (string, error) {
+	p, ok := m.providers[ref.Provider]
+	if !ok {
+		resolveVec.WithLabelValues(ref.Provider, "unknown").Inc()
+		return "", fmt.Errorf("unknown secret provider %q in %s", ref.Provider, ref.Raw)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	v, err := p.Get(ctx, ref)
+	if err != nil {
+		resolveVec.WithLabelValues(ref.Provider, "error").Inc()
+		return "", fmt.Errorf("resolve %s: %w", ref.Raw, err)
+	}
+
+	resolveVec.WithLabelValues(ref.Provider, "ok").Inc()
+	return v, nil
+}
+
+// Watch watches the secret references within conf, the reload callback is
+// called with the key and conf if any of them rotated.
+func (m *Manager) Watch(key, conf string, cb ReloadFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var refs []string
+	for _, ref := range findRefs(conf) {
+		if ref.kind != strComment {
+			refs = append(refs, conf[ref.start:ref.end])
+		}
+	}
+
+	if len(refs) == 0 {
+		delete(m.watchers, key)
+		return
+	}
+
+	m.watchers[key] = &watcher{conf: conf, refs: refs, cb: cb}
+}
+
+// Unwatch removes the watcher of key.
+func (m *Manager) Unwatch(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.watchers, key)
+}
+
+// Refresh re-resolves expired secrets, and reloads the configures with any
+// secret rotated.
+func (m *Manager) Refresh() {
+	type reload struct {
+		key string
+		w   *watcher
+	}
+
+	var reloads []reload
+
+	func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		now := time.Now()
+		rotated := map[string]bool{}
+
+		watched := map[string]bool{}
+		for _, w := range m.watchers {
+			for _, raw := range w.refs {
+				watched[raw] = true
+			}
+		}
+
+		for raw, e := range m.cache {
+			if now.Before(e.expire) {
+				continue
+			}
+
+			// nobody cares about the secret, resolve it on next use
+			if !watched[raw] {
+				delete(m.cache, raw)
+				continue
+			}
+
+			v, err := m.fetch(e.ref)
+			if err != nil {
+				// keep the old value, and retry on next refresh
+				l.Warnf("refresh secret: %s, keep the cached value", err)
+				continue
+			}
+
+			e.expire = now.Add(m.ttl)
+			if v != e.value {
+				l.Infof("secret %s rotated", raw)
+				rotateVec.WithLabelValues(e.ref.Provider).Inc()
+				e.value = v
+				rotated[raw] = true
+			}
+		}
+
+		if len(rotated) == 0 {
+			return
+		}
+
+		for key, w := range m.watchers {
+			for _, raw := range w.refs {
+				if rotated[raw] {
+					reloads = append(reloads, reload{key: key, w: w})
+					break
+				}
+			}
+		}
+	}()
+
+	sort.Slice(reloads, func(i, j int) bool { return reloads[i].key < reloads[j].key })
+
+	for _, r := range reloads {
+		l.Infof("reload %s on secret rotation", r.key)
+		if err := r.w.cb(r.key, r.w.conf); err != nil {
+			l.Warnf("reload %s on secret rotation: %s", r.key, err)
+		}
+	}
+}
+
+// Start starts refreshing secrets in background.
+func (m *Manager) Start() {
+	m.startOnce.Do(func() {
+		g := datakit.G("secret")
+		g.Go(func(ctx context.Context) error {
+			tick := time.NewTicker(m.ttl)
+			defer tick.Stop()
+
+			for {
+				select {
+				case <-datakit.Exit.Wait():
+					l.Info("secret refresher exit")
+					return nil
+
+				case <-tick.C:
+					m.Refresh()
+				}
+			}
+		})
+	})
+}
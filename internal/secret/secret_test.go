@@ -0,0 +1,299 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package secret
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseRef(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect *Ref
+		fail   bool
+	}{
+		{
+			in:     "secret://env/MYSQL_PASSWORD",
+			expect: &Ref{Provider: "env", Path: "MYSQL_PASSWORD"},
+		},
+		{
+			in:     "secret://vault/secret/datakit/mysql#password",
+			expect: &Ref{Provider: "vault", Path: "secret/datakit/mysql", Key: "password"},
+		},
+		{
+			in:     "secret://k8s/mysql-secret#password",
+			expect: &Ref{Provider: "k8s", Path: "mysql-secret", Key: "password"},
+		},
+		{in: "secret://env", fail: true},
+		{in: "secret:///abc", fail: true},
+		{in: "secret://vault/#key", fail: true},
+		{in: "ENC[file:///abc]", fail: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			ref, err := ParseRef(tc.in)
+			if tc.fail {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			tc.expect.Raw = tc.in
+			assert.Equal(t, tc.expect, ref)
+		})
+	}
+}
+
+type fakeProvider struct {
+	values map[string]string
+	calls  int
+}
+
+func (*fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Get(_ context.Context, ref *Ref) (string, error) {
+	p.calls++
+	v, ok := p.values[ref.Path]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "mysql")
+	require.NoError(t, os.WriteFile(f, []byte("file-pass\n"), 0o600))
+
+	t.Setenv("SECRET_TEST_PASSWORD", `env"pass\`)
+
+	m := NewManager(nil)
+
+	conf := `
+[[inputs.mysql]]
+  user = "datakit"
+  pass = "secret://env/SECRET_TEST_PASSWORD"
+  pass2 = "secret://file` + filepath.ToSlash(f) + `"
+  tags = ["secret://env/SECRET_TEST_PASSWORD", "abc"]
+`
+	res, err := m.Resolve(conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, `
+[[inputs.mysql]]
+  user = "datakit"
+  pass = "env\"pass\\"
+  pass2 = "file-pass"
+  tags = ["env\"pass\\", "abc"]
+`, res)
+
+	// no secret reference
+	res, err = m.Resolve("user = 'datakit'")
+	require.NoError(t, err)
+	assert.Equal(t, "user = 'datakit'", res)
+
+	_, err = m.Resolve(`pass = "secret://env/SECRET_TEST_NOT_SET"`)
+	assert.Error(t, err)
+
+	_, err = m.Resolve(`pass = "secret://unknown/abc"`)
+	assert.Error(t, err)
+}
+
+func TestResolveStringContext(t *testing.T) {
+	t.Setenv("SECRET_TEST_PASSWORD", `a\b"c`)
+	t.Setenv("SECRET_TEST_QUOTE", `a'b`)
+
+	m := NewManager(nil)
+
+	cases := []struct {
+		name, conf, expect string
+		fail               bool
+	}{
+		{
+			name:   "basic",
+			conf:   `pass = "secret://env/SECRET_TEST_PASSWORD"`,
+			expect: `pass = "a\\b\"c"`,
+		},
+		{
+			name:   "multi-line-basic",
+			conf:   "pass = \"\"\"\nsecret://env/SECRET_TEST_PASSWORD\"\"\"",
+			expect: "pass = \"\"\"\n" + `a\\b\"c` + "\"\"\"",
+		},
+		{
+			name:   "literal",
+			conf:   `pass = 'secret://env/SECRET_TEST_PASSWORD'`,
+			expect: `pass = 'a\b"c'`,
+		},
+		{
+			name:   "multi-line-literal",
+			conf:   "pass = '''secret://env/SECRET_TEST_QUOTE'''",
+			expect: "pass = '''a'b'''",
+		},
+		{
+			name: "literal-with-quote",
+			conf: `pass = 'secret://env/SECRET_TEST_QUOTE'`,
+			fail: true,
+		},
+		{
+			name: "unquoted",
+			conf: `pass = secret://env/SECRET_TEST_PASSWORD`,
+			fail: true,
+		},
+		{
+			name:   "comment",
+			conf:   "# pass = \"secret://env/SECRET_TEST_NOT_SET\"\nuser = \"# not comment secret://env/SECRET_TEST_QUOTE\"",
+			expect: "# pass = \"secret://env/SECRET_TEST_NOT_SET\"\nuser = \"# not comment a'b\"",
+		},
+		{
+			name:   "quote-within-other-string",
+			conf:   `user = "it's", pass = 'secret://env/SECRET_TEST_PASSWORD'`,
+			expect: `user = "it's", pass = 'a\b"c'`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := m.Resolve(tc.conf)
+			if tc.fail {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, res)
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	p := &fakeProvider{values: map[string]string{"a": "a-1", "b": "b-1"}}
+
+	m := NewManager(&Config{TTL: time.Minute})
+	m.AddProvider(p)
+
+	conf1 := `pass = "secret://fake/a"`
+	conf2 := `pass = "secret://fake/b"`
+
+	res, err := m.Resolve(conf1)
+	require.NoError(t, err)
+	assert.Equal(t, `pass = "a-1"`, res)
+
+	_, err = m.Resolve(conf2)
+	require.NoError(t, err)
+
+	// cached
+	_, err = m.Resolve(conf1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.calls)
+
+	reloaded := map[string]string{}
+	cb := func(key, conf string) error {
+		reloaded[key] = conf
+		return nil
+	}
+
+	m.Watch("conf-1", conf1, cb)
+	m.Watch("conf-2", conf2, cb)
+
+	// commented reference not watched
+	m.Watch("conf-3", `# pass = "secret://fake/a"`, cb)
+	assert.NotContains(t, m.watchers, "conf-3")
+
+	// not expired
+	m.Refresh()
+	assert.Empty(t, reloaded)
+
+	// expired, but not rotated
+	expireAll(m)
+	m.Refresh()
+	assert.Empty(t, reloaded)
+	assert.Equal(t, 4, p.calls)
+
+	// rotated
+	p.values["a"] = "a-2"
+	expireAll(m)
+	m.Refresh()
+	assert.Equal(t, map[string]string{"conf-1": conf1}, reloaded)
+
+	res, err = m.Resolve(conf1)
+	require.NoError(t, err)
+	assert.Equal(t, `pass = "a-2"`, res)
+
+	// provider failed, keep the cached value
+	delete(p.values, "b")
+	expireAll(m)
+	m.Refresh()
+	assert.Equal(t, "b-1", m.cache["secret://fake/b"].value)
+
+	// not watched secret removed from cache
+	m.Unwatch("conf-1")
+	expireAll(m)
+	m.Refresh()
+	assert.NotContains(t, m.cache, "secret://fake/a")
+}
+
+func expireAll(m *Manager) {
+	for _, e := range m.cache {
+		e.expire = time.Now().Add(-time.Second)
+	}
+}
+
+type fakeSecretGetter struct {
+	secrets map[string]*corev1.Secret
+}
+
+func (g *fakeSecretGetter) Get(_ context.Context, namespace, name string) (*corev1.Secret, error) {
+	if s, ok := g.secrets[namespace+"/"+name]; ok {
+		return s, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestKubernetesProvider(t *testing.T) {
+	p := newKubernetesProvider(&KubernetesConfig{Namespace: "datakit"})
+	p.cli = &fakeSecretGetter{secrets: map[string]*corev1.Secret{
+		"datakit/mysql": {Data: map[string][]byte{"password": []byte("pass-1")}},
+		"db/mysql":      {Data: map[string][]byte{"password": []byte("pass-2")}},
+	}}
+
+	ctx := context.Background()
+
+	v, err := p.Get(ctx, &Ref{Path: "mysql", Key: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, "pass-1", v)
+
+	v, err = p.Get(ctx, &Ref{Path: "db/mysql", Key: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, "pass-2", v)
+
+	_, err = p.Get(ctx, &Ref{Path: "mysql", Key: "user"})
+	assert.Error(t, err)
+
+	_, err = p.Get(ctx, &Ref{Path: "mysql"})
+	assert.Error(t, err)
+}
+
+func TestVaultProviderRef(t *testing.T) {
+	p := newVaultProvider(nil)
+
+	for _, path := range []string{"secret", "secret/", "/datakit"} {
+		_, err := p.Get(context.Background(), &Ref{Path: path, Key: "password"})
+		assert.Error(t, err, path)
+	}
+
+	_, err := p.Get(context.Background(), &Ref{Path: "secret/datakit"})
+	assert.Error(t, err)
+}
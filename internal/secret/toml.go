@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package secret
+
+import (
+	"fmt"
+	"strings"
+)
+
+// strKind is the TOML context where a secret reference located.
+type strKind int
+
+const (
+	strBare      strKind = iota // not within any string, such as `pass = secret://...`
+	strComment                  // within comment
+	strBasic                    // "..."
+	strMLBasic                  // """..."""
+	strLiteral                  // '...'
+	strMLLiteral                // '''...'''
+)
+
+func (k strKind) String() string {
+	switch k {
+	case strBare:
+		return "bare value"
+	case strComment:
+		return "comment"
+	case strBasic:
+		return "basic string"
+	case strMLBasic:
+		return "multi-line basic string"
+	case strLiteral:
+		return "literal string"
+	case strMLLiteral:
+		return "multi-line literal string"
+	default:
+		return "unknown"
+	}
+}
+
+type span struct {
+	start, end int
+	kind       strKind
+}
+
+// refMatch is a secret reference within TOML data.
+type refMatch struct {
+	start, end int
+	kind       strKind
+}
+
+// findRefs finds all secret references within TOML data, along with the
+// string context of each of them.
+func findRefs(data string) []refMatch {
+	locs := refRe.FindAllStringIndex(data, -1)
+	if len(locs) == 0 {
+		return nil
+	}
+
+	spans := scanTOMLStrings(data)
+
+	res := make([]refMatch, 0, len(locs))
+	for _, loc := range locs {
+		m := refMatch{start: loc[0], end: loc[1], kind: strBare}
+		for _, s := range spans {
+			if s.start <= loc[0] && loc[0] < s.end {
+				m.kind = s.kind
+				break
+			}
+		}
+		res = append(res, m)
+	}
+
+	return res
+}
+
+// scanTOMLStrings returns the content span of all strings and comments within
+// data. It's not a full TOML parser, invalid TOML is left to the TOML decoder.
+func scanTOMLStrings(data string) []span {
+	var (
+		spans []span
+		i     int
+	)
+
+	for i < len(data) {
+		switch {
+		case data[i] == '#':
+			end := strings.IndexByte(data[i:], '\n')
+			if end < 0 {
+				end = len(data)
+			} else {
+				end += i
+			}
+			spans = append(spans, span{start: i, end: end, kind: strComment})
+			i = end
+
+		case strings.HasPrefix(data[i:], `"""`):
+			start := i + 3
+			end := indexUnescaped(data, start, `"""`)
+			spans = append(spans, span{start: start, end: end, kind: strMLBasic})
+			i = end + 3
+
+		case data[i] == '"':
+			start := i + 1
+			end := indexUnescaped(data, start, `"`)
+			spans = append(spans, span{start: start, end: end, kind: strBasic})
+			i = end + 1
+
+		case strings.HasPrefix(data[i:], `'''`):
+			start := i + 3
+			end := indexFrom(data, start, `'''`)
+			spans = append(spans, span{start: start, end: end, kind: strMLLiteral})
+			i = end + 3
+
+		case data[i] == '\'':
+			start := i + 1
+			end := indexFrom(data, start, `'`)
+			spans = append(spans, span{start: start, end: end, kind: strLiteral})
+			i = end + 1
+
+		default:
+			i++
+		}
+	}
+
+	return spans
+}
+
+// indexFrom returns the index of sep within data[from:], or len(data) if not found.
+func indexFrom(data string, from int, sep string) int {
+	if idx := strings.Index(data[from:], sep); idx >= 0 {
+		return from + idx
+	}
+	return len(data)
+}
+
+// indexUnescaped is same as indexFrom, but skips the backslash escaped chars.
+func indexUnescaped(data string, from int, sep string) int {
+	for i := from; i < len(data); i++ {
+		switch {
+		case data[i] == '\\':
+			i++
+		case strings.HasPrefix(data[i:], sep):
+			return i
+		}
+	}
+	return len(data)
+}
+
+// escapeValue escapes the resolved secret value according to the string
+// context of the reference.
+func escapeValue(kind strKind, raw, v string) (string, error) {
+	switch kind {
+	case strBasic, strMLBasic:
+		return valueEscaper.Replace(v), nil
+
+	case strLiteral: // literal string got no escaping, so these chars not allowed
+		if strings.ContainsAny(v, "'\r\n") {
+			return "", fmt.Errorf("value of %s contains single quote or newline, not allowed within %s, use basic string instead",
+				raw, kind)
+		}
+		return v, nil
+
+	case strMLLiteral:
+		if strings.Contains(v, "'''") {
+			return "", fmt.Errorf("value of %s contains ''', not allowed within %s, use basic string instead", raw, kind)
+		}
+		return v, nil
+
+	case strBare:
+		return "", fmt.Errorf("secret reference %s within %s not allowed, it should be quoted", raw, kind)
+
+	default:
+		return "", fmt.Errorf("secret reference %s within %s not allowed", raw, kind)
+	}
+}
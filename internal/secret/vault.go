@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// VaultConfig is the configure of HashiCorp Vault KV provider. Environments
+// of Vault client(such as VAULT_ADDR/VAULT_TOKEN/VAULT_CACERT) also work.
+type VaultConfig struct {
+	Address   string `toml:"address"`
+	Token     string `toml:"token"`
+	TokenFile string `toml:"token_file"` // re-read on each request, for token renewed by Vault Agent
+	Namespace string `toml:"namespace"`
+	KVVersion int    `toml:"kv_version"` // KV secrets engine version, 1 or 2(default)
+}
+
+// vaultProvider reads secret from Vault KV, such as secret://vault/<mount>/<path>#<key>.
+type vaultProvider struct {
+	cfg *VaultConfig
+
+	mu  sync.Mutex
+	cli *vault.Client
+}
+
+func newVaultProvider(cfg *VaultConfig) *vaultProvider {
+	if cfg == nil {
+		cfg = &VaultConfig{}
+	}
+	return &vaultProvider{cfg: cfg}
+}
+
+func (*vaultProvider) Name() string { return "vault" }
+
+func (p *vaultProvider) client() (*vault.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cli == nil {
+		conf := vault.DefaultConfig()
+		if conf.Error != nil {
+			return nil, conf.Error
+		}
+
+		if p.cfg.Address != "" {
+			conf.Address = p.cfg.Address
+		}
+
+		cli, err := vault.NewClient(conf)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.cfg.Namespace != "" {
+			cli.SetNamespace(p.cfg.Namespace)
+		}
+
+		if p.cfg.Token != "" {
+			cli.SetToken(p.cfg.Token)
+		}
+
+		p.cli = cli
+	}
+
+	if p.cfg.TokenFile != "" {
+		token, err := os.ReadFile(filepath.Clean(p.cfg.TokenFile))
+		if err != nil {
+			return nil, fmt.Errorf("read vault token: %w", err)
+		}
+		p.cli.SetToken(strings.TrimSpace(string(token)))
+	}
+
+	return p.cli, nil
+}
+
+func (p *vaultProvider) Get(ctx context.Context, ref *Ref) (string, error) {
+	mount, path, ok := strings.Cut(ref.Path, "/")
+	if !ok || mount == "" || path == "" || ref.Key == "" {
+		return "", fmt.Errorf("should be secret://vault/<mount>/<path>#<key>")
+	}
+
+	cli, err := p.client()
+	if err != nil {
+		return "", err
+	}
+
+	var s *vault.KVSecret
+	if p.cfg.KVVersion == 1 {
+		s, err = cli.KVv1(mount).Get(ctx, path)
+	} else {
+		s, err = cli.KVv2(mount).Get(ctx, path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := s.Data[ref.Key]
+	if !ok || v == nil {
+		return "", fmt.Errorf("key %q not found", ref.Key)
+	}
+
+	return fmt.Sprintf("%v", v), nil
+}
@@ -62,6 +62,14 @@ type Collector struct {
 	timings       map[string]cachedtimings
 	distributions []cacheddistributions
 
+	// DogStatsD service checks received since last gather, and the latest
+	// state of every service check.
+	serviceChecks      []*serviceCheck
+	serviceCheckStates map[string]*serviceCheck
+
+	// resolve the container of the sender over unix socket, nil if disabled
+	origin *originResolver
+
 	// bucket -> influx templates
 	Templates []string // NOTE: Deprecated
 
@@ -84,6 +92,9 @@ type job struct {
 	*bytes.Buffer
 	time.Time
 	Addr string
+
+	// Tags of the sender's origin, such as container_id.
+	Tags map[string]string
 }
 
 // One statsd metric, form is <bucket>:<value>|<mtype>|@<samplerate>.
@@ -325,6 +336,11 @@ func NewCollector(udplistener *net.UDPConn, tcplistener *net.TCPListener, collec
 	col.sets = make(map[string]cachedset)
 	col.timings = make(map[string]cachedtimings)
 	col.distributions = make([]cacheddistributions, 0)
+	col.serviceCheckStates = make(map[string]*serviceCheck)
+
+	if col.opts.originDetection {
+		col.origin = newOriginResolver()
+	}
 
 	col.Lock()
 	defer col.Unlock()
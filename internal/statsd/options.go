@@ -38,6 +38,7 @@ type option struct {
 	maxTCPConnections      int
 	tcpKeepAlive           bool
 	maxTTL                 time.Duration
+	originDetection        bool
 
 	l *logger.Logger
 }
@@ -150,6 +151,12 @@ func WithMaxTTL(args time.Duration) CollectorOption {
 	}
 }
 
+// WithOriginDetection enable tagging the data received over unix socket with the
+// container_id/pod_uid of the sender, linux only.
+func WithOriginDetection(args bool) CollectorOption {
+	return func(opt *option) { opt.originDetection = args }
+}
+
 func WithLogger(args *logger.Logger) CollectorOption {
 	return func(opt *option) { opt.l = args }
 }
@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package statsd
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	originCacheTTL     = time.Minute
+	originCacheMaxSize = 4096
+)
+
+var (
+	// Container IDs are 64 hex chars for docker/containerd/cri-o, such as
+	//   /docker/<id>
+	//   /kubepods/burstable/pod<uid>/<id>
+	//   /kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<uid>.slice/cri-containerd-<id>.scope
+	cgroupContainerIDRegexp = regexp.MustCompile(`(?:^|[/\-.:])([0-9a-f]{64})(?:\.scope)?$`)
+
+	// Pod UID in cgroupfs(dash) or systemd(underscore) style.
+	cgroupPodUIDRegexp = regexp.MustCompile(
+		`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+)
+
+type originEntry struct {
+	tags      map[string]string
+	expiresAt time.Time
+	elem      *list.Element
+}
+
+// originResolver resolve the container of the process which sending data over
+// the unix socket, the pid of the process comes from the socket credentials.
+type originResolver struct {
+	procDir string
+
+	mtx   sync.Mutex
+	cache map[int32]*originEntry
+	order list.List // pids in order of caching, also the order of expiring
+}
+
+func newOriginResolver() *originResolver {
+	procDir := os.Getenv("HOST_PROC")
+	if procDir == "" {
+		procDir = "/proc"
+	}
+
+	return &originResolver{
+		procDir: procDir,
+		cache:   map[int32]*originEntry{},
+	}
+}
+
+// tags returns the container_id/pod_uid tags of the process pid, or nil if the
+// process not running in container. The result is cached for a while, because
+// the pid may be reused by another process.
+func (r *originResolver) tags(pid int32) map[string]string {
+	if r == nil || pid <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if e, ok := r.cache[pid]; ok {
+		if now.Before(e.expiresAt) {
+			return e.tags
+		}
+		r.order.Remove(e.elem)
+		delete(r.cache, pid)
+	}
+
+	var tags map[string]string
+	if data, err := os.ReadFile(filepath.Join(r.procDir, strconv.Itoa(int(pid)), "cgroup")); err == nil {
+		tags = parseCgroupOrigin(string(data))
+	}
+
+	r.evict(now)
+
+	e := &originEntry{tags: tags, expiresAt: now.Add(originCacheTTL)}
+	e.elem = r.order.PushBack(pid)
+	r.cache[pid] = e
+	return tags
+}
+
+// evict removes the expired entries, and the oldest ones if the cache is full.
+// All entries got the same TTL, so only the oldest entries are checked.
+func (r *originResolver) evict(now time.Time) {
+	for front := r.order.Front(); front != nil; front = r.order.Front() {
+		pid, _ := front.Value.(int32)
+		if e, ok := r.cache[pid]; ok && len(r.cache) < originCacheMaxSize && now.Before(e.expiresAt) {
+			return
+		}
+
+		r.order.Remove(front)
+		delete(r.cache, pid)
+	}
+}
+
+// parseCgroupOrigin parse the container ID and pod UID from the content of
+// /proc/<pid>/cgroup, both cgroup v1 and v2 are supported. Only the IDs within
+// the cgroup path are available, the pod name/namespace are not resolved.
+func parseCgroupOrigin(content string) map[string]string {
+	var containerID, podUID string
+
+	for _, line := range strings.Split(content, "\n") {
+		// like hierarchy-ID:subsystem:cgroup_path
+		items := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(items) != 3 {
+			continue
+		}
+		path := items[2]
+
+		if containerID == "" {
+			if m := cgroupContainerIDRegexp.FindStringSubmatch(path); len(m) == 2 {
+				containerID = m[1]
+			}
+		}
+
+		if podUID == "" {
+			if m := cgroupPodUIDRegexp.FindStringSubmatch(path); len(m) == 2 {
+				podUID = strings.ReplaceAll(m[1], "_", "-")
+			}
+		}
+
+		if containerID != "" && podUID != "" {
+			break
+		}
+	}
+
+	if containerID == "" {
+		return nil
+	}
+
+	tags := map[string]string{"container_id": containerID}
+	if podUID != "" {
+		tags["pod_uid"] = podUID
+	}
+	return tags
+}
@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package statsd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testContainerID = "3f4c1f0e6d5a4b2c9e8d7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c"
+
+func TestParseCgroupOrigin(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		expect  map[string]string
+	}{
+		{
+			name:    "docker",
+			content: "12:memory:/docker/" + testContainerID + "\n11:cpu:/docker/" + testContainerID,
+			expect:  map[string]string{"container_id": testContainerID},
+		},
+		{
+			name: "k8s-cgroupfs",
+			content: "12:memory:/kubepods/burstable/pod5a3e1c2b-7d4f-4e6a-9b8c-1d2e3f4a5b6c/" + testContainerID + "\n" +
+				"1:name=systemd:/kubepods/burstable/pod5a3e1c2b-7d4f-4e6a-9b8c-1d2e3f4a5b6c/" + testContainerID,
+			expect: map[string]string{"container_id": testContainerID, "pod_uid": "5a3e1c2b-7d4f-4e6a-9b8c-1d2e3f4a5b6c"},
+		},
+		{
+			name: "k8s-systemd-cgroup-v2",
+			content: "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod5a3e1c2b_7d4f_4e6a_9b8c_1d2e3f4a5b6c.slice/" +
+				"cri-containerd-" + testContainerID + ".scope",
+			expect: map[string]string{"container_id": testContainerID, "pod_uid": "5a3e1c2b-7d4f-4e6a-9b8c-1d2e3f4a5b6c"},
+		},
+		{
+			name:    "host-process",
+			content: "0::/system.slice/sshd.service",
+			expect:  nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, parseCgroupOrigin(tc.content))
+		})
+	}
+}
+
+func TestOriginResolver(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "100"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "100", "cgroup"),
+		[]byte("0::/docker/"+testContainerID+"\n"), 0o600))
+
+	r := &originResolver{procDir: dir, cache: map[int32]*originEntry{}}
+
+	assert.Equal(t, map[string]string{"container_id": testContainerID}, r.tags(100))
+	assert.Nil(t, r.tags(200))
+	assert.Nil(t, r.tags(0))
+	assert.Len(t, r.cache, 2)
+
+	// disabled
+	var nilResolver *originResolver
+	assert.Nil(t, nilResolver.tags(100))
+
+	t.Run("max-size", func(t *testing.T) {
+		r := &originResolver{procDir: dir, cache: map[int32]*originEntry{}}
+
+		for pid := int32(1); pid <= originCacheMaxSize+10; pid++ {
+			r.tags(pid)
+		}
+
+		assert.Len(t, r.cache, originCacheMaxSize)
+		assert.Equal(t, originCacheMaxSize, r.order.Len())
+
+		// the oldest evicted
+		assert.NotContains(t, r.cache, int32(1))
+		assert.Contains(t, r.cache, int32(originCacheMaxSize+10))
+	})
+}
@@ -35,12 +35,16 @@ func (col *Collector) parser(idx int) {
 
 				switch {
 				case line == "": // pass
+				case col.opts.dataDogExtensions && strings.HasPrefix(line, "_sc|"):
+					if err := col.parseServiceCheckMessage(in.Time, line, in.Tags); err != nil {
+						col.opts.l.Warnf("[%d] parseServiceCheckMessage: %s, ignored", idx, err.Error())
+					}
 				case col.opts.dataDogExtensions && strings.HasPrefix(line, "_e"):
 					if err := col.parseEventMessage(in.Time, line, in.Addr); err != nil {
 						col.opts.l.Warnf("[%d] parseEventMessage: %s, ignored", idx, err.Error())
 					}
 				default:
-					if err := col.parseStatsdLine(line); err != nil {
+					if err := col.parseStatsdLineWithOrigin(line, in.Tags); err != nil {
 						col.opts.l.Warnf("[%d] parseEventMessage: %s, ignored", idx, err.Error())
 					}
 				}
@@ -52,6 +56,12 @@ func (col *Collector) parser(idx int) {
 // parseStatsdLine will parse the given statsd line, validating it as it goes.
 // If the line is valid, it will be cached for the next call to Gather().
 func (col *Collector) parseStatsdLine(line string) error {
+	return col.parseStatsdLineWithOrigin(line, nil)
+}
+
+// parseStatsdLineWithOrigin is parseStatsdLine with the tags of the sender's
+// origin, the tags in the line take precedence.
+func (col *Collector) parseStatsdLineWithOrigin(line string, originTags map[string]string) error {
 	lineTags := make(map[string]string)
 	if col.opts.dataDogExtensions {
 		recombinedSegments := make([]string, 0)
@@ -75,6 +85,12 @@ func (col *Collector) parseStatsdLine(line string) error {
 		line = strings.Join(recombinedSegments, "|")
 	}
 
+	for k, v := range originTags {
+		if _, ok := lineTags[k]; !ok {
+			lineTags[k] = v
+		}
+	}
+
 	// Validate splitting the line on ":"
 	bits := strings.Split(line, ":")
 	if len(bits) < 2 {
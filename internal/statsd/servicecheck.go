@@ -0,0 +1,226 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package statsd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+)
+
+const (
+	serviceCheckOK = iota
+	serviceCheckWarning
+	serviceCheckCritical
+	serviceCheckUnknown
+
+	serviceCheckMeasurement = "statsd_service_check"
+
+	// service checks not updated within the TTL are no longer reported if
+	// max_ttl not configured.
+	defaultServiceCheckTTL = time.Hour
+)
+
+var (
+	serviceCheckStatus = []string{"ok", "warning", "critical", "unknown"}
+
+	// df_status of the keyevent, there is no unknown status in keyevent.
+	serviceCheckDFStatus = []string{"ok", "warning", "critical", "info"}
+
+	serviceCheckUncommenter = strings.NewReplacer("\\n", "\n", "m\\:", "m:")
+)
+
+type serviceCheck struct {
+	name       string
+	status     int
+	hostname   string
+	message    string
+	tags       map[string]string
+	ts         time.Time
+	receivedAt time.Time
+}
+
+// key identify the service check by its name, hostname and tags.
+func (sc *serviceCheck) key() string {
+	parts := make([]string, 0, len(sc.tags)+2)
+	parts = append(parts, sc.name)
+	if sc.hostname != "" {
+		parts = append(parts, "host="+sc.hostname)
+	}
+
+	tags := make([]string, 0, len(sc.tags))
+	for k, v := range sc.tags {
+		tags = append(tags, k+"="+v)
+	}
+	sort.Strings(tags)
+
+	return strings.Join(append(parts, tags...), ",")
+}
+
+func (col *Collector) parseServiceCheckMessage(now time.Time, message string, originTags map[string]string) error {
+	// _sc|name|status
+	//  [
+	//   |d:timestamp
+	//   |h:hostname
+	//   |#tag1:value1,tag2
+	//   |m:service_check_message
+	//  ]
+	//
+	// the message(m:) should be the last field, it may contain '|'.
+	parts := strings.SplitN(message, "|", 4)
+	if len(parts) < 3 || parts[0] != "_sc" || parts[1] == "" {
+		return fmt.Errorf("invalid service check format")
+	}
+
+	status, err := strconv.Atoi(parts[2])
+	if err != nil || status < serviceCheckOK || status > serviceCheckUnknown {
+		return fmt.Errorf("invalid service check status: '%s'", parts[2])
+	}
+
+	sc := &serviceCheck{
+		name:       parts[1],
+		status:     status,
+		tags:       map[string]string{},
+		ts:         now,
+		receivedAt: now,
+	}
+
+	if len(parts) == 4 {
+		rest := parts[3]
+		for rest != "" {
+			if strings.HasPrefix(rest, "m:") {
+				sc.message = serviceCheckUncommenter.Replace(rest[2:])
+				break
+			}
+
+			var field string
+			field, rest, _ = strings.Cut(rest, "|")
+
+			switch {
+			case strings.HasPrefix(field, "d:"):
+				sec, err := strconv.ParseInt(field[2:], 10, 64)
+				if err != nil {
+					continue
+				}
+				sc.ts = time.Unix(sec, 0)
+			case strings.HasPrefix(field, "h:"):
+				sc.hostname = field[2:]
+			case strings.HasPrefix(field, "#"):
+				parseDataDogTags(sc.tags, field[1:])
+			default:
+				return fmt.Errorf("unknown metadata type: '%s'", field)
+			}
+		}
+	}
+
+	// In datadog the host tag and `h:` are interchangeable.
+	if host, ok := sc.tags["host"]; ok {
+		delete(sc.tags, "host")
+		if sc.hostname == "" {
+			sc.hostname = host
+		}
+	}
+
+	for k, v := range originTags {
+		if _, ok := sc.tags[k]; !ok {
+			sc.tags[k] = v
+		}
+	}
+
+	col.Lock()
+	defer col.Unlock()
+
+	col.serviceChecks = append(col.serviceChecks, sc)
+	col.serviceCheckStates[sc.key()] = sc
+	return nil
+}
+
+// GetServiceCheckPoints returns the service checks received since the last call
+// as keyevent points, and the latest state of every service check as object points.
+func (col *Collector) GetServiceCheckPoints() (events, objects []*point.Point) {
+	col.Lock()
+	defer col.Unlock()
+
+	for _, sc := range col.serviceChecks {
+		events = append(events, col.serviceCheckEvent(sc))
+	}
+	col.serviceChecks = col.serviceChecks[:0]
+
+	ttl := col.opts.maxTTL
+	if ttl <= 0 {
+		ttl = defaultServiceCheckTTL
+	}
+
+	now := time.Now()
+	for key, sc := range col.serviceCheckStates {
+		if now.Sub(sc.receivedAt) > ttl {
+			delete(col.serviceCheckStates, key)
+			continue
+		}
+		objects = append(objects, col.serviceCheckObject(key, sc))
+	}
+
+	return events, objects
+}
+
+func (col *Collector) serviceCheckTags(sc *serviceCheck) point.KVs {
+	tags := make(map[string]string, len(sc.tags)+len(col.opts.tags)+2)
+	for k, v := range sc.tags {
+		tags[k] = v
+	}
+	for k, v := range col.opts.tags {
+		tags[k] = v // may override tags in real-data
+	}
+	for _, t := range col.opts.dropTags {
+		delete(tags, t)
+	}
+
+	tags["check"] = sc.name
+	if sc.hostname != "" {
+		tags["host"] = sc.hostname
+	}
+
+	return point.NewTags(tags)
+}
+
+func (col *Collector) serviceCheckEvent(sc *serviceCheck) *point.Point {
+	kvs := col.serviceCheckTags(sc)
+	kvs = kvs.AddTag("df_source", "statsd")
+	kvs = kvs.AddTag("df_status", serviceCheckDFStatus[sc.status])
+	kvs = kvs.AddTag("df_sub_status", serviceCheckDFStatus[sc.status])
+
+	title := fmt.Sprintf("Service check %s is %s", sc.name, serviceCheckStatus[sc.status])
+	if sc.hostname != "" {
+		title += " on " + sc.hostname
+	}
+
+	msg := sc.message
+	if msg == "" {
+		msg = title
+	}
+
+	kvs = kvs.Add("df_title", title)
+	kvs = kvs.Add("df_message", msg)
+	kvs = kvs.Add("status", int64(sc.status))
+
+	return point.NewPoint(serviceCheckMeasurement, kvs,
+		append(point.DefaultLoggingOptions(), point.WithTime(sc.ts))...)
+}
+
+func (col *Collector) serviceCheckObject(key string, sc *serviceCheck) *point.Point {
+	kvs := col.serviceCheckTags(sc)
+	kvs = kvs.AddTag("name", key)
+	kvs = kvs.Add("status", int64(sc.status))
+	kvs = kvs.Add("status_text", serviceCheckStatus[sc.status])
+	kvs = kvs.Add("message", sc.message)
+	kvs = kvs.Add("last_check_time", sc.ts.UnixMilli())
+
+	return point.NewPoint(serviceCheckMeasurement, kvs, point.DefaultObjectOptions()...)
+}
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package statsd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServiceCheckMessage(t *testing.T) {
+	newCollector := func() *Collector {
+		return &Collector{
+			opts: &option{
+				l:        logger.DefaultSLogger("test"),
+				tags:     map[string]string{"env": "test"},
+				dropTags: []string{"runtime-id"},
+			},
+			serviceCheckStates: map[string]*serviceCheck{},
+		}
+	}
+
+	now := time.Now()
+
+	t.Run("full", func(t *testing.T) {
+		col := newCollector()
+		require.NoError(t, col.parseServiceCheckMessage(now,
+			`_sc|db.can_connect|2|d:1700000100|h:db-1|#db:main,runtime-id:123|m:cannot connect\nm\:timeout|retry later`,
+			map[string]string{"container_id": "abc", "db": "origin"}))
+
+		require.Len(t, col.serviceChecks, 1)
+		sc := col.serviceChecks[0]
+		assert.Equal(t, "db.can_connect", sc.name)
+		assert.Equal(t, serviceCheckCritical, sc.status)
+		assert.Equal(t, "db-1", sc.hostname)
+		assert.Equal(t, "cannot connect\nm:timeout|retry later", sc.message)
+		assert.Equal(t, int64(1700000100), sc.ts.Unix())
+		assert.Equal(t, map[string]string{"db": "main", "runtime-id": "123", "container_id": "abc"}, sc.tags)
+
+		events, objects := col.GetServiceCheckPoints()
+		require.Len(t, events, 1)
+		require.Len(t, objects, 1)
+
+		ev := events[0]
+		assert.Equal(t, serviceCheckMeasurement, ev.Name())
+		assert.Equal(t, "critical", ev.GetTag("df_status"))
+		assert.Equal(t, "db.can_connect", ev.GetTag("check"))
+		assert.Equal(t, "db-1", ev.GetTag("host"))
+		assert.Equal(t, "test", ev.GetTag("env"))
+		assert.Equal(t, "", ev.GetTag("runtime-id"))
+		assert.Equal(t, "cannot connect\nm:timeout|retry later", ev.Get("df_message"))
+		assert.Equal(t, int64(2), ev.Get("status"))
+		assert.Equal(t, int64(1700000100), ev.Time().Unix())
+
+		obj := objects[0]
+		assert.Equal(t, "db.can_connect,host=db-1,container_id=abc,db=main,runtime-id=123", obj.GetTag("name"))
+		assert.Equal(t, "critical", obj.Get("status_text"))
+
+		// events are consumed, the states are kept
+		events, objects = col.GetServiceCheckPoints()
+		assert.Len(t, events, 0)
+		assert.Len(t, objects, 1)
+	})
+
+	t.Run("latest-state", func(t *testing.T) {
+		col := newCollector()
+		require.NoError(t, col.parseServiceCheckMessage(now, `_sc|app.health|2|#host:web-1`, nil))
+		require.NoError(t, col.parseServiceCheckMessage(now, `_sc|app.health|0|h:web-1`, nil))
+
+		events, objects := col.GetServiceCheckPoints()
+		assert.Len(t, events, 2)
+		require.Len(t, objects, 1)
+		assert.Equal(t, int64(serviceCheckOK), objects[0].Get("status"))
+		assert.Equal(t, "Service check app.health is ok on web-1", events[1].Get("df_message"))
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		col := newCollector()
+		require.NoError(t, col.parseServiceCheckMessage(now.Add(-2*defaultServiceCheckTTL), `_sc|app.health|0`, nil))
+
+		// expired within the default TTL
+		_, objects := col.GetServiceCheckPoints()
+		assert.Len(t, objects, 0)
+		assert.Len(t, col.serviceCheckStates, 0)
+
+		col.opts.maxTTL = 3 * defaultServiceCheckTTL
+		require.NoError(t, col.parseServiceCheckMessage(now.Add(-2*defaultServiceCheckTTL), `_sc|app.health|0`, nil))
+		_, objects = col.GetServiceCheckPoints()
+		assert.Len(t, objects, 1)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		col := newCollector()
+		for _, msg := range []string{
+			`_sc|app.health`,
+			`_sc||0`,
+			`_sc|app.health|4`,
+			`_sc|app.health|ok`,
+			`_sc|app.health|0|x:unknown`,
+		} {
+			assert.Error(t, col.parseServiceCheckMessage(now, msg, nil), msg)
+		}
+		assert.Len(t, col.serviceChecks, 0)
+	})
+}
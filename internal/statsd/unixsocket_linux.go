@@ -17,6 +17,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"golang.org/x/sys/unix"
 )
 
 func (col *Collector) setupUnixServer() error {
@@ -55,6 +57,15 @@ func (col *Collector) unixListen(conn *net.UnixConn) error {
 		}
 	}
 
+	var oob []byte
+	if col.origin != nil {
+		if err := enablePassCred(conn); err != nil {
+			col.opts.l.Warnf("enable origin detection failed: %s, ignored", err)
+		} else {
+			oob = make([]byte, unix.CmsgSpace(unix.SizeofUcred))
+		}
+	}
+
 	buf := make([]byte, UDPMaxPacketSize)
 	for {
 		select {
@@ -62,8 +73,8 @@ func (col *Collector) unixListen(conn *net.UnixConn) error {
 			col.opts.l.Infof("unixListen exit on done chan")
 			return nil
 		default:
-			n, addr, err := conn.ReadFromUnix(buf)
-			col.opts.l.Debugf("Get conn.ReadFromUDP(buf) bytes: %d %v", n, err)
+			n, oobn, _, addr, err := conn.ReadMsgUnix(buf, oob)
+			col.opts.l.Debugf("Get conn.ReadMsgUnix(buf) bytes: %d %v", n, err)
 			if err != nil {
 				if !strings.Contains(err.Error(), "closed network") {
 					col.opts.l.Errorf("Error reading: %s", err.Error())
@@ -89,6 +100,7 @@ func (col *Collector) unixListen(conn *net.UnixConn) error {
 				Buffer: b,
 				Time:   time.Now(),
 				Addr:   addr.String(),
+				Tags:   col.origin.tags(credentialsPID(oob[:oobn])),
 			}:
 			default:
 				col.dropsUnix++
@@ -133,3 +145,39 @@ func initUnixgramListener(udsPath string) (*net.UnixConn, error) {
 		return nil, fmt.Errorf("uds path %s is not absolute", udsPath)
 	}
 }
+
+// enablePassCred enable SO_PASSCRED on conn, then the credentials of the sender
+// are attached to every message.
+func enablePassCred(conn *net.UnixConn) error {
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	if err := rc.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_PASSCRED, 1)
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
+
+// credentialsPID returns the pid of the sender from the socket control message.
+func credentialsPID(oob []byte) int32 {
+	if len(oob) == 0 {
+		return 0
+	}
+
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return 0
+	}
+
+	for i := range msgs {
+		if cred, err := unix.ParseUnixCredentials(&msgs[i]); err == nil {
+			return cred.Pid
+		}
+	}
+	return 0
+}
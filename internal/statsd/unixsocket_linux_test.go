@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+//go:build linux
+// +build linux
+
+package statsd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestCredentialsPID(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "statsd.sock")
+
+	conn, err := initUnixgramListener(sock)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck
+
+	require.NoError(t, enablePassCred(conn))
+
+	client, err := net.Dial("unixgram", sock)
+	require.NoError(t, err)
+	defer client.Close() //nolint:errcheck
+
+	_, err = client.Write([]byte("a.b:1|c"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	oob := make([]byte, unix.CmsgSpace(unix.SizeofUcred))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	require.NoError(t, err)
+
+	assert.Equal(t, "a.b:1|c", string(buf[:n]))
+	assert.Equal(t, int32(os.Getpid()), credentialsPID(oob[:oobn]))
+	assert.Equal(t, int32(0), credentialsPID(nil))
+}
@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package tailer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/logtail/openfile"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/logtail/recorder"
+)
+
+const compressedLogText = `2024-01-01 00:00:01 first line
+2024-01-01 00:00:02 panic: oops
+	at main.go:10
+	at main.go:20
+2024-01-01 00:00:03 last line without newline`
+
+func writeCompressed(t *testing.T, path string, typ openfile.CompressType, data []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	switch typ {
+	case openfile.CompressGzip:
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	case openfile.CompressZstd:
+		w, err := zstd.NewWriter(&buf)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	default:
+		buf.Write(data)
+	}
+
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+}
+
+func runCompressed(t *testing.T, path string) (*Single, []*point.Point) {
+	t.Helper()
+
+	feeder := dkio.NewMockedFeeder()
+	single, err := NewTailerSingle(path,
+		WithSource("compressed"),
+		WithFeeder(feeder),
+		EnableMultiline(true),
+		WithMultilinePatterns([]string{`^\d{4}-\d{2}-\d{2}`}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	single.Run(ctx) // exit on file end
+	require.NoError(t, ctx.Err())
+
+	var all []*point.Point
+	for {
+		pts, err := feeder.AnyPoints(100 * time.Millisecond)
+		if err != nil {
+			return single, all
+		}
+		all = append(all, pts...)
+	}
+}
+
+func TestSingleCompressed(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, recorder.Init(filepath.Join(dir, "logtail.history")))
+
+	for _, typ := range []openfile.CompressType{openfile.CompressGzip, openfile.CompressZstd} {
+		t.Run(string(typ), func(t *testing.T) {
+			path := filepath.Join(dir, "app.log.1."+string(typ))
+			writeCompressed(t, path, typ, []byte(compressedLogText))
+
+			single, pts := runCompressed(t, path)
+			assert.Equal(t, typ, single.compress)
+			assert.True(t, single.completed)
+
+			require.Len(t, pts, 3)
+			assert.Equal(t, "2024-01-01 00:00:01 first line", pts[0].Get("message"))
+			assert.Equal(t, "2024-01-01 00:00:02 panic: oops\n\tat main.go:10\n\tat main.go:20", pts[1].Get("message"))
+			assert.Equal(t, "2024-01-01 00:00:03 last line without newline", pts[2].Get("message"))
+
+			data := recorder.Get(openfile.FileKey(path))
+			require.NotNil(t, data)
+			assert.True(t, data.Completed)
+
+			// never read again
+			single, pts = runCompressed(t, path)
+			assert.True(t, single.completed)
+			assert.Empty(t, pts)
+		})
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		path := filepath.Join(dir, "resume.log.gz")
+		writeCompressed(t, path, openfile.CompressGzip, []byte(compressedLogText))
+
+		require.NoError(t, recorder.Set(openfile.FileKey(path), &recorder.MetaData{Source: "compressed", Offset: 31}))
+
+		_, pts := runCompressed(t, path)
+		require.Len(t, pts, 2)
+		assert.Equal(t, "2024-01-01 00:00:02 panic: oops\n\tat main.go:10\n\tat main.go:20", pts[0].Get("message"))
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		path := filepath.Join(dir, "truncated.log.gz")
+		writeCompressed(t, path, openfile.CompressGzip, bytes.Repeat([]byte("2024-01-01 00:00:01 some log\n"), 1000))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data[:len(data)-8], 0o600)) // drop the gzip trailer
+
+		single, _ := runCompressed(t, path)
+		assert.False(t, single.completed)
+
+		md := recorder.Get(openfile.FileKey(path))
+		assert.True(t, md == nil || !md.Completed)
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		path := filepath.Join(dir, "plain.log")
+		writeCompressed(t, path, openfile.CompressNone, []byte(compressedLogText))
+
+		single, err := NewTailerSingle(path, WithSource("compressed"))
+		require.NoError(t, err)
+		assert.Equal(t, openfile.CompressNone, single.compress)
+		single.closeFile()
+	})
+}
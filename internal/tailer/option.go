@@ -12,12 +12,17 @@ import (
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/encoding"
 	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/logtail/multiline"
+	dknet "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/net"
 )
 
 // config 日志采集器的配置结构体.
 type config struct {
 	// 网络套接字配置
 	sockets []string
+	// 套接字数据格式，支持 raw（按行分割的文本）、syslog（RFC 3164/5424）
+	socketFormat string
+	// tls:// 套接字的 TLS 配置
+	socketTLS *dknet.TLSClientConfig
 	// 忽略的文件模式，支持通配符
 	ignorePatterns []string
 	// 数据源名称，用于标识日志来源
@@ -124,6 +129,14 @@ func WithSockets(arr []string) Option {
 	return func(cfg *config) { cfg.sockets = arr }
 }
 
+func WithSocketFormat(s string) Option {
+	return func(cfg *config) { cfg.socketFormat = s }
+}
+
+func WithSocketTLS(c *dknet.TLSClientConfig) Option {
+	return func(cfg *config) { cfg.socketTLS = c }
+}
+
 func WithIgnorePatterns(arr []string) Option {
 	return func(cfg *config) { cfg.ignorePatterns = arr }
 }
@@ -8,10 +8,14 @@ package tailer
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/GuanceCloud/cliutils/logger"
 	"github.com/GuanceCloud/cliutils/point"
@@ -77,6 +81,13 @@ func (sk *SocketLogger) setup() error {
 		}
 	}
 
+	switch sk.cfg.socketFormat {
+	case "", SocketFormatRaw, SocketFormatSyslog:
+	default:
+		return fmt.Errorf("invalid socket format %q, only %s/%s supported",
+			sk.cfg.socketFormat, SocketFormatRaw, SocketFormatSyslog)
+	}
+
 	if err := sk.makeServer(); err != nil {
 		sk.log.Warn(err)
 		return err
@@ -104,7 +115,19 @@ func (sk *SocketLogger) makeServer() error {
 
 		switch scheme {
 		case "tcp", "tcp4", "tcp6":
-			srv, err := newTCPServer(scheme, address, sk.cfg)
+			srv, err := newTCPServer(scheme, address, sk.cfg, nil)
+			if err != nil {
+				return fmt.Errorf("%s-socket listen port error: %w", scheme, err)
+			}
+			sk.servers = append(sk.servers, srv)
+
+		case "tls":
+			tlsConf, err := sk.cfg.socketTLS.TLSServerConfig()
+			if err != nil {
+				return fmt.Errorf("tls-socket config error: %w", err)
+			}
+
+			srv, err := newTCPServer("tcp", address, sk.cfg, tlsConf)
 			if err != nil {
 				return fmt.Errorf("%s-socket listen port error: %w", scheme, err)
 			}
@@ -118,7 +141,7 @@ func (sk *SocketLogger) makeServer() error {
 			sk.servers = append(sk.servers, srv)
 
 		default:
-			return fmt.Errorf("socket config like this: socket=[tcp://127.0.0.1:9540] , and please check your logging.conf")
+			return fmt.Errorf("socket config like this: socket=[tcp://127.0.0.1:9540] (tcp/udp/tls supported), and please check your logging.conf")
 		}
 	}
 
@@ -151,6 +174,12 @@ func (sk *SocketLogger) feed(pending [][]byte) {
 		if len(cnt) == 0 {
 			continue
 		}
+
+		if sk.cfg.socketFormat == SocketFormatSyslog {
+			pts = append(pts, sk.syslogPoint(cnt))
+			continue
+		}
+
 		fields := map[string]interface{}{
 			"message_length":       len(cnt),
 			constants.FieldMessage: string(cnt),
@@ -180,6 +209,67 @@ func (sk *SocketLogger) feed(pending [][]byte) {
 	}
 }
 
+// syslogPoint build point from syslog message, the raw message used if parse failed.
+func (sk *SocketLogger) syslogPoint(cnt []byte) *point.Point {
+	var (
+		kvs  = point.NewTags(sk.tags)
+		opts = point.DefaultLoggingOptions()
+	)
+
+	m, err := parseSyslog(cnt, time.Now())
+	if err != nil {
+		sk.log.Debugf("parse syslog message failed: %s, use raw message", err)
+		parseFailCounter.WithLabelValues(sk.cfg.source, "socket", SocketFormatSyslog).Inc()
+
+		kvs = kvs.Add("message_length", len(cnt)).
+			Add(constants.FieldMessage, string(cnt)).
+			Add(constants.FieldStatus, pipeline.DefaultStatus)
+
+		return point.NewPoint(sk.cfg.source, kvs, opts...)
+	}
+
+	kvs = kvs.AddTag("facility", m.facilityName())
+	if m.hostname != "" {
+		kvs = kvs.AddTag("hostname", m.hostname)
+	}
+	if m.appname != "" {
+		kvs = kvs.AddTag("app_name", m.appname)
+	}
+	if m.msgid != "" {
+		kvs = kvs.AddTag("msgid", m.msgid)
+	}
+
+	kvs = kvs.Add("message_length", len(m.message)).
+		Add(constants.FieldMessage, m.message).
+		Add(constants.FieldStatus, m.status()).
+		Add("severity", int64(m.severity)).
+		Add("version", int64(m.version))
+
+	if m.procid != "" {
+		kvs = kvs.Add("procid", m.procid)
+	}
+
+	// structured data as fields named like <SD-ID>_<PARAM-NAME>
+	for _, sd := range m.structuredData {
+		kvs = kvs.Add(syslogSDKey(sd.id, sd.name), sd.value)
+	}
+
+	if !m.timestamp.IsZero() {
+		opts = append(opts, point.WithTime(m.timestamp))
+	}
+
+	return point.NewPoint(sk.cfg.source, kvs, opts...)
+}
+
+func syslogSDKey(id, name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, id+"_"+name)
+}
+
 func buildTags(globalTags map[string]string) map[string]string {
 	tags := make(map[string]string)
 	for k, v := range globalTags {
@@ -220,11 +310,16 @@ type tcpServer struct {
 	cfg      *config
 }
 
-func newTCPServer(scheme, address string, cfg *config) (*tcpServer, error) {
+func newTCPServer(scheme, address string, cfg *config, tlsConf *tls.Config) (*tcpServer, error) {
 	listener, err := net.Listen(scheme, address)
 	if err != nil {
 		return nil, err
 	}
+
+	if tlsConf != nil {
+		listener = tls.NewListener(listener, tlsConf)
+	}
+
 	return &tcpServer{listener, cfg}, nil
 }
 
@@ -246,6 +341,11 @@ func (s *tcpServer) forwardMessage(ctx context.Context, feed func([][]byte)) err
 		socketGoroutine.Go(func(_ context.Context) error {
 			defer conn.Close() // nolint
 
+			if s.cfg.socketFormat == SocketFormatSyslog {
+				s.forwardSyslog(ctx, conn, feed)
+				return nil
+			}
+
 			rd := reader.NewReader(conn)
 			// must not error
 			mult, _ := multiline.New(s.cfg.multilinePatterns, multiline.WithMaxLength(int(s.cfg.maxMultilineLength)))
@@ -306,6 +406,43 @@ func (s *tcpServer) forwardMessage(ctx context.Context, feed func([][]byte)) err
 	}
 }
 
+// forwardSyslog forward syslog messages framed by octet-counting or LF.
+func (s *tcpServer) forwardSyslog(ctx context.Context, conn net.Conn, feed func([][]byte)) {
+	rd := newSyslogFrameReader(conn)
+
+	var decoder *encoding.Decoder
+	if s.cfg.characterEncoding != "" {
+		// must not error
+		decoder, _ = encoding.NewDecoder(s.cfg.characterEncoding)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			// next
+		}
+
+		frame, err := rd.next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				socketConnectCounter.WithLabelValues("tcp", "error").Inc()
+			}
+			return
+		}
+
+		text, err := decodingBytes(decoder, frame)
+		if err != nil {
+			decodeErrorCounter.WithLabelValues(s.cfg.source, s.cfg.characterEncoding, err.Error()).Inc()
+		}
+
+		socketMessageCounter.WithLabelValues("tcp").Inc()
+		socketLengthSummary.WithLabelValues("tcp").Observe(float64(1))
+		feed([][]byte{removeAnsiEscapeCodes(text, s.cfg.removeAnsiEscapeCodes)})
+	}
+}
+
 type udpServer struct {
 	conn net.Conn
 	cfg  *config
@@ -332,6 +469,10 @@ func (s *udpServer) close() error {
 func (s *udpServer) forwardMessage(ctx context.Context, feed func([][]byte)) error {
 	defer s.conn.Close() // nolint
 
+	if s.cfg.socketFormat == SocketFormatSyslog {
+		return s.forwardSyslog(ctx, feed)
+	}
+
 	rd := reader.NewReader(s.conn, reader.DisablePreviousBlock())
 	var decoder *encoding.Decoder
 	if s.cfg.characterEncoding != "" {
@@ -376,6 +517,47 @@ func (s *udpServer) forwardMessage(ctx context.Context, feed func([][]byte)) err
 	}
 }
 
+// forwardSyslog forward syslog messages, each datagram is a message.
+func (s *udpServer) forwardSyslog(ctx context.Context, feed func([][]byte)) error {
+	var decoder *encoding.Decoder
+	if s.cfg.characterEncoding != "" {
+		// must not error
+		decoder, _ = encoding.NewDecoder(s.cfg.characterEncoding)
+	}
+
+	buf := make([]byte, maxSyslogFrameSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			// next
+		}
+
+		n, err := s.conn.Read(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			continue
+		}
+
+		if n == 0 {
+			continue
+		}
+
+		// copy the datagram, buf reused on next read
+		text, err := decodingBytes(decoder, append([]byte(nil), buf[:n]...))
+		if err != nil {
+			decodeErrorCounter.WithLabelValues(s.cfg.source, s.cfg.characterEncoding, err.Error()).Inc()
+		}
+
+		socketMessageCounter.WithLabelValues("udp").Inc()
+		socketLengthSummary.WithLabelValues("udp").Observe(float64(1))
+		feed([][]byte{removeAnsiEscapeCodes(text, s.cfg.removeAnsiEscapeCodes)})
+	}
+}
+
 func decodingBytes(decoder *encoding.Decoder, text []byte) ([]byte, error) {
 	if decoder == nil {
 		return text, nil
@@ -0,0 +1,356 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package tailer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SocketFormatRaw    = "raw"
+	SocketFormatSyslog = "syslog"
+
+	maxSyslogFrameSize = 64 * 1024
+	// max digits of MSG-LEN within octet-counting frame, it's enough for
+	// maxSyslogFrameSize, and longer ones are rejected before reading more.
+	maxSyslogMsgLenDigits = 6
+	syslogNilValue        = "-"
+)
+
+var (
+	syslogSeverities = []string{"emerg", "alert", "critical", "error", "warning", "notice", "info", "debug"}
+
+	syslogFacilities = []string{
+		"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+		"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+		"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+	}
+
+	// TAG[PID]: of RFC 3164 message.
+	rfc3164TagRe = regexp.MustCompile(`^([^\s\[\]:]{1,48})(?:\[([^\]\s]*)\])?:\s?`)
+
+	errSyslogFrameTooLarge = errors.New("syslog frame too large")
+)
+
+type syslogSDParam struct {
+	id, name, value string
+}
+
+type syslogMessage struct {
+	facility,
+	severity,
+	version int // version 0 for RFC 3164
+
+	timestamp time.Time // zero if not set
+
+	hostname,
+	appname,
+	procid,
+	msgid,
+	message string
+
+	structuredData []*syslogSDParam
+}
+
+func (m *syslogMessage) facilityName() string {
+	if m.facility < len(syslogFacilities) {
+		return syslogFacilities[m.facility]
+	}
+	return strconv.Itoa(m.facility)
+}
+
+func (m *syslogMessage) status() string {
+	return syslogSeverities[m.severity]
+}
+
+// parseSyslog parse RFC 5424 or RFC 3164 message. For RFC 3164 message without year
+// in timestamp, the year is guessed on now.
+func parseSyslog(data []byte, now time.Time) (*syslogMessage, error) {
+	s := string(bytes.TrimRight(data, "\r\n\x00"))
+
+	if len(s) < 3 || s[0] != '<' {
+		return nil, fmt.Errorf("missing PRI")
+	}
+
+	end := strings.IndexByte(s, '>')
+	if end < 2 || end > 4 {
+		return nil, fmt.Errorf("invalid PRI")
+	}
+
+	pri, err := strconv.Atoi(s[1:end])
+	if err != nil || pri < 0 || pri > 191 {
+		return nil, fmt.Errorf("invalid PRI %q", s[1:end])
+	}
+
+	m := &syslogMessage{facility: pri / 8, severity: pri % 8}
+	s = s[end+1:]
+
+	// RFC 5424 header starts with VERSION SP
+	if len(s) >= 2 && s[0] >= '1' && s[0] <= '9' && s[1] == ' ' {
+		if err := m.parseRFC5424(s); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+
+	m.parseRFC3164(s, now)
+	return m, nil
+}
+
+// parseRFC5424 parse: VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP STRUCTURED-DATA [SP MSG].
+func (m *syslogMessage) parseRFC5424(s string) error {
+	var header [6]string
+	for i := range header {
+		idx := strings.IndexByte(s, ' ')
+		if idx < 0 {
+			return fmt.Errorf("truncated RFC 5424 header")
+		}
+
+		header[i], s = s[:idx], s[idx+1:]
+	}
+
+	m.version, _ = strconv.Atoi(header[0])
+
+	if header[1] != syslogNilValue {
+		t, err := time.Parse(time.RFC3339Nano, header[1])
+		if err != nil {
+			return fmt.Errorf("invalid RFC 5424 timestamp %q", header[1])
+		}
+		m.timestamp = t
+	}
+
+	nilOr := func(v string) string {
+		if v == syslogNilValue {
+			return ""
+		}
+		return v
+	}
+
+	m.hostname = nilOr(header[2])
+	m.appname = nilOr(header[3])
+	m.procid = nilOr(header[4])
+	m.msgid = nilOr(header[5])
+
+	rest, err := m.parseStructuredData(s)
+	if err != nil {
+		return err
+	}
+
+	rest = strings.TrimPrefix(rest, " ")
+	m.message = strings.TrimPrefix(rest, "\xEF\xBB\xBF") // BOM
+	return nil
+}
+
+func (m *syslogMessage) parseStructuredData(s string) (string, error) {
+	if strings.HasPrefix(s, syslogNilValue) {
+		return s[1:], nil
+	}
+
+	if !strings.HasPrefix(s, "[") {
+		return "", fmt.Errorf("invalid structured data")
+	}
+
+	for len(s) > 0 && s[0] == '[' {
+		s = s[1:]
+
+		idx := strings.IndexAny(s, " ]")
+		if idx <= 0 {
+			return "", fmt.Errorf("invalid SD-ID")
+		}
+
+		id := s[:idx]
+		s = s[idx:]
+
+		for len(s) > 0 && s[0] == ' ' {
+			s = s[1:]
+
+			eq := strings.IndexByte(s, '=')
+			if eq <= 0 || len(s) < eq+2 || s[eq+1] != '"' {
+				return "", fmt.Errorf("invalid SD-PARAM of %q", id)
+			}
+
+			name := s[:eq]
+			s = s[eq+2:]
+
+			var (
+				sb     strings.Builder
+				closed bool
+			)
+
+			for i := 0; i < len(s); i++ {
+				c := s[i]
+				if c == '\\' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\' || s[i+1] == ']') {
+					sb.WriteByte(s[i+1])
+					i++
+					continue
+				}
+
+				if c == '"' {
+					s = s[i+1:]
+					closed = true
+					break
+				}
+
+				sb.WriteByte(c)
+			}
+
+			if !closed {
+				return "", fmt.Errorf("unterminated SD-PARAM %q of %q", name, id)
+			}
+
+			m.structuredData = append(m.structuredData, &syslogSDParam{id: id, name: name, value: sb.String()})
+		}
+
+		if len(s) == 0 || s[0] != ']' {
+			return "", fmt.Errorf("unterminated SD-ELEMENT %q", id)
+		}
+		s = s[1:]
+	}
+
+	return s, nil
+}
+
+// parseRFC3164 parse: TIMESTAMP SP HOSTNAME SP TAG[PID]: MSG. RFC 3164 is only a
+// description of existing practices, so we do our best and never fail, the
+// unrecognized parts are kept in message.
+func (m *syslogMessage) parseRFC3164(s string, now time.Time) {
+	switch {
+	case len(s) > len(time.Stamp) && s[len(time.Stamp)] == ' ':
+		if t, err := time.ParseInLocation(time.Stamp, s[:len(time.Stamp)], now.Location()); err == nil {
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.After(now.Add(24 * time.Hour)) { // logged at last year
+				t = t.AddDate(-1, 0, 0)
+			}
+
+			m.timestamp = t
+			s = s[len(time.Stamp)+1:]
+		}
+
+	default: // some devices send RFC 3339 timestamp
+		if idx := strings.IndexByte(s, ' '); idx > 0 {
+			if t, err := time.Parse(time.RFC3339Nano, s[:idx]); err == nil {
+				m.timestamp = t
+				s = s[idx+1:]
+			}
+		}
+	}
+
+	if m.timestamp.IsZero() {
+		m.message = s
+		return
+	}
+
+	if idx := strings.IndexByte(s, ' '); idx > 0 {
+		m.hostname = s[:idx]
+		s = s[idx+1:]
+	}
+
+	if match := rfc3164TagRe.FindStringSubmatch(s); match != nil {
+		m.appname = match[1]
+		m.procid = match[2]
+		s = s[len(match[0]):]
+	}
+
+	m.message = s
+}
+
+// syslogFrameReader split syslog messages from stream, both octet-counting and
+// non-transparent-framing(LF delimited) are accepted, see RFC 6587.
+type syslogFrameReader struct {
+	rd *bufio.Reader
+}
+
+func newSyslogFrameReader(r io.Reader) *syslogFrameReader {
+	return &syslogFrameReader{rd: bufio.NewReader(r)}
+}
+
+func (r *syslogFrameReader) next() ([]byte, error) {
+	for {
+		b, err := r.rd.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+
+		switch c := b[0]; {
+		case c == '\n', c == '\r', c == 0: // skip empty lines between frames
+			if _, err := r.rd.ReadByte(); err != nil {
+				return nil, err
+			}
+			continue
+
+		case c >= '1' && c <= '9': // octet-counting: MSG-LEN SP SYSLOG-MSG
+			return r.octetCounted()
+
+		default:
+			return r.delimited()
+		}
+	}
+}
+
+func (r *syslogFrameReader) octetCounted() ([]byte, error) {
+	n := 0
+	for digits := 0; ; digits++ {
+		c, err := r.rd.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		if c == ' ' {
+			break
+		}
+
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("invalid octet count: unexpected char %q", c)
+		}
+
+		// do not buffer unlimited digits from the peer
+		if digits >= maxSyslogMsgLenDigits {
+			return nil, errSyslogFrameTooLarge
+		}
+
+		n = n*10 + int(c-'0')
+	}
+
+	if n > maxSyslogFrameSize {
+		return nil, errSyslogFrameTooLarge
+	}
+
+	frame := make([]byte, n)
+	if _, err := io.ReadFull(r.rd, frame); err != nil {
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+func (r *syslogFrameReader) delimited() ([]byte, error) {
+	var frame []byte
+	for {
+		line, err := r.rd.ReadSlice('\n')
+		if len(frame)+len(line) <= maxSyslogFrameSize {
+			frame = append(frame, line...)
+		} // else: truncated
+
+		switch {
+		case err == nil:
+			return bytes.TrimRight(frame, "\r\n"), nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && len(frame) > 0:
+			return frame, nil
+		default:
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,366 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package tailer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
+	dknet "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/net"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/pipeline"
+)
+
+func TestParseSyslog(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("rfc5424", func(t *testing.T) {
+		m, err := parseSyslog([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 `+
+			`[exampleSDID@32473 iut="3" eventSource="App\"lication\]"][examplePriority@32473 class="high"] `+
+			"\xEF\xBB\xBFAn application event log entry...\n"), now)
+		require.NoError(t, err)
+
+		assert.Equal(t, 20, m.facility)
+		assert.Equal(t, "local4", m.facilityName())
+		assert.Equal(t, "notice", m.status())
+		assert.Equal(t, 1, m.version)
+		assert.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC), m.timestamp.UTC())
+		assert.Equal(t, "mymachine.example.com", m.hostname)
+		assert.Equal(t, "evntslog", m.appname)
+		assert.Equal(t, "", m.procid)
+		assert.Equal(t, "ID47", m.msgid)
+		assert.Equal(t, "An application event log entry...", m.message)
+		assert.Equal(t, []*syslogSDParam{
+			{id: "exampleSDID@32473", name: "iut", value: "3"},
+			{id: "exampleSDID@32473", name: "eventSource", value: `App"lication]`},
+			{id: "examplePriority@32473", name: "class", value: "high"},
+		}, m.structuredData)
+	})
+
+	t.Run("rfc5424-nil", func(t *testing.T) {
+		m, err := parseSyslog([]byte(`<34>1 - - - - - -`), now)
+		require.NoError(t, err)
+
+		assert.Equal(t, "auth", m.facilityName())
+		assert.Equal(t, "critical", m.status())
+		assert.True(t, m.timestamp.IsZero())
+		assert.Equal(t, "", m.hostname)
+		assert.Equal(t, "", m.message)
+		assert.Empty(t, m.structuredData)
+	})
+
+	t.Run("rfc5424-invalid", func(t *testing.T) {
+		for _, s := range []string{
+			`<34>1 2003-10-11T22:14:15.003Z host app`,               // truncated
+			`<34>1 invalid-time host app - - - msg`,                 // invalid time
+			`<34>1 2003-10-11T22:14:15.003Z host app - - [id k=v]`,  // unquoted
+			`<34>1 2003-10-11T22:14:15.003Z host app - - [id k="v]`, // unterminated
+			`<34>1 2003-10-11T22:14:15.003Z host app - - msg`,       // no SD
+		} {
+			_, err := parseSyslog([]byte(s), now)
+			assert.Error(t, err, s)
+		}
+	})
+
+	t.Run("rfc3164", func(t *testing.T) {
+		m, err := parseSyslog([]byte(`<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8`), now)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, m.version)
+		assert.Equal(t, "auth", m.facilityName())
+		assert.Equal(t, "critical", m.status())
+		assert.Equal(t, time.Date(2024, 10, 11, 22, 14, 15, 0, time.UTC), m.timestamp) // last year
+		assert.Equal(t, "mymachine", m.hostname)
+		assert.Equal(t, "su", m.appname)
+		assert.Equal(t, "123", m.procid)
+		assert.Equal(t, "'su root' failed for lonvick on /dev/pts/8", m.message)
+
+		m, err = parseSyslog([]byte(`<13>Feb  5 17:32:18 10.0.0.99 Use the BFG!`), now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 2, 5, 17, 32, 18, 0, time.UTC), m.timestamp)
+		assert.Equal(t, "10.0.0.99", m.hostname)
+		assert.Equal(t, "", m.appname)
+		assert.Equal(t, "Use the BFG!", m.message)
+	})
+
+	t.Run("rfc3164-rfc3339-time", func(t *testing.T) {
+		m, err := parseSyslog([]byte(`<190>2025-01-02T03:04:05+08:00 switch-1 %LINK-3-UPDOWN: Interface up`), now)
+		require.NoError(t, err)
+
+		assert.Equal(t, "local7", m.facilityName())
+		assert.Equal(t, "info", m.status())
+		assert.Equal(t, int64(1735758245), m.timestamp.Unix())
+		assert.Equal(t, "switch-1", m.hostname)
+		assert.Equal(t, "%LINK-3-UPDOWN", m.appname)
+		assert.Equal(t, "Interface up", m.message)
+	})
+
+	t.Run("rfc3164-no-header", func(t *testing.T) {
+		m, err := parseSyslog([]byte(`<14>some message: without header`), now)
+		require.NoError(t, err)
+
+		assert.True(t, m.timestamp.IsZero())
+		assert.Equal(t, "", m.appname)
+		assert.Equal(t, "some message: without header", m.message)
+	})
+
+	t.Run("invalid-pri", func(t *testing.T) {
+		for _, s := range []string{``, `hello`, `<>msg`, `<192>msg`, `<abc>msg`, `<1234>msg`} {
+			_, err := parseSyslog([]byte(s), now)
+			assert.Error(t, err, s)
+		}
+	})
+}
+
+func TestSyslogFrameReader(t *testing.T) {
+	msg1 := `<34>1 - - - - - - octet counted` + "\nwith LF"
+	data := fmt.Sprintf("%d %s<13>delimited 1\r\n\n<13>delimited 2\n%d %s<13>last", len(msg1), msg1, len(msg1), msg1)
+
+	rd := newSyslogFrameReader(strings.NewReader(data))
+
+	var frames []string
+	for {
+		frame, err := rd.next()
+		if err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+		frames = append(frames, string(frame))
+	}
+
+	assert.Equal(t, []string{msg1, "<13>delimited 1", "<13>delimited 2", msg1, "<13>last"}, frames)
+
+	t.Run("too-large", func(t *testing.T) {
+		rd := newSyslogFrameReader(strings.NewReader(fmt.Sprintf("%d <13>msg", maxSyslogFrameSize+1)))
+		_, err := rd.next()
+		assert.ErrorIs(t, err, errSyslogFrameTooLarge)
+	})
+
+	t.Run("too-long-msg-len", func(t *testing.T) {
+		// digits without SP should fail without reading them all
+		rd := newSyslogFrameReader(io.MultiReader(strings.NewReader("1234567"), neverEnding('9')))
+		_, err := rd.next()
+		assert.ErrorIs(t, err, errSyslogFrameTooLarge)
+	})
+
+	t.Run("invalid-msg-len", func(t *testing.T) {
+		rd := newSyslogFrameReader(strings.NewReader("12a <13>msg"))
+		_, err := rd.next()
+		assert.Error(t, err)
+	})
+}
+
+// neverEnding is a reader of endless char.
+type neverEnding byte
+
+func (b neverEnding) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(b)
+	}
+	return len(p), nil
+}
+
+func TestSocketLoggerSyslog(t *testing.T) {
+	certFile, keyFile, caFile := genTestCerts(t)
+
+	listenAddr := func(t *testing.T, network string) string {
+		t.Helper()
+
+		if network == "udp" {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer conn.Close() //nolint:errcheck
+			return conn.LocalAddr().String()
+		}
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer l.Close() //nolint:errcheck
+		return l.Addr().String()
+	}
+
+	msg := `<165>1 2003-10-11T22:14:15.003Z host1 app1 1234 ID47 [origin@1 ip="10.0.0.1"] hello`
+
+	cases := []struct {
+		name, scheme string
+		send         func(t *testing.T, addr string)
+	}{
+		{
+			name:   "udp",
+			scheme: "udp",
+			send: func(t *testing.T, addr string) {
+				t.Helper()
+				conn, err := net.Dial("udp", addr)
+				require.NoError(t, err)
+				defer conn.Close() //nolint:errcheck
+
+				_, err = conn.Write([]byte(msg))
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "tcp-octet-counting",
+			scheme: "tcp",
+			send: func(t *testing.T, addr string) {
+				t.Helper()
+				conn, err := net.Dial("tcp", addr)
+				require.NoError(t, err)
+				defer conn.Close() //nolint:errcheck
+
+				_, err = fmt.Fprintf(conn, "%d %s", len(msg), msg)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "tls",
+			scheme: "tls",
+			send: func(t *testing.T, addr string) {
+				t.Helper()
+				cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+				require.NoError(t, err)
+
+				conn, err := tls.Dial("tcp", addr, &tls.Config{
+					InsecureSkipVerify: true, //nolint:gosec
+					Certificates:       []tls.Certificate{cert},
+				})
+				require.NoError(t, err)
+				defer conn.Close() //nolint:errcheck
+
+				_, err = fmt.Fprintf(conn, "%s\n", msg)
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			network := "tcp"
+			if tc.scheme == "udp" {
+				network = "udp"
+			}
+			addr := listenAddr(t, network)
+
+			feeder := dkio.NewMockedFeeder()
+			sk, err := NewSocketLogging(
+				WithSource("syslog"),
+				WithSockets([]string{tc.scheme + "://" + addr}),
+				WithSocketFormat(SocketFormatSyslog),
+				WithSocketTLS(&dknet.TLSClientConfig{Cert: certFile, CertKey: keyFile, CaCerts: []string{caFile}}),
+				WithFeeder(feeder),
+			)
+			require.NoError(t, err)
+
+			sk.Start()
+			defer sk.Close()
+
+			tc.send(t, addr)
+
+			pts, err := feeder.NPoints(1, 3*time.Second)
+			require.NoError(t, err)
+
+			pt := pts[0]
+			assert.Equal(t, "syslog", pt.Name())
+			assert.Equal(t, "local4", pt.GetTag("facility"))
+			assert.Equal(t, "host1", pt.GetTag("hostname"))
+			assert.Equal(t, "app1", pt.GetTag("app_name"))
+			assert.Equal(t, "ID47", pt.GetTag("msgid"))
+			assert.Equal(t, "notice", pt.Get("status"))
+			assert.Equal(t, "1234", pt.Get("procid"))
+			assert.Equal(t, "10.0.0.1", pt.Get("origin_1_ip"))
+			assert.Equal(t, "hello", pt.Get("message"))
+			assert.Equal(t, int64(1065910455003000000), pt.Time().UnixNano())
+		})
+	}
+
+	t.Run("parse-fail", func(t *testing.T) {
+		feeder := dkio.NewMockedFeeder()
+		sk := &SocketLogger{cfg: buildConfig([]Option{WithSource("syslog"), WithSocketFormat(SocketFormatSyslog), WithFeeder(feeder)})}
+		sk.log = logger.DefaultSLogger("test")
+		sk.feed([][]byte{[]byte("not a syslog message")})
+
+		pts, err := feeder.NPoints(1, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "not a syslog message", pts[0].Get("message"))
+		assert.Equal(t, pipeline.DefaultStatus, pts[0].Get("status"))
+	})
+
+	t.Run("invalid-config", func(t *testing.T) {
+		_, err := NewSocketLogging(WithSource("syslog"), WithSockets([]string{"tcp://127.0.0.1:0"}), WithSocketFormat("json"))
+		assert.Error(t, err)
+
+		_, err = NewSocketLogging(WithSource("syslog"), WithSockets([]string{"tls://127.0.0.1:0"}))
+		assert.Error(t, err)
+	})
+}
+
+// genTestCerts generate CA, and server/client certificate signed by the CA.
+func genTestCerts(t *testing.T) (certFile, keyFile, caFile string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	gen := func(cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      pkix.Name{CommonName: cn},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+
+		if parent == nil {
+			tmpl.IsCA = true
+			tmpl.BasicConstraintsValid = true
+			parent, parentKey = tmpl, key
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+		require.NoError(t, err)
+
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err)
+		return cert, key
+	}
+
+	writePEM := func(name, typ string, data []byte) string {
+		f := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(f, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: data}), 0o600))
+		return f
+	}
+
+	ca, caKey := gen("test-ca", nil, nil)
+	cert, key := gen("127.0.0.1", ca, caKey)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return writePEM("cert.pem", "CERTIFICATE", cert.Raw),
+		writePEM("key.pem", "EC PRIVATE KEY", keyDer),
+		writePEM("ca.pem", "CERTIFICATE", ca.Raw)
+}
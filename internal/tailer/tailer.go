@@ -22,6 +22,7 @@ import (
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/logtail"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/logtail/fileprovider"
 	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/logtail/openfile"
+	"gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/logtail/recorder"
 )
 
 const (
@@ -380,10 +381,17 @@ func (t *Tailer) shouldOpenFile(file string) bool {
 		t.log.Warnf("too many open files, limit %d, current %d", t.maxOpenFiles, t.openFileCount.Load())
 		return false
 	}
-	if t.ignoreDeadLog > 0 && !openfile.FileIsActive(file, t.ignoreDeadLog) {
-		t.log.Debugf("file %s is not active, skipping", file)
+	if data := recorder.Get(openfile.FileKey(file)); data != nil && data.Completed {
+		t.log.Debugf("file %s has been completely read, skipping", file)
 		return false
 	}
+	if t.ignoreDeadLog > 0 && !openfile.FileIsActive(file, t.ignoreDeadLog) {
+		// 压缩的归档文件不会再有写入，不受 ignoreDeadLog 限制
+		if typ, _ := openfile.FileCompressType(file); typ == openfile.CompressNone {
+			t.log.Debugf("file %s is not active, skipping", file)
+			return false
+		}
+	}
 	return true
 }
 
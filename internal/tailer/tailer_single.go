@@ -50,6 +50,11 @@ type Single struct {
 	insideFilepath string
 	reader         reader.Reader
 
+	// 压缩文件（如轮转归档的 .gz/.zst）只完整读取一次，读完后记录为 completed
+	compress     openfile.CompressType
+	decompressor io.ReadCloser
+	completed    bool
+
 	offset        int64
 	readLines     int64
 	partialBuffer bytes.Buffer
@@ -134,21 +139,27 @@ func (t *Single) setupFile() error {
 		return fmt.Errorf("failed to open file %s: %w", t.filepath, err)
 	}
 
-	t.reader = reader.NewReader(t.file)
 	t.inode = openfile.Inode(t.filepath)
 	t.recordKey = openfile.FileKey(t.filepath)
 
 	t.log.Debugf("file opened successfully: %s, inode: %s", t.filepath, t.inode)
 
-	if err := t.seekOffset(); err != nil {
+	if t.compress, err = openfile.DetectCompress(t.file); err != nil {
+		t.log.Warnf("failed to detect compress type of file %s: %s, read as plain text", t.filepath, err)
+		t.compress = openfile.CompressNone
+	}
+
+	seek := t.seekOffset
+	if t.compress == openfile.CompressNone {
+		t.reader = reader.NewReader(t.file)
+	} else {
+		seek = t.setupDecompressor
+	}
+
+	if err := seek(); err != nil {
 		t.log.Warnf("failed to set position for file %s: %s", t.filepath, err)
 		// 关闭文件句柄，避免资源泄漏
-		if t.file != nil {
-			if closeErr := t.file.Close(); closeErr != nil {
-				t.log.Warnf("failed to close file %s: %s", t.filepath, closeErr)
-			}
-			t.file = nil
-		}
+		t.closeFile()
 		return fmt.Errorf("failed to set position for file %s: %w", t.filepath, err)
 	}
 
@@ -174,7 +185,11 @@ func (t *Single) Run(ctx context.Context) {
 
 	t.cancelFunc = cancel
 
-	t.forwardMessage(ctx)
+	if t.compress != openfile.CompressNone {
+		t.forwardCompressed(ctx)
+	} else {
+		t.forwardMessage(ctx)
+	}
 	t.log.Infof("tailer for file %s has stopped, source: %s", t.filepath, t.config.source)
 }
 
@@ -262,12 +277,43 @@ func (t *Single) seekOffset() error {
 	return err
 }
 
+// setupDecompressor 压缩文件总是从头读取，已读取的位置记录的是解压后的偏移量，
+// 重启后解压并跳过这部分内容.
+func (t *Single) setupDecompressor() error {
+	var err error
+	if t.decompressor, err = openfile.NewDecompressReader(t.file, t.compress); err != nil {
+		return err
+	}
+
+	// 补充换行符，确保文件末尾没有换行符的最后一行也能被读出
+	t.reader = reader.NewReader(io.MultiReader(t.decompressor, bytes.NewReader([]byte{'\n'})))
+
+	data := recorder.Get(t.recordKey)
+	if data == nil {
+		t.log.Infof("set start position for %s file %s", t.compress, t.filepath)
+		return nil
+	}
+
+	if data.Completed {
+		t.completed = true
+		t.log.Infof("%s file %s has been completely read, skipped", t.compress, t.filepath)
+		return nil
+	}
+
+	if t.offset, err = io.CopyN(io.Discard, t.decompressor, data.Offset); err != nil {
+		return fmt.Errorf("skip to decompressed position %d: %w", data.Offset, err)
+	}
+
+	t.log.Infof("set decompressed position %d for %s file %s", t.offset, t.compress, t.filepath)
+	return nil
+}
+
 func (t *Single) recordPosition() {
-	if t.offset <= 0 {
+	if t.offset <= 0 && !t.completed {
 		return
 	}
 
-	c := &recorder.MetaData{Source: t.config.source, Offset: t.offset}
+	c := &recorder.MetaData{Source: t.config.source, Offset: t.offset, Completed: t.completed}
 
 	if err := recorder.SetAndFlush(t.recordKey, c); err != nil {
 		t.log.Debugf("recording cache %s err: %s", c, err)
@@ -275,6 +321,13 @@ func (t *Single) recordPosition() {
 }
 
 func (t *Single) closeFile() {
+	if t.decompressor != nil {
+		if err := t.decompressor.Close(); err != nil {
+			t.log.Warnf("failed to close decompressor of file %s: %s", t.filepath, err)
+		}
+		t.decompressor = nil
+	}
+
 	if t.file == nil {
 		t.log.Debugf("file already closed: %s", t.filepath)
 		return
@@ -337,6 +390,32 @@ func (t *Single) forwardMessage(ctx context.Context) {
 	}
 }
 
+// forwardCompressed 一次性读取压缩文件直至结束，压缩文件不会再被追加写入，因此无需检测轮转.
+// 读取出错时（例如文件仍在压缩中）不标记 completed，下次扫描时从已记录的位置继续读取.
+func (t *Single) forwardCompressed(ctx context.Context) {
+	for !t.completed {
+		select {
+		case <-ctx.Done():
+			t.handleContextCancellation()
+			return
+		case newOpts := <-t.updateChan:
+			t.handleConfigUpdate(newOpts)
+		default:
+			if err := t.readOnce(); err != nil {
+				t.flushCache()
+
+				if !errors.Is(err, reader.ErrReadEmpty) {
+					t.log.Warnf("failed to read %s file %s, offset %d, error: %s", t.compress, t.filepath, t.offset, err)
+					return
+				}
+
+				t.completed = true
+				t.log.Infof("finished reading %s file %s, %d bytes decompressed", t.compress, t.filepath, t.offset)
+			}
+		}
+	}
+}
+
 func (t *Single) handleContextCancellation() {
 	t.flushCache()
 	t.log.Infof("context canceled, exiting forwardMessage for file: %s, source: %s", t.filepath, t.config.source)
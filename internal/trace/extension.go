@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package trace
+
+import (
+	"strings"
+
+	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
+)
+
+const afterGatherExtensionSample = `
+  ## Tail sampling buffers spans of the same trace within decision_wait, then keeps the
+  ## complete trace if any of the policies matched, other traces dropped.
+  ## Available policy types:
+  ##   - latency: keep traces whose duration not less than threshold
+  ##   - status_code: keep traces with error span
+  ##   - attribute: keep traces with any span's tag(or string field) key matching any of the values(regexp)
+  ##   - rate_limiting: keep at most traces_per_second traces for each service
+  # [inputs.<input>.tail_sampling]
+  #   decision_wait = "10s"
+  #   max_traces = 50000
+  #   [[inputs.<input>.tail_sampling.policies]]
+  #     type = "status_code"
+  #   [[inputs.<input>.tail_sampling.policies]]
+  #     type = "latency"
+  #     threshold = "2s"
+  #   [[inputs.<input>.tail_sampling.policies]]
+  #     type = "attribute"
+  #     key = "http_route"
+  #     values = ["^/api/pay"]
+  #   [[inputs.<input>.tail_sampling.policies]]
+  #     type = "rate_limiting"
+  #     traces_per_second = 10
+
+  ## RED metrics derives request count, error count and latency histogram from all spans
+  ## (before filters and samplers) on dimensions, and reports them as metric tracing_red.
+  ## Dimensions are span tags(or fields), latency buckets are upper bounds of span duration.
+  # [inputs.<input>.red_metrics]
+  #   interval = "60s"
+  #   dimensions = ["service", "resource", "operation", "source", "env", "version"]
+  #   latency_buckets = ["5ms", "10ms", "25ms", "50ms", "100ms", "250ms", "500ms", "1s", "2.5s", "5s", "10s"]
+`
+
+// AfterGatherExtension is the optional trace processing shared by all tracing
+// inputs, embed it into the input to accept the configures.
+type AfterGatherExtension struct {
+	TailSampler *TailSampler `toml:"tail_sampling"`
+	REDMetrics  *REDMetrics  `toml:"red_metrics"`
+}
+
+// AfterGatherExtensionSample get the sample configure of AfterGatherExtension on the input.
+func AfterGatherExtensionSample(inputName string) string {
+	return strings.ReplaceAll(afterGatherExtensionSample, "<input>", inputName)
+}
+
+// SetupAfterGather appends the tail sampler as the last filter of aga, so it
+// should be called after all other filters appended. The returned handler
+// should be used instead of aga, it derives RED metrics from all traces before
+// filters, metrics are fed by feeder with extra tags.
+func (ext *AfterGatherExtension) SetupAfterGather(inputName string,
+	aga *AfterGather,
+	feeder dkio.Feeder,
+	tags map[string]string,
+) AfterGatherHandler {
+	if ext.TailSampler != nil {
+		if _, err := ext.TailSampler.Init(inputName, aga); err != nil {
+			log.Errorf("init tail sampler on %s failed: %s, tail sampling disabled", inputName, err.Error())
+		} else {
+			aga.AppendFilter(ext.TailSampler.Filter)
+		}
+	}
+
+	if ext.REDMetrics != nil {
+		if red, err := ext.REDMetrics.Init(inputName, feeder, aga, tags); err != nil {
+			log.Errorf("init RED metrics on %s failed: %s, RED metrics disabled", inputName, err.Error())
+		} else {
+			return red
+		}
+	}
+
+	return aga
+}
+
+// CloseAfterGather stops the RED metrics and the tail sampler.
+func (ext *AfterGatherExtension) CloseAfterGather() {
+	if ext.REDMetrics != nil {
+		ext.REDMetrics.Close()
+	}
+
+	if ext.TailSampler != nil {
+		ext.TailSampler.Close()
+	}
+}
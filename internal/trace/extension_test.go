@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package trace
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
+)
+
+func TestAfterGatherExtension(t *testing.T) {
+	t.Run("sample", func(t *testing.T) {
+		sample := AfterGatherExtensionSample("ddtrace")
+		assert.Contains(t, sample, "[inputs.ddtrace.tail_sampling]")
+		assert.NotContains(t, sample, "<input>")
+
+		// the uncommented sample accepted by input that embeds the extension
+		var conf struct {
+			Inputs struct {
+				DDTrace []struct {
+					AfterGatherExtension
+					Endpoints []string `toml:"endpoints"`
+				} `toml:"ddtrace"`
+			} `toml:"inputs"`
+		}
+
+		uncommented := strings.NewReplacer("# [", "[", "#   ", "  ").Replace(sample)
+		_, err := toml.Decode("[[inputs.ddtrace]]\nendpoints = [\"/v0.4/traces\"]\n"+uncommented, &conf)
+		require.NoError(t, err)
+		require.Len(t, conf.Inputs.DDTrace, 1)
+
+		ipt := conf.Inputs.DDTrace[0]
+		assert.Equal(t, []string{"/v0.4/traces"}, ipt.Endpoints)
+		require.NotNil(t, ipt.TailSampler)
+		assert.Len(t, ipt.TailSampler.Policies, 4)
+		require.NotNil(t, ipt.REDMetrics)
+		assert.Equal(t, time.Minute, ipt.REDMetrics.Interval)
+	})
+
+	t.Run("setup", func(t *testing.T) {
+		feeder := dkio.NewMockedFeeder()
+		aga := NewAfterGather(WithFeeder(feeder))
+
+		// no policy and invalid buckets: both disabled
+		ext := &AfterGatherExtension{
+			TailSampler: &TailSampler{},
+			REDMetrics:  &REDMetrics{LatencyBuckets: []time.Duration{time.Second, time.Millisecond}},
+		}
+		assert.Equal(t, aga, ext.SetupAfterGather("test", aga, feeder, nil))
+		assert.Len(t, aga.filters, 0)
+		ext.CloseAfterGather()
+
+		ext = &AfterGatherExtension{
+			TailSampler: &TailSampler{Policies: []*TailSamplingPolicy{{Type: PolicyStatusCode}}},
+			REDMetrics:  &REDMetrics{},
+		}
+		h := ext.SetupAfterGather("test", aga, feeder, nil)
+		assert.Equal(t, ext.REDMetrics, h)
+		assert.Len(t, aga.filters, 1)
+		ext.CloseAfterGather()
+
+		(&AfterGatherExtension{}).CloseAfterGather() // nothing configured
+	})
+}
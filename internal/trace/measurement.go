@@ -139,3 +139,47 @@ func (m TracingMetricMeasurement) Info() *inputs.MeasurementInfo {
 		},
 	}
 }
+
+type REDMetricMeasurement struct {
+	Name string
+}
+
+func (m REDMetricMeasurement) Info() *inputs.MeasurementInfo {
+	return &inputs.MeasurementInfo{
+		Name:   REDMetricName,
+		Desc:   fmt.Sprintf("RED(request rate, error and duration) metrics derived from all %s's spans before sampling, only available if `red_metrics` configured", m.Name),
+		DescZh: fmt.Sprintf("基于 %s 采样前的所有 span 统计得到的 RED（请求数、错误数、耗时）指标，仅在配置了 `red_metrics` 时才会采集", m.Name),
+		Cat:    point.Metric,
+		Tags: map[string]interface{}{
+			TagService:    &inputs.TagInfo{Desc: "Service name. Tags of this metric are dimensions configured, here are default dimensions."},
+			FieldResource: &inputs.TagInfo{Desc: "Application resource name."},
+			TagOperation:  &inputs.TagInfo{Desc: "Span name"},
+			TagSource:     &inputs.TagInfo{Desc: "Source of span"},
+			TagEnv:        &inputs.TagInfo{Desc: "Application environment info(if set in span)."},
+			TagVersion:    &inputs.TagInfo{Desc: "Application version info(if set in span)."},
+			"le":          &inputs.TagInfo{Desc: "Upper bound(in microsecond) of latency bucket, only for field `latency_bucket`"},
+		},
+		Fields: map[string]interface{}{
+			"requests": &inputs.FieldInfo{
+				Type: inputs.Count, DataType: inputs.Int,
+				Unit: inputs.NCount, Desc: "Count of spans within the interval.",
+			},
+			"errors": &inputs.FieldInfo{
+				Type: inputs.Count, DataType: inputs.Int,
+				Unit: inputs.NCount, Desc: "Count of error(or critical) spans within the interval.",
+			},
+			"latency_sum": &inputs.FieldInfo{
+				Type: inputs.Count, DataType: inputs.Int,
+				Unit: inputs.DurationUS, Desc: "Total latency of spans within the interval.",
+			},
+			"latency_count": &inputs.FieldInfo{
+				Type: inputs.Count, DataType: inputs.Int,
+				Unit: inputs.NCount, Desc: "Count of spans within the interval, equals to `requests`.",
+			},
+			"latency_bucket": &inputs.FieldInfo{
+				Type: inputs.Histogram, DataType: inputs.Int,
+				Unit: inputs.NCount, Desc: "Cumulative count of spans within the latency bucket, use the `le` tag for filtering.",
+			},
+		},
+	}
+}
@@ -0,0 +1,263 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package trace
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/point"
+
+	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
+)
+
+const (
+	REDMetricName = "tracing_red"
+
+	defaultREDInterval = time.Minute
+	maxREDSeries       = 100000
+)
+
+var (
+	DefaultREDDimensions = []string{TagService, FieldResource, TagOperation, TagSource, TagEnv, TagVersion}
+
+	DefaultREDLatencyBuckets = []time.Duration{
+		5 * time.Millisecond,
+		10 * time.Millisecond,
+		25 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		2500 * time.Millisecond,
+		5 * time.Second,
+		10 * time.Second,
+	}
+)
+
+// REDMetrics is an AfterGatherHandler that derives RED(request rate, error and duration)
+// metrics from spans, then pass the traces to the next handler. The metrics aggregated
+// on dimensions(tags or fields of span) within the interval, and fed as metric points of
+// measurement tracing_red.
+type REDMetrics struct {
+	Interval       time.Duration   `toml:"interval" json:"interval"`
+	Dimensions     []string        `toml:"dimensions" json:"dimensions"`
+	LatencyBuckets []time.Duration `toml:"latency_buckets" json:"latency_buckets"`
+
+	inputName string
+	feeder    dkio.Feeder
+	next      AfterGatherHandler
+	tags      map[string]string
+	buckets   []int64 // upper bounds of latency buckets in microsecond
+
+	mtx    sync.Mutex
+	series map[string]*redSeries
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+type redSeries struct {
+	values     []string // dimension values
+	requests   int64
+	errors     int64
+	latencySum int64   // in microsecond
+	buckets    []int64 // non-cumulative count of each bucket, the last one is +Inf
+}
+
+// Init check the config and start the flush worker. Metrics fed by feeder with extra tags,
+// and traces passed to next after metrics derived.
+func (red *REDMetrics) Init(inputName string, feeder dkio.Feeder, next AfterGatherHandler, tags map[string]string) (*REDMetrics, error) {
+	if red.Interval <= 0 {
+		red.Interval = defaultREDInterval
+	}
+
+	if len(red.Dimensions) == 0 {
+		red.Dimensions = DefaultREDDimensions
+	}
+
+	dims := make([]string, 0, len(red.Dimensions))
+	for _, d := range red.Dimensions {
+		d = replacer.Replace(d)
+		if d == "" {
+			return nil, fmt.Errorf("empty dimension")
+		}
+
+		if !sliceContain(dims, d) {
+			dims = append(dims, d)
+		}
+	}
+	red.Dimensions = dims
+
+	if len(red.LatencyBuckets) == 0 {
+		red.LatencyBuckets = DefaultREDLatencyBuckets
+	}
+
+	red.buckets = red.buckets[:0]
+	for i, b := range red.LatencyBuckets {
+		if b <= 0 || (i > 0 && b <= red.LatencyBuckets[i-1]) {
+			return nil, fmt.Errorf("latency buckets should be positive and in increasing order")
+		}
+
+		red.buckets = append(red.buckets, b.Microseconds())
+	}
+
+	red.inputName = inputName
+	red.feeder = feeder
+	red.next = next
+	red.tags = tags
+	red.series = map[string]*redSeries{}
+	red.stop = make(chan struct{})
+
+	red.wg.Add(1)
+	go func() {
+		defer red.wg.Done()
+		red.run()
+	}()
+
+	log.Infof("init RED metrics on %s, interval=%s, dimensions=%v", inputName, red.Interval, red.Dimensions)
+
+	return red, nil
+}
+
+// Run derives metrics from all spans, then pass traces to next handler.
+func (red *REDMetrics) Run(inputName string, dktraces DatakitTraces) {
+	red.mtx.Lock()
+	for _, dktrace := range dktraces {
+		for _, span := range dktrace {
+			red.observe(span)
+		}
+	}
+	red.mtx.Unlock()
+
+	if red.next != nil {
+		red.next.Run(inputName, dktraces)
+	}
+}
+
+// Close stops the flush worker and flush metrics not fed yet.
+func (red *REDMetrics) Close() {
+	if red.stop == nil {
+		return
+	}
+
+	close(red.stop)
+	red.wg.Wait()
+	red.stop = nil
+}
+
+// observe should be called within lock.
+func (red *REDMetrics) observe(span *DkSpan) {
+	values := make([]string, 0, len(red.Dimensions))
+	for _, d := range red.Dimensions {
+		if v := span.Get(d); v != nil {
+			values = append(values, fmt.Sprintf("%v", v))
+		} else {
+			values = append(values, "")
+		}
+	}
+
+	key := strings.Join(values, "\x00")
+	s, ok := red.series[key]
+	if !ok {
+		if len(red.series) >= maxREDSeries {
+			log.Warnf("too many(%d) RED metric series on %s, span ignored", len(red.series), red.inputName)
+			return
+		}
+
+		s = &redSeries{values: values, buckets: make([]int64, len(red.buckets)+1)}
+		red.series[key] = s
+	}
+
+	duration := toInt64(span.Get(FieldDuration))
+
+	s.requests++
+	s.latencySum += duration
+	s.buckets[sort.Search(len(red.buckets), func(i int) bool { return duration <= red.buckets[i] })]++
+
+	if status := span.GetTag(TagSpanStatus); status == StatusErr || status == StatusCritical {
+		s.errors++
+	}
+}
+
+func (red *REDMetrics) run() {
+	tick := time.NewTicker(red.Interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-red.stop:
+			red.flush(time.Now())
+			return
+
+		case now := <-tick.C:
+			red.flush(now)
+		}
+	}
+}
+
+func (red *REDMetrics) flush(now time.Time) {
+	red.mtx.Lock()
+	series := red.series
+	red.series = map[string]*redSeries{}
+	red.mtx.Unlock()
+
+	if len(series) == 0 {
+		return
+	}
+
+	pts := red.points(series, now)
+	if err := red.feeder.Feed(point.Metric, pts,
+		dkio.WithSource(dkio.FeedSource(red.inputName, REDMetricName))); err != nil {
+		log.Warnf("feed %d RED metric points failed: %s, ignored", len(pts), err.Error())
+	}
+}
+
+func (red *REDMetrics) points(series map[string]*redSeries, now time.Time) []*point.Point {
+	opts := append(point.DefaultMetricOptions(), point.WithTime(now))
+
+	var pts []*point.Point
+	for _, s := range series {
+		var tags point.KVs
+		for k, v := range red.tags {
+			tags = tags.AddTag(k, v)
+		}
+
+		for i, d := range red.Dimensions {
+			if s.values[i] != "" {
+				tags = tags.AddTag(d, s.values[i])
+			}
+		}
+
+		kvs := append(point.KVs{}, tags...)
+		kvs = kvs.Add("requests", s.requests).
+			Add("errors", s.errors).
+			Add("latency_sum", s.latencySum).
+			Add("latency_count", s.requests)
+		pts = append(pts, point.NewPoint(REDMetricName, kvs, opts...))
+
+		var cumulative int64
+		for i, n := range s.buckets {
+			cumulative += n
+
+			le := "+Inf"
+			if i < len(red.buckets) {
+				le = strconv.FormatInt(red.buckets[i], 10)
+			}
+
+			kvs := append(point.KVs{}, tags...)
+			kvs = kvs.AddTag("le", le).Add("latency_bucket", cumulative)
+			pts = append(pts, point.NewPoint(REDMetricName, kvs, opts...))
+		}
+	}
+
+	return pts
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package trace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
+)
+
+func TestREDMetrics(t *testing.T) {
+	t.Run("invalid-buckets", func(t *testing.T) {
+		red := &REDMetrics{LatencyBuckets: []time.Duration{time.Second, time.Millisecond}}
+		_, err := red.Init("test", dkio.NewMockedFeeder(), nil, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("derive", func(t *testing.T) {
+		feeder := dkio.NewMockedFeeder()
+
+		var passed int
+		next := AfterGatherFunc(func(inputName string, dktraces DatakitTraces) {
+			passed += len(dktraces)
+		})
+
+		red := &REDMetrics{
+			Interval:       time.Hour,
+			Dimensions:     []string{TagService, "http.method"},
+			LatencyBuckets: []time.Duration{time.Millisecond, time.Second},
+		}
+
+		_, err := red.Init("test", feeder, next, map[string]string{"host": "h1"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{TagService, "http_method"}, red.Dimensions)
+
+		red.Run("test", DatakitTraces{
+			{
+				newTailSpan("1", "s1", StatusOk, 0, 100, map[string]string{"http_method": "GET"}),        // 100us
+				newTailSpan("1", "s1", StatusErr, 0, 500000, map[string]string{"http_method": "GET"}),    // 500ms
+				newTailSpan("1", "s1", StatusOk, 0, 2000000, map[string]string{"http_method": "GET"}),    // 2s
+				newTailSpan("1", "s2", StatusCritical, 0, 200, map[string]string{"http_method": "POST"}), // 200us
+			},
+			{newTailSpan("2", "s1", StatusOk, 0, 300, nil)},
+		})
+		assert.Equal(t, 2, passed)
+
+		red.Close()
+
+		pts, err := feeder.AnyPoints(time.Second)
+		require.NoError(t, err)
+
+		// 3 series, each with 1 point and 3 bucket points
+		require.Len(t, pts, 12)
+
+		var found bool
+		buckets := map[string]int64{}
+		for _, pt := range pts {
+			assert.Equal(t, REDMetricName, pt.Name())
+			assert.Equal(t, "h1", pt.GetTag("host"))
+
+			if pt.GetTag(TagService) != "s1" || pt.GetTag("http_method") != "GET" {
+				continue
+			}
+
+			if le := pt.GetTag("le"); le != "" {
+				buckets[le] = pt.Get("latency_bucket").(int64)
+				continue
+			}
+
+			found = true
+			assert.Equal(t, int64(3), pt.Get("requests"))
+			assert.Equal(t, int64(1), pt.Get("errors"))
+			assert.Equal(t, int64(2500100), pt.Get("latency_sum"))
+		}
+
+		assert.True(t, found)
+		assert.Equal(t, map[string]int64{"1000": 1, "1000000": 2, "+Inf": 3}, buckets)
+	})
+}
@@ -0,0 +1,377 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package trace
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/logger"
+	"golang.org/x/time/rate"
+)
+
+const (
+	PolicyLatency      = "latency"
+	PolicyStatusCode   = "status_code"
+	PolicyAttribute    = "attribute"
+	PolicyRateLimiting = "rate_limiting"
+
+	defaultDecisionWait  = 10 * time.Second
+	defaultMaxTraces     = 50000
+	maxServiceLimiters   = 10000
+	maxDecisionTickInter = time.Second
+)
+
+// TailSamplingPolicy decides whether a complete trace should be kept. Available policies:
+//
+//   - latency: keep the trace if its duration is not less than threshold
+//   - status_code: keep the trace if any span with error(or critical) status
+//   - attribute: keep the trace if any span's tag(or string field) key matches any of the values(regexp),
+//     if no values set, keep the trace if any span has the key
+//   - rate_limiting: keep at most traces_per_second traces for each service
+type TailSamplingPolicy struct {
+	Name            string        `toml:"name" json:"name"`
+	Type            string        `toml:"type" json:"type"`
+	Threshold       time.Duration `toml:"threshold" json:"threshold"`
+	Key             string        `toml:"key" json:"key"`
+	Values          []string      `toml:"values" json:"values"`
+	TracesPerSecond float64       `toml:"traces_per_second" json:"traces_per_second"`
+
+	valuesRegexp []*regexp.Regexp
+	mtx          sync.Mutex
+	limiters     map[string]*rate.Limiter
+}
+
+func (p *TailSamplingPolicy) init() error {
+	switch p.Type {
+	case PolicyLatency:
+		if p.Threshold <= 0 {
+			return fmt.Errorf("policy %q: invalid latency threshold %s", p.Name, p.Threshold)
+		}
+
+	case PolicyStatusCode:
+
+	case PolicyAttribute:
+		if p.Key == "" {
+			return fmt.Errorf("policy %q: attribute key required", p.Name)
+		}
+
+		p.valuesRegexp = p.valuesRegexp[:0]
+		for _, v := range p.Values {
+			re, err := regexp.Compile(v)
+			if err != nil {
+				return fmt.Errorf("policy %q: invalid attribute value %q: %w", p.Name, v, err)
+			}
+			p.valuesRegexp = append(p.valuesRegexp, re)
+		}
+
+	case PolicyRateLimiting:
+		if p.TracesPerSecond <= 0 {
+			return fmt.Errorf("policy %q: invalid traces_per_second %f", p.Name, p.TracesPerSecond)
+		}
+		p.limiters = map[string]*rate.Limiter{}
+
+	default:
+		return fmt.Errorf("policy %q: unknown policy type %q", p.Name, p.Type)
+	}
+
+	if p.Name == "" {
+		p.Name = p.Type
+	}
+
+	return nil
+}
+
+// evaluate returns true if the trace should be kept by the policy.
+func (p *TailSamplingPolicy) evaluate(dktrace DatakitTrace) bool {
+	switch p.Type {
+	case PolicyLatency:
+		return traceDuration(dktrace) >= p.Threshold
+
+	case PolicyStatusCode:
+		for i := range dktrace {
+			if s := dktrace[i].GetTag(TagSpanStatus); s == StatusErr || s == StatusCritical {
+				return true
+			}
+		}
+
+	case PolicyAttribute:
+		for i := range dktrace {
+			kv := dktrace[i].KVs().Get(p.Key)
+			if kv == nil {
+				continue
+			}
+
+			if len(p.valuesRegexp) == 0 {
+				return true
+			}
+
+			var val string
+			if kv.IsTag {
+				val = kv.GetS()
+			} else if s, ok := kv.Raw().(string); ok {
+				val = s
+			} else {
+				continue
+			}
+
+			for _, re := range p.valuesRegexp {
+				if re.MatchString(val) {
+					return true
+				}
+			}
+		}
+
+	case PolicyRateLimiting:
+		return p.limiter(rootService(dktrace)).Allow()
+	}
+
+	return false
+}
+
+func (p *TailSamplingPolicy) limiter(service string) *rate.Limiter {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	lmt, ok := p.limiters[service]
+	if !ok {
+		if len(p.limiters) >= maxServiceLimiters {
+			log.Warnf("too many(%d) services on tail sampling policy %q, reset them", len(p.limiters), p.Name)
+			p.limiters = map[string]*rate.Limiter{}
+		}
+
+		burst := int(p.TracesPerSecond)
+		if burst < 1 {
+			burst = 1
+		}
+
+		lmt = rate.NewLimiter(rate.Limit(p.TracesPerSecond), burst)
+		p.limiters[service] = lmt
+	}
+
+	return lmt
+}
+
+// TailSampler buffers spans of the same trace within the decision window, then
+// decides to keep or drop the complete trace according to the policies. The trace
+// kept if any of the policies keep it.
+//
+// TailSampler should be the last filter of AfterGather, all traces are absorbed by
+// the filter and the kept traces are fed after the decision window.
+type TailSampler struct {
+	DecisionWait time.Duration         `toml:"decision_wait" json:"decision_wait"`
+	MaxTraces    int                   `toml:"max_traces" json:"max_traces"`
+	Policies     []*TailSamplingPolicy `toml:"policies" json:"policies"`
+
+	mtx    sync.Mutex
+	traces map[string]*list.Element
+	queue  *list.List // traces ordered by arrival time
+
+	inputName string
+	aga       *AfterGather
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+type tailTrace struct {
+	id      string
+	arrival time.Time
+	spans   DatakitTrace
+}
+
+// Init check the policies and start the decision worker, kept traces are fed by aga.
+func (ts *TailSampler) Init(inputName string, aga *AfterGather) (*TailSampler, error) {
+	if len(ts.Policies) == 0 {
+		return nil, fmt.Errorf("no tail sampling policy configured")
+	}
+
+	for _, p := range ts.Policies {
+		if err := p.init(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ts.DecisionWait <= 0 {
+		ts.DecisionWait = defaultDecisionWait
+	}
+
+	if ts.MaxTraces <= 0 {
+		ts.MaxTraces = defaultMaxTraces
+	}
+
+	ts.traces = map[string]*list.Element{}
+	ts.queue = list.New()
+	ts.inputName = inputName
+	ts.aga = aga
+	ts.stop = make(chan struct{})
+
+	ts.wg.Add(1)
+	go func() {
+		defer ts.wg.Done()
+		ts.run()
+	}()
+
+	log.Infof("init tail sampler on %s, decision_wait=%s, max_traces=%d, %d policies",
+		inputName, ts.DecisionWait, ts.MaxTraces, len(ts.Policies))
+
+	return ts, nil
+}
+
+// Filter absorbs the trace into the decision buffer.
+func (ts *TailSampler) Filter(log *logger.Logger, dktrace DatakitTrace) (DatakitTrace, bool) {
+	if len(dktrace) == 0 {
+		return nil, true
+	}
+
+	id := fmt.Sprintf("%v", dktrace[0].Get(FieldTraceID))
+
+	var evicted []*tailTrace
+
+	ts.mtx.Lock()
+	if elem, ok := ts.traces[id]; ok { // another part of the trace
+		tt := elem.Value.(*tailTrace) //nolint:forcetypeassert
+		tt.spans = append(tt.spans, dktrace...)
+	} else {
+		for ts.queue.Len() >= ts.MaxTraces {
+			evicted = append(evicted, ts.popFront())
+		}
+
+		ts.traces[id] = ts.queue.PushBack(&tailTrace{id: id, arrival: time.Now(), spans: dktrace})
+	}
+	ts.mtx.Unlock()
+
+	if len(evicted) > 0 {
+		log.Debugf("tail sampler buffer full(%d traces), decide %d traces ahead of time", ts.MaxTraces, len(evicted))
+		ts.decide(evicted)
+	}
+
+	return nil, true
+}
+
+// Close stops the decision worker and decides all buffered traces.
+func (ts *TailSampler) Close() {
+	if ts.stop == nil {
+		return
+	}
+
+	close(ts.stop)
+	ts.wg.Wait()
+	ts.stop = nil
+}
+
+func (ts *TailSampler) run() {
+	interval := ts.DecisionWait / 10
+	if interval > maxDecisionTickInter {
+		interval = maxDecisionTickInter
+	}
+
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ts.stop:
+			ts.decide(ts.expired(time.Time{}))
+			return
+
+		case now := <-tick.C:
+			ts.decide(ts.expired(now))
+		}
+	}
+}
+
+// expired pops traces arrived before now-DecisionWait, zero now pops all traces.
+func (ts *TailSampler) expired(now time.Time) (res []*tailTrace) {
+	ts.mtx.Lock()
+	defer ts.mtx.Unlock()
+
+	for ts.queue.Len() > 0 {
+		tt := ts.queue.Front().Value.(*tailTrace) //nolint:forcetypeassert
+		if !now.IsZero() && now.Sub(tt.arrival) < ts.DecisionWait {
+			break
+		}
+
+		res = append(res, ts.popFront())
+	}
+
+	return res
+}
+
+func (ts *TailSampler) popFront() *tailTrace {
+	tt := ts.queue.Remove(ts.queue.Front()).(*tailTrace) //nolint:forcetypeassert
+	delete(ts.traces, tt.id)
+	return tt
+}
+
+func (ts *TailSampler) decide(traces []*tailTrace) {
+	for _, tt := range traces {
+		if ts.keep(tt.spans) {
+			ts.aga.doFeed(ts.inputName, tt.spans)
+		} else {
+			tracingDropVec.WithLabelValues(tt.spans[0].GetTag(TagSource),
+				rootService(tt.spans), "tail_sample").Observe(float64(len(tt.spans)))
+		}
+	}
+}
+
+func (ts *TailSampler) keep(dktrace DatakitTrace) bool {
+	for _, p := range ts.Policies {
+		if p.evaluate(dktrace) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rootService get service of the root span, or the first span if root span not found.
+func rootService(dktrace DatakitTrace) string {
+	for i := range dktrace {
+		if pid := dktrace[i].Get(FieldParentID); pid == nil || pid == "0" || pid == "" {
+			return dktrace[i].GetTag(TagService)
+		}
+	}
+
+	return dktrace[0].GetTag(TagService)
+}
+
+// traceDuration get the duration from the earliest span start to the latest span end.
+// Both start and duration of span are in microsecond.
+func traceDuration(dktrace DatakitTrace) time.Duration {
+	var start, end int64
+	for i := range dktrace {
+		s := toInt64(dktrace[i].Get(FieldStart))
+		e := s + toInt64(dktrace[i].Get(FieldDuration))
+
+		if i == 0 || s < start {
+			start = s
+		}
+
+		if e > end {
+			end = e
+		}
+	}
+
+	return time.Duration(end-start) * time.Microsecond
+}
+
+func toInt64(v any) int64 {
+	switch x := v.(type) {
+	case int64:
+		return x
+	case int:
+		return int64(x)
+	case uint64:
+		return int64(x)
+	case float64:
+		return int64(x)
+	default:
+		return 0
+	}
+}
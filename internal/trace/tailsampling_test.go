@@ -0,0 +1,197 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the MIT License.
+// This product includes software developed at Guance Cloud (https://www.guance.com/).
+// Copyright 2021-present Guance, Inc.
+
+package trace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GuanceCloud/cliutils/logger"
+	"github.com/GuanceCloud/cliutils/point"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dkio "gitlab.jiagouyun.com/cloudcare-tools/datakit/internal/io"
+)
+
+func newTailSpan(tid, service, status string, start, duration int64, tags map[string]string) *DkSpan {
+	kvs := point.NewKVs(map[string]any{
+		FieldTraceID:  tid,
+		FieldParentID: "0",
+		FieldStart:    start,
+		FieldDuration: duration,
+	}).AddTag(TagService, service).AddTag(TagSpanStatus, status).AddTag(TagSource, "test")
+
+	for k, v := range tags {
+		kvs = kvs.AddTag(k, v)
+	}
+
+	return &DkSpan{Point: point.NewPoint("test", kvs)}
+}
+
+func TestTailSamplingPolicy(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy *TailSamplingPolicy
+		trace  DatakitTrace
+		keep   bool
+		fail   bool
+	}{
+		{
+			name:   "latency-keep",
+			policy: &TailSamplingPolicy{Type: PolicyLatency, Threshold: time.Second},
+			trace: DatakitTrace{
+				newTailSpan("1", "s1", StatusOk, 0, 100, nil),
+				newTailSpan("1", "s1", StatusOk, 500000, 600000, nil),
+			},
+			keep: true,
+		},
+		{
+			name:   "latency-drop",
+			policy: &TailSamplingPolicy{Type: PolicyLatency, Threshold: time.Second},
+			trace:  DatakitTrace{newTailSpan("1", "s1", StatusOk, 0, 999999, nil)},
+		},
+		{
+			name:   "latency-invalid",
+			policy: &TailSamplingPolicy{Type: PolicyLatency},
+			fail:   true,
+		},
+		{
+			name:   "status-keep",
+			policy: &TailSamplingPolicy{Type: PolicyStatusCode},
+			trace: DatakitTrace{
+				newTailSpan("1", "s1", StatusOk, 0, 1, nil),
+				newTailSpan("1", "s1", StatusErr, 0, 1, nil),
+			},
+			keep: true,
+		},
+		{
+			name:   "status-drop",
+			policy: &TailSamplingPolicy{Type: PolicyStatusCode},
+			trace:  DatakitTrace{newTailSpan("1", "s1", StatusOk, 0, 1, nil)},
+		},
+		{
+			name:   "attribute-keep",
+			policy: &TailSamplingPolicy{Type: PolicyAttribute, Key: "http_route", Values: []string{"^/api/pay"}},
+			trace:  DatakitTrace{newTailSpan("1", "s1", StatusOk, 0, 1, map[string]string{"http_route": "/api/pay/1"})},
+			keep:   true,
+		},
+		{
+			name:   "attribute-drop",
+			policy: &TailSamplingPolicy{Type: PolicyAttribute, Key: "http_route", Values: []string{"^/api/pay"}},
+			trace:  DatakitTrace{newTailSpan("1", "s1", StatusOk, 0, 1, map[string]string{"http_route": "/api/user"})},
+		},
+		{
+			name:   "attribute-exist",
+			policy: &TailSamplingPolicy{Type: PolicyAttribute, Key: "http_route"},
+			trace:  DatakitTrace{newTailSpan("1", "s1", StatusOk, 0, 1, map[string]string{"http_route": "/"})},
+			keep:   true,
+		},
+		{
+			name:   "attribute-invalid-regexp",
+			policy: &TailSamplingPolicy{Type: PolicyAttribute, Key: "k", Values: []string{"("}},
+			fail:   true,
+		},
+		{
+			name:   "unknown",
+			policy: &TailSamplingPolicy{Type: "unknown"},
+			fail:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.init()
+			if tc.fail {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.keep, tc.policy.evaluate(tc.trace))
+		})
+	}
+
+	t.Run("rate-limiting", func(t *testing.T) {
+		p := &TailSamplingPolicy{Type: PolicyRateLimiting, TracesPerSecond: 2}
+		require.NoError(t, p.init())
+
+		kept := map[string]int{}
+		for i := 0; i < 10; i++ {
+			for _, svc := range []string{"s1", "s2"} {
+				if p.evaluate(DatakitTrace{newTailSpan("1", svc, StatusOk, 0, 1, nil)}) {
+					kept[svc]++
+				}
+			}
+		}
+
+		assert.Equal(t, map[string]int{"s1": 2, "s2": 2}, kept)
+	})
+}
+
+func TestTailSampler(t *testing.T) {
+	feeder := dkio.NewMockedFeeder()
+	aga := NewAfterGather(WithFeeder(feeder))
+
+	ts := &TailSampler{
+		DecisionWait: 100 * time.Millisecond,
+		MaxTraces:    3,
+		Policies: []*TailSamplingPolicy{
+			{Type: PolicyStatusCode},
+			{Type: PolicyLatency, Threshold: time.Second},
+		},
+	}
+
+	_, err := ts.Init("test", aga)
+	require.NoError(t, err)
+	aga.AppendFilter(ts.Filter)
+
+	log := logger.DefaultSLogger("test")
+
+	// spans of trace 1 arrived in 2 parts, and the error span comes later.
+	_, skip := ts.Filter(log, DatakitTrace{newTailSpan("1", "s1", StatusOk, 0, 1, nil)})
+	assert.True(t, skip)
+
+	aga.Run("test", DatakitTraces{
+		{newTailSpan("1", "s1", StatusErr, 0, 1, nil)},
+		{newTailSpan("2", "s1", StatusOk, 0, 1, nil)},                         // dropped
+		{newTailSpan("3", "s1", StatusOk, 0, int64(2*time.Second/1000), nil)}, // slow
+	})
+
+	pts, err := feeder.NPoints(3, time.Second)
+	require.NoError(t, err)
+
+	var tids []string
+	for _, pt := range pts {
+		tids = append(tids, pt.Get(FieldTraceID).(string))
+	}
+	assert.ElementsMatch(t, []string{"1", "1", "3"}, tids)
+
+	t.Run("buffer-full", func(t *testing.T) {
+		ts := &TailSampler{
+			DecisionWait: time.Hour,
+			MaxTraces:    1,
+			Policies:     []*TailSamplingPolicy{{Type: PolicyStatusCode}},
+		}
+		_, err := ts.Init("test", aga)
+		require.NoError(t, err)
+
+		ts.Filter(log, DatakitTrace{newTailSpan("4", "s1", StatusErr, 0, 1, nil)})
+		ts.Filter(log, DatakitTrace{newTailSpan("5", "s1", StatusErr, 0, 1, nil)}) // trace 4 evicted
+
+		pts, err := feeder.NPoints(1, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "4", pts[0].Get(FieldTraceID))
+
+		ts.Close() // trace 5 decided on close
+
+		pts, err = feeder.NPoints(1, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "5", pts[0].Get(FieldTraceID))
+	})
+
+	ts.Close()
+}